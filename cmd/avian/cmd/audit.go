@@ -0,0 +1,301 @@
+/*
+Copyright © 2020 Avian Digital Forensics <sja@avian.dk>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/audit"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// auditCmd represents the audit command
+//
+// "avian audit"
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit-trail for the configuration- and control-actions",
+	Long: `The audit-trail records who applied, changed or deleted a runner,
+server, nms or webhook (and uploaded files) and when - with the source-ip
+and the changes compared with the state before the call.
+
+The audit-trail is append-only and can only be listed by admins.`,
+}
+
+// auditListCmd represents the list audit-trail command
+//
+// "avian audit list"
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the audit-trail (the latest first)",
+	Long: `Lists the audit-trail (the latest first). - For example:
+
+	avian audit list --resource runner --target case-1234 --since 168h
+	avian audit list --identity simon --since 2020-10-01 --format csv -o audit.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listAudit(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "could not list audit-trail from backend: %v\n", err)
+		}
+	},
+}
+
+var (
+	auditService  *avian.AuditService
+	auditIdentity string
+	auditMethod   string
+	auditResource string
+	auditTarget   string
+	auditSince    string
+	auditUntil    string
+	auditLimit    int64
+	auditFormat   string
+	auditOutput   string
+)
+
+func init() {
+	// Set the client for the audit-service
+	// (the url, api-key and CA-bundle are from the env or config)
+	auditService = avian.NewAuditService(newClient())
+
+	// Add the commands to the correct hierarchy
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditListCmd)
+	auditListCmd.Flags().StringVar(&auditIdentity, "identity", "", "only list the entries for the api-key")
+	auditListCmd.Flags().StringVar(&auditMethod, "method", "", "only list the entries for the method (e.g RunnerService.Delete)")
+	auditListCmd.Flags().StringVar(&auditResource, "resource", "", "only list the entries for the resource (runner, server, nms, webhook or file)")
+	auditListCmd.Flags().StringVar(&auditTarget, "target", "", "only list the entries for the target (e.g the name of the runner)")
+	auditListCmd.Flags().StringVar(&auditSince, "since", "", "only list the entries since the time (e.g 24h, 2020-10-01 or 2020-10-01T12:00:00+02:00)")
+	auditListCmd.Flags().StringVar(&auditUntil, "until", "", "only list the entries before the time (same format as --since)")
+	auditListCmd.Flags().Int64Var(&auditLimit, "limit", 100, "amount of entries to list")
+	auditListCmd.Flags().StringVar(&auditFormat, "format", "table", "format for the audit-trail (table, csv or json)")
+	auditListCmd.Flags().StringVarP(&auditOutput, "output", "o", "", "file to write the audit-trail to (stdout if not set)")
+}
+
+// listAudit lists the audit-trail in the format
+func listAudit(ctx context.Context) error {
+	request := avian.AuditListRequest{
+		Identity: auditIdentity,
+		Method:   auditMethod,
+		Resource: auditResource,
+		Target:   auditTarget,
+		Limit:    auditLimit,
+	}
+	var err error
+	if request.Since, err = parseTimeFlag(auditSince, time.Now()); err != nil {
+		return fmt.Errorf("invalid --since: %v", err)
+	}
+	if request.Until, err = parseTimeFlag(auditUntil, time.Now()); err != nil {
+		return fmt.Errorf("invalid --until: %v", err)
+	}
+
+	resp, err := auditService.List(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if auditOutput != "" {
+		file, err := os.Create(auditOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create file for the audit-trail: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch auditFormat {
+	case "table":
+		err = writeAuditTable(w, resp.Entries)
+	case "csv":
+		err = writeAuditCSV(w, resp.Entries)
+	case "json":
+		err = writeAuditJSON(w, resp.Entries)
+	default:
+		return fmt.Errorf("unknown format for the audit-trail: %s - use 'table', 'csv' or 'json'", auditFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the audit-trail: %v", err)
+	}
+
+	if auditOutput != "" {
+		fmt.Fprintf(os.Stdout, "Audit-trail (%d entries) has been written to %s\n", len(resp.Entries), auditOutput)
+	}
+	return nil
+}
+
+// parseTimeFlag parses a time (RFC3339 or a date) or a
+// duration back from now, nil if the value is empty
+func parseTimeFlag(value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		t := now.Add(-d)
+		return &t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%s - use a duration (24h), a date (2020-10-01) or RFC3339", value)
+	}
+	return &t, nil
+}
+
+// writeAuditTable writes the entries as a table
+// with a summary of the changes for each entry
+func writeAuditTable(w io.Writer, entries []avian.AuditEntry) error {
+	headers := table.Row{"Time", "Identity", "Source", "Method", "Target", "Status", "Changes"}
+	var body []table.Row
+	for _, e := range entries {
+		status := strconv.FormatInt(e.StatusCode, 10)
+		if e.Exception != "" {
+			status = fmt.Sprintf("%s - %s", status, e.Exception)
+		}
+		source := e.RemoteAddr
+		if e.ForwardedFor != "" {
+			source = fmt.Sprintf("%s (%s)", source, e.ForwardedFor)
+		}
+		body = append(body, table.Row{
+			e.Time.Local().Format("2006-01-02 15:04:05"),
+			e.Identity,
+			source,
+			e.Method,
+			e.Target,
+			status,
+			auditChanges(e.Diff, 5),
+		})
+	}
+	_, err := fmt.Fprintln(w, pretty.Format(headers, body))
+	return err
+}
+
+// auditChanges returns the changes in the diff
+// (one per line), at most max changes
+func auditChanges(diff string, max int) string {
+	var list []audit.Change
+	if err := json.Unmarshal([]byte(diff), &list); err != nil {
+		return ""
+	}
+	var lines []string
+	for i, c := range list {
+		if i == max {
+			lines = append(lines, fmt.Sprintf("... (%d more)", len(list)-max))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s -> %s", c.Path, auditValue(c.Old), auditValue(c.New)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// auditValue formats a value in a change
+func auditValue(v interface{}) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprint(v)
+}
+
+// writeAuditCSV writes the entries as csv (with a header)
+func writeAuditCSV(w io.Writer, entries []avian.AuditEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "time", "identity", "role", "remoteAddr", "forwardedFor", "method", "resource", "target", "statusCode", "exception", "diff", "request"})
+	for _, e := range entries {
+		cw.Write([]string{
+			strconv.FormatUint(uint64(e.ID), 10),
+			e.Time.Format(time.RFC3339Nano),
+			e.Identity,
+			e.Role,
+			e.RemoteAddr,
+			e.ForwardedFor,
+			e.Method,
+			e.Resource,
+			e.Target,
+			strconv.FormatInt(e.StatusCode, 10),
+			e.Exception,
+			e.Diff,
+			e.Request,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeAuditJSON writes the entries as a JSON-array,
+// with the diff and the request as JSON (not strings)
+func writeAuditJSON(w io.Writer, entries []avian.AuditEntry) error {
+	type entry struct {
+		ID           uint            `json:"id"`
+		Time         time.Time       `json:"time"`
+		Identity     string          `json:"identity"`
+		Role         string          `json:"role"`
+		RemoteAddr   string          `json:"remoteAddr"`
+		ForwardedFor string          `json:"forwardedFor,omitempty"`
+		Method       string          `json:"method"`
+		Resource     string          `json:"resource"`
+		Target       string          `json:"target"`
+		StatusCode   int64           `json:"statusCode"`
+		Exception    string          `json:"exception,omitempty"`
+		Diff         json.RawMessage `json:"diff,omitempty"`
+		Request      json.RawMessage `json:"request,omitempty"`
+	}
+	list := make([]entry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, entry{
+			ID:           e.ID,
+			Time:         e.Time,
+			Identity:     e.Identity,
+			Role:         e.Role,
+			RemoteAddr:   e.RemoteAddr,
+			ForwardedFor: e.ForwardedFor,
+			Method:       e.Method,
+			Resource:     e.Resource,
+			Target:       e.Target,
+			StatusCode:   e.StatusCode,
+			Exception:    e.Exception,
+			Diff:         rawJSON(e.Diff),
+			Request:      rawJSON(e.Request),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+// rawJSON returns s as raw JSON, or as a
+// JSON-string if it is not valid JSON
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	b, _ := json.Marshal(s)
+	return b
+}
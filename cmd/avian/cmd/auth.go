@@ -0,0 +1,201 @@
+/*
+Copyright © 2020 AVIAN DIGITAL FORENSICS <sja@avian.dk>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/audit"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jinzhu/gorm"
+	"github.com/spf13/cobra"
+)
+
+// authCmd represents the auth command
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Handles the api-keys for the service",
+}
+
+// authCreateKeyCmd represents the auth create-key command
+// (creates the key in the db for the service, so it
+// must run on the same machine as the service)
+//
+// "avian auth create-key <name>"
+var authCreateKeyCmd = &cobra.Command{
+	Use:   "create-key",
+	Short: "Creates an api-key with a role for the CLI (run on the machine for the service)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := createKey(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not create api-key: %v\n", err)
+		}
+	},
+}
+
+// authListKeysCmd represents the auth list-keys command
+//
+// "avian auth list-keys"
+var authListKeysCmd = &cobra.Command{
+	Use:   "list-keys",
+	Short: "Lists the api-keys and their roles (run on the machine for the service)",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listKeys(); err != nil {
+			fmt.Fprintf(os.Stderr, "could not list api-keys: %v\n", err)
+		}
+	},
+}
+
+// authDeleteKeyCmd represents the auth delete-key command
+//
+// "avian auth delete-key <name>"
+var authDeleteKeyCmd = &cobra.Command{
+	Use:   "delete-key",
+	Short: "Deletes an api-key (run on the machine for the service)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := deleteKey(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not delete api-key: %v\n", err)
+		}
+	},
+}
+
+var (
+	authDB   string // path to the sqlite-db for the service
+	authRole string // role for the api-key
+)
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authCreateKeyCmd)
+	authCmd.AddCommand(authListKeysCmd)
+	authCmd.AddCommand(authDeleteKeyCmd)
+	authCmd.PersistentFlags().StringVar(&authDB, "db", "avian.db", "path to sqlite database for the service")
+	authCreateKeyCmd.Flags().StringVar(&authRole, "role", auth.RoleViewer, "role for the api-key (viewer, investigator or admin)")
+}
+
+// openDB opens the db for the service
+func openDB() (*gorm.DB, error) {
+	if _, err := os.Stat(authDB); err != nil {
+		return nil, fmt.Errorf("cannot find database for the service: %s - use --db", authDB)
+	}
+	db, err := gorm.Open("sqlite3", authDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect database : %v", err)
+	}
+	if err := tables.Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// createKey creates an api-key and prints it
+func createKey(name string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	key, createErr := auth.Create(db, name, authRole)
+	state := &keyState{Name: name, Role: authRole}
+	err = recordKey(db, "auth.CreateKey", name, state, nil, state, createErr)
+	if createErr != nil {
+		return createErr
+	}
+
+	fmt.Fprintf(os.Stdout, "Api-key: %s (%s) has been created - it will not be shown again\n\n%s\n\n", name, authRole, key)
+	fmt.Fprintf(os.Stdout, "Set it as the env-variable %s or as apiKey in ~/.avian/config.yml\n", avian.KeyEnv)
+	return err
+}
+
+// listKeys lists the api-keys
+func listKeys() error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	keys, err := auth.List(db)
+	if err != nil {
+		return err
+	}
+
+	var headers = table.Row{"Name", "Role", "Key", "Created"}
+	var body []table.Row
+	for _, key := range keys {
+		body = append(body, table.Row{key.Name, key.Role, key.Prefix + "...", time.Unix(key.CTime, 0).Format("2006-01-02 15:04:05")})
+	}
+	fmt.Fprintf(os.Stdout, "%s\n", pretty.Format(headers, body))
+	return nil
+}
+
+// deleteKey deletes an api-key
+func deleteKey(name string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// the role for the key is kept in the audit-trail
+	keys, err := auth.List(db)
+	if err != nil {
+		return err
+	}
+	var before *keyState
+	for _, key := range keys {
+		if key.Name == name {
+			before = &keyState{Name: key.Name, Role: key.Role}
+		}
+	}
+
+	deleteErr := auth.Delete(db, name)
+	err = recordKey(db, "auth.DeleteKey", name, keyState{Name: name}, before, nil, deleteErr)
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	fmt.Fprintf(os.Stdout, "Api-key: %s has been deleted", name)
+	return err
+}
+
+// keyState is an api-key in the audit-trail
+type keyState struct {
+	Name string `json:"name"`
+	Role string `json:"role,omitempty"`
+}
+
+// recordKey records the change to the api-keys in the audit-trail, the
+// commands uses the db for the service - so they are not recorded by the api
+func recordKey(db *gorm.DB, method, name string, request interface{}, before, after *keyState, changeErr error) error {
+	entry, err := audit.Local(method, audit.ResourceKey, name, request, before, after, changeErr)
+	if err != nil {
+		return err
+	}
+	if err := audit.Record(db, &entry); err != nil {
+		return fmt.Errorf("cannot record the change for api-key: %s in the audit-trail - %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2020 AVIAN DIGITAL FORENSICS <sja@avian.dk>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// tlsEnv is the env-variable to use https for the service
+const tlsEnv = "AVIAN_TLS"
+
+// cliConfig is the config for the CLI (~/.avian/config.yml)
+type cliConfig struct {
+	APIKey   string `yaml:"apiKey"`
+	CABundle string `yaml:"caBundle"`
+	TLS      bool   `yaml:"tls"`
+}
+
+// serviceURL returns the url for the API (where the avian service is listening at)
+func serviceURL(cfg cliConfig) string {
+	address := os.Getenv("AVIAN_ADDRESS")
+	if address == "" {
+		ip, err := utils.GetIPAddress()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot get ip-address: %v", err)
+			os.Exit(1)
+		}
+		address = ip
+	}
+
+	port := os.Getenv("AVIAN_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	scheme := "http"
+	if cfg.TLS || cfg.CABundle != "" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%s/oto/", scheme, address, port)
+}
+
+// newClient returns a client for the service with the api-key and the
+// CA-bundle from the env-variables or ~/.avian/config.yml
+func newClient() *avian.Client {
+	cfg := loadConfig()
+	client := avian.NewWithKey(serviceURL(cfg), cfg.APIKey)
+	if cfg.CABundle == "" {
+		return client
+	}
+
+	b, err := ioutil.ReadFile(cfg.CABundle)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot read CA-bundle: %v\n", err)
+		os.Exit(1)
+	}
+	if err := client.SetCA(b); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot use CA-bundle: %s - %v\n", cfg.CABundle, err)
+		os.Exit(1)
+	}
+	return client
+}
+
+// loadConfig returns the config for the CLI,
+// the env-variables overrides ~/.avian/config.yml
+func loadConfig() cliConfig {
+	var cfg cliConfig
+	if home, err := os.UserHomeDir(); err == nil {
+		if b, err := ioutil.ReadFile(filepath.Join(home, ".avian", "config.yml")); err == nil {
+			if err := yaml.Unmarshal(b, &cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Cannot parse ~/.avian/config.yml: %v\n", err)
+			}
+		}
+	}
+
+	if key := os.Getenv(avian.KeyEnv); key != "" {
+		cfg.APIKey = key
+	}
+	if bundle := os.Getenv(avian.CABundleEnv); bundle != "" {
+		cfg.CABundle = bundle
+	}
+	if v := os.Getenv(tlsEnv); v != "" {
+		cfg.TLS, _ = strconv.ParseBool(v)
+	}
+	return cfg
+}
@@ -1,10 +1,13 @@
 package heartbeat
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/metrics"
 	"github.com/avian-digital-forensics/auto-processing/pkg/services"
 	"github.com/jinzhu/gorm"
 	"go.uber.org/zap"
@@ -24,44 +27,161 @@ func New(r services.RunnerService, logger *zap.Logger) Service {
 	return Service{r, 2 * time.Minute, r.DB, logger}
 }
 
-// Beat will check if there is any unhealthy runners
-// and set those to timed out
+// Beat will check if there is any unhealthy or overrunning
+// runners and set those to timed out
 func (s Service) Beat() {
 	for {
-		// get the unhealthy runners
-		var runners []api.Runner
-		var lastCheck = time.Now().Add(-s.pause)
-		var query = s.db.Where("active = ? AND healthy_at < ?", true, lastCheck)
-		if err := query.Find(&runners).Error; err != nil {
-			s.logger.Error("Failed to fetch runners", zap.String("exception", err.Error()))
+		s.Check()
+		time.Sleep(s.pause)
+	}
+}
+
+// Check sets the unhealthy and the overrunning runners to timed out
+func (s Service) Check() {
+	// get the unhealthy runners
+	var runners []api.Runner
+	var lastCheck = time.Now().Add(-s.pause)
+	var query = s.db.Where("active = ? AND healthy_at < ?", true, lastCheck)
+	if err := query.Find(&runners).Error; err != nil {
+		s.logger.Error("Failed to fetch runners", zap.String("exception", err.Error()))
+	}
+	s.logger.Info("Got unhealthy runners from db", zap.Int("amount", len(runners)))
+
+	// iterate over the unhealthy runnres
+	for _, runner := range runners {
+		reason := "no heartbeat from the runner"
+		if runner.HealthyAt != nil {
+			reason += " since " + runner.HealthyAt.Format(time.RFC3339)
 		}
-		s.logger.Info("Got unhealthy runners from db", zap.Int("amount", len(runners)))
-
-		// iterate over the unhealthy runnres
-		for _, runner := range runners {
-			// set status to timeout and active to false
-			runner.Status = avian.StatusTimeout
-			runner.Active = false
-			if err := s.db.Save(&runner).Error; err != nil {
-				s.logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
-			}
+		metrics.HeartbeatTimeouts.Inc(metrics.ReasonHeartbeat)
+		s.timeout(runner, reason)
+	}
 
-			// Set servers activity
-			if err := s.runnersvc.SetServerActivity(runner, false); err != nil {
-				s.logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
-			}
+	// check the runners that have run for too long
+	s.overruns()
+}
+
+// overruns will check if there is any active runners that have run
+// longer than their maxRuntime or have a stage that have run longer
+// than its timeout, stop those and set them to timed out
+func (s Service) overruns() {
+	var runners []api.Runner
+	if err := s.db.Where("active = ? AND status = ?", true, avian.StatusRunning).Find(&runners).Error; err != nil {
+		s.logger.Error("Failed to fetch running runners", zap.String("exception", err.Error()))
+		return
+	}
+
+	now := time.Now()
+	for _, r := range runners {
+		logger := s.logger.With(zap.String("runner", r.Name))
+		resp, err := s.runnersvc.Get(context.Background(), api.RunnerGetRequest{Name: r.Name})
+		if err != nil {
+			logger.Error("Failed to get runner", zap.String("exception", err.Error()))
+			continue
+		}
+		runner := resp.Runner
 
-			// update nms information
-			if err := s.runnersvc.ResetNms(runner); err != nil {
-				s.logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
+		// check the runners max-runtime
+		overrun := false
+		var kind, reason string
+		if len(runner.MaxRuntime) > 0 && runner.StartedAt != nil {
+			maxRuntime, err := time.ParseDuration(runner.MaxRuntime)
+			if err == nil && now.Sub(*runner.StartedAt) > maxRuntime {
+				logger.Info("Runner has exceeded its max-runtime",
+					zap.String("max_runtime", runner.MaxRuntime),
+					zap.Time("started_at", *runner.StartedAt),
+				)
+				overrun = true
+				kind = metrics.ReasonMaxRuntime
+				reason = fmt.Sprintf("runner has exceeded its max-runtime: %s", runner.MaxRuntime)
 			}
+		}
 
-			// remove the script from the remote machine
-			if err := s.runnersvc.RemoveScript(runner); err != nil {
-				s.logger.Error("Cannot remove script for runner", zap.String("exception", err.Error()))
+		// check the timeout for the running stages
+		for _, stage := range runner.Stages {
+			if avian.StageState(stage) != avian.StatusRunning {
+				continue
+			}
+			if len(stage.Timeout) == 0 || stage.StartedAt == nil {
+				if overrun {
+					s.stageTimeout(runner, stage)
+				}
+				continue
+			}
+			timeout, err := time.ParseDuration(stage.Timeout)
+			if err != nil {
+				continue
+			}
+			if overrun || now.Sub(*stage.StartedAt) > timeout {
+				logger.Info("Stage has exceeded its timeout",
+					zap.String("stage", avian.Name(stage)),
+					zap.Int("stage_id", int(stage.ID)),
+					zap.String("timeout", stage.Timeout),
+					zap.Time("started_at", *stage.StartedAt),
+				)
+				s.stageTimeout(runner, stage)
+				if !overrun {
+					kind = metrics.ReasonStageTimeout
+					reason = fmt.Sprintf("stage: %s has exceeded its timeout: %s", avian.Name(stage), stage.Timeout)
+				}
+				overrun = true
 			}
 		}
 
-		time.Sleep(s.pause)
+		if !overrun {
+			continue
+		}
+
+		// stop the nuix-process on the remote machine
+		if err := s.runnersvc.StopRunner(runner); err != nil {
+			logger.Error("Cannot stop runner", zap.String("exception", err.Error()))
+		}
+		metrics.HeartbeatTimeouts.Inc(kind)
+		s.timeout(runner, reason)
+	}
+}
+
+// stageTimeout sets the stage to timed out
+func (s Service) stageTimeout(runner api.Runner, stage *api.Stage) {
+	avian.SetStatusTimeout(stage)
+	if err := s.db.Save(stage).Error; err != nil {
+		s.logger.Error("Cannot save the timed out stage",
+			zap.String("runner", runner.Name),
+			zap.Int("stage_id", int(stage.ID)),
+			zap.String("exception", err.Error()),
+		)
+	}
+	s.runnersvc.PublishStage(runner.Name, stage)
+}
+
+// timeout sets the runner to timed out (with the reason as its
+// exception) and releases its server, nms and script
+func (s Service) timeout(runner api.Runner, reason string) {
+	// set status to timeout and active to false
+	runner.Status = avian.StatusTimeout
+	runner.Active = false
+	runner.Exception = reason
+	if err := s.db.Model(&api.Runner{}).Where("id = ?", runner.ID).Updates(map[string]interface{}{
+		"status":    runner.Status,
+		"active":    runner.Active,
+		"exception": runner.Exception,
+	}).Error; err != nil {
+		s.logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
+	}
+	s.runnersvc.PublishRunner(runner)
+
+	// Set servers activity
+	if err := s.runnersvc.SetServerActivity(runner, false); err != nil {
+		s.logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
+	}
+
+	// update nms information
+	if err := s.runnersvc.ResetNms(runner); err != nil {
+		s.logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
+	}
+
+	// remove the script from the remote machine
+	if err := s.runnersvc.RemoveScript(runner); err != nil {
+		s.logger.Error("Cannot remove script for runner", zap.String("exception", err.Error()))
 	}
 }
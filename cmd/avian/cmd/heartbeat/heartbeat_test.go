@@ -0,0 +1,146 @@
+package heartbeat_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/cmd/avian/cmd/heartbeat"
+	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/logging"
+	"github.com/avian-digital-forensics/auto-processing/pkg/pwsh"
+	"github.com/avian-digital-forensics/auto-processing/pkg/services"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+// fakeShell records the stopped processes instead of
+// connecting to the remote servers with powershell
+type fakeShell struct {
+	mu      sync.Mutex
+	stopped []string
+}
+
+func (f *fakeShell) Close() error { return nil }
+
+func (f *fakeShell) NewSession(host, username, password string) (pwsh.Session, error) {
+	return fakeSession{f}, nil
+}
+
+func (f *fakeShell) NewSessionCredSSP(host, username, password string) (pwsh.Session, error) {
+	return fakeSession{f}, nil
+}
+
+type fakeSession struct {
+	*fakeShell
+}
+
+func (f fakeSession) CopyItemFromHost(src, dst string) error          { return nil }
+func (f fakeSession) CheckPath(path string) error                     { return nil }
+func (f fakeSession) CreateFile(path, name string, data []byte) error { return nil }
+func (f fakeSession) Echo(arg string) (string, error)                 { return arg, nil }
+func (f fakeSession) EnableCredSSP() error                            { return nil }
+func (f fakeSession) RemoveItem(path string) error                    { return nil }
+func (f fakeSession) Run(program string, args ...string) error        { return nil }
+func (f fakeSession) SetEnv(variable, arg string) error               { return nil }
+func (f fakeSession) SetLocation(path string) error                   { return nil }
+
+func (f fakeSession) StopProcess(program, match string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopped = append(f.stopped, match)
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	is := is.New(t)
+	db, err := gorm.Open("sqlite3", ":memory:")
+	is.NoErr(err)
+	defer db.Close()
+	is.NoErr(tables.Migrate(db))
+
+	logPath, err := ioutil.TempDir("", "avian-logs")
+	is.NoErr(err)
+	defer os.RemoveAll(logPath)
+
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01", NuixPath: `C:\Nuix`, Active: true}).Error)
+	is.NoErr(db.Create(&api.Nms{Address: "nms01", Workers: 8, InUse: 6, Licences: []api.Licence{{Type: "enterprise-workstation", Amount: 3, InUse: 3}}}).Error)
+
+	now := time.Now()
+	started := func(ago time.Duration) *time.Time {
+		t := now.Add(-ago)
+		return &t
+	}
+	running := func(name, maxRuntime string, stages ...*api.Stage) api.Runner {
+		runner := api.Runner{
+			Name:       name,
+			Hostname:   "dev01",
+			Nms:        "nms01",
+			Licence:    "enterprise-workstation",
+			Workers:    2,
+			Active:     true,
+			Status:     avian.StatusRunning,
+			HealthyAt:  &now,
+			StartedAt:  started(2 * time.Hour),
+			MaxRuntime: maxRuntime,
+			Stages:     stages,
+		}
+		is.NoErr(db.Create(&runner).Error)
+		return runner
+	}
+
+	// the max-runtime is exceeded - the running stage has no timeout
+	overrun := running("overrun", "1h", &api.Stage{StartedAt: started(time.Hour), Ocr: &api.Ocr{Status: avian.StatusRunning}})
+	// the timeout is exceeded for the running stage
+	stageTimeout := running("stage-timeout", "",
+		&api.Stage{Timeout: "30m", StartedAt: started(time.Hour), Ocr: &api.Ocr{Status: avian.StatusRunning}},
+		&api.Stage{Timeout: "30m", Exclude: &api.Exclude{Status: avian.StatusWaiting}},
+	)
+	// within the max-runtime and the timeout
+	healthy := running("healthy", "4h", &api.Stage{Timeout: "2h", StartedAt: started(time.Hour), Ocr: &api.Ocr{Status: avian.StatusRunning}})
+
+	shell := &fakeShell{}
+	runnersvc := services.NewRunnerService(db, shell, zap.NewNop(), logging.New(logPath), "", logPath, ruby.DefaultTemplates(), events.NewBroker(10))
+	heartbeat.New(runnersvc, zap.NewNop()).Check()
+
+	get := func(runner api.Runner) api.Runner {
+		var r api.Runner
+		is.NoErr(tables.PreloadStages(db, "Stages.").First(&r, runner.ID).Error)
+		return r
+	}
+
+	r := get(overrun)
+	is.Equal(r.Status, avian.StatusTimeout)
+	is.True(!r.Active)
+	is.Equal(r.Exception, "runner has exceeded its max-runtime: 1h")
+	is.Equal(avian.StageState(r.Stages[0]), avian.StatusTimeout) // the running stage is timed out with the runner
+
+	r = get(stageTimeout)
+	is.Equal(r.Status, avian.StatusTimeout)
+	is.True(strings.HasSuffix(r.Exception, "has exceeded its timeout: 30m"))
+	is.Equal(avian.StageState(r.Stages[0]), avian.StatusTimeout)
+	is.Equal(avian.StageState(r.Stages[1]), avian.StatusWaiting)
+
+	r = get(healthy)
+	is.Equal(r.Status, avian.StatusRunning)
+	is.True(r.Active)
+	is.Equal(avian.StageState(r.Stages[0]), avian.StatusRunning)
+
+	// the nuix-processes are stopped for the timed out runners
+	is.Equal(shell.stopped, []string{"overrun.gen.rb", "stage-timeout.gen.rb"})
+
+	// the licences and the workers are released for the timed out runners
+	var nms api.Nms
+	is.NoErr(db.Preload("Licences").First(&nms, "address = ?", "nms01").Error)
+	is.Equal(nms.InUse, int64(2))
+	is.Equal(nms.Licences[0].InUse, int64(1))
+}
@@ -22,8 +22,8 @@ import (
 
 	"github.com/avian-digital-forensics/auto-processing/configs"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/nms"
 	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
-	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
@@ -57,10 +57,16 @@ var nmsApplyCmd = &cobra.Command{
 //
 // "avian nms licences"
 var nmsLicencesCmd = &cobra.Command{
-	Use:   "licences",
+	Use:   "licences [address]",
 	Short: "List licences for the specified nms (specify by address)",
+	Long: `Lists the licence-pools and the leases in the NMS next to the licences
+configured and reserved by the runners in the service - and flags the
+mismatches. Every NMS is listed if the address is not specified.
+
+	avian nms licences license.avian.dk --leases`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := licencesNms(context.Background()); err != nil {
+		if err := licencesNms(context.Background(), args); err != nil {
 			fmt.Fprintf(os.Stderr, "could not list licences from backend: %v\n", err)
 		}
 	},
@@ -72,6 +78,11 @@ var nmsLicencesCmd = &cobra.Command{
 var nmsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List nms-servers from the backend",
+	Long: `List the nms-servers, 100 at a time - use the printed --cursor to list the
+next page (or --all to list every page). - For example:
+
+	avian nms list --licence enterprise-workstation
+	avian nms list --address '10.0.*' --sort address`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := listNms(context.Background()); err != nil {
 			fmt.Fprintf(os.Stderr, "could not list servers from backend: %v\n", err)
@@ -79,36 +90,69 @@ var nmsListCmd = &cobra.Command{
 	},
 }
 
-var nmsService *avian.NmsService
+// nmsDescribeCmd represents the describe command
+//
+// "avian nms describe <address>"
+var nmsDescribeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Describes the specified nms (specified by address) with its licences and the runners that uses it",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := describeNms(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not describe nms from backend: %v\n", err)
+		}
+	},
+}
 
-func init() {
-	// Get the address for the API (where the avian service is listening at)
-	address := os.Getenv("AVIAN_ADDRESS")
-	if address == "" {
-		ip, err := utils.GetIPAddress()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Cannot get ip-address: %v", err)
-			os.Exit(1)
+// nmsDeleteCmd represents the delete command
+//
+// "avian nms delete <address>"
+var nmsDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Deletes the specified nms (specified by address) and its licences",
+	Long: `Deletes the specified nms (specified by address) and its licences. The
+delete is refused while unfinished runners uses the nms - unless --force is used.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := deleteNms(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not delete nms from backend: %v\n", err)
 		}
-		address = ip
-	}
+	},
+}
 
-	// Get the port for the API (where the avian service is listening at)
-	port := os.Getenv("AVIAN_PORT")
-	if port == "" {
-		port = "8080"
-	}
+var (
+	nmsService     *avian.NmsService
+	nmsLeases      bool
+	forceDeleteNms bool
+	nmsRunners     int64
 
-	// create the uri for the service
-	url := fmt.Sprintf("http://%s:%s/oto/", address, port)
+	nmsListAddress string
+	nmsListLicence string
+	nmsListSort    string
+	nmsListLimit   int64
+	nmsListCursor  string
+	nmsListAll     bool
+)
 
+func init() {
 	// set the client to the NmsService to speak to the API
-	nmsService = avian.NewNmsService(avian.New(url, ""))
+	nmsService = avian.NewNmsService(newClient())
 
 	rootCmd.AddCommand(nmsCmd)
 	nmsCmd.AddCommand(nmsApplyCmd)
 	nmsCmd.AddCommand(nmsListCmd)
+	nmsListCmd.Flags().StringVar(&nmsListAddress, "address", "", "only list the nms-servers with addresses matching the pattern (* matches any characters)")
+	nmsListCmd.Flags().StringVar(&nmsListLicence, "licence", "", "only list the nms-servers with the licencetype")
+	nmsListCmd.Flags().StringVar(&nmsListSort, "sort", "id", "field to sort by (id, address or created), prefix with - for descending")
+	nmsListCmd.Flags().Int64Var(&nmsListLimit, "limit", 100, "amount of nms-servers to list")
+	nmsListCmd.Flags().StringVar(&nmsListCursor, "cursor", "", "cursor for the next page (printed after the list)")
+	nmsListCmd.Flags().BoolVar(&nmsListAll, "all", false, "list every page")
 	nmsCmd.AddCommand(nmsLicencesCmd)
+	nmsLicencesCmd.Flags().BoolVar(&nmsLeases, "leases", false, "list the leases in the NMS")
+	nmsCmd.AddCommand(nmsDescribeCmd)
+	nmsDescribeCmd.Flags().Int64Var(&nmsRunners, "runners", 20, "amount of runners to list (the latest first)")
+	nmsCmd.AddCommand(nmsDeleteCmd)
+	nmsDeleteCmd.Flags().BoolVar(&forceDeleteNms, "force", false, "force deleting an nms used by unfinished runners")
 }
 
 // applyNms applies the specified nms-servers in the yaml-file
@@ -138,9 +182,26 @@ func applyNms(ctx context.Context, path string) error {
 
 // listNms lists all the nms-servers from the service
 func listNms(ctx context.Context) error {
-	resp, err := nmsService.List(ctx, avian.NmsListRequest{})
-	if err != nil {
-		return err
+	request := avian.NmsListRequest{
+		Address: nmsListAddress,
+		Licence: nmsListLicence,
+		Sort:    nmsListSort,
+		Limit:   nmsListLimit,
+		Cursor:  nmsListCursor,
+	}
+
+	var resp avian.NmsListResponse
+	for {
+		page, err := nmsService.List(ctx, request)
+		if err != nil {
+			return err
+		}
+		resp.Nms = append(resp.Nms, page.Nms...)
+		resp.NextCursor = page.NextCursor
+		if !nmsListAll || page.NextCursor == "" {
+			break
+		}
+		request.Cursor = page.NextCursor
 	}
 
 	var headers table.Row
@@ -151,25 +212,134 @@ func listNms(ctx context.Context) error {
 	}
 
 	fmt.Println(pretty.Format(headers, body))
+	if resp.NextCursor != "" {
+		fmt.Fprintf(os.Stderr, "more nms-servers - list the next page with: --cursor %s\n", resp.NextCursor)
+	}
 	return nil
 }
 
-// licencesNms should list all the licences from the service
-func licencesNms(ctx context.Context) error {
-	resp, err := nmsService.List(ctx, avian.NmsListRequest{})
-	if err != nil {
-		return err
+// licencesNms lists the licences in the NMS compared
+// with the licences in the service for the addresses
+// (every nms if no address is specified)
+func licencesNms(ctx context.Context, addresses []string) error {
+	if len(addresses) == 0 {
+		var request avian.NmsListRequest
+		for {
+			page, err := nmsService.List(ctx, request)
+			if err != nil {
+				return err
+			}
+			for _, s := range page.Nms {
+				addresses = append(addresses, s.Address)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			request.Cursor = page.NextCursor
+		}
 	}
 
-	var headers table.Row
+	var list []*avian.NmsListLicencesResponse
+	for _, address := range addresses {
+		resp, err := nmsService.ListLicences(ctx, avian.NmsListLicencesRequest{Address: address})
+		if err != nil {
+			return err
+		}
+		list = append(list, resp)
+	}
+
+	headers := table.Row{"Address", "Type", "Configured", "Reserved", "NMS pool", "NMS leased", "Mismatch"}
 	var body []table.Row
-	headers = table.Row{"ID", "Address", "Type", "Licences", "In-Use"}
-	for _, s := range resp.Nms {
-		for _, lic := range s.Licences {
-			body = append(body, table.Row{lic.ID, s.Address, lic.Type, lic.Amount, lic.InUse})
+	for _, resp := range list {
+		if resp.Exception != "" {
+			fmt.Fprintf(os.Stderr, "WARNING: could not query NMS %s - listing the licences in the service: %s\n", resp.Nms.Address, resp.Exception)
+			for _, lic := range resp.Licences {
+				body = append(body, table.Row{resp.Nms.Address, lic.Type, lic.Amount, lic.InUse, "-", "-", "-"})
+			}
+			continue
+		}
+		for _, u := range resp.Usage {
+			body = append(body, table.Row{resp.Nms.Address, u.Type, u.Amount, u.InUse, u.Pool, u.Leased, u.Mismatch})
+		}
+	}
+	fmt.Println(pretty.Format(headers, body))
+
+	if !nmsLeases {
+		return nil
+	}
+	headers = table.Row{"Address", "Type", "Workers", "User", "Host", "Leased at"}
+	body = nil
+	for _, resp := range list {
+		for _, lease := range resp.Leases {
+			var leasedAt string
+			if lease.LeasedAt != nil {
+				leasedAt = lease.LeasedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			body = append(body, table.Row{resp.Nms.Address, lease.Type, lease.Workers, lease.User, lease.Host, leasedAt})
 		}
 	}
+	fmt.Println(pretty.Format(headers, body))
+	return nil
+}
+
+// describeNms prints the nms with its
+// licences and the runners that uses it
+func describeNms(ctx context.Context, address string) error {
+	resp, err := nmsService.Get(ctx, avian.NmsGetRequest{Address: address, Limit: nmsRunners})
+	if err != nil {
+		return err
+	}
+
+	s := resp.Nms
+	fmt.Fprintf(os.Stdout, "Address:  %s\n", s.Address)
+	fmt.Fprintf(os.Stdout, "Port:     %d\n", s.Port)
+	fmt.Fprintf(os.Stdout, "Username: %s\n", s.Username)
+	fmt.Fprintf(os.Stdout, "Relay:    %t\n", s.IsRelay)
+	fmt.Fprintf(os.Stdout, "Workers:  %d/%d in use\n", s.InUse, s.Workers)
+	fmt.Fprintf(os.Stdout, "Auth:     %s\n", defaultString(s.Auth, nms.AuthBasic))
+	fmt.Fprintf(os.Stdout, "Pools:    %s\n", defaultString(s.PoolsPath, nms.DefaultPoolsPath))
+	fmt.Fprintf(os.Stdout, "Leases:   %s\n", defaultString(s.LeasesPath, nms.DefaultLeasesPath))
+	fmt.Fprintf(os.Stdout, "TLS:      %s\n", formatNmsTLS(s))
 
+	headers := table.Row{"Type", "Licences", "In-Use"}
+	var body []table.Row
+	for _, lic := range s.Licences {
+		body = append(body, table.Row{lic.Type, lic.Amount, lic.InUse})
+	}
 	fmt.Println(pretty.Format(headers, body))
+
+	fmt.Fprintf(os.Stdout, "Runners:  %s\n", formatRunnerTotal(resp.Runners, resp.TotalRunners))
+	if len(resp.Runners) > 0 {
+		fmt.Println(formatLinkedRunners(resp.Runners))
+	}
+	return nil
+}
+
+// formatNmsTLS formats how the certificate
+// for the nms is verified
+func formatNmsTLS(s avian.Nms) string {
+	if s.Insecure {
+		return "insecure (the certificate is not verified)"
+	}
+	if s.Fingerprint != "" {
+		return "pinned (sha256: " + s.Fingerprint + ")"
+	}
+	return "verified"
+}
+
+// deleteNms deletes the specified nms
+func deleteNms(ctx context.Context, address string) error {
+	if _, err := nmsService.Delete(ctx, avian.NmsDeleteRequest{Address: address, Force: forceDeleteNms}); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "NMS: %s has been deleted\n", address)
 	return nil
 }
+
+// defaultString returns the value, or the default (marked) if it is not set
+func defaultString(value, def string) string {
+	if value == "" {
+		return def + " (default)"
+	}
+	return value
+}
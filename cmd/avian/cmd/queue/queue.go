@@ -4,16 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
-	"github.com/avian-digital-forensics/auto-processing/pkg/inapp"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
 	"github.com/avian-digital-forensics/auto-processing/pkg/pwsh"
-	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
+	"github.com/avian-digital-forensics/auto-processing/pkg/services"
+	"github.com/avian-digital-forensics/auto-processing/pkg/tracing"
 	"go.uber.org/zap"
 
 	"github.com/jinzhu/gorm"
@@ -32,39 +33,57 @@ type Queue struct {
 	// shell for remote connections
 	shell pwsh.Powershell
 
+	// runnersvc sets the runners that cannot
+	// be started to failed (without an api-key)
+	runnersvc services.RunnerService
+
 	// uri for the service to speak to
 	// for the scripts that the queue
 	// will generate
 	uri string
 
+	// templates for the scripts
+	// that the queue will generate
+	templates *ruby.Templates
+
+	// ca is the CA-certificate (PEM) for the service
+	// when it uses https, it is created next to the
+	// scripts so they can verify the service
+	ca []byte
+
 	// logger for the service
 	logger *zap.Logger
 }
 
 // New returns a new queue
-func New(db *gorm.DB, shell pwsh.Powershell, uri string, logger *zap.Logger) Queue {
-	return Queue{db: db, shell: shell, uri: uri, logger: logger}
+func New(db *gorm.DB, shell pwsh.Powershell, runnersvc services.RunnerService, uri string, templates *ruby.Templates, ca []byte, logger *zap.Logger) Queue {
+	return Queue{db: db, shell: shell, runnersvc: runnersvc, uri: uri, templates: templates, ca: ca, logger: logger}
 }
 
 // Start the queue
 func (q *Queue) Start() {
 	q.logger.Info("Queue started")
 	for {
-		q.loop()
+		q.Poll()
 		time.Sleep(time.Duration(sleepMinutes * time.Minute))
 	}
 }
 
-// loop will get all the relevant runners
+// Poll will get all the relevant runners
 // and iterate over them, and try to run each
-func (q *Queue) loop() {
+func (q *Queue) Poll() {
+	ctx, span := tracing.Start(context.Background(), "Queue.loop")
+	defer span.End()
+
 	q.logger.Debug("Getting runners from queue")
 	runners, err := getRunners(q.db)
 	if err != nil {
+		span.RecordError(err)
 		q.logger.Error("cannot get runners", zap.String("exception", err.Error()))
 		return
 	}
 	q.logger.Debug("Found runners from Queue", zap.Int("amount", len(runners)))
+	span.SetAttributes(tracing.Int("runners", int64(len(runners))))
 
 	// loop over the runners
 	for _, runner := range runners {
@@ -78,24 +97,6 @@ func (q *Queue) loop() {
 			continue
 		}
 
-		// Check if the runner has an inApp-stage
-		for _, s := range runner.Stages {
-			if s.InApp != nil {
-				// Decode the settings for the inApp-stage
-				var settings inapp.Settings
-				if err := inapp.Config(s.InApp.Config, &settings); err != nil {
-					q.logger.Error("Failed to decode inapp config", zap.String("exception", err.Error()))
-					// FIXME: currently just logging the error - maybe we should return the error(?)
-					continue
-				}
-
-				//  No neet to set the root directory in the script settings, as it is done in the ruby script.
-
-				// set the settings to the inApp-stage
-				s.InApp.Settings = settings
-			}
-		}
-
 		// Check to see if licence is active
 		nms, err := activeLicence(q.db, runner.Nms, runner.Licence, runner.Workers)
 		if err != nil {
@@ -123,7 +124,7 @@ func (q *Queue) loop() {
 			zap.Int("workers", int(runner.Workers)),
 		)
 
-		go run.handle(run.start())
+		go run.handle(run.start(ctx))
 	}
 }
 
@@ -135,6 +136,9 @@ type run struct {
 	server  *api.Server
 	nms     *api.Nms
 	session pwsh.Session
+
+	// key for the runner-script (scoped to the runner)
+	key string
 }
 
 // newRun creates a new run
@@ -164,13 +168,14 @@ func (r *run) setActive() error {
 		return fmt.Errorf("Failed to set server to active: %v", err)
 	}
 
-	// Set new values to NMS
-	r.nms.InUse = r.runner.Workers
-	for _, lic := range r.nms.Licences {
+	// Set new values to NMS - the licence in the nms is
+	// updated, since the licences are saved again with the nms
+	r.nms.InUse += r.runner.Workers
+	for i := range r.nms.Licences {
+		lic := &r.nms.Licences[i]
 		if lic.Type == r.runner.Licence {
-			// FIXME: this doesnt work accordingly
 			lic.InUse++
-			if err := db.Save(&lic).Error; err != nil {
+			if err := db.Save(lic).Error; err != nil {
 				return fmt.Errorf("Failed to update licence: %s %s : %v", r.nms.Address, lic.Type, err)
 			}
 		}
@@ -184,23 +189,65 @@ func (r *run) setActive() error {
 	return nil
 }
 
-// start the run
-func (r *run) start() error {
+// step runs a step for the run in a child-span
+func step(ctx context.Context, name string, fn func() error, attrs ...tracing.Attribute) error {
+	_, span := tracing.Start(ctx, name, attrs...)
+	defer span.End()
+	err := fn()
+	span.RecordError(err)
+	return err
+}
+
+// start the run, the callbacks from the runner-script
+// joins the trace for the run (see tracing.TraceParentEnv)
+func (r *run) start(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "run.start",
+		tracing.String("runner", r.runner.Name),
+		tracing.String("server", r.server.Hostname),
+		tracing.String("nms", r.nms.Address),
+		tracing.String("licence", r.runner.Licence),
+		tracing.Int("workers", r.runner.Workers),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	logger := r.queue.logger.With(
 		zap.String("runner", r.runner.Name),
 		zap.String("server", r.server.Hostname),
 	)
+	if span != nil {
+		logger = logger.With(zap.String("trace_id", span.TraceID()))
+	}
+
+	// Create the api-key for the runner-script
+	key, err := auth.CreateRunnerKey(r.queue.db, r.runner.ID, r.runner.Name)
+	if err != nil {
+		return err
+	}
+	r.key = key
+
 	// Generate the ruby-script for the runner
 	logger.Info("Generating script for runner")
-	script, err := ruby.Generate(r.queue.uri, utils.RemoteScriptDir(r.server.NuixPath, r.server.AvianScripts), *r.runner)
+	script, err := r.queue.templates.Generate(r.queue.uri, r.runner.Name)
 	if err != nil {
 		return fmt.Errorf("failed to generate script for runner: %s - %v", r.runner.Name, err)
 	}
-	logger.Debug("Script has been generated")
+	logger.Debug("Script has been generated", zap.String("templates", r.queue.templates.Version))
+
+	// Record the version of the templates for the run
+	if err := r.queue.db.Model(r.runner).Update("template_version", r.queue.templates.Version).Error; err != nil {
+		return fmt.Errorf("failed to set template-version for runner: %s - %v", r.runner.Name, err)
+	}
 
 	// Create powershell-connection
 	logger.Info("Starting powershell-connection for runner")
-	session, err := r.queue.shell.NewSessionCredSSP(r.server.Hostname, r.server.Username, r.server.Password)
+	var session pwsh.Session
+	err = step(ctx, "pwsh.NewSessionCredSSP", func() (err error) {
+		session, err = r.queue.shell.NewSessionCredSSP(r.server.Hostname, r.server.Username, r.server.Password)
+		return err
+	}, tracing.String("server", r.server.Hostname))
 	if err != nil {
 		logger.Error("Failed to create remote-client for powershell", zap.String("exception", err.Error()))
 		return fmt.Errorf("failed to create remote-client for powershell: %v", err)
@@ -212,19 +259,44 @@ func (r *run) start() error {
 	logger.Debug("Powershell-client has been created for runner")
 
 	// Check for case-locks
-	if err := removeCaseLocks(session, logger, r.runner.CaseSettings); err != nil {
+	if err := step(ctx, "pwsh.RemoveCaseLocks", func() error {
+		return removeCaseLocks(session, logger, r.runner.CaseSettings)
+	}); err != nil {
 		session.Close()
 		return err
 	}
 
+	// Set the api-key for the runner-script as an env-variable
+	if err := step(ctx, "pwsh.SetEnv", func() error {
+		return session.SetEnv(auth.RunnerKeyEnv, r.key)
+	}, tracing.String("name", auth.RunnerKeyEnv)); err != nil {
+		session.Close()
+		return fmt.Errorf("unable to set %s env-variable: %v", auth.RunnerKeyEnv, err)
+	}
+
+	// Set the trace-context for the runner-script as an env-variable,
+	// so the callbacks from the script joins the trace for the run
+	if span != nil {
+		if err := step(ctx, "pwsh.SetEnv", func() error {
+			return session.SetEnv(tracing.TraceParentEnv, span.TraceParent())
+		}, tracing.String("name", tracing.TraceParentEnv)); err != nil {
+			session.Close()
+			return fmt.Errorf("unable to set %s env-variable: %v", tracing.TraceParentEnv, err)
+		}
+	}
+
 	// Set nuix username as an env-variable
-	if err := session.SetEnv("NUIX_USERNAME", r.nms.Username); err != nil {
+	if err := step(ctx, "pwsh.SetEnv", func() error {
+		return session.SetEnv("NUIX_USERNAME", r.nms.Username)
+	}, tracing.String("name", "NUIX_USERNAME")); err != nil {
 		session.Close()
 		return fmt.Errorf("unable to set NUIX_USERNAME env-variable: %v", err)
 	}
 
 	// Set nuix password as an env-variable
-	if err := session.SetEnv("NUIX_PASSWORD", r.nms.Password); err != nil {
+	if err := step(ctx, "pwsh.SetEnv", func() error {
+		return session.SetEnv("NUIX_PASSWORD", r.nms.Password)
+	}, tracing.String("name", "NUIX_PASSWORD")); err != nil {
 		session.Close()
 		return fmt.Errorf("unable to set NUIX_PASSWORD env-variable: %v", err)
 	}
@@ -239,7 +311,9 @@ func (r *run) start() error {
 		}
 		// copy avian scripts from service to the remote machine
 		logger.Info("Copying avian-scripts directory to session", zap.String("src", r.server.AvianScripts), zap.String("dst", r.server.NuixPath))
-		if err := session.CopyItemFromHost(r.server.AvianScripts, r.server.NuixPath); err != nil {
+		if err := step(ctx, "pwsh.CopyItemFromHost", func() error {
+			return session.CopyItemFromHost(r.server.AvianScripts, r.server.NuixPath)
+		}, tracing.String("src", r.server.AvianScripts), tracing.String("dst", r.server.NuixPath)); err != nil {
 			logger.Error("Failed to copy avian-scripts directory to session",
 				zap.String("src", r.server.AvianScripts),
 				zap.String("dst", r.server.NuixPath),
@@ -249,10 +323,32 @@ func (r *run) start() error {
 		}
 	}
 
+	// Write the stage-handlers for the script to the remote machine
+	logger.Info("Creating stage-handlers to server", zap.String("library", ruby.LibraryName))
+	if err := step(ctx, "pwsh.CreateFile", func() error {
+		return session.CreateFile(r.server.NuixPath, ruby.LibraryName, []byte(r.queue.templates.Library()))
+	}, tracing.String("file", ruby.LibraryName)); err != nil {
+		session.Close()
+		return fmt.Errorf("Failed to create library-file: %v", err)
+	}
+
+	// Write the CA-certificate for the service to the remote machine
+	if len(r.queue.ca) != 0 {
+		logger.Info("Creating CA-certificate to server", zap.String("ca", ruby.CAName))
+		if err := step(ctx, "pwsh.CreateFile", func() error {
+			return session.CreateFile(r.server.NuixPath, ruby.CAName, r.queue.ca)
+		}, tracing.String("file", ruby.CAName)); err != nil {
+			session.Close()
+			return fmt.Errorf("Failed to create CA-file: %v", err)
+		}
+	}
+
 	// Write the generated script to the remote machine
 	scriptName := r.runner.Name + ".gen.rb"
 	logger.Info("Creating runner-script to server", zap.String("script", scriptName))
-	if err := session.CreateFile(r.server.NuixPath, scriptName, []byte(script)); err != nil {
+	if err := step(ctx, "pwsh.CreateFile", func() error {
+		return session.CreateFile(r.server.NuixPath, scriptName, []byte(script))
+	}, tracing.String("file", scriptName)); err != nil {
 		session.Close()
 		return fmt.Errorf("Failed to create script-file: %v", err)
 	}
@@ -290,14 +386,56 @@ func (r *run) start() error {
 	// Set the generated scripts name in the end of the args.
 	args = append(args, scriptName)
 
+	// Archive the script with the args before it is started
+	if err := r.archive(script, args); err != nil {
+		logger.Error("Failed to archive script", zap.String("exception", err.Error()))
+		return err
+	}
+
 	// set the powershell-sessions location to the nuix-path
-	if err := session.SetLocation(r.server.NuixPath); err != nil {
+	if err := step(ctx, "pwsh.SetLocation", func() error {
+		return session.SetLocation(r.server.NuixPath)
+	}, tracing.String("path", r.server.NuixPath)); err != nil {
 		logger.Error("Failed to set location", zap.String("exception", err.Error()))
 		return fmt.Errorf("Failed to set location: %v", err)
 	}
 	//log powershell arguments to service log
 	logger.Info("Running powershell command.", zap.String("args", strings.Join(args, " ")))
-	return session.Run("nuix_console.exe", args...)
+	return step(ctx, "pwsh.Run", func() error {
+		return session.Run("nuix_console.exe", args...)
+	}, tracing.String("script", scriptName))
+}
+
+// archive stores the script and the library with their hashes, the
+// args for nuix_console and the template-version for the start of the runner
+func (r *run) archive(script string, args []string) error {
+	library := r.queue.templates.Library()
+	archive := api.ScriptArchive{
+		RunnerID:        r.runner.ID,
+		Script:          script,
+		Library:         library,
+		Hash:            ruby.Hash(script),
+		LibraryHash:     ruby.Hash(library),
+		TemplateVersion: r.queue.templates.Version,
+	}
+	for _, arg := range args {
+		archive.Arguments = append(archive.Arguments, api.ScriptArgument{Value: arg})
+	}
+
+	db := r.queue.db
+	if err := db.Model(&api.ScriptArchive{}).Where("runner_id = ?", r.runner.ID).Count(&archive.Attempt).Error; err != nil {
+		return fmt.Errorf("failed to count archived scripts for runner: %s - %v", r.runner.Name, err)
+	}
+	archive.Attempt++
+	if err := db.Create(&archive).Error; err != nil {
+		return fmt.Errorf("failed to archive script for runner: %s - %v", r.runner.Name, err)
+	}
+	r.queue.logger.Info("Archived script for runner",
+		zap.String("runner", r.runner.Name),
+		zap.Int64("attempt", archive.Attempt),
+		zap.String("hash", archive.Hash),
+	)
+	return nil
 }
 
 // handle the error will not return an error
@@ -312,25 +450,21 @@ func (r *run) handle(err error) {
 	)
 	defer r.close()
 
-	// handle the error
+	// handle the error - the runner is set to failed in the
+	// service, since the key for the runner may not exist
 	if err != nil {
 		logger.Error("Runner failed", zap.String("exception", nuixError(err).Error()))
 
-		port := os.Getenv("AVIAN_PORT")
-		if port == "" {
-			port = "8080"
-		}
-		url := fmt.Sprintf("http://%s:%s/oto/", "localhost", port)
-
-		runnerService := avian.NewRunnerService(avian.New(url, ""))
-		runnerService.Failed(
+		if _, err := r.queue.runnersvc.Failed(
 			context.Background(),
-			avian.RunnerFailedRequest{
+			api.RunnerFailedRequest{
 				ID:        r.runner.ID,
 				Runner:    r.runner.Name,
 				Exception: err.Error(),
 			},
-		)
+		); err != nil {
+			logger.Error("Cannot set runner to failed", zap.String("exception", err.Error()))
+		}
 		return
 	}
 	logger.Debug("Runner is executing")
@@ -355,16 +489,7 @@ func (r *run) close() error {
 // getRunners from the database
 func getRunners(db *gorm.DB) ([]*api.Runner, error) {
 	var runners []*api.Runner
-	err := db.
-		Preload("Stages.Process.EvidenceStore").
-		Preload("Stages.SearchAndTag.Files").
-		Preload("Stages.Exclude").
-		Preload("Stages.Ocr").
-		Preload("Stages.Reload").
-		Preload("Stages.Populate.Types").
-		Preload("Stages.InApp").
-		Preload("Stages.SyncDescendants").
-		Preload("Stages.ScanNewChildItems").
+	err := tables.PreloadStages(db, "Stages.").
 		Preload("CaseSettings.Case.ElasticSearch").
 		Preload("CaseSettings.CompoundCase").
 		Preload("CaseSettings.ReviewCompound").
@@ -377,15 +502,7 @@ func getRunners(db *gorm.DB) ([]*api.Runner, error) {
 // getRunnerByName
 func getRunnerByName(db *gorm.DB, name string) (*api.Runner, error) {
 	var runner api.Runner
-	err := db.Preload("Stages.Process.EvidenceStore").
-		Preload("Stages.SearchAndTag.Files").
-		Preload("Stages.Exclude").
-		Preload("Stages.Ocr").
-		Preload("Stages.Reload").
-		Preload("Stages.Populate.Types").
-		Preload("Stages.InApp").
-		Preload("Stages.SyncDescendants").
-		Preload("Stages.ScanNewChildItems").
+	err := tables.PreloadStages(db, "Stages.").
 		Preload("CaseSettings.Case.ElasticSearch").
 		Preload("CaseSettings.CompoundCase").
 		Preload("CaseSettings.ReviewCompound").
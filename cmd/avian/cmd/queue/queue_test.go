@@ -0,0 +1,104 @@
+package queue_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/cmd/avian/cmd/queue"
+	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/logging"
+	"github.com/avian-digital-forensics/auto-processing/pkg/pwsh"
+	"github.com/avian-digital-forensics/auto-processing/pkg/services"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+// fakeShell connects to fake sessions instead of
+// the remote servers, the sessions only remove files
+type fakeShell struct{}
+
+func (fakeShell) Close() error { return nil }
+
+func (fakeShell) NewSession(host, username, password string) (pwsh.Session, error) {
+	return fakeSession{}, nil
+}
+
+func (fakeShell) NewSessionCredSSP(host, username, password string) (pwsh.Session, error) {
+	return fakeSession{}, nil
+}
+
+type fakeSession struct {
+	pwsh.Session
+}
+
+func (fakeSession) Close() error                 { return nil }
+func (fakeSession) CheckPath(path string) error  { return nil }
+func (fakeSession) RemoveItem(path string) error { return nil }
+
+func TestPollKeyFailed(t *testing.T) {
+	is := is.New(t)
+	db, err := gorm.Open("sqlite3", ":memory:")
+	is.NoErr(err)
+	defer db.Close()
+	db.DB().SetMaxOpenConns(1) // the in-memory db is shared with the run
+	is.NoErr(tables.Migrate(db))
+
+	logPath, err := ioutil.TempDir("", "avian-logs")
+	is.NoErr(err)
+	defer os.RemoveAll(logPath)
+
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01", NuixPath: `C:\Nuix`}).Error)
+	is.NoErr(db.Create(&api.Nms{Address: "nms01", Workers: 8, Licences: []api.Licence{{Type: "enterprise-workstation", Amount: 2}}}).Error)
+	runner := api.Runner{
+		Name:         "runner",
+		Hostname:     "dev01",
+		Nms:          "nms01",
+		Licence:      "enterprise-workstation",
+		Workers:      2,
+		CaseSettings: &api.CaseSettings{Case: &api.Case{Name: "case", Directory: `C:\Cases\case`}},
+		Stages:       []*api.Stage{{Ocr: &api.Ocr{}}},
+	}
+	is.NoErr(db.Create(&runner).Error)
+
+	// the api-key for the runner-script cannot be created
+	is.NoErr(db.DropTable(&auth.Key{}).Error)
+
+	runnersvc := services.NewRunnerService(db, fakeShell{}, zap.NewNop(), logging.New(logPath), "", logPath, ruby.DefaultTemplates(), events.NewBroker(10))
+	q := queue.New(db, fakeShell{}, runnersvc, "http://avian.test:8080/oto/", ruby.DefaultTemplates(), nil, zap.NewNop())
+	q.Poll()
+
+	// the workers and the licence are reserved for the run
+	var nms api.Nms
+	is.NoErr(db.Preload("Licences").First(&nms, "address = ?", "nms01").Error)
+	is.Equal(nms.InUse, int64(2))
+	is.Equal(nms.Licences[0].InUse, int64(1))
+
+	// the run fails without its api-key - the nms is released last
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		is.NoErr(db.Preload("Licences").First(&nms, "address = ?", "nms01").Error)
+		if nms.InUse == 0 {
+			break
+		}
+	}
+	is.Equal(nms.InUse, int64(0))
+	is.Equal(nms.Licences[0].InUse, int64(0))
+
+	var failed api.Runner
+	is.NoErr(db.First(&failed, runner.ID).Error)
+	is.Equal(failed.Status, avian.StatusFailed)
+	is.True(!failed.Active)
+	is.True(strings.Contains(failed.Exception, "keys")) // the error for the api-key
+	var server api.Server
+	is.NoErr(db.First(&server, "hostname = ?", "dev01").Error)
+	is.True(!server.Active)
+}
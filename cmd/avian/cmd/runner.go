@@ -18,13 +18,16 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/avian-digital-forensics/auto-processing/configs"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
 	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
-	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 )
@@ -64,6 +67,13 @@ var runnersApplyCmd = &cobra.Command{
 var runnersListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List the runners",
+	Long: `List the runners, 100 at a time - use the printed --cursor to list the
+next page (or --all to list every page). - For example:
+
+	avian runners list --status running,failed --server dev01
+	avian runners list --owner simon --created-after 168h --sort -created
+	avian runners list --investigator 'Simon Sigre' --status finished
+	avian runners list --name 'case-12*' --limit 20`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := listRunners(context.Background()); err != nil {
 			fmt.Fprintf(os.Stderr, "could not list runners from backend: %v\n", err)
@@ -99,6 +109,20 @@ var runnerDeleteCmd = &cobra.Command{
 	},
 }
 
+// runnerCancelCmd represents the runner cancel command
+//
+// "avian runners cancel <runner-name>"
+var runnerCancelCmd = &cobra.Command{
+	Use:   "cancel",
+	Short: "Cancels the specified waiting or running runner (specified by name)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cancelRunner(context.Background(), strings.ToLower(args[0])); err != nil {
+			fmt.Fprintf(os.Stderr, "could not cancel runner: %v\n", err)
+		}
+	},
+}
+
 // runnerScriptCmd represents the runner script command
 // (mostly used for debugging (it will generate the script for the runner))
 //
@@ -114,36 +138,101 @@ var runnerScriptCmd = &cobra.Command{
 	},
 }
 
-var (
-	runnerService *avian.RunnerService
-	forceDelete   bool
-	forceApply    bool
-)
+// runnerCustodyCmd represents the runner custody command
+//
+// "avian runners custody <runner-name>"
+// "avian runners custody --public-key"
+var runnerCustodyCmd = &cobra.Command{
+	Use:   "custody",
+	Short: "Exports the signed chain of custody for the specified runner (specified by name)",
+	Long: `Exports the signed chain of custody for the specified runner (specified by name).
+The signature is only trusted if its fingerprint matches the pinned fingerprint for
+the service - get the public-key and its fingerprint with --public-key (the service
+also logs the fingerprint when it starts).`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if custodyPublicKey {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if custodyPublicKey {
+			if err := custodyKey(context.Background()); err != nil {
+				fmt.Fprintf(os.Stderr, "could not get the key for the chain of custody from backend: %v\n", err)
+			}
+			return
+		}
+		if err := custodyRunner(context.Background(), strings.ToLower(args[0])); err != nil {
+			fmt.Fprintf(os.Stderr, "could not get the chain of custody for runner from backend: %v\n", err)
+		}
+	},
+}
 
-func init() {
-	// Get the address for the API (where the avian service is listening at)
-	address := os.Getenv("AVIAN_ADDRESS")
-	if address == "" {
-		ip, err := utils.GetIPAddress()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Cannot get ip-address: %v", err)
-			os.Exit(1)
+// runnerLogsCmd represents the runner logs command
+//
+// "avian runners logs <runner-name>"
+var runnerLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Prints the log from the script for the specified runner (specified by name)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := signalContext()
+		defer cancel()
+		if err := logsRunner(ctx, strings.ToLower(args[0])); err != nil {
+			fmt.Fprintf(os.Stderr, "could not get the log for runner from backend: %v\n", err)
 		}
-		address = ip
-	}
+	},
+}
 
-	// Get the port for the API (where the avian service is listening at)
-	port := os.Getenv("AVIAN_PORT")
-	if port == "" {
-		port = "8080"
-	}
+// runnersWatchCmd represents the runners watch command
+//
+// "avian runners watch [runner-name]"
+var runnersWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Prints the status-changes for the runners and their stages as they happen",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := signalContext()
+		defer cancel()
+		var name string
+		if len(args) > 0 {
+			name = strings.ToLower(args[0])
+		}
+		if err := watchRunners(ctx, name); err != nil {
+			fmt.Fprintf(os.Stderr, "could not watch runners from backend: %v\n", err)
+		}
+	},
+}
 
-	// create the uri for the service
-	url := fmt.Sprintf("http://%s:%s/oto/", address, port)
+var (
+	runnerService    *avian.RunnerService
+	followLogs       bool
+	forceDelete      bool
+	cancelReason     string
+	forceApply       bool
+	custodyFormat    string
+	custodyOutput    string
+	custodyPublicKey bool
+	scriptLibrary    bool
+	scriptPlan       bool
+	scriptAttempt    int64
+
+	runnersListStatus       []string
+	runnersListServer       string
+	runnersListNms          string
+	runnersListOwner        string
+	runnersListInvestigator string
+	runnersListCreatedAfter string
+	runnersListName         string
+	runnersListSort         string
+	runnersListLimit        int64
+	runnersListCursor       string
+	runnersListAll          bool
+)
 
+func init() {
 	// Set the client for the runner-service
-	// pass the uri and an empty token (since no token is needed)
-	runnerService = avian.NewRunnerService(avian.New(url, "hej"))
+	// (the url, api-key and CA-bundle are from the env or config)
+	runnerService = avian.NewRunnerService(newClient())
 
 	// Add the commands to the correct hierarchy
 	rootCmd.AddCommand(runnersCmd)
@@ -151,9 +240,32 @@ func init() {
 	runnersCmd.AddCommand(runnersListCmd)
 	runnersCmd.AddCommand(runnerStagesCmd)
 	runnersCmd.AddCommand(runnerDeleteCmd)
+	runnersCmd.AddCommand(runnerCancelCmd)
 	runnersCmd.AddCommand(runnerScriptCmd)
+	runnersCmd.AddCommand(runnerCustodyCmd)
+	runnersCmd.AddCommand(runnerLogsCmd)
+	runnersCmd.AddCommand(runnersWatchCmd)
 	runnerDeleteCmd.Flags().BoolVar(&forceDelete, "force", false, "force deleting an active runner")
+	runnerCancelCmd.Flags().StringVar(&cancelReason, "reason", "", "reason for the cancel (set as the exception for the runner)")
 	runnersApplyCmd.Flags().BoolVar(&forceApply, "force", false, "force applying a runner")
+	runnerCustodyCmd.Flags().StringVar(&custodyFormat, "format", "json", "format for the chain of custody (json or csv)")
+	runnerScriptCmd.Flags().BoolVar(&scriptLibrary, "library", false, "return the stage-handlers for the script instead")
+	runnerScriptCmd.Flags().BoolVar(&scriptPlan, "plan", false, "return the plan the archived script got from the service instead (requires --attempt)")
+	runnerScriptCmd.Flags().Int64Var(&scriptAttempt, "attempt", 0, "return the archived script for a start of the runner (1 for the first start)")
+	runnerLogsCmd.Flags().BoolVarP(&followLogs, "follow", "f", false, "follow the log as the script writes to it")
+	runnerCustodyCmd.Flags().StringVarP(&custodyOutput, "output", "o", "", "file to write the chain of custody to (signature is written to <file>.sig)")
+	runnerCustodyCmd.Flags().BoolVar(&custodyPublicKey, "public-key", false, "print the public-key and the fingerprint that signs the chain of custody (to pin it)")
+	runnersListCmd.Flags().StringSliceVar(&runnersListStatus, "status", nil, "only list the runners with the statuses (waiting, running, failed, finished or timeout)")
+	runnersListCmd.Flags().StringVar(&runnersListServer, "server", "", "only list the runners for the server (hostname)")
+	runnersListCmd.Flags().StringVar(&runnersListNms, "nms", "", "only list the runners for the nms (address)")
+	runnersListCmd.Flags().StringVar(&runnersListOwner, "owner", "", "only list the runners owned by the api-key (name of the key that applied them)")
+	runnersListCmd.Flags().StringVar(&runnersListInvestigator, "investigator", "", "only list the runners with the investigator for their case")
+	runnersListCmd.Flags().StringVar(&runnersListCreatedAfter, "created-after", "", "only list the runners created after the time (e.g 24h, 2020-10-01 or 2020-10-01T12:00:00+02:00)")
+	runnersListCmd.Flags().StringVar(&runnersListName, "name", "", "only list the runners with names matching the pattern (* matches any characters)")
+	runnersListCmd.Flags().StringVar(&runnersListSort, "sort", "id", "field to sort by (id, name, created or status), prefix with - for descending")
+	runnersListCmd.Flags().Int64Var(&runnersListLimit, "limit", 100, "amount of runners to list")
+	runnersListCmd.Flags().StringVar(&runnersListCursor, "cursor", "", "cursor for the next page (printed after the list)")
+	runnersListCmd.Flags().BoolVar(&runnersListAll, "all", false, "list every page")
 }
 
 // applyRunner applies the specified runner (from config) to the service
@@ -184,21 +296,50 @@ func applyRunner(ctx context.Context, path string) error {
 
 // listRunners lists all runners from the service
 func listRunners(ctx context.Context) error {
-	resp, err := runnerService.List(ctx, avian.RunnerListRequest{})
-	if err != nil {
-		return err
+	request := avian.RunnerListRequest{
+		Status:       runnersListStatus,
+		Hostname:     runnersListServer,
+		Nms:          runnersListNms,
+		Owner:        runnersListOwner,
+		Investigator: runnersListInvestigator,
+		Name:         runnersListName,
+		Sort:         runnersListSort,
+		Limit:        runnersListLimit,
+		Cursor:       runnersListCursor,
+	}
+	var err error
+	if request.CreatedAfter, err = parseTimeFlag(runnersListCreatedAfter, time.Now()); err != nil {
+		return fmt.Errorf("invalid --created-after: %v", err)
+	}
+
+	var resp avian.RunnerListResponse
+	for {
+		page, err := runnerService.List(ctx, request)
+		if err != nil {
+			return err
+		}
+		resp.Runners = append(resp.Runners, page.Runners...)
+		resp.NextCursor = page.NextCursor
+		if !runnersListAll || page.NextCursor == "" {
+			break
+		}
+		request.Cursor = page.NextCursor
 	}
 
 	// format the response
 	var headers table.Row
 	var body []table.Row
-	headers = table.Row{"ID", "Runner", "Host", "Nms", "Licencetype", "Workers", "Status", "Stage"}
+	headers = table.Row{"ID", "Runner", "Owner", "Host", "Nms", "Licencetype", "Workers", "Status", "Stage"}
 	for _, r := range resp.Runners {
 		var status string
 		var stage string
 		for _, s := range r.Stages {
-			stage = s.Name()
-			status = s.Status()
+			if stage, err = s.Name(); err != nil {
+				return err
+			}
+			if status, err = s.Status(); err != nil {
+				return err
+			}
 
 			// Break if the stage is running
 			if status == "Running" {
@@ -214,10 +355,13 @@ func listRunners(ctx context.Context) error {
 				break
 			}
 		}
-		body = append(body, table.Row{r.ID, r.Name, r.Hostname, r.Nms, r.Licence, r.Workers, avian.Status(r.Status), stage})
+		body = append(body, table.Row{r.ID, r.Name, r.Owner, r.Hostname, r.Nms, r.Licence, r.Workers, avian.Status(r.Status), stage})
 	}
 
 	fmt.Fprintf(os.Stdout, "%s\n", pretty.Format(headers, body))
+	if resp.NextCursor != "" {
+		fmt.Fprintf(os.Stderr, "more runners - list the next page with: --cursor %s\n", resp.NextCursor)
+	}
 	return nil
 }
 
@@ -230,15 +374,31 @@ func stagesRunner(ctx context.Context, runner string) error {
 
 	var headers table.Row
 	var body []table.Row
-	headers = table.Row{"ID", "Runner", "Stage", "Status"}
+	headers = table.Row{"ID", "Runner", "Stage", "Status", "Items", "Checkpoint"}
 
 	for _, s := range resp.Runner.Stages {
-		if !s.Nil() {
-			body = append(body, table.Row{s.ID, resp.Runner.Name, s.Name(), s.Status()})
+		unknown, err := s.Nil()
+		if err != nil {
+			return err
+		}
+		if unknown {
+			continue
+		}
+		name, err := s.Name()
+		if err != nil {
+			return err
 		}
+		status, err := s.Status()
+		if err != nil {
+			return err
+		}
+		body = append(body, table.Row{s.ID, resp.Runner.Name, name, status, s.Items, s.Checkpoint})
 	}
 
 	fmt.Fprintf(os.Stdout, "%s\n", pretty.Format(headers, body))
+	if resp.Runner.Exception != "" {
+		fmt.Fprintf(os.Stdout, "Exception: %s\n", resp.Runner.Exception)
+	}
 	return nil
 }
 
@@ -253,14 +413,159 @@ func deleteRunner(ctx context.Context, runner string) error {
 	return nil
 }
 
+// cancelRunner cancels the specified runner
+func cancelRunner(ctx context.Context, runner string) error {
+	resp, err := runnerService.Cancel(ctx, avian.RunnerCancelRequest{Name: runner, Reason: cancelReason})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Runner: %s has been cancelled - %s", runner, resp.Runner.Exception)
+	return nil
+}
+
 // scriptRunner generates a script for the specified runner
-// (good to use for debugging)
+// (good to use for debugging), or gets the archived script
+// for a start of the runner (or the plan it got) if --attempt is used
 func scriptRunner(ctx context.Context, runner string) error {
-	resp, err := runnerService.Script(ctx, avian.RunnerGetRequest{Name: runner})
+	resp, err := runnerService.Script(ctx, avian.RunnerScriptRequest{Name: runner, Attempt: scriptAttempt})
 	if err != nil {
 		return err
 	}
 
+	// print the information for the archived script to stderr,
+	// so the script on stdout can be compared with the hash
+	if resp.Attempt != 0 {
+		fmt.Fprintf(os.Stderr, "Attempt: %d of %d\n", resp.Attempt, resp.Attempts)
+		fmt.Fprintf(os.Stderr, "Archived: %s\n", time.Unix(resp.ArchivedAt, 0).Format(time.RFC3339))
+		fmt.Fprintf(os.Stderr, "Templates: %s\n", resp.TemplateVersion)
+		fmt.Fprintf(os.Stderr, "Script-hash (sha256): %s\n", resp.Hash)
+		fmt.Fprintf(os.Stderr, "Library-hash (sha256): %s\n", resp.LibraryHash)
+		if resp.PlanHash != "" {
+			fmt.Fprintf(os.Stderr, "Plan-hash (sha256): %s\n", resp.PlanHash)
+		}
+		fmt.Fprintf(os.Stderr, "Command: nuix_console.exe %s\n\n", strings.Join(resp.Arguments, " "))
+	}
+
+	if scriptLibrary {
+		fmt.Fprintf(os.Stdout, "%s", resp.Library)
+		return nil
+	}
+	if scriptPlan {
+		if resp.Attempt == 0 {
+			return fmt.Errorf("the plan is only archived for a start of the runner - use --attempt")
+		}
+		if resp.Plan == "" {
+			return fmt.Errorf("the script for attempt: %d never got the plan from the service", resp.Attempt)
+		}
+		fmt.Fprintf(os.Stdout, "%s", resp.Plan)
+		return nil
+	}
 	fmt.Fprintf(os.Stdout, "%s", resp.Script)
 	return nil
 }
+
+// custodyRunner exports the signed chain of custody for the specified runner
+func custodyRunner(ctx context.Context, runner string) error {
+	resp, err := runnerService.Custody(ctx, avian.CustodyRequest{Name: runner, Format: custodyFormat})
+	if err != nil {
+		return err
+	}
+
+	signature := fmt.Sprintf("algorithm: ed25519\nsignature: %s\npublicKey: %s\nfingerprint: %s\n", resp.Signature, resp.PublicKey, resp.Fingerprint)
+
+	// print the document to stdout and the signature to stderr if
+	// no output-file is specified - exactly the signed bytes are
+	// written, so a redirected document can be verified
+	if custodyOutput == "" {
+		os.Stdout.Write([]byte(resp.Document))
+		fmt.Fprintf(os.Stderr, "%s", signature)
+		return nil
+	}
+
+	if err := ioutil.WriteFile(custodyOutput, []byte(resp.Document), 0644); err != nil {
+		return fmt.Errorf("failed to write chain of custody: %v", err)
+	}
+
+	if err := ioutil.WriteFile(custodyOutput+".sig", []byte(signature), 0644); err != nil {
+		return fmt.Errorf("failed to write signature for chain of custody: %v", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Chain of custody for runner: %s has been written to %s", runner, custodyOutput)
+	return nil
+}
+
+// custodyKey prints the public-key that signs the chain of custody
+// (or writes it to the output-file) and its fingerprint to stderr
+func custodyKey(ctx context.Context) error {
+	resp, err := runnerService.CustodyKey(ctx, avian.CustodyKeyRequest{})
+	if err != nil {
+		return err
+	}
+
+	if custodyOutput == "" {
+		fmt.Fprintf(os.Stdout, "%s", resp.PublicKey)
+	} else if err := ioutil.WriteFile(custodyOutput, []byte(resp.PublicKey), 0644); err != nil {
+		return fmt.Errorf("failed to write the public-key: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "fingerprint (sha256): %s\n", resp.Fingerprint)
+	return nil
+}
+
+// logsRunner prints the log-lines from the script for the runner, the
+// service keeps the latest lines - the full log is <runner>-runner.log
+// in the log-path for the service
+func logsRunner(ctx context.Context, name string) error {
+	request := avian.EventsRequest{
+		Runner:  name,
+		Types:   []string{events.TypeLog},
+		History: true,
+		Follow:  followLogs,
+	}
+	return runnerService.Events(ctx, request, func(e events.Event) error {
+		var stage string
+		if e.Stage != "" {
+			stage = fmt.Sprintf(" [%s]", e.Stage)
+		}
+		var exception string
+		if e.Exception != "" {
+			exception = fmt.Sprintf(" - %s", e.Exception)
+		}
+		fmt.Fprintf(os.Stdout, "%s %-5s%s %s%s\n", e.Time.Local().Format("2006-01-02 15:04:05"), strings.ToUpper(e.Level), stage, e.Message, exception)
+		return nil
+	})
+}
+
+// watchRunners prints the status-changes for the runners
+// and their stages (for the runner if name is specified)
+func watchRunners(ctx context.Context, name string) error {
+	request := avian.EventsRequest{
+		Runner: name,
+		Types:  []string{events.TypeRunner, events.TypeStage},
+		Follow: true,
+	}
+	return runnerService.Events(ctx, request, func(e events.Event) error {
+		target := e.Runner
+		if e.Type == events.TypeStage {
+			target = fmt.Sprintf("%s - stage %d: %s", e.Runner, e.StageID, e.Stage)
+		}
+		fmt.Fprintf(os.Stdout, "%s %-8s %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Status, target)
+		return nil
+	})
+}
+
+// signalContext returns a context that is cancelled on ctrl+c
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+	return ctx, cancel
+}
@@ -19,11 +19,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/avian-digital-forensics/auto-processing/configs"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
 	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
-	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 )
@@ -63,6 +63,11 @@ var serversApplyCmd = &cobra.Command{
 var serversListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List servers available for remote-connection",
+	Long: `List the servers, 100 at a time - use the printed --cursor to list the
+next page (or --all to list every page). - For example:
+
+	avian servers list --status active --sort hostname
+	avian servers list --hostname 'dev*' --sort -created`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := listServers(context.Background()); err != nil {
 			fmt.Fprintf(os.Stderr, "could not list servers from backend: %v\n", err)
@@ -70,39 +75,72 @@ var serversListCmd = &cobra.Command{
 	},
 }
 
-// srvService is needed by all server-commands
-// to be able to speak to the API
-var srvService *avian.ServerService
+// serversDescribeCmd represents the describe server command
+//
+// "avian servers describe <hostname>"
+var serversDescribeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Describes the specified server (specified by hostname) with the runners that uses it",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := describeServer(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not describe server from backend: %v\n", err)
+		}
+	},
+}
 
-func init() {
-	// Get the address for the API (where the avian service is listening at)
-	address := os.Getenv("AVIAN_ADDRESS")
-	if address == "" {
-		ip, err := utils.GetIPAddress()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Cannot get ip-address: %v", err)
-			os.Exit(1)
+// serversDeleteCmd represents the delete server command
+//
+// "avian servers delete <hostname>"
+var serversDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Deletes the specified server (specified by hostname)",
+	Long: `Deletes the specified server (specified by hostname). The delete is
+refused while unfinished runners uses the server - unless --force is used.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := deleteServer(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not delete server from backend: %v\n", err)
 		}
-		address = ip
-	}
+	},
+}
 
-	// Get the port for the API (where the avian service is listening at)
-	port := os.Getenv("AVIAN_PORT")
-	if port == "" {
-		port = "8080"
-	}
+var (
+	// srvService is needed by all server-commands
+	// to be able to speak to the API
+	srvService *avian.ServerService
+
+	forceDeleteServer bool
 
-	// create the uri for the service
-	url := fmt.Sprintf("http://%s:%s/oto/", address, port)
+	serversDescribeRunners int64
 
+	serversListHostname string
+	serversListStatus   string
+	serversListSort     string
+	serversListLimit    int64
+	serversListCursor   string
+	serversListAll      bool
+)
+
+func init() {
 	// Set the client for the server-service
-	// pass the uri and an empty token (since no token is needed)
-	srvService = avian.NewServerService(avian.New(url, ""))
+	// (the url, api-key and CA-bundle are from the env or config)
+	srvService = avian.NewServerService(newClient())
 
 	// Add the commands to the correct hierarchy
 	rootCmd.AddCommand(serversCmd)
 	serversCmd.AddCommand(serversApplyCmd)
 	serversCmd.AddCommand(serversListCmd)
+	serversListCmd.Flags().StringVar(&serversListHostname, "hostname", "", "only list the servers with hostnames matching the pattern (* matches any characters)")
+	serversListCmd.Flags().StringVar(&serversListStatus, "status", "", "only list the active or inactive servers")
+	serversListCmd.Flags().StringVar(&serversListSort, "sort", "id", "field to sort by (id, hostname or created), prefix with - for descending")
+	serversListCmd.Flags().Int64Var(&serversListLimit, "limit", 100, "amount of servers to list")
+	serversListCmd.Flags().StringVar(&serversListCursor, "cursor", "", "cursor for the next page (printed after the list)")
+	serversListCmd.Flags().BoolVar(&serversListAll, "all", false, "list every page")
+	serversCmd.AddCommand(serversDescribeCmd)
+	serversDescribeCmd.Flags().Int64Var(&serversDescribeRunners, "runners", 20, "amount of runners to list (the latest first)")
+	serversCmd.AddCommand(serversDeleteCmd)
+	serversDeleteCmd.Flags().BoolVar(&forceDeleteServer, "force", false, "force deleting a server used by unfinished runners")
 }
 
 // applyServers will apply the servers from the config-file
@@ -143,9 +181,36 @@ func applyServers(ctx context.Context, path string) error {
 // listServers lists all the servers
 // that has been applied to the backend
 func listServers(ctx context.Context) error {
-	resp, err := srvService.List(ctx, avian.ServerListRequest{})
-	if err != nil {
-		return err
+	request := avian.ServerListRequest{
+		Hostname: serversListHostname,
+		Sort:     serversListSort,
+		Limit:    serversListLimit,
+		Cursor:   serversListCursor,
+	}
+	switch strings.ToLower(serversListStatus) {
+	case "":
+	case "active":
+		active := true
+		request.Active = &active
+	case "inactive":
+		active := false
+		request.Active = &active
+	default:
+		return fmt.Errorf("invalid --status: %s (active or inactive)", serversListStatus)
+	}
+
+	var resp avian.ServerListResponse
+	for {
+		page, err := srvService.List(ctx, request)
+		if err != nil {
+			return err
+		}
+		resp.Servers = append(resp.Servers, page.Servers...)
+		resp.NextCursor = page.NextCursor
+		if !serversListAll || page.NextCursor == "" {
+			break
+		}
+		request.Cursor = page.NextCursor
 	}
 
 	// format the response
@@ -161,5 +226,64 @@ func listServers(ctx context.Context) error {
 	}
 
 	fmt.Println(pretty.Format(headers, body))
+	if resp.NextCursor != "" {
+		fmt.Fprintf(os.Stderr, "more servers - list the next page with: --cursor %s\n", resp.NextCursor)
+	}
+	return nil
+}
+
+// describeServer prints the server
+// with the runners that uses it
+func describeServer(ctx context.Context, hostname string) error {
+	resp, err := srvService.Get(ctx, avian.ServerGetRequest{Hostname: hostname, Limit: serversDescribeRunners})
+	if err != nil {
+		return err
+	}
+
+	s := resp.Server
+	status := "Inactive"
+	if s.Active {
+		status = "Active"
+	}
+	fmt.Fprintf(os.Stdout, "Hostname:      %s\n", s.Hostname)
+	fmt.Fprintf(os.Stdout, "Port:          %d\n", s.Port)
+	fmt.Fprintf(os.Stdout, "OS:            %s\n", s.OperatingSystem)
+	fmt.Fprintf(os.Stdout, "Username:      %s\n", s.Username)
+	fmt.Fprintf(os.Stdout, "Nuix-Path:     %s\n", s.NuixPath)
+	fmt.Fprintf(os.Stdout, "Avian-Scripts: %s\n", s.AvianScripts)
+	fmt.Fprintf(os.Stdout, "Status:        %s\n", status)
+	fmt.Fprintf(os.Stdout, "Runners:       %s\n", formatRunnerTotal(resp.Runners, resp.TotalRunners))
+	if len(resp.Runners) > 0 {
+		fmt.Println(formatLinkedRunners(resp.Runners))
+	}
 	return nil
 }
+
+// deleteServer deletes the specified server
+func deleteServer(ctx context.Context, hostname string) error {
+	if _, err := srvService.Delete(ctx, avian.ServerDeleteRequest{Hostname: hostname, Force: forceDeleteServer}); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Server: %s has been deleted\n", hostname)
+	return nil
+}
+
+// formatRunnerTotal formats the total amount of
+// runners (and how many of them that are listed)
+func formatRunnerTotal(runners []avian.Runner, total int64) string {
+	if int64(len(runners)) < total {
+		return fmt.Sprintf("%d (listing the latest %d)", total, len(runners))
+	}
+	return fmt.Sprintf("%d", total)
+}
+
+// formatLinkedRunners formats the runners
+// that uses a server or an nms as a table
+func formatLinkedRunners(runners []avian.Runner) string {
+	headers := table.Row{"ID", "Runner", "Owner", "Host", "Nms", "Licencetype", "Workers", "Status"}
+	var body []table.Row
+	for _, r := range runners {
+		body = append(body, table.Row{r.ID, r.Name, r.Owner, r.Hostname, r.Nms, r.Licence, r.Workers, avian.Status(r.Status)})
+	}
+	return pretty.Format(headers, body)
+}
@@ -16,7 +16,10 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
@@ -26,11 +29,21 @@ import (
 
 	"github.com/avian-digital-forensics/auto-processing/cmd/avian/cmd/heartbeat"
 	"github.com/avian-digital-forensics/auto-processing/cmd/avian/cmd/queue"
+	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	"github.com/avian-digital-forensics/auto-processing/pkg/audit"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/certs"
 	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
 	"github.com/avian-digital-forensics/auto-processing/pkg/logging"
+	"github.com/avian-digital-forensics/auto-processing/pkg/metrics"
+	"github.com/avian-digital-forensics/auto-processing/pkg/notify"
 	"github.com/avian-digital-forensics/auto-processing/pkg/pwsh"
 	"github.com/avian-digital-forensics/auto-processing/pkg/services"
+	"github.com/avian-digital-forensics/auto-processing/pkg/tracing"
+	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
+	"github.com/avian-digital-forensics/auto-processing/pkg/webhooks"
 	"github.com/gorilla/handlers"
 	"github.com/natefinch/lumberjack"
 	"go.uber.org/zap"
@@ -62,13 +75,23 @@ with the backend and the running Nuix-scripts.`,
 
 // variables from flags
 var (
-	address  string // Address for http to listen on
-	port     string // Port for http to listen on
-	debug    bool   // To debug the service
-	dbName   string // name for the SQLite-db
-	logPath  string // path for the log-files
-	verbose  bool   // Used to log to the console
-	dataPath string // path for data
+	address     string // Address for http to listen on
+	port        string // Port for http to listen on
+	debug       bool   // To debug the service
+	dbName      string // name for the SQLite-db
+	logPath     string // path for the log-files
+	verbose     bool   // Used to log to the console
+	dataPath    string // path for data
+	templateDir string // path for templates that overrides the embedded
+	tlsCert     string // path to the certificate for https
+	tlsKey      string // path to the key for the certificate
+	tlsCA       string // path to the CA for the certificate (distributed to the servers)
+	selfSigned  bool   // to use https with a self-signed certificate
+
+	smtp              notify.Config // smtp-server for the emails to the investigators
+	notifyTemplateDir string        // path for email-templates that overrides the embedded
+
+	otlpEndpoint string // endpoint for the OTLP/HTTP-collector for the traces
 )
 
 // loggers
@@ -96,6 +119,19 @@ func init() {
 	serviceCmd.Flags().StringVar(&logPath, "log-path", "./log/", "path to log-files")
 	serviceCmd.Flags().StringVar(&dataPath, "data-path", wd, "path to raw-data")
 	serviceCmd.Flags().BoolVar(&verbose, "verbose", false, "for logging to the console")
+	serviceCmd.Flags().StringVar(&templateDir, "template-dir", "", "path to script-templates (script.rb, stages/<stage>.rb) that overrides the embedded")
+	serviceCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "path to certificate (PEM) for https")
+	serviceCmd.Flags().StringVar(&tlsKey, "tls-key", "", "path to key (PEM) for the certificate")
+	serviceCmd.Flags().StringVar(&tlsCA, "tls-ca", "", "path to CA (PEM) for the certificate - distributed to the servers")
+	serviceCmd.Flags().BoolVar(&selfSigned, "tls-self-signed", false, "use https with a self-signed CA and certificate (created in <data-path>/tls)")
+	serviceCmd.Flags().StringVar(&smtp.Host, "smtp-host", "", "host for the smtp-server to send emails when runners finish, fail or time out")
+	serviceCmd.Flags().IntVar(&smtp.Port, "smtp-port", 587, "port for the smtp-server")
+	serviceCmd.Flags().StringVar(&smtp.Username, "smtp-username", "", "username for the smtp-server (password from "+notify.PasswordEnv+")")
+	serviceCmd.Flags().StringVar(&smtp.From, "smtp-from", "", "sender of the emails (e.g \"Avian <avian@example.com>\")")
+	serviceCmd.Flags().StringVar(&smtp.Domain, "smtp-domain", "", "domain for recipients without a domain (e.g the investigator simon -> simon@<domain>)")
+	serviceCmd.Flags().BoolVar(&smtp.TLS, "smtp-tls", false, "connect to the smtp-server with implicit TLS (e.g port 465) instead of STARTTLS")
+	serviceCmd.Flags().StringVar(&notifyTemplateDir, "notify-template-dir", "", "path to email-templates (subject.txt, body.txt, body.html) that overrides the embedded")
+	serviceCmd.Flags().StringVar(&otlpEndpoint, "otlp-endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "endpoint for the OTLP/HTTP-collector to export the traces to (e.g http://localhost:4318)")
 }
 
 func run() error {
@@ -173,7 +209,43 @@ func run() error {
 		port = os.Getenv("AVIAN_PORT")
 	}
 
-	serviceURI := fmt.Sprintf("http://%s:%s/oto/", address, port)
+	// Set the certificates for https
+	scheme := "http"
+	var ca []byte
+	if selfSigned {
+		if tlsCert != "" || tlsKey != "" || tlsCA != "" {
+			return fmt.Errorf("--tls-self-signed cannot be used with --tls-cert, --tls-key or --tls-ca")
+		}
+		files, err := certs.SelfSigned(filepath.Join(dataPath, "tls"), tlsHosts())
+		if err != nil {
+			return fmt.Errorf("failed to create self-signed certificate : %v", err)
+		}
+		tlsCert, tlsKey, tlsCA = files.Cert, files.Key, files.CA
+		logger.Info("Using self-signed certificate", zap.String("cert", tlsCert), zap.String("ca", tlsCA))
+	}
+	if tlsCert != "" || tlsKey != "" {
+		if tlsCert == "" || tlsKey == "" {
+			return fmt.Errorf("both --tls-cert and --tls-key must be specified for https")
+		}
+		scheme = "https"
+	}
+	if tlsCA != "" {
+		if scheme != "https" {
+			return fmt.Errorf("--tls-ca requires --tls-cert and --tls-key")
+		}
+		var err error
+		if ca, err = ioutil.ReadFile(tlsCA); err != nil {
+			return fmt.Errorf("failed to read CA-certificate : %v", err)
+		}
+		if _, err := certs.Pool(ca); err != nil {
+			return fmt.Errorf("invalid CA-certificate : %s - %v", tlsCA, err)
+		}
+	}
+	if scheme == "http" {
+		logger.Warn("The service is listening on plain http - use --tls-cert and --tls-key or --tls-self-signed for https")
+	}
+
+	serviceURI := fmt.Sprintf("%s://%s:%s/oto/", scheme, address, port)
 
 	// Connect to the database
 	logger.Info("Connecting to database")
@@ -197,6 +269,36 @@ func run() error {
 		return err
 	}
 
+	// Check that there are api-keys for the CLI
+	var keys int64
+	if err := db.Model(&auth.Key{}).Where("scope = ?", auth.ScopeAPI).Count(&keys).Error; err != nil {
+		return fmt.Errorf("failed to check api-keys : %v", err)
+	}
+	if keys == 0 {
+		logger.Warn("No api-keys has been created - create one with: avian auth create-key <name>")
+	}
+
+	// Load the templates for the runner-scripts
+	templates := ruby.DefaultTemplates()
+	if templateDir != "" {
+		logger.Info("Loading script-templates", zap.String("template-dir", templateDir))
+		templates, err = ruby.LoadTemplates(templateDir)
+		if err != nil {
+			return fmt.Errorf("failed to load script-templates : %v", err)
+		}
+	}
+	logger.Info("Using script-templates", zap.String("version", templates.Version), zap.Strings("overrides", templates.Overrides))
+
+	// Load the templates for the emails
+	notifyTemplates := notify.DefaultTemplates()
+	if notifyTemplateDir != "" {
+		logger.Info("Loading email-templates", zap.String("notify-template-dir", notifyTemplateDir))
+		notifyTemplates, err = notify.LoadTemplates(notifyTemplateDir)
+		if err != nil {
+			return fmt.Errorf("failed to load email-templates : %v", err)
+		}
+	}
+
 	// Create a powershell-shell for remote connections
 	logger.Info("Creating powershell-process for remote-connections")
 	shell, err := pwsh.New()
@@ -204,36 +306,102 @@ func run() error {
 		return fmt.Errorf("unable to create powershell-process : %v", err)
 	}
 
+	// export the traces for the queue, the runners and the
+	// callbacks from the scripts to the OTLP-collector
+	if otlpEndpoint != "" {
+		logger.Info("Exporting traces", zap.String("otlp-endpoint", otlpEndpoint))
+		tracer := tracing.New(tracing.Config{
+			Endpoint:    otlpEndpoint,
+			Headers:     tracing.ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+			ServiceName: "avian",
+		}, logger)
+		tracing.SetDefault(tracer)
+		go tracer.Run(context.Background())
+	}
+
+	// Create a oto-server
+	logger.Debug("Creating oto http-server")
+	server := otohttp.NewServer()
+	server.OnErr = auth.OnErr
+
+	// Register our services
+	logger.Debug("Registering our oto http-services")
+	broker := events.NewBroker(10000)
+	runnersvc := services.NewRunnerService(db, shell, logger, logHandler, serviceURI, dataPath, templates, broker)
+
+	// the fingerprint for the custody-key is logged, so the
+	// signatures for the chain of custody can be pinned to it
+	fingerprint, err := services.CustodyFingerprint(dataPath)
+	if err != nil {
+		return fmt.Errorf("failed to get the key for the chain of custody: %v", err)
+	}
+	logger.Info("Signing the chain of custody", zap.String("fingerprint", fingerprint))
+
 	// start the queue (queue handles when the runners should start)
 	logger.Info("Starting queue-service")
 	queue := queue.New(db,
 		shell,
+		runnersvc,
 		serviceURI,
+		templates,
+		ca,
 		logger,
 	)
 	go queue.Start()
-
-	// Create a oto-server
-	logger.Debug("Creating oto http-server")
-	server := otohttp.NewServer()
-
-	// Register our services
-	logger.Debug("Registering our oto http-services")
-	runnersvc := services.NewRunnerService(db, shell, logger, logHandler, serviceURI, dataPath)
 	api.RegisterRunnerService(server, runnersvc)
 	api.RegisterServerService(server, services.NewServerService(db, shell, logger))
 	api.RegisterNmsService(server, services.NewNmsService(db, logger))
+	api.RegisterWebhookService(server, services.NewWebhookService(db, logger))
+	api.RegisterAuditService(server, services.NewAuditService(db, logger))
 
 	logger.Debug("Starting heartbeat-service")
 	heartbeat := heartbeat.New(runnersvc, logger)
 	go heartbeat.Beat()
 
-	// Handle our oto-server @ /oto
+	// deliver the lifecycle-events for the runners to the webhooks
+	logger.Debug("Starting webhook-dispatcher")
+	dispatcher := webhooks.New(db, logger)
+	go dispatcher.Run(context.Background(), broker)
+
+	// send the emails when the runners finish, fail or time out
+	if smtp.Enabled() {
+		smtp.Password = os.Getenv(notify.PasswordEnv)
+		if smtp.From == "" {
+			smtp.From = smtp.Username
+		}
+		if smtp.From == "" {
+			return fmt.Errorf("specify the sender of the emails with --smtp-from")
+		}
+		logger.Debug("Starting notifier", zap.String("smtp-host", smtp.Host), zap.Int("smtp-port", smtp.Port))
+		notifier := notify.New(db, smtp, notifyTemplates, logger)
+		go notifier.Run(context.Background(), broker)
+	} else {
+		logger.Info("No smtp-server - the emails for the runners are not sent, use --smtp-host to send them")
+	}
+
+	// Handle our oto-server @ /oto, the oto-methods must respond within
+	// 15 seconds - the event-stream is kept open as long as the client listens
 	logger.Debug("Handle oto @ /oto/")
-	http.Handle("/oto/", server)
+	mux := http.NewServeMux()
+	mux.Handle("/oto/", tracing.Middleware(audit.Middleware(db, logger, metrics.Instrument(http.TimeoutHandler(server, 15*time.Second, `{"error": "timeout"}`)))))
+	mux.Handle("/oto/RunnerService.Events", events.Handler(broker, logger))
+
+	// Authenticate the requests to the oto-server with the api-keys
+	authServer := auth.Middleware(db, logger, mux)
+
+	// Serve the OpenAPI-document for the services without an api-key
+	logger.Debug("Handle the OpenAPI-document @ " + api.OpenAPIPath)
+	root := http.NewServeMux()
+	root.Handle(api.OpenAPIPath, api.OpenAPIHandler())
+
+	// Serve the metrics for Prometheus without an api-key
+	logger.Debug("Handle the metrics @ /metrics")
+	metrics.RegisterDB(metrics.Default, db)
+	root.Handle("/metrics", metrics.Default.Handler())
+	root.Handle("/", authServer)
 
 	// Wrap the http-server with the accesslogger
-	loggedServer := handlers.LoggingHandler(accessLogger, server)
+	loggedServer := handlers.LoggingHandler(accessLogger, root)
 
 	// Create our CORS-handlers
 	corsOrigins := handlers.AllowedOrigins([]string{"*"})
@@ -250,16 +418,21 @@ func run() error {
 		Handler: handlers.CORS(corsOrigins, corsMethods, corsHeaders)(loggedServer),
 		Addr:    fmt.Sprintf("%s:%s", address, port),
 		// Good practice: enforce timeouts for servers you create!
-		WriteTimeout: 15 * time.Second,
-		ReadTimeout:  15 * time.Second,
+		// (the write-timeout for the oto-methods is set by the TimeoutHandler)
+		ReadTimeout: 15 * time.Second,
+		TLSConfig:   &tls.Config{MinVersion: tls.VersionTLS12},
 	}
 
-	logger.Info("http-service listening", zap.String("address", address), zap.String("port", port))
+	logger.Info("http-service listening", zap.String("address", address), zap.String("port", port), zap.String("scheme", scheme))
 	if !verbose {
-		log.Printf("http-service listening @ %s:%s", address, port)
+		log.Printf("http-service listening @ %s://%s:%s", scheme, address, port)
 	}
 
-	if err := srv.ListenAndServe(); err != nil {
+	listen := srv.ListenAndServe
+	if scheme == "https" {
+		listen = func() error { return srv.ListenAndServeTLS(tlsCert, tlsKey) }
+	}
+	if err := listen(); err != nil {
 		logger.Error("cannot start http-server", zap.String("address", address), zap.String("port", port), zap.String("exception", err.Error()))
 		return err
 	}
@@ -332,6 +505,21 @@ func isAdmin() (bool, error) {
 	return member, nil
 }
 
+// tlsHosts returns the hosts for the self-signed certificate
+func tlsHosts() []string {
+	hosts := []string{"localhost", "127.0.0.1"}
+	if address != "0.0.0.0" && address != "" {
+		hosts = append(hosts, address)
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		hosts = append(hosts, hostname)
+	}
+	if ip, err := utils.GetIPAddress(); err == nil {
+		hosts = append(hosts, ip)
+	}
+	return hosts
+}
+
 func fixPath(path string) string {
 	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, "\\") {
 		return path
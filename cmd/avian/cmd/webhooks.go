@@ -0,0 +1,218 @@
+/*
+Copyright © 2020 Avian Digital Forensics <sja@avian.dk>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
+	"github.com/avian-digital-forensics/auto-processing/pkg/webhooks"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// webhooksCmd represents the webhooks command
+//
+// "avian webhooks"
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Webhooks for the lifecycle-events of the runners",
+	Long: `Webhooks sends the lifecycle-events for the runners and the stages
+as signed JSON-POSTs to your endpoints (e.g ticketing-system or chat).
+
+The events are: ` + strings.Join(webhooks.Events, ", "),
+}
+
+// webhooksAddCmd represents the add webhook command
+//
+// "avian webhooks add <name> --endpoint <url>"
+var webhooksAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Adds a webhook for the lifecycle-events",
+	Long: `Adds a webhook for the lifecycle-events. - For example:
+
+	avian webhooks add chat --endpoint https://chat.example.com/hooks/avian --events runner.finished,runner.failed,runner.timeout,stage.failed
+
+The secret for the signatures (X-Avian-Signature) is only shown when the webhook is added.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := addWebhook(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not add webhook to backend: %v\n", err)
+		}
+	},
+}
+
+// webhooksListCmd represents the list webhooks command
+//
+// "avian webhooks list"
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the webhooks",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listWebhooks(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "could not list webhooks from backend: %v\n", err)
+		}
+	},
+}
+
+// webhooksDeleteCmd represents the delete webhook command
+//
+// "avian webhooks delete <name>"
+var webhooksDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Deletes the specified webhook (specified by name) and its deliveries",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := deleteWebhook(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not delete webhook from backend: %v\n", err)
+		}
+	},
+}
+
+// webhooksDeliveriesCmd represents the webhook deliveries command
+//
+// "avian webhooks deliveries <name>"
+var webhooksDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries",
+	Short: "Lists the latest deliveries for the specified webhook (specified by name)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listDeliveries(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not list deliveries from backend: %v\n", err)
+		}
+	},
+}
+
+var (
+	webhookService  *avian.WebhookService
+	webhookEndpoint string
+	webhookEvents   []string
+	webhookRunner   string
+	webhookSecret   string
+	deliveriesLimit int64
+)
+
+func init() {
+	// Set the client for the webhook-service
+	// (the url, api-key and CA-bundle are from the env or config)
+	webhookService = avian.NewWebhookService(newClient())
+
+	// Add the commands to the correct hierarchy
+	rootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(webhooksAddCmd)
+	webhooksCmd.AddCommand(webhooksListCmd)
+	webhooksCmd.AddCommand(webhooksDeleteCmd)
+	webhooksCmd.AddCommand(webhooksDeliveriesCmd)
+	webhooksAddCmd.Flags().StringVar(&webhookEndpoint, "endpoint", "", "url to POST the events to (http or https)")
+	webhooksAddCmd.Flags().StringSliceVar(&webhookEvents, "events", nil, "events to send (comma-separated) - all events if not set")
+	webhooksAddCmd.Flags().StringVar(&webhookRunner, "runner", "", "only send the events for the runner")
+	webhooksAddCmd.Flags().StringVar(&webhookSecret, "secret", "", "secret to sign the events with - generated if not set")
+	webhooksAddCmd.MarkFlagRequired("endpoint")
+	webhooksDeliveriesCmd.Flags().Int64Var(&deliveriesLimit, "limit", 50, "amount of deliveries to list")
+}
+
+// addWebhook adds the webhook and
+// outputs the secret for the signatures
+func addWebhook(ctx context.Context, name string) error {
+	resp, err := webhookService.Add(ctx, avian.WebhookAddRequest{
+		Name:     name,
+		Endpoint: webhookEndpoint,
+		Events:   webhookEvents,
+		Runner:   strings.ToLower(webhookRunner),
+		Secret:   webhookSecret,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Webhook: %s has been added\n", name)
+	fmt.Fprintf(os.Stdout, "Secret for the signatures (only shown once): %s\n", resp.Webhook.Secret)
+	return nil
+}
+
+// listWebhooks lists all the webhooks
+func listWebhooks(ctx context.Context) error {
+	resp, err := webhookService.List(ctx, avian.WebhookListRequest{})
+	if err != nil {
+		return err
+	}
+
+	// format the response
+	headers := table.Row{"ID", "Name", "Endpoint", "Events", "Runner"}
+	var body []table.Row
+	for _, w := range resp.Webhooks {
+		events, runner := w.Events, w.Runner
+		if events == "" {
+			events = "all"
+		}
+		if runner == "" {
+			runner = "all"
+		}
+		body = append(body, table.Row{w.ID, w.Name, w.Endpoint, events, runner})
+	}
+
+	fmt.Println(pretty.Format(headers, body))
+	return nil
+}
+
+// deleteWebhook deletes the webhook
+func deleteWebhook(ctx context.Context, name string) error {
+	if _, err := webhookService.Delete(ctx, avian.WebhookDeleteRequest{Name: name}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Webhook: %s has been deleted", name)
+	return nil
+}
+
+// listDeliveries lists the latest deliveries for the webhook
+func listDeliveries(ctx context.Context, name string) error {
+	resp, err := webhookService.Deliveries(ctx, avian.WebhookDeliveriesRequest{Name: name, Limit: deliveriesLimit})
+	if err != nil {
+		return err
+	}
+
+	// format the response
+	headers := table.Row{"ID", "Event", "Runner", "Updated", "Attempts", "Status", "Next attempt", "Exception"}
+	var body []table.Row
+	for _, d := range resp.Deliveries {
+		status := "Failed"
+		if d.Delivered {
+			status = "Delivered"
+		}
+		next := ""
+		if d.NextAttemptAt != nil {
+			status = "Retrying"
+			if d.Attempts == 0 {
+				status = "Pending"
+			}
+			next = d.NextAttemptAt.Local().Format("2006-01-02 15:04:05")
+		}
+		if d.StatusCode != 0 {
+			status = fmt.Sprintf("%s (%d)", status, d.StatusCode)
+		}
+		updated := time.Unix(d.MTime, 0).Format("2006-01-02 15:04:05")
+		body = append(body, table.Row{d.ID, d.Event, d.Runner, updated, d.Attempts, status, next, d.Exception})
+	}
+
+	fmt.Println(pretty.Format(headers, body))
+	return nil
+}
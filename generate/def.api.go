@@ -11,6 +11,14 @@ import (
 type ServerService interface {
 	Apply(ServerApplyRequest) ServerApplyResponse
 	List(ServerListRequest) ServerListResponse
+
+	// Get returns the server with
+	// the runners that uses it
+	Get(ServerGetRequest) ServerGetResponse
+
+	// Delete deletes the server, refused while unfinished
+	// runners uses the server (unless forced)
+	Delete(ServerDeleteRequest) ServerDeleteResponse
 }
 
 // Server is the main struct for the
@@ -64,19 +72,89 @@ type ServerApplyResponse struct {
 
 // ServerListRequest is the input-object
 // for List in the server-service.
-type ServerListRequest struct{}
+type ServerListRequest struct {
+	// Hostname - only list the servers with hostnames
+	// matching the pattern (* matches any characters).
+	Hostname string
+
+	// Active - only list the active (or inactive) servers.
+	Active *bool
+
+	// Sort - the field to sort the servers by (id, hostname
+	// or created), prefixed with - for descending.
+	Sort string
+
+	// Limit - the amount of servers
+	// to list (every server if 0).
+	Limit int64
+
+	// Cursor - the NextCursor from the previous
+	// response to list the next page.
+	Cursor string
+}
 
 // ServerListResponse is the output-object
 // for List in the server-service.
 type ServerListResponse struct {
 	Servers []Server
+
+	// NextCursor - the cursor for the next page,
+	// empty if there are no more servers.
+	NextCursor string
 }
 
+// ServerGetRequest is the input-object
+// for Get in the server-service.
+type ServerGetRequest struct {
+	// Hostname of the server.
+	Hostname string
+
+	// Limit - the amount of runners to get,
+	// the latest first (defaults to 20).
+	Limit int64
+}
+
+// ServerGetResponse is the output-object
+// for Get in the server-service.
+type ServerGetResponse struct {
+	Server Server
+
+	// Runners that uses the server (the latest first).
+	Runners []Runner
+
+	// TotalRunners - the amount of runners
+	// that uses the server.
+	TotalRunners int64
+}
+
+// ServerDeleteRequest is the input-object
+// for Delete in the server-service.
+type ServerDeleteRequest struct {
+	// Hostname of the server.
+	Hostname string
+
+	// Force - delete the server even if
+	// unfinished runners uses it.
+	Force bool
+}
+
+// ServerDeleteResponse is the output-object
+// for Delete in the server-service.
+type ServerDeleteResponse struct{}
+
 // NmsService handles the Nuix Management Servers.
 type NmsService interface {
 	Apply(NmsApplyRequests) NmsApplyResponse
 	List(NmsListRequest) NmsListResponse
 	ListLicences(NmsListLicencesRequest) NmsListLicencesResponse
+
+	// Get returns the NMS with its licences
+	// and the runners that uses it
+	Get(NmsGetRequest) NmsGetResponse
+
+	// Delete deletes the NMS (and its licences), refused
+	// while unfinished runners uses the NMS (unless forced)
+	Delete(NmsDeleteRequest) NmsDeleteResponse
 }
 
 // Nms is the main struct for the Nuix Management Servers.
@@ -109,6 +187,25 @@ type Nms struct {
 
 	// Is NMS a CLS relay server.
 	IsRelay bool
+
+	// PoolsPath is the path for the licence-pools in the REST-api
+	// for the NMS (default: /api/v1/licences/pools).
+	PoolsPath string
+
+	// LeasesPath is the path for the leased licences in the REST-api
+	// for the NMS (default: /api/v1/licences/leases).
+	LeasesPath string
+
+	// Auth for the REST-api for the NMS - basic (default, with the
+	// username and password) or bearer (the password is the token).
+	Auth string
+
+	// Fingerprint (sha256) for the certificate for the NMS, pins
+	// the self-signed certificate instead of verifying its chain.
+	Fingerprint string
+
+	// Insecure - skip verifying the certificate for the NMS.
+	Insecure bool
 }
 
 // Licence holds information about licences
@@ -174,6 +271,25 @@ type NmsApplyRequest struct {
 
 	// Whether the NMS is a CLS relay server.
 	IsRelay bool
+
+	// PoolsPath is the path for the licence-pools in the REST-api
+	// for the NMS (default: /api/v1/licences/pools).
+	PoolsPath string
+
+	// LeasesPath is the path for the leased licences in the REST-api
+	// for the NMS (default: /api/v1/licences/leases).
+	LeasesPath string
+
+	// Auth for the REST-api for the NMS - basic (default, with the
+	// username and password) or bearer (the password is the token).
+	Auth string
+
+	// Fingerprint (sha256) for the certificate for the NMS, pins
+	// the self-signed certificate instead of verifying its chain.
+	Fingerprint string
+
+	// Insecure - skip verifying the certificate for the NMS.
+	Insecure bool
 }
 
 // NmsApplyResponse is the output-object for
@@ -184,26 +300,150 @@ type NmsApplyResponse struct {
 
 // NmsListRequest is the input-object for
 // List in the NMS-service.
-type NmsListRequest struct{}
+type NmsListRequest struct {
+	// Address - only list the nms-servers with addresses
+	// matching the pattern (* matches any characters).
+	Address string
+
+	// Licence - only list the nms-servers
+	// with the licence-type.
+	Licence string
+
+	// Sort - the field to sort the nms-servers by (id,
+	// address or created), prefixed with - for descending.
+	Sort string
+
+	// Limit - the amount of nms-servers
+	// to list (every nms-server if 0).
+	Limit int64
+
+	// Cursor - the NextCursor from the previous
+	// response to list the next page.
+	Cursor string
+}
 
 // NmsListResponse is the output-object for
 // List in the NMS-service.
 type NmsListResponse struct {
 	Nms []Nms
+
+	// NextCursor - the cursor for the next page,
+	// empty if there are no more nms-servers.
+	NextCursor string
+}
+
+// NmsGetRequest is the input-object for
+// Get in the NMS-service.
+type NmsGetRequest struct {
+	// Address for the nms-server.
+	Address string
+
+	// Limit - the amount of runners to get,
+	// the latest first (defaults to 20).
+	Limit int64
 }
 
+// NmsGetResponse is the output-object for
+// Get in the NMS-service.
+type NmsGetResponse struct {
+	Nms Nms
+
+	// Runners that uses the nms-server (the latest first).
+	Runners []Runner
+
+	// TotalRunners - the amount of runners
+	// that uses the nms-server.
+	TotalRunners int64
+}
+
+// NmsDeleteRequest is the input-object for
+// Delete in the NMS-service.
+type NmsDeleteRequest struct {
+	// Address for the nms-server.
+	Address string
+
+	// Force - delete the nms-server even
+	// if unfinished runners uses it.
+	Force bool
+}
+
+// NmsDeleteResponse is the output-object for
+// Delete in the NMS-service.
+type NmsDeleteResponse struct{}
+
 // NmsListLicencesRequest is the input-object for
 // listing licences for a specific NMS.
 type NmsListLicencesRequest struct {
 	// ID for the nms-server
 	// to list the licences for.
 	NmsID uint
+
+	// Address for the nms-server to list the
+	// licences for (if the ID is not specified).
+	Address string
 }
 
 // NmsListLicencesResponse is the output-object for
 // listing licences for a specific NMS.
 type NmsListLicencesResponse struct {
+	// Nms the licences are listed for.
+	Nms Nms
+
+	// Licences configured for the NMS in the service,
+	// with the licences reserved by the runners (InUse).
 	Licences []Licence
+
+	// Usage compares the licence-pools and the leases in
+	// the NMS with the licences in the service by type.
+	Usage []LicenceUsage
+
+	// Leases - the current leases in the NMS.
+	Leases []LicenceLease
+
+	// Exception is the error from the NMS if it could not
+	// be queried (only the licences in the service are listed).
+	Exception string
+}
+
+// LicenceUsage compares a licence-type in the NMS
+// with the licence-type in the service.
+type LicenceUsage struct {
+	// Type of licence.
+	Type string
+
+	// Amount of licences for the type in the service.
+	Amount int64
+
+	// InUse - licences reserved by the runners in the service.
+	InUse int64
+
+	// Pool - the size of the licence-pool in the NMS.
+	Pool int64
+
+	// Leased - licences leased from the pool in the NMS.
+	Leased int64
+
+	// Mismatch describes how the NMS and the service
+	// differs for the type, empty if they match.
+	Mismatch string
+}
+
+// LicenceLease is a lease of a licence in the NMS.
+type LicenceLease struct {
+	// Type of licence.
+	Type string
+
+	// Workers leased with the licence.
+	Workers int64
+
+	// User that has the lease.
+	User string
+
+	// Host that has the lease.
+	Host string
+
+	// LeasedAt is when the licence was leased.
+	LeasedAt *time.Time
 }
 
 // RunnerService handles all the runners.
@@ -220,6 +460,9 @@ type RunnerService interface {
 	// Delete deletes the requested Runner
 	Delete(RunnerDeleteRequest) RunnerDeleteResponse
 
+	// Cancel cancels a waiting or running Runner
+	Cancel(RunnerCancelRequest) RunnerCancelResponse
+
 	// Start sets a runner to started
 	Start(RunnerStartRequest) RunnerStartResponse
 
@@ -238,6 +481,9 @@ type RunnerService interface {
 	// FinishStage sets a stage to Finished
 	FinishStage(StageRequest) StageResponse
 
+	// Checkpoint stores the checkpoint for a stage (used by ruby script)
+	Checkpoint(CheckpointRequest) CheckpointResponse
+
 	// LogItem logs an item
 	LogItem(LogItemRequest) LogResponse
 
@@ -253,9 +499,29 @@ type RunnerService interface {
 	// Heartbeat sends a heartbeat for the api
 	Heartbeat(RunnerStartRequest) RunnerStartResponse
 
-	// Script returns the script for the runner
-	Script(RunnerGetRequest) RunnerScriptResponse
+	// Script returns the script for the runner, or
+	// the archived script for an earlier start of the runner
+	Script(RunnerScriptRequest) RunnerScriptResponse
+
+	// Plan returns the stages for the runner-script to execute (used by ruby script)
+	Plan(RunnerPlanRequest) RunnerPlanResponse
+
+	// HashSetMatches reports the matches for a hash-list
+	HashSetMatches(HashSetMatchesRequest) HashSetMatchesResponse
+
+	// ArchiveResult reports the result of an archived case
+	ArchiveResult(ArchiveResultRequest) ArchiveResultResponse
+
+	// CustodyRecords stores the hashes for evidence-files
+	CustodyRecords(CustodyRecordsRequest) CustodyRecordsResponse
+
+	// Custody returns the signed chain of custody for the runner
+	Custody(CustodyRequest) CustodyResponse
 
+	// CustodyKey returns the public-key that signs the chain of custody
+	CustodyKey(CustodyKeyRequest) CustodyKeyResponse
+
+	// UploadFile uploads a file to the uploads-folder in the data-path
 	UploadFile(UploadFileRequest) UploadFileResponse
 }
 
@@ -291,6 +557,28 @@ type Runner struct {
 	// HealthyAt - last time the runner was healthy
 	HealthyAt *time.Time
 
+	// MaxRuntime for the runner (e.g 12h) - the runner
+	// is stopped and set to timed out if it runs longer
+	MaxRuntime string
+
+	// StartedAt - when the runner was started
+	StartedAt *time.Time
+
+	// TemplateVersion - version of the script-templates
+	// used for the last run of the runner
+	TemplateVersion string
+
+	// Owner - the name of the api-key that applied the runner,
+	// investigators can only update and delete their own runners
+	Owner string
+
+	// Notify - comma-separated recipients for the emails when the
+	// runner has finished, failed or timed out (defaults to the investigator)
+	Notify string
+
+	// Exception for the last run if the runner failed or timed out
+	Exception string
+
 	// CaseSettings for the cases to use
 	CaseSettingsID uint
 	CaseSettings   *CaseSettings
@@ -325,6 +613,9 @@ type RunnerApplyRequest struct {
 	// Amount of workers to use for the runner
 	Workers int64
 
+	// MaxRuntime for the runner (e.g 12h)
+	MaxRuntime string
+
 	// CaseSettings is the settings for the cases
 	// that should be processed if Process-stage is used
 	CaseSettings *CaseSettings
@@ -335,6 +626,10 @@ type RunnerApplyRequest struct {
 	// Switches to use for nuix-console
 	Switches []string
 
+	// Notify - recipients for the emails when the runner has finished,
+	// failed or timed out (defaults to the investigator for the case)
+	Notify []string
+
 	// Update - if the runner should be updated
 	Update bool
 }
@@ -347,12 +642,55 @@ type RunnerApplyResponse struct {
 
 // RunnerListRequest is the input-object for
 // listing the runners from the backend
-type RunnerListRequest struct{}
+type RunnerListRequest struct {
+	// Status - only list the runners with the statuses
+	// (waiting, running, failed, finished or timeout).
+	Status []string
+
+	// Hostname - only list the runners for the server.
+	Hostname string
+
+	// Nms - only list the runners for the nms-server.
+	Nms string
+
+	// Owner - only list the runners owned by the
+	// api-key (the name of the key that applied them).
+	Owner string
+
+	// Investigator - only list the runners with
+	// the investigator for their single-case.
+	Investigator string
+
+	// CreatedAfter - only list the runners created after the time
+	// (approximate for the runners saved before the creation-time
+	// was kept - their creation-time is the time for their latest save).
+	CreatedAfter *time.Time
+
+	// Name - only list the runners with names matching
+	// the pattern (* matches any characters).
+	Name string
+
+	// Sort - the field to sort the runners by (id, name,
+	// created or status), prefixed with - for descending.
+	Sort string
+
+	// Limit - the amount of runners
+	// to list (defaults to 100).
+	Limit int64
+
+	// Cursor - the NextCursor from the previous
+	// response to list the next page.
+	Cursor string
+}
 
 // RunnerListResponse is the input-object for
 // listing the runners from the backend
 type RunnerListResponse struct {
 	Runners []Runner
+
+	// NextCursor - the cursor for the next page,
+	// empty if there are no more runners.
+	NextCursor string
 }
 
 // RunnerGetRequest is the input-object
@@ -389,10 +727,167 @@ type RunnerDeleteRequest struct {
 // for deleting a runner by name
 type RunnerDeleteResponse struct{}
 
+// RunnerCancelRequest is the input-object
+// for cancelling a runner by name
+type RunnerCancelRequest struct {
+	// Name of the runner
+	Name string
+
+	// Reason for the cancel (optional), it is
+	// set as the exception for the runner
+	Reason string
+}
+
+// RunnerCancelResponse is the output-object
+// for cancelling a runner by name
+type RunnerCancelResponse struct {
+	// Runner that was cancelled
+	Runner Runner
+}
+
+// RunnerScriptRequest is the input-object
+// for getting the script for a runner
+type RunnerScriptRequest struct {
+	// Name of the runner
+	Name string
+
+	// Attempt - the start of the runner to get the archived
+	// script for (1 for the first start), 0 generates the script
+	Attempt int64
+}
+
 // RunnerScriptResponse is the output-object
 // for GetScript
 type RunnerScriptResponse struct {
 	Script string
+
+	// Library holds the stage-handlers for the script
+	Library string
+
+	// Attempt the script was archived for,
+	// 0 if the script was generated for the request
+	Attempt int64
+
+	// Attempts - the amount of archived scripts for the runner
+	Attempts int64
+
+	// Hash for the script (sha256)
+	Hash string
+
+	// LibraryHash - hash for the library (sha256)
+	LibraryHash string
+
+	// Plan the script got from the service for the attempt (JSON),
+	// empty if the script was generated for the request or
+	// the script never got the plan
+	Plan string
+
+	// PlanHash - hash for the plan (sha256)
+	PlanHash string
+
+	// TemplateVersion - version of the script-templates
+	TemplateVersion string
+
+	// Arguments for nuix_console when the script was started
+	Arguments []string
+
+	// ArchivedAt is when the script was archived - the runner was started (unix-timestamp)
+	ArchivedAt int64
+}
+
+// ScriptArchive holds the generated script for a start of a
+// runner, so it can be shown exactly what was executed
+type ScriptArchive struct {
+	// Base for the datastore
+	datastore.Base
+
+	// RunnerID foreign-key for runner-table
+	RunnerID uint
+
+	// Attempt - the start of the runner (1 for the first start)
+	Attempt int64
+
+	// Script that was generated for the runner
+	Script string
+
+	// Library with the stage-handlers for the script
+	Library string
+
+	// Hash for the script (sha256)
+	Hash string
+
+	// LibraryHash - hash for the library (sha256)
+	LibraryHash string
+
+	// Plan the script got from the service (JSON),
+	// stored when the script requests the plan
+	Plan string
+
+	// PlanHash - hash for the plan (sha256)
+	PlanHash string
+
+	// TemplateVersion - version of the script-templates
+	TemplateVersion string
+
+	// Arguments for nuix_console in the order they were used
+	Arguments []ScriptArgument
+}
+
+// ScriptArgument is a command argument for
+// nuix_console when a script was started
+type ScriptArgument struct {
+	datastore.Base
+	ScriptArchiveID uint
+	Value           string
+}
+
+// RunnerPlanRequest is the input-object
+// for getting the plan for a runner
+type RunnerPlanRequest struct {
+	// Runner - name of the runner
+	Runner string
+}
+
+// RunnerPlanResponse is the output-object
+// for getting the plan for a runner
+type RunnerPlanResponse struct {
+	// ID of the runner
+	ID uint
+
+	// Runner - name of the runner
+	Runner string
+
+	// ScriptDir is the path to the avian-scripts on the server
+	ScriptDir string
+
+	// CaseSettings for the cases to open
+	CaseSettings *CaseSettings
+
+	// Stages to execute - in the order to execute them
+	Stages []*PlanStage
+}
+
+// PlanStage is a stage in the plan for a runner
+type PlanStage struct {
+	// ID of the stage
+	ID uint
+
+	// Kind of the stage - the name of the
+	// stage-handler to execute the stage with
+	Kind string
+
+	// Name of the stage
+	Name string
+
+	// Status of the stage
+	Status int64
+
+	// Checkpoint to resume the stage from (JSON) - empty
+	// if the stage should start from the beginning
+	Checkpoint string
+
+	// Stage with the settings for the stage
+	Stage *Stage
 }
 
 // RunnerStartRequest is the input-object
@@ -523,6 +1018,20 @@ type StageResponse struct {
 	Stage Stage
 }
 
+// CheckpointRequest is the input-object
+// for reporting a checkpoint for a stage
+type CheckpointRequest struct {
+	Runner  string
+	StageID uint
+
+	// Checkpoint for the stage (JSON)
+	Checkpoint string
+}
+
+// CheckpointResponse is the output-object
+// for reporting a checkpoint for a stage
+type CheckpointResponse struct{}
+
 // Stage holds different types of stages for a Runner
 type Stage struct {
 	// Base for the datastore
@@ -534,6 +1043,21 @@ type Stage struct {
 	// Index for where the stage where indexed in the yaml
 	Index uint
 
+	// Timeout for the stage (e.g 2h30m) - the runner
+	// is stopped and set to timed out if the stage runs longer
+	Timeout string
+
+	// StartedAt - when the stage was started
+	StartedAt *time.Time
+
+	// Checkpoint reported by the runner-script (JSON) - used
+	// to resume the stage from where it failed in the next run
+	Checkpoint string
+
+	// Items - the amount of items the stage has handled
+	// (the highest count logged by the runner-script)
+	Items int64
+
 	// Process-stage processes data into a Nuix-case
 	Process *Process
 
@@ -560,6 +1084,12 @@ type Stage struct {
 
 	// ScanNewChildItems scans for new child items based on a search
 	ScanNewChildItems *ScanNewChildItems
+
+	// HashSet tags or excludes items matching lists of known hashes
+	HashSet *HashSet
+
+	// Archive copies and verifies the single-case to an archive-share
+	Archive *Archive
 }
 
 // Process -stage processes data into a Nuix-case
@@ -577,6 +1107,10 @@ type Process struct {
 	// EvidenceStore to process to the nuix-case
 	EvidenceStore []*Evidence
 
+	// HashEvidence hashes every evidence-file
+	// before processing (chain of custody)
+	HashEvidence bool
+
 	// Status for the stage
 	Status int64
 }
@@ -787,7 +1321,113 @@ type ScanNewChildItems struct {
 	Status int64
 }
 
+// HashSet tags or excludes items in a Nuix-case
+// that match one or more lists of known hashes
+type HashSet struct {
+	// Base for the datastore
+	datastore.Base
+
+	// StageID foreign-key for stage-table
+	StageID uint
+
+	// Lists of known hashes to match the items against
+	Lists []*HashList
+
+	// Action for the matching items (tag or exclude)
+	Action string
+
+	// Tag for the matching items if the action is tag
+	Tag string
+
+	// Reason to exclude the matching items if the action is exclude
+	Reason string
+
+	// Status for the stage
+	Status int64
+}
+
+// HashList holds information about a file of known hashes
+type HashList struct {
+	// Base for the datastore
+	datastore.Base
+
+	// HashSetID foreign-key for hashset-table
+	HashSetID uint
+
+	// Path for where the list is located at
+	Path string
+
+	// Algorithm for the hashes in the list (md5 or sha1)
+	Algorithm string
+
+	// Matches is the amount of items that matched the list
+	Matches int64
+}
+
+// HashSetMatchesRequest is the input-object
+// for reporting the matches for a hash-list
+type HashSetMatchesRequest struct {
+	Runner  string
+	StageID uint
+	ListID  uint
+	Matches int64
+}
+
+// HashSetMatchesResponse is the output-object
+// for reporting the matches for a hash-list
+type HashSetMatchesResponse struct{}
+
+// Archive closes the cases and copies the single-, compound-
+// and review-cases to an archive-share, verified with per-file hashes
+type Archive struct {
+	// Base for the datastore
+	datastore.Base
+
+	// StageID foreign-key for stage-table
+	StageID uint
+
+	// Destination is the archive-share to copy the case to
+	Destination string
+
+	// RemoveSource - if the single-case directory should be removed
+	// after the copies has been verified (refused if a compound-
+	// or review-case is configured, they list the single-case)
+	RemoveSource bool
+
+	// Location of the archived cases (comma-separated)
+	Location string
+
+	// Verified - if all files in the archived cases
+	// matched the hashes of the source
+	Verified bool
+
+	// Files is the amount of files that was archived
+	Files int64
+
+	// Mismatches is the amount of files that
+	// did not match the hashes of the source
+	Mismatches int64
+
+	// Status for the stage
+	Status int64
+}
+
+// ArchiveResultRequest is the input-object
+// for reporting the result of an archived case
+type ArchiveResultRequest struct {
+	Runner     string
+	StageID    uint
+	Location   string
+	Files      int64
+	Mismatches int64
+}
+
+// ArchiveResultResponse is the output-object
+// for reporting the result of an archived case
+type ArchiveResultResponse struct{}
+
 type UploadFileRequest struct {
+	// Name of the file (without a path)
 	Name        string
 	Description string
 	Content     []byte
@@ -796,3 +1436,303 @@ type UploadFileRequest struct {
 type UploadFileResponse struct {
 	Path string
 }
+
+// CustodyRecord holds the hash for an evidence-file
+// that was ingested by a process-stage (chain of custody)
+type CustodyRecord struct {
+	// Base for the datastore
+	datastore.Base
+
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint
+
+	// Path to the evidence-file
+	Path string
+
+	// Size of the evidence-file in bytes
+	Size int64
+
+	// Algorithm used to hash the evidence-file
+	Algorithm string
+
+	// Hash for the evidence-file
+	Hash string
+
+	// ModifiedAt is when the evidence-file was last modified (unix-timestamp)
+	ModifiedAt int64
+
+	// HashedAt is when the evidence-file was hashed (unix-timestamp)
+	HashedAt int64
+}
+
+// CustodyRecordsRequest is the input-object
+// for storing the hashes for evidence-files
+type CustodyRecordsRequest struct {
+	Runner     string
+	StageID    uint
+	EvidenceID uint
+	Records    []CustodyRecord
+}
+
+// CustodyRecordsResponse is the output-object
+// for storing the hashes for evidence-files
+type CustodyRecordsResponse struct{}
+
+// CustodyRequest is the input-object
+// for exporting the chain of custody for a runner
+type CustodyRequest struct {
+	// Name of the runner
+	Name string
+
+	// Format for the document - json or csv
+	Format string
+}
+
+// CustodyResponse is the output-object
+// for exporting the chain of custody for a runner
+type CustodyResponse struct {
+	// Document for the chain of custody
+	Document string
+
+	// Signature for the document (base64-encoded ed25519-signature)
+	Signature string
+
+	// PublicKey to verify the signature with (base64-encoded)
+	PublicKey string
+
+	// Fingerprint for the public-key (sha256), it must match the
+	// fingerprint that is pinned from CustodyKey or the service-log
+	Fingerprint string
+}
+
+// CustodyKeyRequest is the input-object
+// for getting the key for the chain of custody
+type CustodyKeyRequest struct{}
+
+// CustodyKeyResponse is the output-object
+// for getting the key for the chain of custody
+type CustodyKeyResponse struct {
+	// PublicKey that verifies the signatures (PEM-encoded)
+	PublicKey string
+
+	// Fingerprint for the public-key (sha256)
+	Fingerprint string
+}
+
+// WebhookService handles the webhooks for the
+// lifecycle-events of the runners and the stages
+type WebhookService interface {
+	// Add adds a webhook, the secret for the signatures is only returned here
+	Add(WebhookAddRequest) WebhookAddResponse
+
+	// List returns the webhooks (without their secrets)
+	List(WebhookListRequest) WebhookListResponse
+
+	// Delete deletes a webhook and its deliveries
+	Delete(WebhookDeleteRequest) WebhookDeleteResponse
+
+	// Deliveries returns the delivery-log for a webhook
+	Deliveries(WebhookDeliveriesRequest) WebhookDeliveriesResponse
+}
+
+// Webhook is a subscription for the lifecycle-events,
+// the events are sent as signed JSON-POSTs to the URL
+type Webhook struct {
+	// Base for the datastore
+	datastore.Base
+
+	// Name for the webhook
+	Name string
+
+	// Endpoint is the URL to POST the events to
+	Endpoint string
+
+	// Events to send (comma-separated), all events if empty
+	Events string
+
+	// Runner to send the events for, all runners if empty
+	Runner string
+
+	// Secret to sign the events with (HMAC-SHA256)
+	Secret string
+}
+
+// WebhookDelivery is the delivery of an event to a webhook
+type WebhookDelivery struct {
+	// Base for the datastore
+	datastore.Base
+
+	// WebhookID foreign-key for webhook-table
+	WebhookID uint
+
+	// Event that was delivered (e.g runner.started)
+	Event string
+
+	// Runner the event belongs to
+	Runner string
+
+	// Payload is the JSON-body for the event
+	Payload string
+
+	// Attempts to deliver the event
+	Attempts int64
+
+	// StatusCode for the last attempt (0 if there was no response)
+	StatusCode int64
+
+	// Exception for the last attempt
+	Exception string
+
+	// Delivered is true when the webhook responded with 2xx
+	Delivered bool
+
+	// NextAttemptAt is when the delivery is retried,
+	// nil when it is delivered or has no attempts left
+	NextAttemptAt *time.Time
+}
+
+// WebhookAddRequest is the input-object
+// for adding a webhook
+type WebhookAddRequest struct {
+	// Name for the webhook
+	Name string
+
+	// Endpoint is the URL to POST the events to (http or https)
+	Endpoint string
+
+	// Events to send (e.g runner.started, stage.failed), all events if empty
+	Events []string
+
+	// Runner to send the events for, all runners if empty
+	Runner string
+
+	// Secret to sign the events with, generated if empty
+	Secret string
+}
+
+// WebhookAddResponse is the output-object
+// for adding a webhook
+type WebhookAddResponse struct {
+	// Webhook that was added (with its secret)
+	Webhook Webhook
+}
+
+// WebhookListRequest is the input-object
+// for listing the webhooks
+type WebhookListRequest struct{}
+
+// WebhookListResponse is the output-object
+// for listing the webhooks
+type WebhookListResponse struct {
+	Webhooks []Webhook
+}
+
+// WebhookDeleteRequest is the input-object
+// for deleting a webhook
+type WebhookDeleteRequest struct {
+	// Name of the webhook
+	Name string
+}
+
+// WebhookDeleteResponse is the output-object
+// for deleting a webhook
+type WebhookDeleteResponse struct{}
+
+// WebhookDeliveriesRequest is the input-object
+// for the delivery-log for a webhook
+type WebhookDeliveriesRequest struct {
+	// Name of the webhook
+	Name string
+
+	// Limit for the amount of deliveries (the latest first), 50 if 0
+	Limit int64
+}
+
+// WebhookDeliveriesResponse is the output-object
+// for the delivery-log for a webhook
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery
+}
+
+// AuditService returns the audit-trail for the
+// configuration- and control-actions
+type AuditService interface {
+	// List returns the entries in the audit-trail (the latest first)
+	List(AuditListRequest) AuditListResponse
+}
+
+// AuditEntry is an entry in the append-only audit-trail (the db refuses
+// to update or delete it), recorded for every mutating call to the api
+// and for the api-keys managed by the CLI
+type AuditEntry struct {
+	// Base for the datastore
+	datastore.Base
+
+	// Time for the call
+	Time time.Time
+
+	// Identity is the name of the api-key for the call
+	Identity string
+
+	// Role for the api-key
+	Role string
+
+	// RemoteAddr is the source-ip for the call
+	RemoteAddr string
+
+	// ForwardedFor is the X-Forwarded-For header for the call (if set)
+	ForwardedFor string
+
+	// Method that was called (e.g RunnerService.Apply)
+	Method string
+
+	// Resource the method changes (runner, server, nms, webhook, file or api-key)
+	Resource string
+
+	// Target is the name of the resource (e.g the name of the runner)
+	Target string
+
+	// StatusCode for the response
+	StatusCode int64
+
+	// Exception is the error for the call (if it failed)
+	Exception string
+
+	// Request is the JSON-body for the call (without passwords and secrets)
+	Request string
+
+	// Diff is the changes (JSON) from the request compared
+	// with the state in the db before the call
+	Diff string
+}
+
+// AuditListRequest is the input-object
+// for listing the audit-trail
+type AuditListRequest struct {
+	// Identity to list the entries for
+	Identity string
+
+	// Method to list the entries for (e.g RunnerService.Delete)
+	Method string
+
+	// Resource to list the entries for (runner, server, nms, webhook or file)
+	Resource string
+
+	// Target to list the entries for (e.g the name of the runner)
+	Target string
+
+	// Since lists the entries from this time
+	Since *time.Time
+
+	// Until lists the entries before this time
+	Until *time.Time
+
+	// Limit for the amount of entries (the latest first), 100 if 0
+	Limit int64
+}
+
+// AuditListResponse is the output-object
+// for listing the audit-trail
+type AuditListResponse struct {
+	Entries []AuditEntry
+}
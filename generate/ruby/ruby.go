@@ -1,129 +1,162 @@
 package ruby
 
 import (
-	"html/template"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
 
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
-	"github.com/avian-digital-forensics/auto-processing/pkg/inapp"
-	"github.com/gobuffalo/plush"
 )
 
-// Generates a ruby script to be used by runner.
-func Generate(remoteAddress, scriptDir string, runner api.Runner) (string, error) {
-	ctx := plush.NewContext()
+// LibraryName is the name of the library with the stage-handlers,
+// it is created next to the runner-script on the server
+const LibraryName = "avian.stages.rb"
+
+// CAName is the name of the CA-certificate for the service, it is
+// created next to the runner-script on the server when the service
+// uses https - the script verifies the service against it
+const CAName = "avian-ca.pem"
+
+// libraryHeader is the start of the library, the stage-handlers
+// are registered with stage_handler by the key of their stage-type
+const libraryHeader = `# Code generated by Avian; DO NOT EDIT.
+# Stage-handlers for the runner-scripts, a handler is called
+# with the stage from the plan, the settings for the stage-type
+# and the context with the cases that are shared between the stages.
+STATUS_WAITING = 0
+STATUS_RUNNING = 1
+STATUS_FAILED = 2
+STATUS_FINISHED = 3
+STATUS_TIMEOUT = 4
+
+# Items between the checkpoints for the stages
+# that are not processed in batches
+CHECKPOINT_INTERVAL = 1000
+
+STAGE_HANDLERS = {}
+
+# Registers the handler for the stage-type
+def stage_handler(kind, &handler)
+  STAGE_HANDLERS[kind] = handler
+end
+
+# Returns the checkpoint for the stage from the plan,
+# empty if the stage should start from the beginning
+def stage_checkpoint(stage)
+  return {} if stage['checkpoint'].nil? || stage['checkpoint'].empty?
+  JSON.parse(stage['checkpoint'])
+end
+
+# Returns the position (processed items or files) to resume the stage
+# from, the checkpoint is only used if it was reported for the same
+# amount of items - otherwise the stage starts from the beginning
+def resume_position(stage, items)
+  resume = stage_checkpoint(stage)
+  return 0 if resume.empty?
+  if resume['items'] != items
+    log_info(stage['name'], stage['id'], "Checkpoint was reported for #{resume['items']} items but found #{items} - starting from the beginning")
+    return 0
+  end
+  log_info(stage['name'], stage['id'], "Resuming from checkpoint at #{resume['position']} of #{items}")
+  resume['position'].to_i
+end
+`
+
+// Generate generates the runner-script for the runner
+// with the embedded templates (see Templates.Generate)
+func Generate(remoteAddress, runner string) (string, error) {
+	return DefaultTemplates().Generate(remoteAddress, runner)
+}
 
-	// The code below defines functions that can be called when generating the ruby script.
+// Library returns the library with the embedded
+// stage-handlers (see Templates.Library)
+func Library() string { return DefaultTemplates().Library() }
 
-	// Whether the the runner has an unfinished processing stage.
-	ctx.Set("hasProcessingStage", func(runner api.Runner) bool {
-		for _, stage := range runner.Stages {
-			if stage.Process != nil && !avian.Finished(stage.Process.Status) {
-				return true
-			}
-		}
-		return false
-	})
-
-	// Whether the the runner has an unfinished in-app script stage.
-	ctx.Set("hasInAppStage", func(runner api.Runner) bool {
-		for _, stage := range runner.Stages {
-			if stage.InApp != nil && !avian.Finished(stage.InApp.Status) {
-				return true
-			}
-		}
-		return false
-	})
-
-	// Gets the processing profile of the first processing stage.
-	ctx.Set("getProcessingProfile", func(runner api.Runner) string {
-		for _, stage := range runner.Stages {
-			if stage.Process != nil {
-				return stage.Process.Profile
-			}
-		}
-		return ""
-	})
-
-	// Gets the settings file of the given in-app script settings as a string.
-	ctx.Set("settingsFile", func(settings inapp.Settings) string {
+// Hash returns the hash (hex-encoded sha256) for a script or library,
+// archived with the script for every start of a runner
+func Hash(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
 
-		return settings.SettingsFile
-	})
+// Plan returns the plan for the runner-script with the unfinished
+// stages for the runner. The processing-stage is executed first
+// since it opens the compound-cases for the other stages.
+func Plan(runner api.Runner, scriptDir string) *api.RunnerPlanResponse {
+	plan := &api.RunnerPlanResponse{
+		ID:           runner.ID,
+		Runner:       runner.Name,
+		ScriptDir:    scriptDir,
+		CaseSettings: runner.CaseSettings,
+		Stages:       []*api.PlanStage{},
+	}
+
+	var stages []*api.PlanStage
+	for _, stage := range runner.Stages {
+		kind := stage.Kind()
+		if kind == nil || avian.Finished(kind.Status(stage)) {
+			continue
+		}
 
-	// Gets the stage ID of the first processing stage.
-	ctx.Set("getProcessingStageID", func(runner api.Runner) uint {
-		for _, stage := range runner.Stages {
-			if stage.Process != nil {
-				return stage.ID
-			}
+		s := &api.PlanStage{
+			ID:         stage.ID,
+			Kind:       kind.Key(),
+			Name:       kind.Name(stage),
+			Status:     kind.Status(stage),
+			Checkpoint: stage.Checkpoint,
+			Stage:      stage,
 		}
-		return 0
-	})
-
-	// Returns whether the first processing stage has failed.
-	ctx.Set("getProcessingFailed", func(runner api.Runner) bool {
-		for _, stage := range runner.Stages {
-			if stage.Process != nil {
-				return (stage.Process.Status == avian.StatusFailed)
-			}
+		if stage.Process != nil {
+			plan.Stages = append(plan.Stages, s)
+			continue
 		}
-		return false
-	})
-
-	// Gets the path to the processing profile of the first processing stage.
-	// Retuns "" if there is no processing stage.
-	ctx.Set("getProcessingProfilePath", func(runner api.Runner) string {
-		for _, stage := range runner.Stages {
-			if stage.Process != nil {
-				return stage.Process.ProfilePath
+		stages = append(stages, s)
+	}
+	plan.Stages = append(plan.Stages, stages...)
+	return plan
+}
+
+// Quote returns s as a double-quoted ruby string-literal
+// where quotes, backslashes, control-characters and
+// interpolations (#{}) are escaped.
+func Quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '#':
+			b.WriteString(`\#`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\x%02X`, r)
+				continue
 			}
+			b.WriteRune(r)
 		}
-		return ""
-	})
-
-	// Gets the evidence store of the first processing stage.
-	// Returns nil if there is no processing stage.
-	ctx.Set("getEvidence", func(runner api.Runner) []*api.Evidence {
-		for _, stage := range runner.Stages {
-			if stage.Process != nil {
-				return stage.Process.EvidenceStore
-			}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// Comment returns s with the control-characters replaced
+// by spaces, so it can be used in a ruby comment.
+func Comment(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
 		}
-		return nil
-	})
-
-	// Returns all stages for the runner.
-	ctx.Set("getStages", func(runner api.Runner) []*api.Stage { return runner.Stages })
-	ctx.Set("elasticSearch", func(runner api.Runner) bool { return runner.CaseSettings.Case.ElasticSearch != nil })
-	ctx.Set("isNoProcessing", func(stage *api.Stage) bool { return stage.Process == nil })
-	// The next functions return for a stage whether they are the specic type and are unfinished.
-	ctx.Set("searchAndTag", func(stage *api.Stage) bool {
-		return stage.SearchAndTag != nil && !avian.Finished(stage.SearchAndTag.Status)
-	})
-	ctx.Set("exclude", func(stage *api.Stage) bool { return stage.Exclude != nil && !avian.Finished(stage.Exclude.Status) })
-	ctx.Set("ocr", func(stage *api.Stage) bool { return stage.Ocr != nil && !avian.Finished(stage.Ocr.Status) })
-	ctx.Set("populate", func(stage *api.Stage) bool { return stage.Populate != nil && !avian.Finished(stage.Populate.Status) })
-	ctx.Set("reload", func(stage *api.Stage) bool { return stage.Reload != nil && !avian.Finished(stage.Reload.Status) })
-	ctx.Set("inApp", func(stage *api.Stage) bool { return stage.InApp != nil && !avian.Finished(stage.InApp.Status) })
-	ctx.Set("scanNewChildItems", func(stage *api.Stage) bool {
-		return stage.ScanNewChildItems != nil && !avian.Finished(stage.ScanNewChildItems.Status)
-	})
-	ctx.Set("syncDescendants", func(stage *api.Stage) bool {
-		return stage.SyncDescendants != nil && !avian.Finished(stage.SyncDescendants.Status)
-	})
-
-	ctx.Set("stageName", func(stage *api.Stage) string { return avian.Name(stage) })
-	ctx.Set("formatQuotes", func(s string) template.HTML { return template.HTML(s) })
-	ctx.Set("shouldRun", func(stage *api.Stage) bool { return avian.StageState(stage) != avian.StatusFinished })
-
-	// Returns the remote address.
-	ctx.Set("remoteAddress", remoteAddress)
-	// Returns the path to the avian scripts directory.
-	ctx.Set("scriptDir", scriptDir)
-	// Returns the runner.
-	ctx.Set("runner", runner)
-
-	// Creates the template.
-	return plush.Render(rubyTemplate, ctx)
+		return r
+	}, s)
 }
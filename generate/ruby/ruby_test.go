@@ -0,0 +1,593 @@
+package ruby_test
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/inapp"
+	"github.com/matryer/is"
+)
+
+var update = flag.Bool("update", false, "update the golden-files in testdata")
+
+const (
+	remoteAddress = "http://avian.test:8080/oto/"
+	scriptDir     = "C:\\Program Files\\Nuix\\Nuix 8.4\\avian-scripts"
+)
+
+func TestGenerate(t *testing.T) {
+	var tt = []struct {
+		name   string
+		runner string
+	}{
+		{name: "script", runner: "test-runner"},
+		{name: "script-escaping", runner: "o'brien\"#{system('calc')}\"\nrunner"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+
+			script, err := ruby.Generate(remoteAddress, tc.runner)
+			is.NoErr(err)
+			is.NoErr(checkBlocks(script))
+			checkGolden(t, filepath.Join("testdata", tc.name+".rb"), script)
+		})
+	}
+}
+
+func TestLibrary(t *testing.T) {
+	is := is.New(t)
+	library := ruby.Library()
+	is.NoErr(checkBlocks(library))
+
+	// every stage-type must have a stage-handler
+	for _, kind := range api.StageKinds() {
+		if !strings.Contains(library, fmt.Sprintf("stage_handler('%s') do |stage, ", kind.Key())) {
+			t.Errorf("no stage-handler for stage-type: %s", kind.Key())
+		}
+	}
+	checkGolden(t, filepath.Join("testdata", "library.rb"), library)
+}
+
+func TestPlan(t *testing.T) {
+	// the fixtures get the same stage-ids in every run of the test
+	stageID = 0
+	var tt = []struct {
+		name   string
+		runner api.Runner
+	}{
+		{name: "process", runner: newRunner(process(avian.StatusWaiting))},
+		{name: "process-failed", runner: newRunner(process(avian.StatusFailed))},
+		{name: "process-timeout", runner: newRunner(process(avian.StatusTimeout))},
+		{name: "process-hash-evidence", runner: newRunner(hashEvidence(process(avian.StatusWaiting)))},
+		{name: "process-finished", runner: newRunner(process(avian.StatusFinished), exclude(avian.StatusWaiting))},
+		{name: "elastic-search", runner: elasticSearch(newRunner(process(avian.StatusWaiting)))},
+		{name: "search-and-tag", runner: newRunner(searchAndTag(avian.StatusWaiting))},
+		{name: "search-and-tag-files", runner: newRunner(searchAndTagFiles(avian.StatusWaiting))},
+		{name: "exclude", runner: newRunner(exclude(avian.StatusWaiting))},
+		{name: "ocr", runner: newRunner(ocr(avian.StatusWaiting))},
+		{name: "ocr-failed", runner: newRunner(ocr(avian.StatusFailed))},
+		{name: "ocr-checkpoint", runner: newRunner(checkpoint(ocr(avian.StatusFailed), `{"position":200,"items":1000}`))},
+		{name: "populate", runner: newRunner(populate(avian.StatusWaiting))},
+		{name: "reload", runner: newRunner(reload(avian.StatusWaiting))},
+		{name: "in-app", runner: newRunner(inApp(avian.StatusWaiting))},
+		{name: "sync-descendants", runner: newRunner(syncDescendants(avian.StatusWaiting))},
+		{name: "scan-new-child-items", runner: newRunner(scanNewChildItems(avian.StatusWaiting))},
+		{name: "hash-set", runner: newRunner(hashSet(avian.StatusWaiting))},
+		{name: "archive", runner: newRunner(process(avian.StatusWaiting), archive(avian.StatusWaiting))},
+		{name: "finished-stages", runner: newRunner(
+			process(avian.StatusFinished),
+			searchAndTag(avian.StatusFinished),
+			ocr(avian.StatusFinished),
+			exclude(avian.StatusWaiting),
+		)},
+		{name: "all-stages", runner: newRunner(
+			process(avian.StatusWaiting),
+			searchAndTagFiles(avian.StatusWaiting),
+			exclude(avian.StatusWaiting),
+			ocr(avian.StatusWaiting),
+			populate(avian.StatusWaiting),
+			reload(avian.StatusWaiting),
+			inApp(avian.StatusWaiting),
+			syncDescendants(avian.StatusWaiting),
+			scanNewChildItems(avian.StatusWaiting),
+			hashSet(avian.StatusWaiting),
+			archive(avian.StatusWaiting),
+		)},
+		{name: "process-last", runner: newRunner(exclude(avian.StatusWaiting), process(avian.StatusWaiting))},
+		{name: "archive-remove-source", runner: singleCase(newRunner(process(avian.StatusWaiting), removeSource(archive(avian.StatusWaiting))))},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+
+			// the fixtures must be valid configs, the in-app stages are
+			// skipped since their config-files are read by the validator
+			valid := tc.runner
+			valid.Stages = nil
+			for _, stage := range tc.runner.Stages {
+				if stage.InApp == nil {
+					copied := *stage // the validator sets the index
+					valid.Stages = append(valid.Stages, &copied)
+				}
+			}
+			is.NoErr(valid.Validate())
+
+			plan := ruby.Plan(tc.runner, scriptDir)
+			for _, stage := range plan.Stages {
+				is.True(stage.Kind != "")
+				is.True(!avian.Finished(stage.Status))
+			}
+
+			b, err := json.MarshalIndent(plan, "", "  ")
+			is.NoErr(err)
+			checkGolden(t, filepath.Join("testdata", "plan", tc.name+".json"), string(b)+"\n")
+		})
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	var tt = []struct {
+		name      string
+		files     map[string]string
+		overrides []string
+		err       string
+	}{
+		{name: "empty"},
+		{
+			name: "overrides",
+			files: map[string]string{
+				"script.rb":     "# custom-script for <%= rubyComment(runner) %>\nRUNNER = <%= rubyString(runner) %>\n",
+				"stages/ocr.rb": "stage_handler('ocr') do |stage, settings, context|\n  log_info(stage['name'], stage['id'], 'custom-ocr')\nend\n",
+			},
+			overrides: []string{"script.rb", "stages/ocr.rb"},
+		},
+		{name: "unknown-stage", files: map[string]string{"stages/export.rb": ""}, err: "unknown stage-template: stages/export.rb"},
+		{name: "unknown-file", files: map[string]string{"ocr.rb": ""}, err: "unknown file in template-dir: ocr.rb"},
+		{name: "no-handler", files: map[string]string{"stages/ocr.rb": "stage_handler('populate') do |stage, settings, context|\nend\n"}, err: "must register the handler with stage_handler('ocr')"},
+		{name: "invalid-script", files: map[string]string{"script.rb": "RUNNER = <%= rubyString(runner %>"}, err: "invalid template: script.rb"},
+	}
+
+	defaults := ruby.DefaultTemplates()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			dir, err := ioutil.TempDir("", "avian-templates")
+			is.NoErr(err)
+			defer os.RemoveAll(dir)
+			for name, content := range tc.files {
+				is.NoErr(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), os.ModePerm))
+				is.NoErr(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+			}
+
+			templates, err := ruby.LoadTemplates(dir)
+			if tc.err != "" {
+				is.True(err != nil)
+				is.True(strings.Contains(err.Error(), tc.err))
+				return
+			}
+			is.NoErr(err)
+			is.Equal(templates.Overrides, tc.overrides)
+
+			script, err := templates.Generate(remoteAddress, "test-runner")
+			is.NoErr(err)
+			if len(tc.overrides) == 0 {
+				// without overrides the templates are the embedded
+				is.Equal(templates.Version, defaults.Version)
+				is.True(strings.HasPrefix(templates.Version, "embedded-"))
+				is.Equal(templates.Library(), defaults.Library())
+				return
+			}
+			is.True(strings.HasPrefix(templates.Version, "custom-"))
+			is.Equal(script, "# custom-script for test-runner\nRUNNER = \"test-runner\"\n")
+			is.True(strings.Contains(templates.Library(), "custom-ocr"))
+			is.True(strings.Contains(templates.Library(), "stage_handler('populate')"))
+		})
+	}
+}
+
+// checkGolden compares the output with the golden-file,
+// the golden-file is updated when the tests runs with -update
+func checkGolden(t *testing.T, golden, output string) {
+	t.Helper()
+	is := is.NewRelaxed(t)
+	if *update {
+		is.NoErr(os.MkdirAll(filepath.Dir(golden), 0755))
+		is.NoErr(ioutil.WriteFile(golden, []byte(output), 0644))
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	is.NoErr(err)
+	if output != string(expected) {
+		t.Errorf("output does not match %s (run go test with -update to update the golden-files)", golden)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	is := is.New(t)
+
+	var tt = []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "empty", value: "", expected: `""`},
+		{name: "plain", value: "kind:email", expected: `"kind:email"`},
+		{name: "single-quote", value: "O'Brien", expected: `"O'Brien"`},
+		{name: "double-quote", value: `name:"John Doe"`, expected: `"name:\"John Doe\""`},
+		{name: "backslash", value: `C:\Evidence\`, expected: `"C:\\Evidence\\"`},
+		{name: "unc-path", value: `\\server\share`, expected: `"\\\\server\\share"`},
+		{name: "newline", value: "first\nsecond\r\n", expected: `"first\nsecond\r\n"`},
+		{name: "tab", value: "a\tb", expected: `"a\tb"`},
+		{name: "control-character", value: "a\x00b\x1b", expected: `"a\x00b\x1B"`},
+		{name: "interpolation", value: "#{system('calc')}", expected: `"\#{system('calc')}"`},
+		{name: "injection", value: "x'); system('calc'); ('", expected: `"x'); system('calc'); ('"`},
+		{name: "unicode", value: "Ærø", expected: `"Ærø"`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			is.Equal(ruby.Quote(tc.value), tc.expected)
+		})
+	}
+}
+
+func TestComment(t *testing.T) {
+	is := is.New(t)
+
+	var tt = []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "plain", value: "evidence_1", expected: "evidence_1"},
+		{name: "newline", value: "evidence\nsystem('calc')", expected: "evidence system('calc')"},
+		{name: "carriage-return", value: "a\r\nb", expected: "a  b"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			is.Equal(ruby.Comment(tc.value), tc.expected)
+		})
+	}
+}
+
+func TestCheckBlocks(t *testing.T) {
+	is := is.New(t)
+
+	var tt = []struct {
+		name   string
+		script string
+		err    string
+	}{
+		{name: "balanced", script: "begin\n  puts('a')\nrescue => e\n  puts(e)\nend\n"},
+		{name: "nested", script: "def a(b)\n  [1].each do |i|\n    if i\n      puts(i)\n    end\n  end\nrescue\n  puts('end')\nend\n"},
+		{name: "modifiers", script: "x = 1 if true\ny = 2 unless false\nbegin\n  z = 3\nend while false\n"},
+		{name: "missing-end", script: "begin\n  puts('a')\nrescue => e\n", err: "unclosed block: begin (line 1)"},
+		{name: "extra-end", script: "puts('a')\nend\n", err: "line 2: end without a block"},
+		{name: "rescue-outside-begin", script: "if a\nrescue\nend\n", err: "line 2: rescue outside begin/def/do-block"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkBlocks(tc.script)
+			if tc.err == "" {
+				is.NoErr(err)
+				return
+			}
+			is.True(err != nil)
+			is.Equal(err.Error(), tc.err)
+		})
+	}
+}
+
+var (
+	// strings are removed before the keywords are checked
+	rubyStrings = regexp.MustCompile(`'(\\.|[^'\\])*'|"(\\.|[^"\\])*"`)
+	// do-blocks opened at the end of a line
+	rubyDo = regexp.MustCompile(`\bdo(\s*\|[^|]*\|)?\s*$`)
+	// keywords that opens a block when they start a statement
+	rubyOpeners = regexp.MustCompile(`^(begin|def|if|unless|while|until|for|case|class|module)\b|=\s*(begin|if|unless|case)\b`)
+)
+
+// checkBlocks checks that the begin/rescue/end-blocks
+// (and the other ruby-blocks) are balanced in the script
+func checkBlocks(script string) error {
+	type block struct {
+		keyword string
+		line    int
+	}
+	var stack []block
+
+	for i, line := range strings.Split(script, "\n") {
+		n := i + 1
+		line = strings.TrimSpace(rubyStrings.ReplaceAllString(line, "''"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		words := strings.Fields(line)
+		switch {
+		case words[0] == "end" || strings.HasPrefix(words[0], "end."):
+			if len(stack) == 0 {
+				return fmt.Errorf("line %d: end without a block", n)
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		case words[0] == "rescue" || words[0] == "ensure":
+			if len(stack) == 0 {
+				return fmt.Errorf("line %d: %s outside begin/def/do-block", n, words[0])
+			}
+			switch stack[len(stack)-1].keyword {
+			case "begin", "def", "do":
+			default:
+				return fmt.Errorf("line %d: %s outside begin/def/do-block", n, words[0])
+			}
+			continue
+		}
+
+		if m := rubyOpeners.FindStringSubmatch(line); m != nil {
+			keyword := m[1]
+			if keyword == "" {
+				keyword = m[2]
+			}
+			// single-line blocks (e.g "if a then b end")
+			if !strings.HasSuffix(line, " end") {
+				stack = append(stack, block{keyword, n})
+			}
+			continue
+		}
+
+		if rubyDo.MatchString(line) {
+			stack = append(stack, block{"do", n})
+		}
+	}
+
+	if len(stack) != 0 {
+		b := stack[len(stack)-1]
+		return fmt.Errorf("unclosed block: %s (line %d)", b.keyword, b.line)
+	}
+	return nil
+}
+
+// stageID is incremented for each stage in the fixtures,
+// the tests reset it before they create their fixtures
+var stageID uint
+
+func newStage() *api.Stage {
+	stageID++
+	s := &api.Stage{}
+	s.ID = stageID
+	return s
+}
+
+func newRunner(stages ...*api.Stage) api.Runner {
+	runner := api.Runner{
+		Name:     "test-runner",
+		Hostname: "nuix-server",
+		Nms:      "nms.avian.test",
+		Licence:  "enterprise-workstation",
+		Xmx:      "16g",
+		Workers:  2,
+		CaseSettings: &api.CaseSettings{
+			CaseLocation: "C:\\Cases",
+			Case: &api.Case{
+				Name:         "test-runner-single",
+				Directory:    "C:\\Cases/test-runner-single",
+				Description:  "single-case",
+				Investigator: "investigator",
+			},
+			CompoundCase: &api.Case{
+				Name:         "test-runner-compound",
+				Directory:    "C:\\Cases/test-runner-compound",
+				Description:  "compound-case",
+				Investigator: "investigator",
+			},
+			ReviewCompound: &api.Case{
+				Name:         "test-runner-review",
+				Directory:    "C:\\Cases/test-runner-review",
+				Description:  "review-compound",
+				Investigator: "investigator",
+			},
+		},
+		Stages: stages,
+	}
+	runner.ID = 1
+	for i, s := range runner.Stages {
+		s.RunnerID = runner.ID
+		s.Index = uint(i)
+	}
+	return runner
+}
+
+func elasticSearch(runner api.Runner) api.Runner {
+	runner.CaseSettings.Case.ElasticSearch = &api.Elasticsearch{
+		ClusterName:           "avian",
+		NuixTransportHost:     "elastic.avian.test:9300",
+		IndexNumberOfReplicas: 1,
+		IndexNumberOfShards:   2,
+	}
+	return runner
+}
+
+func process(status int64) *api.Stage {
+	s := newStage()
+	s.Process = &api.Process{
+		StageID:     s.ID,
+		Profile:     "Default",
+		ProfilePath: "C:\\ProgramData\\Nuix\\Processing Profiles\\Default.xml",
+		EvidenceStore: []*api.Evidence{
+			{
+				Name:        "evidence_1",
+				Directory:   "C:\\Evidence\\evidence_1.pst",
+				Description: "first evidence",
+				Encoding:    "UTF-8",
+				TimeZone:    "Europe/Copenhagen",
+				Custodian:   "Suspect",
+				Locale:      "en-US",
+			},
+			{
+				Name:        "evidence_2",
+				Directory:   "C:\\Evidence\\evidence_2",
+				Description: "second evidence",
+				Encoding:    "UTF-8",
+				TimeZone:    "Europe/Copenhagen",
+				Custodian:   "Suspect",
+				Locale:      "en-US",
+			},
+		},
+		Status: status,
+	}
+	for i, e := range s.Process.EvidenceStore {
+		e.ID = uint(i + 1)
+		e.ProcessID = s.ID
+	}
+	return s
+}
+
+// checkpoint sets the checkpoint reported for the stage in an earlier run
+func checkpoint(s *api.Stage, checkpoint string) *api.Stage {
+	s.Checkpoint = checkpoint
+	return s
+}
+
+func hashEvidence(s *api.Stage) *api.Stage {
+	s.Process.HashEvidence = true
+	return s
+}
+
+func searchAndTag(status int64) *api.Stage {
+	s := newStage()
+	s.SearchAndTag = &api.SearchAndTag{StageID: s.ID, Search: "kind:email", Tag: "emails", Status: status}
+	return s
+}
+
+func searchAndTagFiles(status int64) *api.Stage {
+	s := newStage()
+	s.SearchAndTag = &api.SearchAndTag{
+		StageID: s.ID,
+		Files: []*api.File{
+			{Path: "C:\\Searches\\keywords.txt"},
+			{Path: "C:\\Searches\\persons.json"},
+		},
+		Status: status,
+	}
+	return s
+}
+
+func exclude(status int64) *api.Stage {
+	s := newStage()
+	s.Exclude = &api.Exclude{StageID: s.ID, Search: "kind:system", Reason: "not_needed", Status: status}
+	return s
+}
+
+func ocr(status int64) *api.Stage {
+	s := newStage()
+	s.Ocr = &api.Ocr{
+		StageID:     s.ID,
+		Profile:     "Default",
+		ProfilePath: "C:\\ProgramData\\Nuix\\OCR Profiles\\Default.xml",
+		Search:      "kind:image",
+		BatchSize:   100,
+		Status:      status,
+	}
+	return s
+}
+
+func populate(status int64) *api.Stage {
+	s := newStage()
+	s.Populate = &api.Populate{
+		StageID: s.ID,
+		Search:  "kind:document",
+		Types:   []*api.Type{{Type: "native"}, {Type: "pdf"}},
+		Status:  status,
+	}
+	return s
+}
+
+func reload(status int64) *api.Stage {
+	s := newStage()
+	s.Reload = &api.Reload{
+		StageID:     s.ID,
+		Profile:     "Default",
+		ProfilePath: "C:\\ProgramData\\Nuix\\Processing Profiles\\Default.xml",
+		Search:      "flag:encrypted",
+		Status:      status,
+	}
+	return s
+}
+
+func inApp(status int64) *api.Stage {
+	s := newStage()
+	s.InApp = &api.InApp{
+		StageID:  s.ID,
+		Name:     "number_of_descendants",
+		Config:   "C:\\Config\\number_of_descendants.yml",
+		Settings: inapp.Settings{SettingsFile: "tag: descendants\nrun_on: all"},
+		Status:   status,
+	}
+	return s
+}
+
+func syncDescendants(status int64) *api.Stage {
+	s := newStage()
+	s.SyncDescendants = &api.SyncDescendants{StageID: s.ID, Search: "tag:emails", Status: status}
+	return s
+}
+
+func scanNewChildItems(status int64) *api.Stage {
+	s := newStage()
+	s.ScanNewChildItems = &api.ScanNewChildItems{StageID: s.ID, Profile: "Default", Search: "kind:container", Status: status}
+	return s
+}
+
+func hashSet(status int64) *api.Stage {
+	s := newStage()
+	s.HashSet = &api.HashSet{
+		StageID: s.ID,
+		Lists: []*api.HashList{
+			{Path: "C:\\HashSets\\known_good.txt", Algorithm: "md5"},
+			{Path: "C:\\HashSets\\known_bad.txt", Algorithm: "sha1"},
+		},
+		Action: "tag",
+		Tag:    "known",
+		Status: status,
+	}
+	for i, l := range s.HashSet.Lists {
+		l.ID = uint(i + 1)
+		l.HashSetID = s.ID
+	}
+	return s
+}
+
+func archive(status int64) *api.Stage {
+	s := newStage()
+	s.Archive = &api.Archive{StageID: s.ID, Destination: "\\\\archive\\cases", Status: status}
+	return s
+}
+
+// removeSource removes the single-case when it is archived,
+// the runner must not have a compound- or review-case
+func removeSource(s *api.Stage) *api.Stage {
+	s.Archive.RemoveSource = true
+	return s
+}
+
+// singleCase removes the compound- and review-case for the runner
+func singleCase(runner api.Runner) api.Runner {
+	runner.CaseSettings.CompoundCase = nil
+	runner.CaseSettings.ReviewCompound = nil
+	return runner
+}
@@ -0,0 +1,177 @@
+package ruby
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/gobuffalo/plush"
+)
+
+const (
+	// scriptTemplate is the name of the file that
+	// overrides the runner-script in a template-dir
+	scriptTemplate = "script.rb"
+
+	// stagesDir is the directory in a template-dir with
+	// the files that overrides the stage-handlers (<key>.rb)
+	stagesDir = "stages"
+)
+
+// Templates holds the templates for the runner-script and the
+// stage-handlers in the library. The embedded defaults can be
+// overridden per deployment with the templates in a directory.
+type Templates struct {
+	// Version of the templates (hash of the templates),
+	// recorded for each run of a runner
+	Version string
+
+	// Overrides lists the templates that overrides the defaults
+	Overrides []string
+
+	script   string
+	handlers map[string]string
+}
+
+// DefaultTemplates returns the templates embedded in the binary
+func DefaultTemplates() *Templates {
+	t := &Templates{script: rubyTemplate, handlers: make(map[string]string)}
+	for _, kind := range api.StageKinds() {
+		t.handlers[kind.Key()] = kind.Script()
+	}
+	t.Version = t.version("embedded")
+	return t
+}
+
+// LoadTemplates returns the default templates overridden by the templates in dir:
+//
+//	script.rb       - the runner-script (plush-template)
+//	stages/<key>.rb - the stage-handler for a stage-type (e.g stages/ocr.rb)
+//
+// The templates are checked when they are loaded, so
+// an invalid template is found when the service starts.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := DefaultTemplates()
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template-dir: %v", err)
+	}
+
+	for _, file := range files {
+		switch {
+		case file.Name() == stagesDir && file.IsDir():
+			if err := t.loadHandlers(filepath.Join(dir, stagesDir)); err != nil {
+				return nil, err
+			}
+		case file.Name() == scriptTemplate:
+			b, err := ioutil.ReadFile(filepath.Join(dir, scriptTemplate))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template: %s - %v", scriptTemplate, err)
+			}
+			t.script = string(b)
+			if _, err := t.Generate("http://localhost:8080/oto/", "template-check"); err != nil {
+				return nil, fmt.Errorf("invalid template: %s - %v", scriptTemplate, err)
+			}
+			t.Overrides = append(t.Overrides, scriptTemplate)
+		default:
+			return nil, fmt.Errorf("unknown file in template-dir: %s - expected %s or %s/<stage>.rb", file.Name(), scriptTemplate, stagesDir)
+		}
+	}
+
+	sort.Strings(t.Overrides)
+	if len(t.Overrides) != 0 {
+		t.Version = t.version("custom")
+	}
+	return t, nil
+}
+
+// loadHandlers loads the stage-handlers from dir
+func (t *Templates) loadHandlers(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read stages in template-dir: %v", err)
+	}
+
+	for _, file := range files {
+		key := strings.TrimSuffix(file.Name(), ".rb")
+		if _, ok := t.handlers[key]; !ok || file.IsDir() || key == file.Name() {
+			return fmt.Errorf("unknown stage-template: %s/%s - expected <stage>.rb for one of: %s", stagesDir, file.Name(), strings.Join(stageKeys(), ", "))
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read stage-template: %s - %v", file.Name(), err)
+		}
+		handler := string(b)
+		if !strings.Contains(handler, fmt.Sprintf("stage_handler('%s')", key)) {
+			return fmt.Errorf("invalid stage-template: %s/%s - must register the handler with stage_handler('%s')", stagesDir, file.Name(), key)
+		}
+		t.handlers[key] = handler
+		t.Overrides = append(t.Overrides, filepath.ToSlash(filepath.Join(stagesDir, file.Name())))
+	}
+	return nil
+}
+
+// Generate generates the runner-script for the runner, the script
+// gets its stages from the service (RunnerService.Plan) and executes
+// them with the stage-handlers from the Library.
+func (t *Templates) Generate(remoteAddress, runner string) (string, error) {
+	ctx := plush.NewContext()
+
+	// Returns the value as an escaped ruby string-literal,
+	// used for every value that is interpolated in the script.
+	ctx.Set("rubyString", func(s string) template.HTML { return template.HTML(Quote(s)) })
+	// Returns the value without line-breaks, used for values in comments.
+	ctx.Set("rubyComment", func(s string) template.HTML { return template.HTML(Comment(s)) })
+
+	// Returns the remote address.
+	ctx.Set("remoteAddress", remoteAddress)
+	// Returns the name of the runner.
+	ctx.Set("runner", runner)
+	// Returns the name of the library.
+	ctx.Set("library", LibraryName)
+	// Returns the name of the CA-certificate for the service.
+	ctx.Set("caFile", CAName)
+	// Returns the version of the templates.
+	ctx.Set("templateVersion", t.Version)
+
+	// Creates the template.
+	return plush.Render(t.script, ctx)
+}
+
+// Library returns the library with the stage-handlers
+// for all the stage-types, it is the same for every runner.
+func (t *Templates) Library() string {
+	var b strings.Builder
+	b.WriteString(libraryHeader)
+	for _, key := range stageKeys() {
+		b.WriteString("\n")
+		b.WriteString(t.handlers[key])
+	}
+	return b.String()
+}
+
+// version returns the version for the templates
+func (t *Templates) version(prefix string) string {
+	h := sha256.New()
+	h.Write([]byte(t.script))
+	for _, key := range stageKeys() {
+		h.Write([]byte(t.handlers[key]))
+	}
+	return prefix + "-" + hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// stageKeys returns the keys for the stage-types in registry-order
+func stageKeys() []string {
+	var keys []string
+	for _, kind := range api.StageKinds() {
+		keys = append(keys, kind.Key())
+	}
+	return keys
+}
@@ -1,5 +1,7 @@
 oto -template generate/templates/server.go.plush -out pkg/avian-api/api.gen.go -pkg api ./generate
 oto -template generate/templates/client.go.plush -out pkg/avian-client/avian.gen.go -pkg avian ./generate
+oto -template generate/templates/openapi.go.plush -out pkg/avian-api/openapi.gen.go -pkg api ./generate
 gofmt -w ./pkg/avian-api/api.gen.go ./pkg/avian-api/api.gen.go
 gofmt -w ./pkg/avian-client/avian.gen.go ./pkg/avian-client/avian.gen.go
-echo "Generated and formatted api.gen.go and avian.gen.go"
+gofmt -w ./pkg/avian-api/openapi.gen.go
+echo "Generated and formatted api.gen.go, avian.gen.go and openapi.gen.go"
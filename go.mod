@@ -15,9 +15,10 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/simonjanss/go-powershell v0.0.0-20201014075207-9ada2bb4ef25
 	github.com/spf13/cobra v1.0.0
+	go.opentelemetry.io/proto/otlp v0.19.0
 	go.uber.org/zap v1.16.0
-	golang.org/x/sys v0.0.0-20200323222414-85ca7c5b95cd
-	golang.org/x/tools v0.0.0-20200821200730-1e23e48ab93b // indirect
+	golang.org/x/sys v0.0.0-20210510120138-977fb7262007
+	google.golang.org/protobuf v1.27.1
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0 // indirect
 	gopkg.in/yaml.v2 v2.3.0
 )
@@ -0,0 +1,186 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Redacted replaces the passwords, secrets and
+// file-contents in the requests and the diffs
+const Redacted = "[redacted]"
+
+// Change is a field that is changed by a call, Old is nil
+// for an added field and New is nil for a removed field
+type Change struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// ignored are the fields that are not compared, the ids and
+// timestamps for the datastore and the state for the runs
+var ignored = map[string]bool{
+	"id":         true,
+	"cTime":      true,
+	"mTime":      true,
+	"dTime":      true,
+	"status":     true,
+	"startedAt":  true,
+	"checkpoint": true,
+	"items":      true,
+	"inUse":      true,
+	"active":     true,
+}
+
+// sensitive are the fields that are redacted
+var sensitive = map[string]bool{
+	"password": true,
+	"secret":   true,
+	"content":  true,
+}
+
+// Diff returns the changes from before to after (compared as JSON),
+// before is nil for a created resource and after is nil for a deleted
+// - zero-values are compared as missing fields
+func Diff(before, after interface{}) ([]Change, error) {
+	from, err := flatten(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := flatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool)
+	for path := range from {
+		paths[path] = true
+	}
+	for path := range to {
+		paths[path] = true
+	}
+
+	changes := []Change{}
+	for path := range paths {
+		o, n := from[path], to[path]
+		if reflect.DeepEqual(o, n) {
+			continue
+		}
+		if sensitive[leaf(path)] {
+			o, n = redact(o), redact(n)
+		}
+		changes = append(changes, Change{Path: path, Old: o, New: n})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// Redact returns the JSON-body without the passwords,
+// secrets and file-contents (the body is returned as
+// it is if it is not a JSON-object)
+func Redact(body []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	b, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return string(body)
+	}
+	return string(b)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			if sensitive[k] {
+				value[k] = redact(child)
+				continue
+			}
+			value[k] = redactValue(child)
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = redactValue(child)
+		}
+	}
+	return v
+}
+
+// redact returns Redacted for a value that is set
+func redact(v interface{}) interface{} {
+	if zero(v) {
+		return v
+	}
+	return Redacted
+}
+
+// flatten returns the fields (by their path) for the
+// JSON of v, without the ignored and the zero-values
+func flatten(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return fields, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+	walk(fields, "", generic)
+	return fields, nil
+}
+
+func walk(fields map[string]interface{}, path string, v interface{}) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			if ignored[k] || strings.HasSuffix(k, "ID") {
+				continue
+			}
+			walk(fields, join(path, k), child)
+		}
+	case []interface{}:
+		for i, child := range value {
+			walk(fields, join(path, strconv.Itoa(i)), child)
+		}
+	default:
+		if !zero(v) {
+			fields[path] = v
+		}
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// leaf returns the last key in the path
+func leaf(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+// zero returns true for the zero-values in JSON
+func zero(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case float64:
+		return value == 0
+	case bool:
+		return !value
+	}
+	return false
+}
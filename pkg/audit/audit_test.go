@@ -0,0 +1,151 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/audit"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/services"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestDiff(t *testing.T) {
+	is := is.New(t)
+	before := api.ServerApplyRequest{Hostname: "dev01", Port: 5985, Username: "avian", Password: "old", NuixPath: `C:\Nuix`}
+	after := api.ServerApplyRequest{Hostname: "dev01", Port: 5986, Username: "avian", Password: "new", AvianScripts: `C:\scripts`}
+
+	changes, err := audit.Diff(before, after)
+	is.NoErr(err)
+	is.Equal(changes, []audit.Change{
+		{Path: "avianScripts", Old: nil, New: `C:\scripts`},
+		{Path: "nuixPath", Old: `C:\Nuix`, New: nil},
+		{Path: "password", Old: audit.Redacted, New: audit.Redacted},
+		{Path: "port", Old: float64(5985), New: float64(5986)},
+	})
+
+	// every field is removed for a deleted resource
+	changes, err = audit.Diff(&before, (*api.ServerApplyRequest)(nil))
+	is.NoErr(err)
+	is.Equal(len(changes), 5)
+	for _, c := range changes {
+		is.Equal(c.New, nil)
+	}
+
+	// the state for the runs is not compared
+	stage := &api.Stage{Ocr: &api.Ocr{Profile: "ocr"}}
+	started := &api.Stage{Ocr: &api.Ocr{Profile: "ocr", Status: 2}, Items: 40}
+	changes, err = audit.Diff(api.RunnerApplyRequest{Stages: []*api.Stage{started}}, api.RunnerApplyRequest{Stages: []*api.Stage{stage}})
+	is.NoErr(err)
+	is.Equal(len(changes), 0)
+
+	is.Equal(audit.Redact([]byte(`{"nms":[{"address":"nms","password":"secret"}],"secret":""}`)), `{"nms":[{"address":"nms","password":"[redacted]"}],"secret":""}`)
+}
+
+func TestMiddleware(t *testing.T) {
+	is := is.New(t)
+	db, err := gorm.Open("sqlite3", ":memory:")
+	is.NoErr(err)
+	defer db.Close()
+	is.NoErr(tables.Migrate(db))
+	is.NoErr(tables.Index(db))
+
+	key, err := auth.Create(db, "simon", auth.RoleAdmin)
+	is.NoErr(err)
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01", Port: 5985, Password: "old"}).Error)
+
+	// a fake ServerService.Apply that updates the server
+	handler := auth.Middleware(db, zap.NewNop(), audit.Middleware(db, zap.NewNop(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req api.ServerApplyRequest
+		is.NoErr(json.NewDecoder(r.Body).Decode(&req)) // the body is passed on
+		if req.Port == 0 {
+			http.Error(w, `{"error": "specify a port"}`, http.StatusInternalServerError)
+			return
+		}
+		is.NoErr(db.Model(&api.Server{}).Where("hostname = ?", req.Hostname).Updates(map[string]interface{}{"port": req.Port, "password": req.Password}).Error)
+		w.Write([]byte(`{}`))
+	})))
+
+	call := func(method, body string) {
+		req := httptest.NewRequest(http.MethodPost, "/oto/"+method, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("X-Forwarded-For", "10.0.0.7")
+		req.RemoteAddr = "192.168.1.20:51234"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	call("ServerService.Apply", `{"hostname": "dev01", "port": 5986, "password": "new"}`)
+	call("ServerService.Apply", `{"hostname": "dev01", "password": "new"}`)
+	call("ServerService.List", `{}`) // not audited
+
+	list, err := services.NewAuditService(db, zap.NewNop()).List(context.Background(), api.AuditListRequest{Method: "ServerService.Apply"})
+	is.NoErr(err)
+	is.Equal(len(list.Entries), 2)
+
+	// the latest first
+	failed, applied := list.Entries[0], list.Entries[1]
+	is.Equal(applied.Identity, "simon")
+	is.Equal(applied.Role, auth.RoleAdmin)
+	is.Equal(applied.RemoteAddr, "192.168.1.20")
+	is.Equal(applied.ForwardedFor, "10.0.0.7")
+	is.Equal(applied.Resource, audit.ResourceServer)
+	is.Equal(applied.Target, "dev01")
+	is.Equal(applied.StatusCode, int64(http.StatusOK))
+	is.Equal(applied.Exception, "")
+	is.Equal(applied.Request, `{"hostname":"dev01","password":"[redacted]","port":5986}`)
+	is.Equal(applied.Diff, `[{"path":"password","old":"[redacted]","new":"[redacted]"},{"path":"port","old":5985,"new":5986}]`)
+
+	is.Equal(failed.StatusCode, int64(http.StatusInternalServerError))
+	is.Equal(failed.Exception, "specify a port")
+	is.Equal(failed.Diff, `[{"path":"port","old":5986,"new":null}]`)
+
+	// the filters
+	since := time.Now().Add(time.Hour)
+	list, err = services.NewAuditService(db, zap.NewNop()).List(context.Background(), api.AuditListRequest{Since: &since})
+	is.NoErr(err)
+	is.Equal(len(list.Entries), 0)
+	list, err = services.NewAuditService(db, zap.NewNop()).List(context.Background(), api.AuditListRequest{Identity: "simon", Target: "dev01", Limit: 1})
+	is.NoErr(err)
+	is.Equal(len(list.Entries), 1)
+
+	// the audit-trail is append-only
+	is.True(db.Model(&api.AuditEntry{}).Where("id = ?", applied.ID).Update("identity", "someone").Error != nil)
+	is.True(db.Delete(&applied).Error != nil)
+	is.True(db.Unscoped().Delete(&applied).Error != nil)
+}
+
+func TestLocal(t *testing.T) {
+	is := is.New(t)
+
+	type key struct {
+		Name string `json:"name"`
+		Role string `json:"role,omitempty"`
+	}
+	state := &key{Name: "simon", Role: auth.RoleAdmin}
+	entry, err := audit.Local("auth.CreateKey", audit.ResourceKey, "simon", state, nil, state, nil)
+	is.NoErr(err)
+	is.True(entry.Identity != "") // the user on the machine
+	is.Equal(entry.Method, "auth.CreateKey")
+	is.Equal(entry.Resource, audit.ResourceKey)
+	is.Equal(entry.Target, "simon")
+	is.Equal(entry.StatusCode, int64(http.StatusOK))
+	is.Equal(entry.Request, `{"name":"simon","role":"admin"}`)
+	is.Equal(entry.Diff, `[{"path":"name","old":null,"new":"simon"},{"path":"role","old":null,"new":"admin"}]`)
+
+	// a failed change is recorded with the error
+	entry, err = audit.Local("auth.DeleteKey", audit.ResourceKey, "carol", key{Name: "carol"}, (*key)(nil), nil, errors.New("key not found: carol"))
+	is.NoErr(err)
+	is.Equal(entry.StatusCode, int64(http.StatusInternalServerError))
+	is.Equal(entry.Exception, "key not found: carol")
+	is.Equal(entry.Diff, `[]`)
+}
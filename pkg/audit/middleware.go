@@ -0,0 +1,446 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+)
+
+// The resources that are changed by the audited methods
+const (
+	ResourceRunner  = "runner"
+	ResourceServer  = "server"
+	ResourceNms     = "nms"
+	ResourceWebhook = "webhook"
+	ResourceFile    = "file"
+	ResourceKey     = "api-key"
+)
+
+// resolver returns the target and the state before and after the
+// call for the request-body - in the shape of the request, so they
+// can be compared (after is nil if the call deletes the target)
+type resolver func(db *gorm.DB, body []byte) (target string, before, after interface{}, err error)
+
+// method is an audited method
+type method struct {
+	resource string
+	resolve  resolver
+}
+
+// methods are the mutating methods for the configuration and the
+// control of the runners, servers, nms and webhooks - the callbacks
+// from the runner-scripts are recorded in the logs for the runners
+var methods = map[string]method{
+	"RunnerService.Apply":      {ResourceRunner, runnerApply},
+	"RunnerService.Delete":     {ResourceRunner, runnerDelete},
+	"RunnerService.Cancel":     {ResourceRunner, runnerCancel},
+	"RunnerService.UploadFile": {ResourceFile, uploadFile},
+	"ServerService.Apply":      {ResourceServer, serverApply},
+	"ServerService.Delete":     {ResourceServer, serverDelete},
+	"NmsService.Apply":         {ResourceNms, nmsApply},
+	"NmsService.Delete":        {ResourceNms, nmsDelete},
+	"WebhookService.Add":       {ResourceWebhook, webhookAdd},
+	"WebhookService.Delete":    {ResourceWebhook, webhookDelete},
+}
+
+// Middleware records an entry in the audit-trail for every call to
+// an audited method (after the call, with the status for the response),
+// it must be used after auth.Middleware for the identity of the caller
+func Middleware(db *gorm.DB, logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Base(r.URL.Path)
+		m, ok := methods[name]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, "cannot read request", http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		entry := api.AuditEntry{
+			Time:         time.Now(),
+			RemoteAddr:   remoteAddr(r),
+			ForwardedFor: r.Header.Get("X-Forwarded-For"),
+			Method:       name,
+			Resource:     m.resource,
+			Request:      Redact(body),
+		}
+		if k, ok := auth.FromContext(r.Context()); ok {
+			entry.Identity = k.Name
+			entry.Role = k.Role
+			if k.Scope == auth.ScopeRunner {
+				entry.Role = auth.RoleRunner
+			}
+		}
+
+		// compare the request with the state before the call
+		target, before, after, err := m.resolve(db, body)
+		if err != nil {
+			logger.Error("Cannot get the state before the call for the audit-trail",
+				zap.String("method", name),
+				zap.String("exception", err.Error()),
+			)
+		} else if changes, err := Diff(before, after); err != nil {
+			logger.Error("Cannot diff the call for the audit-trail", zap.String("method", name), zap.String("exception", err.Error()))
+		} else if b, err := json.Marshal(changes); err == nil {
+			entry.Diff = string(b)
+		}
+		entry.Target = target
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		entry.StatusCode = int64(rec.status)
+		entry.Exception = rec.exception()
+
+		if err := Record(db, &entry); err != nil {
+			logger.Error("Cannot record the call in the audit-trail",
+				zap.String("method", name),
+				zap.String("identity", entry.Identity),
+				zap.String("target", entry.Target),
+				zap.String("exception", err.Error()),
+			)
+		}
+	})
+}
+
+// Record appends the entry to the audit-trail
+func Record(db *gorm.DB, entry *api.AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	// the times are compared as strings in sqlite
+	entry.Time = entry.Time.UTC()
+	return db.Create(entry).Error
+}
+
+// Local returns the entry for a change that is made on the machine for
+// the service without a call to the api (the CLI-commands for the api-keys
+// uses the db for the service) - the identity is the user and the source is
+// the hostname for the machine, the status is 500 if the change failed with err
+func Local(method, resource, target string, request, before, after interface{}, err error) (api.AuditEntry, error) {
+	entry := api.AuditEntry{
+		Time:       time.Now(),
+		Identity:   localUser(),
+		Method:     method,
+		Resource:   resource,
+		Target:     target,
+		StatusCode: http.StatusOK,
+	}
+	entry.RemoteAddr, _ = os.Hostname()
+	if err != nil {
+		entry.StatusCode = http.StatusInternalServerError
+		entry.Exception = err.Error()
+	}
+
+	b, err := json.Marshal(request)
+	if err != nil {
+		return entry, fmt.Errorf("cannot marshal the request for the audit-trail: %v", err)
+	}
+	entry.Request = Redact(b)
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		return entry, fmt.Errorf("cannot diff the change for the audit-trail: %v", err)
+	}
+	if b, err = json.Marshal(changes); err != nil {
+		return entry, fmt.Errorf("cannot marshal the diff for the audit-trail: %v", err)
+	}
+	entry.Diff = string(b)
+	return entry, nil
+}
+
+// localUser returns the name of the user on the machine
+func localUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USERNAME")
+}
+
+// remoteAddr returns the ip for the request
+func remoteAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// recorder records the status for the response,
+// and the body if the call failed (for the error)
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status >= http.StatusBadRequest && r.body.Len() < 4096 {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// exception returns the error for a failed call
+func (r *recorder) exception() string {
+	if r.status < http.StatusBadRequest {
+		return ""
+	}
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(r.body.Bytes(), &resp); err == nil && resp.Error != "" {
+		return resp.Error
+	}
+	if msg := strings.TrimSpace(r.body.String()); msg != "" {
+		return msg
+	}
+	return http.StatusText(r.status)
+}
+
+func runnerApply(db *gorm.DB, body []byte) (string, interface{}, interface{}, error) {
+	var r api.RunnerApplyRequest
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, nil, err
+	}
+	before, err := runnerState(db, r.Name)
+	if before != nil {
+		before.Update = r.Update
+	}
+	return r.Name, before, r, err
+}
+
+func runnerDelete(db *gorm.DB, body []byte) (string, interface{}, interface{}, error) {
+	var r api.RunnerDeleteRequest
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, nil, err
+	}
+	before, err := runnerState(db, r.Name)
+	return r.Name, before, nil, err
+}
+
+// runnerCancel has no diff, the state for the runs are not compared
+func runnerCancel(db *gorm.DB, body []byte) (string, interface{}, interface{}, error) {
+	var r api.RunnerCancelRequest
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, nil, err
+	}
+	return r.Name, nil, nil, nil
+}
+
+// runnerState returns the runner in the shape of the apply-request,
+// nil if the runner does not exist
+func runnerState(db *gorm.DB, name string) (*api.RunnerApplyRequest, error) {
+	var runner api.Runner
+	err := tables.PreloadStages(db, "Stages.").
+		Preload("Switches").
+		Preload("CaseSettings.Case.ElasticSearch").
+		Preload("CaseSettings.CompoundCase").
+		Preload("CaseSettings.ReviewCompound").
+		First(&runner, "name = ?", name).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get runner: %s - %v", name, err)
+	}
+
+	state := api.RunnerApplyRequest{
+		Name:         runner.Name,
+		Hostname:     runner.Hostname,
+		Nms:          runner.Nms,
+		Licence:      runner.Licence,
+		Xmx:          runner.Xmx,
+		Workers:      runner.Workers,
+		MaxRuntime:   runner.MaxRuntime,
+		CaseSettings: runner.CaseSettings,
+		Stages:       runner.Stages,
+	}
+	for _, sw := range runner.Switches {
+		state.Switches = append(state.Switches, sw.Value)
+	}
+	if runner.Notify != "" {
+		state.Notify = strings.Split(runner.Notify, ",")
+	}
+	return &state, nil
+}
+
+func serverApply(db *gorm.DB, body []byte) (string, interface{}, interface{}, error) {
+	var r api.ServerApplyRequest
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, nil, err
+	}
+	before, err := serverState(db, r.Hostname)
+	return r.Hostname, before, r, err
+}
+
+func serverDelete(db *gorm.DB, body []byte) (string, interface{}, interface{}, error) {
+	var r api.ServerDeleteRequest
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, nil, err
+	}
+	before, err := serverState(db, r.Hostname)
+	return r.Hostname, before, nil, err
+}
+
+// serverState returns the server in the shape of the apply-request,
+// nil if the server does not exist
+func serverState(db *gorm.DB, hostname string) (*api.ServerApplyRequest, error) {
+	var server api.Server
+	err := db.First(&server, "hostname = ?", hostname).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get server: %s - %v", hostname, err)
+	}
+
+	return &api.ServerApplyRequest{
+		Hostname:        server.Hostname,
+		Port:            server.Port,
+		OperatingSystem: server.OperatingSystem,
+		Username:        server.Username,
+		Password:        server.Password,
+		NuixPath:        server.NuixPath,
+		AvianScripts:    server.AvianScripts,
+	}, nil
+}
+
+func nmsApply(db *gorm.DB, body []byte) (string, interface{}, interface{}, error) {
+	var r api.NmsApplyRequests
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, nil, err
+	}
+
+	// the state for each nms in the request (in the same order)
+	before := api.NmsApplyRequests{Nms: make([]api.NmsApplyRequest, len(r.Nms))}
+	var addresses []string
+	for i, req := range r.Nms {
+		addresses = append(addresses, req.Address)
+		state, err := nmsState(db, req.Address)
+		if err != nil {
+			return strings.Join(addresses, ","), nil, r, err
+		}
+		if state != nil {
+			before.Nms[i] = *state
+		}
+	}
+	return strings.Join(addresses, ","), before, r, nil
+}
+
+func nmsDelete(db *gorm.DB, body []byte) (string, interface{}, interface{}, error) {
+	var r api.NmsDeleteRequest
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, nil, err
+	}
+	before, err := nmsState(db, r.Address)
+	return r.Address, before, nil, err
+}
+
+// nmsState returns the nms in the shape of the apply-request,
+// nil if the nms does not exist
+func nmsState(db *gorm.DB, address string) (*api.NmsApplyRequest, error) {
+	var nms api.Nms
+	err := db.Preload("Licences").First(&nms, "address = ?", address).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get nms: %s - %v", address, err)
+	}
+
+	state := api.NmsApplyRequest{
+		Address:     nms.Address,
+		Port:        nms.Port,
+		Username:    nms.Username,
+		Password:    nms.Password,
+		Workers:     nms.Workers,
+		IsRelay:     nms.IsRelay,
+		PoolsPath:   nms.PoolsPath,
+		LeasesPath:  nms.LeasesPath,
+		Auth:        nms.Auth,
+		Fingerprint: nms.Fingerprint,
+		Insecure:    nms.Insecure,
+	}
+	for _, licence := range nms.Licences {
+		state.Licences = append(state.Licences, api.Licences{
+			Licence: api.LicenceApplyRequest{Type: licence.Type, Amount: licence.Amount},
+		})
+	}
+	return &state, nil
+}
+
+func webhookAdd(db *gorm.DB, body []byte) (string, interface{}, interface{}, error) {
+	var r api.WebhookAddRequest
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, nil, err
+	}
+	before, err := webhookState(db, r.Name)
+	return r.Name, before, r, err
+}
+
+func webhookDelete(db *gorm.DB, body []byte) (string, interface{}, interface{}, error) {
+	var r api.WebhookDeleteRequest
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, nil, err
+	}
+	before, err := webhookState(db, r.Name)
+	return r.Name, before, nil, err
+}
+
+// webhookState returns the webhook in the shape of the add-request,
+// nil if the webhook does not exist
+func webhookState(db *gorm.DB, name string) (*api.WebhookAddRequest, error) {
+	var webhook api.Webhook
+	err := db.First(&webhook, "name = ?", name).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get webhook: %s - %v", name, err)
+	}
+
+	state := api.WebhookAddRequest{
+		Name:     webhook.Name,
+		Endpoint: webhook.Endpoint,
+		Runner:   webhook.Runner,
+		Secret:   webhook.Secret,
+	}
+	if webhook.Events != "" {
+		state.Events = strings.Split(webhook.Events, ",")
+	}
+	return &state, nil
+}
+
+func uploadFile(db *gorm.DB, body []byte) (string, interface{}, interface{}, error) {
+	var r api.UploadFileRequest
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", nil, nil, err
+	}
+	return r.Name, nil, r, nil
+}
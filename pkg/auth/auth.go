@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+)
+
+const (
+	// ScopeAPI is the scope for the keys created
+	// with the CLI, they can call every method
+	ScopeAPI = "api"
+
+	// ScopeRunner is the scope for the keys created for
+	// the runner-scripts, they can only call the methods
+	// that the runner-scripts uses
+	ScopeRunner = "runner"
+
+	// RunnerKeyEnv is the env-variable for the
+	// key in the session for the runner-script
+	RunnerKeyEnv = "AVIAN_RUNNER_KEY"
+
+	// RunnerKeyTTL is how long a runner-key is valid after it was
+	// last used, the runner-scripts sends a heartbeat every 90s
+	RunnerKeyTTL = 30 * time.Minute
+
+	// keyPrefix is the start of every key
+	keyPrefix = "avian_"
+)
+
+// Key is an API-key for the http-api,
+// only the hash of the key is stored
+type Key struct {
+	// Base for the datastore
+	datastore.Base
+
+	// Name for the key
+	Name string
+
+	// Prefix - the start of the key, to recognize it
+	Prefix string
+
+	// Hash for the key (hex-encoded sha256)
+	Hash string `gorm:"unique_index"`
+
+	// Scope for the key (api or runner)
+	Scope string
+
+	// Role for the key with ScopeAPI (viewer, investigator or admin),
+	// the keys created before the roles are admins
+	Role string `gorm:"default:'admin'"`
+
+	// RunnerID for the runner the key is scoped to
+	RunnerID uint
+
+	// ExpiresAt is when the key expires (unix-timestamp),
+	// 0 if the key does not expire
+	ExpiresAt int64
+}
+
+// Expired returns true if the key has expired
+func (k *Key) Expired(now time.Time) bool {
+	return k.ExpiresAt != 0 && now.Unix() >= k.ExpiresAt
+}
+
+// Allowed returns true if the key can call the method (see permissions)
+func (k *Key) Allowed(method string) bool {
+	role, ok := Permission(method)
+	if !ok {
+		role = RoleAdmin
+	}
+	if k.Scope == ScopeRunner {
+		return role == RoleRunner
+	}
+	if role == RoleRunner {
+		role = RoleAdmin
+	}
+	return k.HasRole(role)
+}
+
+// Hash returns the hash for a key
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newKey generates a new random key
+func newKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate key: %v", err)
+	}
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Create creates a key with ScopeAPI for the role, the key
+// is only returned here - the hash is stored in the db
+func Create(db *gorm.DB, name, role string) (string, error) {
+	if err := ValidRole(role); err != nil {
+		return "", err
+	}
+	var count int64
+	if err := db.Model(&Key{}).Where("name = ? AND scope = ?", name, ScopeAPI).Count(&count).Error; err != nil {
+		return "", fmt.Errorf("failed to check keys: %v", err)
+	}
+	if count != 0 {
+		return "", fmt.Errorf("key already exists: %s", name)
+	}
+	return create(db, &Key{Name: name, Scope: ScopeAPI, Role: role})
+}
+
+// CreateRunnerKey creates a short-lived key with ScopeRunner
+// for a start of the runner, the earlier keys for the runner
+// are deleted so only the running script can use its key.
+// The key expires when it has not been used for RunnerKeyTTL.
+func CreateRunnerKey(db *gorm.DB, runnerID uint, runner string) (string, error) {
+	if err := db.Unscoped().Where("scope = ? AND runner_id = ?", ScopeRunner, runnerID).Delete(&Key{}).Error; err != nil {
+		return "", fmt.Errorf("failed to delete keys for runner: %s - %v", runner, err)
+	}
+	expiresAt := time.Now().Add(RunnerKeyTTL).Unix()
+	return create(db, &Key{Name: runner, Scope: ScopeRunner, Role: RoleRunner, RunnerID: runnerID, ExpiresAt: expiresAt})
+}
+
+func create(db *gorm.DB, k *Key) (string, error) {
+	key, err := newKey()
+	if err != nil {
+		return "", err
+	}
+	k.Prefix = key[:len(keyPrefix)+6]
+	k.Hash = Hash(key)
+	if err := db.Create(k).Error; err != nil {
+		return "", fmt.Errorf("failed to store key: %v", err)
+	}
+	return key, nil
+}
+
+// List returns the keys with ScopeAPI
+func List(db *gorm.DB) ([]Key, error) {
+	var keys []Key
+	if err := db.Where("scope = ?", ScopeAPI).Order("name").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list keys: %v", err)
+	}
+	return keys, nil
+}
+
+// Delete deletes the key with ScopeAPI by name
+func Delete(db *gorm.DB, name string) error {
+	query := db.Unscoped().Where("name = ? AND scope = ?", name, ScopeAPI).Delete(&Key{})
+	if query.Error != nil {
+		return fmt.Errorf("failed to delete key: %v", query.Error)
+	}
+	if query.RowsAffected == 0 {
+		return fmt.Errorf("key not found: %s", name)
+	}
+	return nil
+}
+
+type contextKey struct{}
+
+// FromContext returns the key for the request
+func FromContext(ctx context.Context) (*Key, bool) {
+	k, ok := ctx.Value(contextKey{}).(*Key)
+	return k, ok
+}
+
+// RunnerID returns the ID for the runner if the request
+// is made with a runner-key, ok is false for other requests
+func RunnerID(ctx context.Context) (id uint, ok bool) {
+	k, ok := FromContext(ctx)
+	if !ok || k.Scope != ScopeRunner {
+		return 0, false
+	}
+	return k.RunnerID, true
+}
+
+// Middleware returns a handler that authenticates the
+// requests with the key in the Authorization-header
+// (Bearer <key>) before they are passed to the next handler
+func Middleware(db *gorm.DB, logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := path.Base(r.URL.Path)
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || token == r.Header.Get("Authorization") {
+			deny(w, r, http.StatusUnauthorized, "unauthorized: missing api-key")
+			return
+		}
+
+		var key Key
+		if err := db.Where("hash = ?", Hash(token)).First(&key).Error; err != nil {
+			if !gorm.IsRecordNotFoundError(err) {
+				logger.Error("Cannot get api-key", zap.String("exception", err.Error()))
+			}
+			logger.Debug("Invalid api-key", zap.String("method", method), zap.String("remote", r.RemoteAddr))
+			deny(w, r, http.StatusUnauthorized, "unauthorized: invalid api-key")
+			return
+		}
+
+		now := time.Now()
+		if key.Expired(now) {
+			logger.Debug("Expired api-key", zap.String("method", method), zap.String("key", key.Name), zap.String("scope", key.Scope))
+			deny(w, r, http.StatusUnauthorized, "unauthorized: api-key has expired")
+			return
+		}
+
+		if !key.Allowed(method) {
+			logger.Warn("Api-key not allowed for method", zap.String("method", method), zap.String("key", key.Name), zap.String("scope", key.Scope), zap.String("role", key.Role))
+			deny(w, r, http.StatusForbidden, forbiddenMessage(&key, method))
+			return
+		}
+
+		// extend the expiry for a used runner-key (at most once a minute)
+		if key.Scope == ScopeRunner && key.ExpiresAt-now.Unix() < int64((RunnerKeyTTL-time.Minute).Seconds()) {
+			key.ExpiresAt = now.Add(RunnerKeyTTL).Unix()
+			if err := db.Model(&Key{}).Where("id = ?", key.ID).Update("expires_at", key.ExpiresAt).Error; err != nil {
+				logger.Error("Cannot extend expiry for api-key", zap.String("key", key.Name), zap.String("exception", err.Error()))
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, &key)))
+	})
+}
@@ -0,0 +1,114 @@
+package auth_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestMiddleware(t *testing.T) {
+	is := is.New(t)
+	db, err := gorm.Open("sqlite3", ":memory:")
+	is.NoErr(err)
+	defer db.Close()
+	is.NoErr(db.AutoMigrate(&auth.Key{}).Error)
+
+	apiKey, err := auth.Create(db, "cli", auth.RoleAdmin)
+	is.NoErr(err)
+	_, err = auth.Create(db, "cli", auth.RoleAdmin)
+	is.True(err != nil) // the name must be unique
+	_, err = auth.Create(db, "unknown", "operator")
+	is.True(err != nil) // the role must exist
+	viewerKey, err := auth.Create(db, "alice", auth.RoleViewer)
+	is.NoErr(err)
+	investigatorKey, err := auth.Create(db, "bob", auth.RoleInvestigator)
+	is.NoErr(err)
+
+	oldRunnerKey, err := auth.CreateRunnerKey(db, 1, "runner")
+	is.NoErr(err)
+	runnerKey, err := auth.CreateRunnerKey(db, 1, "runner")
+	is.NoErr(err)
+	expiredKey, err := auth.CreateRunnerKey(db, 2, "expired")
+	is.NoErr(err)
+	is.NoErr(db.Model(&auth.Key{}).Where("runner_id = ?", 2).Update("expires_at", 1).Error)
+
+	// only the hash is stored
+	var keys []auth.Key
+	is.NoErr(db.Find(&keys).Error)
+	is.Equal(len(keys), 5)
+	for _, key := range keys {
+		is.True(key.Hash != apiKey && key.Hash != runnerKey && key.Hash != expiredKey)
+	}
+
+	handler := auth.Middleware(db, zap.NewNop(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := auth.FromContext(r.Context())
+		is.True(ok)
+		w.Write([]byte(key.Name))
+	}))
+
+	var tt = []struct {
+		name   string
+		header string
+		method string
+		status int
+	}{
+		{name: "missing", method: "RunnerService.List", status: http.StatusUnauthorized},
+		{name: "no-bearer", header: apiKey, method: "RunnerService.List", status: http.StatusUnauthorized},
+		{name: "invalid", header: "Bearer avian_invalid", method: "RunnerService.List", status: http.StatusUnauthorized},
+		{name: "api", header: "Bearer " + apiKey, method: "RunnerService.List", status: http.StatusOK},
+		{name: "api-runner-method", header: "Bearer " + apiKey, method: "RunnerService.Plan", status: http.StatusOK},
+		{name: "runner", header: "Bearer " + runnerKey, method: "RunnerService.Plan", status: http.StatusOK},
+		{name: "runner-not-allowed", header: "Bearer " + runnerKey, method: "RunnerService.Delete", status: http.StatusForbidden},
+		{name: "viewer-list", header: "Bearer " + viewerKey, method: "RunnerService.List", status: http.StatusOK},
+		{name: "viewer-apply", header: "Bearer " + viewerKey, method: "RunnerService.Apply", status: http.StatusForbidden},
+		{name: "investigator-apply", header: "Bearer " + investigatorKey, method: "RunnerService.Apply", status: http.StatusOK},
+		{name: "investigator-server", header: "Bearer " + investigatorKey, method: "ServerService.Apply", status: http.StatusForbidden},
+		{name: "investigator-callback", header: "Bearer " + investigatorKey, method: "RunnerService.FinishStage", status: http.StatusForbidden},
+		{name: "admin-server", header: "Bearer " + apiKey, method: "ServerService.Apply", status: http.StatusOK},
+		{name: "unknown-method", header: "Bearer " + investigatorKey, method: "RunnerService.Unknown", status: http.StatusForbidden},
+		{name: "runner-expired", header: "Bearer " + expiredKey, method: "RunnerService.Plan", status: http.StatusUnauthorized},
+		{name: "runner-rotated", header: "Bearer " + oldRunnerKey, method: "RunnerService.Plan", status: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			req := httptest.NewRequest(http.MethodPost, "/oto/"+tc.method, bytes.NewBufferString("{}"))
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			is.Equal(w.Code, tc.status)
+		})
+	}
+
+	is.NoErr(auth.Delete(db, "cli"))
+	is.True(auth.Delete(db, "cli") != nil) // already deleted
+}
+
+func TestPermissions(t *testing.T) {
+	// every oto-method must have a permission
+	services := []reflect.Type{
+		reflect.TypeOf((*api.ServerService)(nil)).Elem(),
+		reflect.TypeOf((*api.NmsService)(nil)).Elem(),
+		reflect.TypeOf((*api.RunnerService)(nil)).Elem(),
+	}
+	for _, service := range services {
+		for i := 0; i < service.NumMethod(); i++ {
+			method := service.Name() + "." + service.Method(i).Name
+			if _, ok := auth.Permission(method); !ok {
+				t.Errorf("no permission for: %s", method)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/pacedotdev/oto/otohttp"
+)
+
+// Roles for the api-keys, each role
+// has the permissions of the roles below it
+const (
+	// RoleViewer can list the runners, servers and nms
+	RoleViewer = "viewer"
+
+	// RoleInvestigator can apply, cancel and delete their own runners
+	RoleInvestigator = "investigator"
+
+	// RoleAdmin can manage servers, nms and every runner
+	RoleAdmin = "admin"
+
+	// RoleRunner is the permission for the methods used by the
+	// runner-scripts, they are called with runner-keys (or by admins)
+	RoleRunner = "runner"
+)
+
+// roleLevels orders the roles
+var roleLevels = map[string]int{
+	RoleViewer:       1,
+	RoleInvestigator: 2,
+	RoleAdmin:        3,
+}
+
+// ErrForbidden is returned (wrapped) when
+// the key is not allowed to do the request
+var ErrForbidden = errors.New("forbidden")
+
+// permissions holds the role needed to call the oto-methods,
+// methods that are not listed can only be called by an admin
+var permissions = map[string]string{
+	"ServerService.Apply":  RoleAdmin,
+	"ServerService.List":   RoleViewer,
+	"ServerService.Get":    RoleViewer,
+	"ServerService.Delete": RoleAdmin,
+
+	"NmsService.Apply":        RoleAdmin,
+	"NmsService.List":         RoleViewer,
+	"NmsService.ListLicences": RoleViewer,
+	"NmsService.Get":          RoleViewer,
+	"NmsService.Delete":       RoleAdmin,
+
+	"RunnerService.Apply":      RoleInvestigator,
+	"RunnerService.List":       RoleViewer,
+	"RunnerService.Get":        RoleViewer,
+	"RunnerService.Delete":     RoleInvestigator,
+	"RunnerService.Cancel":     RoleInvestigator,
+	"RunnerService.Script":     RoleViewer,
+	"RunnerService.Custody":    RoleViewer,
+	"RunnerService.CustodyKey": RoleViewer,
+	"RunnerService.UploadFile": RoleInvestigator,
+	"RunnerService.Events":     RoleViewer,
+
+	"WebhookService.Add":        RoleAdmin,
+	"WebhookService.List":       RoleViewer,
+	"WebhookService.Delete":     RoleAdmin,
+	"WebhookService.Deliveries": RoleViewer,
+
+	"AuditService.List": RoleAdmin,
+
+	"RunnerService.Start":          RoleRunner,
+	"RunnerService.Failed":         RoleRunner,
+	"RunnerService.Finish":         RoleRunner,
+	"RunnerService.StartStage":     RoleRunner,
+	"RunnerService.FailedStage":    RoleRunner,
+	"RunnerService.FinishStage":    RoleRunner,
+	"RunnerService.Checkpoint":     RoleRunner,
+	"RunnerService.LogItem":        RoleRunner,
+	"RunnerService.LogDebug":       RoleRunner,
+	"RunnerService.LogInfo":        RoleRunner,
+	"RunnerService.LogError":       RoleRunner,
+	"RunnerService.Heartbeat":      RoleRunner,
+	"RunnerService.Plan":           RoleRunner,
+	"RunnerService.HashSetMatches": RoleRunner,
+	"RunnerService.ArchiveResult":  RoleRunner,
+	"RunnerService.CustodyRecords": RoleRunner,
+}
+
+// Permission returns the role needed to call the method,
+// ok is false if the method has no permission defined
+func Permission(method string) (role string, ok bool) {
+	role, ok = permissions[method]
+	return role, ok
+}
+
+// ValidRole returns an error if the role does not exist
+func ValidRole(role string) error {
+	if _, ok := roleLevels[role]; !ok {
+		return fmt.Errorf("unknown role: %s - use %s, %s or %s", role, RoleViewer, RoleInvestigator, RoleAdmin)
+	}
+	return nil
+}
+
+// HasRole returns true if the key has the role (or a role above it)
+func (k *Key) HasRole(role string) bool {
+	return k.Scope == ScopeAPI && roleLevels[k.Role] >= roleLevels[role]
+}
+
+// AuthorizeOwner returns an error if the request is not allowed to
+// modify a runner owned by owner, admins and calls within the service
+// (without a key) can modify every runner - investigators only their own
+func AuthorizeOwner(ctx context.Context, owner string) error {
+	k, ok := FromContext(ctx)
+	if !ok || k.HasRole(RoleAdmin) || (k.Scope == ScopeAPI && k.Name == owner) {
+		return nil
+	}
+	return Forbidden("%s (%s) can only modify their own runners - owned by: %s", k.Name, k.Role, owner)
+}
+
+// Identity returns the name of the key for the request,
+// empty if the request is not made with a key
+func Identity(ctx context.Context) string {
+	if k, ok := FromContext(ctx); ok {
+		return k.Name
+	}
+	return ""
+}
+
+// OnErr writes the errors from the oto-methods, errors that
+// wraps ErrForbidden gets the status 403 instead of 500
+func OnErr(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, ErrForbidden) {
+		status = http.StatusForbidden
+	}
+	deny(w, r, status, err.Error())
+}
+
+// forbiddenMessage returns the error for a key that cannot call the method
+func forbiddenMessage(k *Key, method string) string {
+	role, ok := Permission(method)
+	if !ok || role == RoleRunner {
+		role = RoleAdmin
+	}
+	if k.Scope == ScopeRunner {
+		return fmt.Sprintf("%v: the runner-key for: %s cannot call: %s", ErrForbidden, k.Name, method)
+	}
+	return fmt.Sprintf("%v: %s (%s) cannot call: %s - requires the role: %s", ErrForbidden, k.Name, k.Role, method, role)
+}
+
+// Forbidden returns an error that wraps ErrForbidden
+func Forbidden(format string, a ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrForbidden, fmt.Sprintf(format, a...))
+}
+
+// deny writes the error for a request
+// in the same format as the oto-server
+func deny(w http.ResponseWriter, r *http.Request, status int, message string) {
+	errObj := struct {
+		Error string `json:"error"`
+	}{
+		Error: message,
+	}
+	otohttp.Encode(w, r, status, errObj)
+}
@@ -17,9 +17,21 @@ import (
 	time "time"
 )
 
+// AuditService returns the audit-trail for the configuration- and
+// control-actions
+type AuditService interface {
+
+	// List returns the entries in the audit-trail (the latest first)
+	List(context.Context, AuditListRequest) (*AuditListResponse, error)
+}
+
 // NmsService handles the Nuix Management Servers.
 type NmsService interface {
 	Apply(context.Context, NmsApplyRequests) (*NmsApplyResponse, error)
+	// Delete deletes the NMS (and its licences), refused while unfinished runners uses the NMS (unless forced)
+	Delete(context.Context, NmsDeleteRequest) (*NmsDeleteResponse, error)
+	// Get returns the NMS with its licences and the runners that uses it
+	Get(context.Context, NmsGetRequest) (*NmsGetResponse, error)
 	List(context.Context, NmsListRequest) (*NmsListResponse, error)
 	ListLicences(context.Context, NmsListLicencesRequest) (*NmsListLicencesResponse, error)
 }
@@ -29,6 +41,18 @@ type RunnerService interface {
 
 	// Apply applies the configuration to the backend.
 	Apply(context.Context, RunnerApplyRequest) (*RunnerApplyResponse, error)
+	// ArchiveResult reports the result of an archived case
+	ArchiveResult(context.Context, ArchiveResultRequest) (*ArchiveResultResponse, error)
+	// Cancel cancels a waiting or running Runner
+	Cancel(context.Context, RunnerCancelRequest) (*RunnerCancelResponse, error)
+	// Checkpoint stores the checkpoint for a stage (used by ruby script)
+	Checkpoint(context.Context, CheckpointRequest) (*CheckpointResponse, error)
+	// Custody returns the signed chain of custody for the runner
+	Custody(context.Context, CustodyRequest) (*CustodyResponse, error)
+	// CustodyKey returns the public-key that signs the chain of custody
+	CustodyKey(context.Context, CustodyKeyRequest) (*CustodyKeyResponse, error)
+	// CustodyRecords stores the hashes for evidence-files
+	CustodyRecords(context.Context, CustodyRecordsRequest) (*CustodyRecordsResponse, error)
 	// Delete deletes the requested Runner
 	Delete(context.Context, RunnerDeleteRequest) (*RunnerDeleteResponse, error)
 	// Failed sets a runner to failed
@@ -41,6 +65,8 @@ type RunnerService interface {
 	FinishStage(context.Context, StageRequest) (*StageResponse, error)
 	// Get returns the requested Runner
 	Get(context.Context, RunnerGetRequest) (*RunnerGetResponse, error)
+	// HashSetMatches reports the matches for a hash-list
+	HashSetMatches(context.Context, HashSetMatchesRequest) (*HashSetMatchesResponse, error)
 	// Heartbeat sends a heartbeat for the api
 	Heartbeat(context.Context, RunnerStartRequest) (*RunnerStartResponse, error)
 	// List returns the runners from the backend.
@@ -53,21 +79,75 @@ type RunnerService interface {
 	LogInfo(context.Context, LogRequest) (*LogResponse, error)
 	// LogItem logs an item
 	LogItem(context.Context, LogItemRequest) (*LogResponse, error)
-	// Script returns the script for the runner
-	Script(context.Context, RunnerGetRequest) (*RunnerScriptResponse, error)
+	// Plan returns the stages for the runner-script to execute (used by ruby script)
+	Plan(context.Context, RunnerPlanRequest) (*RunnerPlanResponse, error)
+	// Script returns the script for the runner, or the archived script for an
+	// earlier start of the runner
+	Script(context.Context, RunnerScriptRequest) (*RunnerScriptResponse, error)
 	// Start sets a runner to started
 	Start(context.Context, RunnerStartRequest) (*RunnerStartResponse, error)
 	// StartStage sets a stage to Active
 	StartStage(context.Context, StageRequest) (*StageResponse, error)
+	// UploadFile uploads a file to the uploads-folder in the data-path
 	UploadFile(context.Context, UploadFileRequest) (*UploadFileResponse, error)
 }
 
 // ServerService handles all the servers
 type ServerService interface {
 	Apply(context.Context, ServerApplyRequest) (*ServerApplyResponse, error)
+	// Delete deletes the server, refused while unfinished runners uses the server (unless forced)
+	Delete(context.Context, ServerDeleteRequest) (*ServerDeleteResponse, error)
+	// Get returns the server with the runners that uses it
+	Get(context.Context, ServerGetRequest) (*ServerGetResponse, error)
 	List(context.Context, ServerListRequest) (*ServerListResponse, error)
 }
 
+// WebhookService handles the webhooks for the lifecycle-events of the runners
+// and the stages
+type WebhookService interface {
+
+	// Add adds a webhook, the secret for the signatures is only returned here
+	Add(context.Context, WebhookAddRequest) (*WebhookAddResponse, error)
+	// Delete deletes a webhook and its deliveries
+	Delete(context.Context, WebhookDeleteRequest) (*WebhookDeleteResponse, error)
+	// Deliveries returns the delivery-log for a webhook
+	Deliveries(context.Context, WebhookDeliveriesRequest) (*WebhookDeliveriesResponse, error)
+	// List returns the webhooks (without their secrets)
+	List(context.Context, WebhookListRequest) (*WebhookListResponse, error)
+}
+
+type auditServiceServer struct {
+	server       *otohttp.Server
+	auditService AuditService
+}
+
+// Register adds the AuditService to the otohttp.Server.
+func RegisterAuditService(server *otohttp.Server, auditService AuditService) {
+	handler := &auditServiceServer{
+		server:       server,
+		auditService: auditService,
+	}
+	server.Register("AuditService", "List", handler.handleList)
+}
+
+func (s *auditServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
+	var request AuditListRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.auditService.List(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
 type nmsServiceServer struct {
 	server     *otohttp.Server
 	nmsService NmsService
@@ -80,6 +160,8 @@ func RegisterNmsService(server *otohttp.Server, nmsService NmsService) {
 		nmsService: nmsService,
 	}
 	server.Register("NmsService", "Apply", handler.handleApply)
+	server.Register("NmsService", "Delete", handler.handleDelete)
+	server.Register("NmsService", "Get", handler.handleGet)
 	server.Register("NmsService", "List", handler.handleList)
 	server.Register("NmsService", "ListLicences", handler.handleListLicences)
 }
@@ -102,6 +184,42 @@ func (s *nmsServiceServer) handleApply(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *nmsServiceServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var request NmsDeleteRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.nmsService.Delete(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *nmsServiceServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	var request NmsGetRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.nmsService.Get(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
 func (s *nmsServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
 	var request NmsListRequest
 	if err := otohttp.Decode(r, &request); err != nil {
@@ -150,18 +268,26 @@ func RegisterRunnerService(server *otohttp.Server, runnerService RunnerService)
 		runnerService: runnerService,
 	}
 	server.Register("RunnerService", "Apply", handler.handleApply)
+	server.Register("RunnerService", "ArchiveResult", handler.handleArchiveResult)
+	server.Register("RunnerService", "Cancel", handler.handleCancel)
+	server.Register("RunnerService", "Checkpoint", handler.handleCheckpoint)
+	server.Register("RunnerService", "Custody", handler.handleCustody)
+	server.Register("RunnerService", "CustodyKey", handler.handleCustodyKey)
+	server.Register("RunnerService", "CustodyRecords", handler.handleCustodyRecords)
 	server.Register("RunnerService", "Delete", handler.handleDelete)
 	server.Register("RunnerService", "Failed", handler.handleFailed)
 	server.Register("RunnerService", "FailedStage", handler.handleFailedStage)
 	server.Register("RunnerService", "Finish", handler.handleFinish)
 	server.Register("RunnerService", "FinishStage", handler.handleFinishStage)
 	server.Register("RunnerService", "Get", handler.handleGet)
+	server.Register("RunnerService", "HashSetMatches", handler.handleHashSetMatches)
 	server.Register("RunnerService", "Heartbeat", handler.handleHeartbeat)
 	server.Register("RunnerService", "List", handler.handleList)
 	server.Register("RunnerService", "LogDebug", handler.handleLogDebug)
 	server.Register("RunnerService", "LogError", handler.handleLogError)
 	server.Register("RunnerService", "LogInfo", handler.handleLogInfo)
 	server.Register("RunnerService", "LogItem", handler.handleLogItem)
+	server.Register("RunnerService", "Plan", handler.handlePlan)
 	server.Register("RunnerService", "Script", handler.handleScript)
 	server.Register("RunnerService", "Start", handler.handleStart)
 	server.Register("RunnerService", "StartStage", handler.handleStartStage)
@@ -186,6 +312,114 @@ func (s *runnerServiceServer) handleApply(w http.ResponseWriter, r *http.Request
 	}
 }
 
+func (s *runnerServiceServer) handleArchiveResult(w http.ResponseWriter, r *http.Request) {
+	var request ArchiveResultRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.ArchiveResult(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+	var request RunnerCancelRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.Cancel(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleCheckpoint(w http.ResponseWriter, r *http.Request) {
+	var request CheckpointRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.Checkpoint(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleCustody(w http.ResponseWriter, r *http.Request) {
+	var request CustodyRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.Custody(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleCustodyKey(w http.ResponseWriter, r *http.Request) {
+	var request CustodyKeyRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.CustodyKey(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleCustodyRecords(w http.ResponseWriter, r *http.Request) {
+	var request CustodyRecordsRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.CustodyRecords(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
 func (s *runnerServiceServer) handleDelete(w http.ResponseWriter, r *http.Request) {
 	var request RunnerDeleteRequest
 	if err := otohttp.Decode(r, &request); err != nil {
@@ -294,6 +528,24 @@ func (s *runnerServiceServer) handleGet(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+func (s *runnerServiceServer) handleHashSetMatches(w http.ResponseWriter, r *http.Request) {
+	var request HashSetMatchesRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.HashSetMatches(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
 func (s *runnerServiceServer) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
 	var request RunnerStartRequest
 	if err := otohttp.Decode(r, &request); err != nil {
@@ -402,8 +654,26 @@ func (s *runnerServiceServer) handleLogItem(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+func (s *runnerServiceServer) handlePlan(w http.ResponseWriter, r *http.Request) {
+	var request RunnerPlanRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.Plan(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
 func (s *runnerServiceServer) handleScript(w http.ResponseWriter, r *http.Request) {
-	var request RunnerGetRequest
+	var request RunnerScriptRequest
 	if err := otohttp.Decode(r, &request); err != nil {
 		s.server.OnErr(w, r, err)
 		return
@@ -486,6 +756,8 @@ func RegisterServerService(server *otohttp.Server, serverService ServerService)
 		serverService: serverService,
 	}
 	server.Register("ServerService", "Apply", handler.handleApply)
+	server.Register("ServerService", "Delete", handler.handleDelete)
+	server.Register("ServerService", "Get", handler.handleGet)
 	server.Register("ServerService", "List", handler.handleList)
 }
 
@@ -507,6 +779,42 @@ func (s *serverServiceServer) handleApply(w http.ResponseWriter, r *http.Request
 	}
 }
 
+func (s *serverServiceServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var request ServerDeleteRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.serverService.Delete(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *serverServiceServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	var request ServerGetRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.serverService.Get(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
 func (s *serverServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
 	var request ServerListRequest
 	if err := otohttp.Decode(r, &request); err != nil {
@@ -525,6 +833,95 @@ func (s *serverServiceServer) handleList(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+type webhookServiceServer struct {
+	server         *otohttp.Server
+	webhookService WebhookService
+}
+
+// Register adds the WebhookService to the otohttp.Server.
+func RegisterWebhookService(server *otohttp.Server, webhookService WebhookService) {
+	handler := &webhookServiceServer{
+		server:         server,
+		webhookService: webhookService,
+	}
+	server.Register("WebhookService", "Add", handler.handleAdd)
+	server.Register("WebhookService", "Delete", handler.handleDelete)
+	server.Register("WebhookService", "Deliveries", handler.handleDeliveries)
+	server.Register("WebhookService", "List", handler.handleList)
+}
+
+func (s *webhookServiceServer) handleAdd(w http.ResponseWriter, r *http.Request) {
+	var request WebhookAddRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.webhookService.Add(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *webhookServiceServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var request WebhookDeleteRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.webhookService.Delete(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *webhookServiceServer) handleDeliveries(w http.ResponseWriter, r *http.Request) {
+	var request WebhookDeliveriesRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.webhookService.Deliveries(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *webhookServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
+	var request WebhookListRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.webhookService.List(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
 type Base struct {
 	ID    uint   `json:"id" yaml:"id"`
 	CTime int64  `json:"cTime" yaml:"cTime"`
@@ -532,6 +929,104 @@ type Base struct {
 	DTime *int64 `json:"dTime" yaml:"dTime"`
 }
 
+// Archive closes the cases and copies the single-, compound- and review-cases to
+// an archive-share, verified with per-file hashes
+type Archive struct {
+	datastore.Base
+	// StageID foreign-key for stage-table
+	StageID uint `json:"stageID" yaml:"stageID"`
+	// Destination is the archive-share to copy the case to
+	Destination string `json:"destination" yaml:"destination"`
+	// RemoveSource - if the single-case directory should be removed after the copies
+	// has been verified (refused if a compound- or review-case is configured, they
+	// list the single-case)
+	RemoveSource bool `json:"removeSource" yaml:"removeSource"`
+	// Location of the archived cases (comma-separated)
+	Location string `json:"location" yaml:"location"`
+	// Verified - if all files in the archived cases matched the hashes of the source
+	Verified bool `json:"verified" yaml:"verified"`
+	// Files is the amount of files that was archived
+	Files int64 `json:"files" yaml:"files"`
+	// Mismatches is the amount of files that did not match the hashes of the source
+	Mismatches int64 `json:"mismatches" yaml:"mismatches"`
+	// Status for the stage
+	Status int64 `json:"status" yaml:"status"`
+}
+
+// ArchiveResultRequest is the input-object for reporting the result of an archived
+// case
+type ArchiveResultRequest struct {
+	Runner     string `json:"runner" yaml:"runner"`
+	StageID    uint   `json:"stageID" yaml:"stageID"`
+	Location   string `json:"location" yaml:"location"`
+	Files      int64  `json:"files" yaml:"files"`
+	Mismatches int64  `json:"mismatches" yaml:"mismatches"`
+}
+
+// ArchiveResultResponse is the output-object for reporting the result of an
+// archived case
+type ArchiveResultResponse struct {
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// AuditEntry is an entry in the append-only audit-trail (the db refuses to update
+// or delete it), recorded for every mutating call to the api and for the api-keys
+// managed by the CLI
+type AuditEntry struct {
+	datastore.Base
+	// Time for the call
+	Time time.Time `json:"time" yaml:"time"`
+	// Identity is the name of the api-key for the call
+	Identity string `json:"identity" yaml:"identity"`
+	// Role for the api-key
+	Role string `json:"role" yaml:"role"`
+	// RemoteAddr is the source-ip for the call
+	RemoteAddr string `json:"remoteAddr" yaml:"remoteAddr"`
+	// ForwardedFor is the X-Forwarded-For header for the call (if set)
+	ForwardedFor string `json:"forwardedFor" yaml:"forwardedFor"`
+	// Method that was called (e.g RunnerService.Apply)
+	Method string `json:"method" yaml:"method"`
+	// Resource the method changes (runner, server, nms, webhook, file or api-key)
+	Resource string `json:"resource" yaml:"resource"`
+	// Target is the name of the resource (e.g the name of the runner)
+	Target string `json:"target" yaml:"target"`
+	// StatusCode for the response
+	StatusCode int64 `json:"statusCode" yaml:"statusCode"`
+	// Exception is the error for the call (if it failed)
+	Exception string `json:"exception" yaml:"exception"`
+	// Request is the JSON-body for the call (without passwords and secrets)
+	Request string `json:"request" yaml:"request"`
+	// Diff is the changes (JSON) from the request compared with the state in the db
+	// before the call
+	Diff string `json:"diff" yaml:"diff"`
+}
+
+// AuditListRequest is the input-object for listing the audit-trail
+type AuditListRequest struct {
+	// Identity to list the entries for
+	Identity string `json:"identity" yaml:"identity"`
+	// Method to list the entries for (e.g RunnerService.Delete)
+	Method string `json:"method" yaml:"method"`
+	// Resource to list the entries for (runner, server, nms, webhook or file)
+	Resource string `json:"resource" yaml:"resource"`
+	// Target to list the entries for (e.g the name of the runner)
+	Target string `json:"target" yaml:"target"`
+	// Since lists the entries from this time
+	Since *time.Time `json:"since" yaml:"since"`
+	// Until lists the entries before this time
+	Until *time.Time `json:"until" yaml:"until"`
+	// Limit for the amount of entries (the latest first), 100 if 0
+	Limit int64 `json:"limit" yaml:"limit"`
+}
+
+// AuditListResponse is the output-object for listing the audit-trail
+type AuditListResponse struct {
+	Entries []AuditEntry `json:"entries" yaml:"entries"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 // Case holds the information for a case
 type Case struct {
 	datastore.Base
@@ -564,6 +1059,83 @@ type CaseSettings struct {
 	ReviewCompound   *Case `json:"reviewCompound" yaml:"reviewCompound"`
 }
 
+// CustodyRecord holds the hash for an evidence-file that was ingested by a
+// process-stage (chain of custody)
+type CustodyRecord struct {
+	datastore.Base
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint `json:"evidenceID" yaml:"evidenceID"`
+	// Path to the evidence-file
+	Path string `json:"path" yaml:"path"`
+	// Size of the evidence-file in bytes
+	Size int64 `json:"size" yaml:"size"`
+	// Algorithm used to hash the evidence-file
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+	// Hash for the evidence-file
+	Hash string `json:"hash" yaml:"hash"`
+	// ModifiedAt is when the evidence-file was last modified (unix-timestamp)
+	ModifiedAt int64 `json:"modifiedAt" yaml:"modifiedAt"`
+	// HashedAt is when the evidence-file was hashed (unix-timestamp)
+	HashedAt int64 `json:"hashedAt" yaml:"hashedAt"`
+}
+
+// CustodyRecordsRequest is the input-object for storing the hashes for
+// evidence-files
+type CustodyRecordsRequest struct {
+	Runner     string          `json:"runner" yaml:"runner"`
+	StageID    uint            `json:"stageID" yaml:"stageID"`
+	EvidenceID uint            `json:"evidenceID" yaml:"evidenceID"`
+	Records    []CustodyRecord `json:"records" yaml:"records"`
+}
+
+// CustodyRecordsResponse is the output-object for storing the hashes for
+// evidence-files
+type CustodyRecordsResponse struct {
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// CustodyRequest is the input-object for exporting the chain of custody for a
+// runner
+type CustodyRequest struct {
+	// Name of the runner
+	Name string `json:"name" yaml:"name"`
+	// Format for the document - json or csv
+	Format string `json:"format" yaml:"format"`
+}
+
+// CustodyResponse is the output-object for exporting the chain of custody for a
+// runner
+type CustodyResponse struct {
+	// Document for the chain of custody
+	Document string `json:"document" yaml:"document"`
+	// Signature for the document (base64-encoded ed25519-signature)
+	Signature string `json:"signature" yaml:"signature"`
+	// PublicKey to verify the signature with (base64-encoded)
+	PublicKey string `json:"publicKey" yaml:"publicKey"`
+	// Fingerprint for the public-key (sha256), it must match the fingerprint that is
+	// pinned from CustodyKey or the service-log
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// CustodyKeyRequest is the input-object for getting the key for the chain of
+// custody
+type CustodyKeyRequest struct {
+}
+
+// CustodyKeyResponse is the output-object for getting the key for the chain of
+// custody
+type CustodyKeyResponse struct {
+	// PublicKey that verifies the signatures (PEM-encoded)
+	PublicKey string `json:"publicKey" yaml:"publicKey"`
+	// Fingerprint for the public-key (sha256)
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 type Elasticsearch struct {
 	datastore.Base
 	ClusterName           string `json:"clusterName" yaml:"clusterName"`
@@ -615,6 +1187,53 @@ type File struct {
 	Path string `json:"path" yaml:"path"`
 }
 
+// HashList holds information about a file of known hashes
+type HashList struct {
+	datastore.Base
+	// HashSetID foreign-key for hashset-table
+	HashSetID uint `json:"hashSetID" yaml:"hashSetID"`
+	// Path for where the list is located at
+	Path string `json:"path" yaml:"path"`
+	// Algorithm for the hashes in the list (md5 or sha1)
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+	// Matches is the amount of items that matched the list
+	Matches int64 `json:"matches" yaml:"matches"`
+}
+
+// HashSet tags or excludes items in a Nuix-case that match one or more lists of
+// known hashes
+type HashSet struct {
+	datastore.Base
+	// StageID foreign-key for stage-table
+	StageID uint `json:"stageID" yaml:"stageID"`
+	// Lists of known hashes to match the items against
+	Lists []*HashList `json:"lists" yaml:"lists"`
+	// Action for the matching items (tag or exclude)
+	Action string `json:"action" yaml:"action"`
+	// Tag for the matching items if the action is tag
+	Tag string `json:"tag" yaml:"tag"`
+	// Reason to exclude the matching items if the action is exclude
+	Reason string `json:"reason" yaml:"reason"`
+	// Status for the stage
+	Status int64 `json:"status" yaml:"status"`
+}
+
+// HashSetMatchesRequest is the input-object for reporting the matches for a
+// hash-list
+type HashSetMatchesRequest struct {
+	Runner  string `json:"runner" yaml:"runner"`
+	StageID uint   `json:"stageID" yaml:"stageID"`
+	ListID  uint   `json:"listID" yaml:"listID"`
+	Matches int64  `json:"matches" yaml:"matches"`
+}
+
+// HashSetMatchesResponse is the output-object for reporting the matches for a
+// hash-list
+type HashSetMatchesResponse struct {
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 type Settings struct {
 	SettingsFile string `json:"settingsFile" yaml:"settingsFile"`
 }
@@ -655,6 +1274,38 @@ type LicenceApplyRequest struct {
 	Amount int64 `json:"amount" yaml:"amount"`
 }
 
+// LicenceUsage compares a licence-type in the NMS with the licence-type in the
+// service.
+type LicenceUsage struct {
+	// Type of licence.
+	Type string `json:"type" yaml:"type"`
+	// Amount of licences for the type in the service.
+	Amount int64 `json:"amount" yaml:"amount"`
+	// InUse - licences reserved by the runners in the service.
+	InUse int64 `json:"inUse" yaml:"inUse"`
+	// Pool - the size of the licence-pool in the NMS.
+	Pool int64 `json:"pool" yaml:"pool"`
+	// Leased - licences leased from the pool in the NMS.
+	Leased int64 `json:"leased" yaml:"leased"`
+	// Mismatch describes how the NMS and the service differs for the type, empty if
+	// they match.
+	Mismatch string `json:"mismatch" yaml:"mismatch"`
+}
+
+// LicenceLease is a lease of a licence in the NMS.
+type LicenceLease struct {
+	// Type of licence.
+	Type string `json:"type" yaml:"type"`
+	// Workers leased with the licence.
+	Workers int64 `json:"workers" yaml:"workers"`
+	// User that has the lease.
+	User string `json:"user" yaml:"user"`
+	// Host that has the lease.
+	Host string `json:"host" yaml:"host"`
+	// LeasedAt is when the licence was leased.
+	LeasedAt *time.Time `json:"leasedAt" yaml:"leasedAt"`
+}
+
 // Licences is a holder for Licence.
 type Licences struct {
 	Licence LicenceApplyRequest `json:"licence" yaml:"licence"`
@@ -706,6 +1357,20 @@ type Nms struct {
 	Licences []Licence `json:"licences" yaml:"licences"`
 	// Is NMS a CLS relay server.
 	IsRelay bool `json:"isRelay" yaml:"isRelay"`
+	// PoolsPath is the path for the licence-pools in the REST-api for the NMS
+	// (default: /api/v1/licences/pools).
+	PoolsPath string `json:"poolsPath" yaml:"poolsPath"`
+	// LeasesPath is the path for the leased licences in the REST-api for the NMS
+	// (default: /api/v1/licences/leases).
+	LeasesPath string `json:"leasesPath" yaml:"leasesPath"`
+	// Auth for the REST-api for the NMS - basic (default, with the username and
+	// password) or bearer (the password is the token).
+	Auth string `json:"auth" yaml:"auth"`
+	// Fingerprint (sha256) for the certificate for the NMS, pins the self-signed
+	// certificate instead of verifying its chain.
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+	// Insecure - skip verifying the certificate for the NMS.
+	Insecure bool `json:"insecure" yaml:"insecure"`
 }
 
 // NmsApplyRequest is the input-object for Apply in the NMS-service.
@@ -724,6 +1389,20 @@ type NmsApplyRequest struct {
 	Licences []Licences `json:"licences" yaml:"licences"`
 	// Whether the NMS is a CLS relay server.
 	IsRelay bool `json:"isRelay" yaml:"isRelay"`
+	// PoolsPath is the path for the licence-pools in the REST-api for the NMS
+	// (default: /api/v1/licences/pools).
+	PoolsPath string `json:"poolsPath" yaml:"poolsPath"`
+	// LeasesPath is the path for the leased licences in the REST-api for the NMS
+	// (default: /api/v1/licences/leases).
+	LeasesPath string `json:"leasesPath" yaml:"leasesPath"`
+	// Auth for the REST-api for the NMS - basic (default, with the username and
+	// password) or bearer (the password is the token).
+	Auth string `json:"auth" yaml:"auth"`
+	// Fingerprint (sha256) for the certificate for the NMS, pins the self-signed
+	// certificate instead of verifying its chain.
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+	// Insecure - skip verifying the certificate for the NMS.
+	Insecure bool `json:"insecure" yaml:"insecure"`
 }
 
 type NmsApplyRequests struct {
@@ -742,23 +1421,86 @@ type NmsApplyResponse struct {
 type NmsListLicencesRequest struct {
 	// ID for the nms-server to list the licences for.
 	NmsID uint `json:"nmsID" yaml:"nmsID"`
+	// Address for the nms-server to list the licences for (if the ID is not
+	// specified).
+	Address string `json:"address" yaml:"address"`
 }
 
 // NmsListLicencesResponse is the output-object for listing licences for a specific
 // NMS.
 type NmsListLicencesResponse struct {
+	// Nms the licences are listed for.
+	Nms Nms `json:"nms" yaml:"nms"`
+	// Licences configured for the NMS in the service, with the licences reserved by
+	// the runners (InUse).
 	Licences []Licence `json:"licences" yaml:"licences"`
+	// Usage compares the licence-pools and the leases in the NMS with the licences in
+	// the service by type.
+	Usage []LicenceUsage `json:"usage" yaml:"usage"`
+	// Leases - the current leases in the NMS.
+	Leases []LicenceLease `json:"leases" yaml:"leases"`
+	// Exception is the error from the NMS if it could not be queried (only the
+	// licences in the service are listed).
+	Exception string `json:"exception" yaml:"exception"`
 	// Error is string explaining what went wrong. Empty if everything was fine.
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 // NmsListRequest is the input-object for List in the NMS-service.
 type NmsListRequest struct {
+	// Address - only list the nms-servers with addresses matching the pattern (*
+	// matches any characters).
+	Address string `json:"address" yaml:"address"`
+	// Licence - only list the nms-servers with the licence-type.
+	Licence string `json:"licence" yaml:"licence"`
+	// Sort - the field to sort the nms-servers by (id, address or created), prefixed
+	// with - for descending.
+	Sort string `json:"sort" yaml:"sort"`
+	// Limit - the amount of nms-servers to list (every nms-server if 0).
+	Limit int64 `json:"limit" yaml:"limit"`
+	// Cursor - the NextCursor from the previous response to list the next page.
+	Cursor string `json:"cursor" yaml:"cursor"`
 }
 
 // NmsListResponse is the output-object for List in the NMS-service.
 type NmsListResponse struct {
 	Nms []Nms `json:"nms" yaml:"nms"`
+	// NextCursor - the cursor for the next page, empty if there are no more
+	// nms-servers.
+	NextCursor string `json:"nextCursor" yaml:"nextCursor"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// NmsGetRequest is the input-object for Get in the NMS-service.
+type NmsGetRequest struct {
+	// Address for the nms-server.
+	Address string `json:"address" yaml:"address"`
+	// Limit - the amount of runners to get, the latest first (defaults to 20).
+	Limit int64 `json:"limit" yaml:"limit"`
+}
+
+// NmsGetResponse is the output-object for Get in the NMS-service.
+type NmsGetResponse struct {
+	Nms Nms `json:"nms" yaml:"nms"`
+	// Runners that uses the nms-server (the latest first).
+	Runners []Runner `json:"runners" yaml:"runners"`
+	// TotalRunners - the amount of runners that uses the nms-server.
+	TotalRunners int64 `json:"totalRunners" yaml:"totalRunners"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// NmsDeleteRequest is the input-object for Delete in the NMS-service.
+type NmsDeleteRequest struct {
+	// Address for the nms-server.
+	Address string `json:"address" yaml:"address"`
+	// Force - delete the nms-server even if unfinished runners uses it.
+	Force bool `json:"force" yaml:"force"`
+}
+
+// NmsDeleteResponse is the output-object for Delete in the NMS-service.
+type NmsDeleteResponse struct {
 	// Error is string explaining what went wrong. Empty if everything was fine.
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
@@ -809,6 +1551,8 @@ type Process struct {
 	ProfilePath string `json:"profilePath" yaml:"profilePath"`
 	// EvidenceStore to process to the nuix-case
 	EvidenceStore []*Evidence `json:"evidenceStore" yaml:"evidenceStore"`
+	// HashEvidence hashes every evidence-file before processing (chain of custody)
+	HashEvidence bool `json:"hashEvidence" yaml:"hashEvidence"`
 	// Status for the stage
 	Status int64 `json:"status" yaml:"status"`
 }
@@ -848,6 +1592,22 @@ type Runner struct {
 	Status int64 `json:"status" yaml:"status"`
 	// HealthyAt - last time the runner was healthy
 	HealthyAt *time.Time `json:"healthyAt" yaml:"healthyAt"`
+	// MaxRuntime for the runner (e.g 12h) - the runner is stopped and set to timed out
+	// if it runs longer
+	MaxRuntime string `json:"maxRuntime" yaml:"maxRuntime"`
+	// StartedAt - when the runner was started
+	StartedAt *time.Time `json:"startedAt" yaml:"startedAt"`
+	// TemplateVersion - version of the script-templates used for the last run of the
+	// runner
+	TemplateVersion string `json:"templateVersion" yaml:"templateVersion"`
+	// Owner - the name of the api-key that applied the runner, investigators can only
+	// update and delete their own runners
+	Owner string `json:"owner" yaml:"owner"`
+	// Notify - comma-separated recipients for the emails when the runner has finished,
+	// failed or timed out (defaults to the investigator)
+	Notify string `json:"notify" yaml:"notify"`
+	// Exception for the last run if the runner failed or timed out
+	Exception string `json:"exception" yaml:"exception"`
 	// CaseSettings for the cases to use
 	CaseSettingsID uint          `json:"caseSettingsID" yaml:"caseSettingsID"`
 	CaseSettings   *CaseSettings `json:"caseSettings" yaml:"caseSettings"`
@@ -873,6 +1633,8 @@ type RunnerApplyRequest struct {
 	Xmx string `json:"xmx" yaml:"xmx"`
 	// Amount of workers to use for the runner
 	Workers int64 `json:"workers" yaml:"workers"`
+	// MaxRuntime for the runner (e.g 12h)
+	MaxRuntime string `json:"maxRuntime" yaml:"maxRuntime"`
 	// CaseSettings is the settings for the cases that should be processed if
 	// Process-stage is used
 	CaseSettings *CaseSettings `json:"caseSettings" yaml:"caseSettings"`
@@ -880,6 +1642,9 @@ type RunnerApplyRequest struct {
 	Stages []*Stage `json:"stages" yaml:"stages"`
 	// Switches to use for nuix-console
 	Switches []string `json:"switches" yaml:"switches"`
+	// Notify - recipients for the emails when the runner has finished, failed or timed
+	// out (defaults to the investigator for the case)
+	Notify []string `json:"notify" yaml:"notify"`
 	// Update - if the runner should be updated
 	Update bool `json:"update" yaml:"update"`
 }
@@ -910,6 +1675,22 @@ type RunnerDeleteResponse struct {
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// RunnerCancelRequest is the input-object for cancelling a runner by name
+type RunnerCancelRequest struct {
+	// Name of the runner
+	Name string `json:"name" yaml:"name"`
+	// Reason for the cancel (optional), it is set as the exception for the runner
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// RunnerCancelResponse is the output-object for cancelling a runner by name
+type RunnerCancelResponse struct {
+	// Runner that was cancelled
+	Runner Runner `json:"runner" yaml:"runner"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 // RunnerFailedRequest is the input-object for failing a runner by id
 type RunnerFailedRequest struct {
 	ID        uint   `json:"id" yaml:"id"`
@@ -949,22 +1730,156 @@ type RunnerGetResponse struct {
 
 // RunnerListRequest is the input-object for listing the runners from the backend
 type RunnerListRequest struct {
+	// Status - only list the runners with the statuses (waiting, running, failed,
+	// finished or timeout).
+	Status []string `json:"status" yaml:"status"`
+	// Hostname - only list the runners for the server.
+	Hostname string `json:"hostname" yaml:"hostname"`
+	// Nms - only list the runners for the nms-server.
+	Nms string `json:"nms" yaml:"nms"`
+	// Owner - only list the runners owned by the api-key (the name of the key that
+	// applied them).
+	Owner string `json:"owner" yaml:"owner"`
+	// Investigator - only list the runners with the investigator for their
+	// single-case.
+	Investigator string `json:"investigator" yaml:"investigator"`
+	// CreatedAfter - only list the runners created after the time (approximate for the
+	// runners saved before the creation-time was kept - their creation-time is the
+	// time for their latest save).
+	CreatedAfter *time.Time `json:"createdAfter" yaml:"createdAfter"`
+	// Name - only list the runners with names matching the pattern (* matches any
+	// characters).
+	Name string `json:"name" yaml:"name"`
+	// Sort - the field to sort the runners by (id, name, created or status), prefixed
+	// with - for descending.
+	Sort string `json:"sort" yaml:"sort"`
+	// Limit - the amount of runners to list (defaults to 100).
+	Limit int64 `json:"limit" yaml:"limit"`
+	// Cursor - the NextCursor from the previous response to list the next page.
+	Cursor string `json:"cursor" yaml:"cursor"`
 }
 
 // RunnerListResponse is the input-object for listing the runners from the backend
 type RunnerListResponse struct {
 	Runners []Runner `json:"runners" yaml:"runners"`
+	// NextCursor - the cursor for the next page, empty if there are no more runners.
+	NextCursor string `json:"nextCursor" yaml:"nextCursor"`
 	// Error is string explaining what went wrong. Empty if everything was fine.
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// RunnerScriptRequest is the input-object for getting the script for a runner
+type RunnerScriptRequest struct {
+	// Name of the runner
+	Name string `json:"name" yaml:"name"`
+	// Attempt - the start of the runner to get the archived script for (1 for the
+	// first start), 0 generates the script
+	Attempt int64 `json:"attempt" yaml:"attempt"`
+}
+
 // RunnerScriptResponse is the output-object for GetScript
 type RunnerScriptResponse struct {
 	Script string `json:"script" yaml:"script"`
+	// Library holds the stage-handlers for the script
+	Library string `json:"library" yaml:"library"`
+	// Attempt the script was archived for, 0 if the script was generated for the
+	// request
+	Attempt int64 `json:"attempt" yaml:"attempt"`
+	// Attempts - the amount of archived scripts for the runner
+	Attempts int64 `json:"attempts" yaml:"attempts"`
+	// Hash for the script (sha256)
+	Hash string `json:"hash" yaml:"hash"`
+	// LibraryHash - hash for the library (sha256)
+	LibraryHash string `json:"libraryHash" yaml:"libraryHash"`
+	// Plan the script got from the service for the attempt (JSON), empty if the script
+	// was generated for the request or the script never got the plan
+	Plan string `json:"plan" yaml:"plan"`
+	// PlanHash - hash for the plan (sha256)
+	PlanHash string `json:"planHash" yaml:"planHash"`
+	// TemplateVersion - version of the script-templates
+	TemplateVersion string `json:"templateVersion" yaml:"templateVersion"`
+	// Arguments for nuix_console when the script was started
+	Arguments []string `json:"arguments" yaml:"arguments"`
+	// ArchivedAt is when the script was archived - the runner was started
+	// (unix-timestamp)
+	ArchivedAt int64 `json:"archivedAt" yaml:"archivedAt"`
 	// Error is string explaining what went wrong. Empty if everything was fine.
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// ScriptArchive holds the generated script for a start of a runner, so it can be
+// shown exactly what was executed
+type ScriptArchive struct {
+	datastore.Base
+	// RunnerID foreign-key for runner-table
+	RunnerID uint `json:"runnerID" yaml:"runnerID"`
+	// Attempt - the start of the runner (1 for the first start)
+	Attempt int64 `json:"attempt" yaml:"attempt"`
+	// Script that was generated for the runner
+	Script string `json:"script" yaml:"script"`
+	// Library with the stage-handlers for the script
+	Library string `json:"library" yaml:"library"`
+	// Hash for the script (sha256)
+	Hash string `json:"hash" yaml:"hash"`
+	// LibraryHash - hash for the library (sha256)
+	LibraryHash string `json:"libraryHash" yaml:"libraryHash"`
+	// Plan the script got from the service (JSON), stored when the script requests the
+	// plan
+	Plan string `json:"plan" yaml:"plan"`
+	// PlanHash - hash for the plan (sha256)
+	PlanHash string `json:"planHash" yaml:"planHash"`
+	// TemplateVersion - version of the script-templates
+	TemplateVersion string `json:"templateVersion" yaml:"templateVersion"`
+	// Arguments for nuix_console in the order they were used
+	Arguments []ScriptArgument `json:"arguments" yaml:"arguments"`
+}
+
+// ScriptArgument is a command argument for nuix_console when a script was started
+type ScriptArgument struct {
+	datastore.Base
+	ScriptArchiveID uint   `json:"scriptArchiveID" yaml:"scriptArchiveID"`
+	Value           string `json:"value" yaml:"value"`
+}
+
+// RunnerPlanRequest is the input-object for getting the plan for a runner
+type RunnerPlanRequest struct {
+	// Runner - name of the runner
+	Runner string `json:"runner" yaml:"runner"`
+}
+
+// RunnerPlanResponse is the output-object for getting the plan for a runner
+type RunnerPlanResponse struct {
+	// ID of the runner
+	ID uint `json:"id" yaml:"id"`
+	// Runner - name of the runner
+	Runner string `json:"runner" yaml:"runner"`
+	// ScriptDir is the path to the avian-scripts on the server
+	ScriptDir string `json:"scriptDir" yaml:"scriptDir"`
+	// CaseSettings for the cases to open
+	CaseSettings *CaseSettings `json:"caseSettings" yaml:"caseSettings"`
+	// Stages to execute - in the order to execute them
+	Stages []*PlanStage `json:"stages" yaml:"stages"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// PlanStage is a stage in the plan for a runner
+type PlanStage struct {
+	// ID of the stage
+	ID uint `json:"id" yaml:"id"`
+	// Kind of the stage - the name of the stage-handler to execute the stage with
+	Kind string `json:"kind" yaml:"kind"`
+	// Name of the stage
+	Name string `json:"name" yaml:"name"`
+	// Status of the stage
+	Status int64 `json:"status" yaml:"status"`
+	// Checkpoint to resume the stage from (JSON) - empty if the stage should start
+	// from the beginning
+	Checkpoint string `json:"checkpoint" yaml:"checkpoint"`
+	// Stage with the settings for the stage
+	Stage *Stage `json:"stage" yaml:"stage"`
+}
+
 type StageRequest struct {
 	Runner  string `json:"runner" yaml:"runner"`
 	StageID uint   `json:"stageID" yaml:"stageID"`
@@ -977,6 +1892,17 @@ type Stage struct {
 	RunnerID uint `json:"runnerID" yaml:"runnerID"`
 	// Index for where the stage where indexed in the yaml
 	Index uint `json:"index" yaml:"index"`
+	// Timeout for the stage (e.g 2h30m) - the runner is stopped and set to timed out
+	// if the stage runs longer
+	Timeout string `json:"timeout" yaml:"timeout"`
+	// StartedAt - when the stage was started
+	StartedAt *time.Time `json:"startedAt" yaml:"startedAt"`
+	// Checkpoint reported by the runner-script (JSON) - used to resume the stage from
+	// where it failed in the next run
+	Checkpoint string `json:"checkpoint" yaml:"checkpoint"`
+	// Items - the amount of items the stage has handled (the highest count logged by
+	// the runner-script)
+	Items int64 `json:"items" yaml:"items"`
 	// Process-stage processes data into a Nuix-case
 	Process *Process `json:"process" yaml:"process"`
 	// SearchAndTag searches and tags data in a Nuix-case
@@ -995,6 +1921,10 @@ type Stage struct {
 	SyncDescendants *SyncDescendants `json:"syncDescendants" yaml:"syncDescendants"`
 	// ScanNewChildItems scans for new child items based on a search
 	ScanNewChildItems *ScanNewChildItems `json:"scanNewChildItems" yaml:"scanNewChildItems"`
+	// HashSet tags or excludes items matching lists of known hashes
+	HashSet *HashSet `json:"hashSet" yaml:"hashSet"`
+	// Archive copies and verifies the single-case to an archive-share
+	Archive *Archive `json:"archive" yaml:"archive"`
 }
 
 type StageResponse struct {
@@ -1003,6 +1933,20 @@ type StageResponse struct {
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// CheckpointRequest is the input-object for reporting a checkpoint for a stage
+type CheckpointRequest struct {
+	Runner  string `json:"runner" yaml:"runner"`
+	StageID uint   `json:"stageID" yaml:"stageID"`
+	// Checkpoint for the stage (JSON)
+	Checkpoint string `json:"checkpoint" yaml:"checkpoint"`
+}
+
+// CheckpointResponse is the output-object for reporting a checkpoint for a stage
+type CheckpointResponse struct {
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 // RunnerStartRequest is the input-object for starting a runner by id
 type RunnerStartRequest struct {
 	ID     uint   `json:"id" yaml:"id"`
@@ -1017,6 +1961,7 @@ type RunnerStartResponse struct {
 }
 
 type UploadFileRequest struct {
+	// Name of the file (without a path)
 	Name        string `json:"name" yaml:"name"`
 	Description string `json:"description" yaml:"description"`
 	Content     []byte `json:"content" yaml:"content"`
@@ -1028,6 +1973,107 @@ type UploadFileResponse struct {
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// Webhook is a subscription for the lifecycle-events, the events are sent as
+// signed JSON-POSTs to the URL
+type Webhook struct {
+	datastore.Base
+	// Name for the webhook
+	Name string `json:"name" yaml:"name"`
+	// Endpoint is the URL to POST the events to
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	// Events to send (comma-separated), all events if empty
+	Events string `json:"events" yaml:"events"`
+	// Runner to send the events for, all runners if empty
+	Runner string `json:"runner" yaml:"runner"`
+	// Secret to sign the events with (HMAC-SHA256)
+	Secret string `json:"secret" yaml:"secret"`
+}
+
+// WebhookDelivery is the delivery of an event to a webhook
+type WebhookDelivery struct {
+	datastore.Base
+	// WebhookID foreign-key for webhook-table
+	WebhookID uint `json:"webhookID" yaml:"webhookID"`
+	// Event that was delivered (e.g runner.started)
+	Event string `json:"event" yaml:"event"`
+	// Runner the event belongs to
+	Runner string `json:"runner" yaml:"runner"`
+	// Payload is the JSON-body for the event
+	Payload string `json:"payload" yaml:"payload"`
+	// Attempts to deliver the event
+	Attempts int64 `json:"attempts" yaml:"attempts"`
+	// StatusCode for the last attempt (0 if there was no response)
+	StatusCode int64 `json:"statusCode" yaml:"statusCode"`
+	// Exception for the last attempt
+	Exception string `json:"exception" yaml:"exception"`
+	// Delivered is true when the webhook responded with 2xx
+	Delivered bool `json:"delivered" yaml:"delivered"`
+	// NextAttemptAt is when the delivery is retried, nil when it is delivered or has
+	// no attempts left
+	NextAttemptAt *time.Time `json:"nextAttemptAt" yaml:"nextAttemptAt"`
+}
+
+// WebhookAddRequest is the input-object for adding a webhook
+type WebhookAddRequest struct {
+	// Name for the webhook
+	Name string `json:"name" yaml:"name"`
+	// Endpoint is the URL to POST the events to (http or https)
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	// Events to send (e.g runner.started, stage.failed), all events if empty
+	Events []string `json:"events" yaml:"events"`
+	// Runner to send the events for, all runners if empty
+	Runner string `json:"runner" yaml:"runner"`
+	// Secret to sign the events with, generated if empty
+	Secret string `json:"secret" yaml:"secret"`
+}
+
+// WebhookAddResponse is the output-object for adding a webhook
+type WebhookAddResponse struct {
+	// Webhook that was added (with its secret)
+	Webhook Webhook `json:"webhook" yaml:"webhook"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// WebhookListRequest is the input-object for listing the webhooks
+type WebhookListRequest struct {
+}
+
+// WebhookListResponse is the output-object for listing the webhooks
+type WebhookListResponse struct {
+	Webhooks []Webhook `json:"webhooks" yaml:"webhooks"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// WebhookDeleteRequest is the input-object for deleting a webhook
+type WebhookDeleteRequest struct {
+	// Name of the webhook
+	Name string `json:"name" yaml:"name"`
+}
+
+// WebhookDeleteResponse is the output-object for deleting a webhook
+type WebhookDeleteResponse struct {
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// WebhookDeliveriesRequest is the input-object for the delivery-log for a webhook
+type WebhookDeliveriesRequest struct {
+	// Name of the webhook
+	Name string `json:"name" yaml:"name"`
+	// Limit for the amount of deliveries (the latest first), 50 if 0
+	Limit int64 `json:"limit" yaml:"limit"`
+}
+
+// WebhookDeliveriesResponse is the output-object for the delivery-log for a
+// webhook
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries" yaml:"deliveries"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 type ScanNewChildItems struct {
 	datastore.Base
 	// StageID foreign-key for stage-table
@@ -1096,11 +2142,58 @@ type ServerApplyResponse struct {
 
 // ServerListRequest is the input-object for List in the server-service.
 type ServerListRequest struct {
+	// Hostname - only list the servers with hostnames matching the pattern (* matches
+	// any characters).
+	Hostname string `json:"hostname" yaml:"hostname"`
+	// Active - only list the active (or inactive) servers.
+	Active *bool `json:"active" yaml:"active"`
+	// Sort - the field to sort the servers by (id, hostname or created), prefixed with
+	// - for descending.
+	Sort string `json:"sort" yaml:"sort"`
+	// Limit - the amount of servers to list (every server if 0).
+	Limit int64 `json:"limit" yaml:"limit"`
+	// Cursor - the NextCursor from the previous response to list the next page.
+	Cursor string `json:"cursor" yaml:"cursor"`
 }
 
 // ServerListResponse is the output-object for List in the server-service.
 type ServerListResponse struct {
 	Servers []Server `json:"servers" yaml:"servers"`
+	// NextCursor - the cursor for the next page, empty if there are no more servers.
+	NextCursor string `json:"nextCursor" yaml:"nextCursor"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ServerGetRequest is the input-object for Get in the server-service.
+type ServerGetRequest struct {
+	// Hostname of the server.
+	Hostname string `json:"hostname" yaml:"hostname"`
+	// Limit - the amount of runners to get, the latest first (defaults to 20).
+	Limit int64 `json:"limit" yaml:"limit"`
+}
+
+// ServerGetResponse is the output-object for Get in the server-service.
+type ServerGetResponse struct {
+	Server Server `json:"server" yaml:"server"`
+	// Runners that uses the server (the latest first).
+	Runners []Runner `json:"runners" yaml:"runners"`
+	// TotalRunners - the amount of runners that uses the server.
+	TotalRunners int64 `json:"totalRunners" yaml:"totalRunners"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ServerDeleteRequest is the input-object for Delete in the server-service.
+type ServerDeleteRequest struct {
+	// Hostname of the server.
+	Hostname string `json:"hostname" yaml:"hostname"`
+	// Force - delete the server even if unfinished runners uses it.
+	Force bool `json:"force" yaml:"force"`
+}
+
+// ServerDeleteResponse is the output-object for Delete in the server-service.
+type ServerDeleteResponse struct {
 	// Error is string explaining what went wrong. Empty if everything was fine.
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
@@ -0,0 +1,20 @@
+package api
+
+import "net/http"
+
+// OpenAPIPath is the well-known path for the OpenAPI-document
+const OpenAPIPath = "/openapi.json"
+
+// OpenAPIHandler returns a handler that serves the OpenAPI-document
+// (generated from generate/templates/openapi.go.plush)
+func OpenAPIHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(OpenAPI))
+	})
+}
@@ -0,0 +1,312 @@
+package api_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/matryer/is"
+)
+
+// services are the interfaces for the oto-services
+var services = []reflect.Type{
+	reflect.TypeOf((*api.AuditService)(nil)).Elem(),
+	reflect.TypeOf((*api.NmsService)(nil)).Elem(),
+	reflect.TypeOf((*api.RunnerService)(nil)).Elem(),
+	reflect.TypeOf((*api.ServerService)(nil)).Elem(),
+	reflect.TypeOf((*api.WebhookService)(nil)).Elem(),
+}
+
+// TestOpenAPI checks that every method is in the OpenAPI-document
+// and that the requests and the responses are valid against it
+func TestOpenAPI(t *testing.T) {
+	is := is.New(t)
+	var spec map[string]interface{}
+	is.NoErr(json.Unmarshal([]byte(api.OpenAPI), &spec))
+	is.Equal(spec["openapi"], "3.0.3")
+
+	paths := spec["paths"].(map[string]interface{})
+	var methods []string
+	for _, service := range services {
+		for i := 0; i < service.NumMethod(); i++ {
+			method := service.Method(i)
+			path := "/" + service.Name() + "." + method.Name
+			methods = append(methods, path)
+
+			operation, ok := lookup(paths, path, "post").(map[string]interface{})
+			if !ok {
+				t.Errorf("no operation for: %s", path)
+				continue
+			}
+
+			request := method.Type.In(1)
+			response := method.Type.Out(0).Elem()
+			is.Equal(lookup(operation, "requestBody", "content", "application/json", "schema", "$ref"), "#/components/schemas/"+request.Name())
+			is.Equal(lookup(operation, "responses", "200", "content", "application/json", "schema", "$ref"), "#/components/schemas/"+response.Name())
+
+			// check the zero-values (with nulls) and filled values
+			for _, typ := range []reflect.Type{request, response} {
+				for _, value := range []reflect.Value{reflect.New(typ).Elem(), fill(typ, 0)} {
+					b, err := json.Marshal(value.Interface())
+					is.NoErr(err)
+					var body interface{}
+					is.NoErr(json.Unmarshal(b, &body))
+					v := validator{spec: spec}
+					v.validate(ref(typ.Name()), body, path+" "+typ.Name())
+					for _, err := range v.errs {
+						t.Error(err)
+					}
+				}
+			}
+		}
+	}
+
+	// and no operations without a method (or the stream for the events)
+	methods = append(methods, "/RunnerService.Events")
+	var documented []string
+	for path := range paths {
+		documented = append(documented, path)
+	}
+	sort.Strings(methods)
+	sort.Strings(documented)
+	is.Equal(documented, methods)
+}
+
+// TestOpenAPIEvents checks the GET for the stream of
+// events and that the events are valid against the schema
+func TestOpenAPIEvents(t *testing.T) {
+	is := is.New(t)
+	var spec map[string]interface{}
+	is.NoErr(json.Unmarshal([]byte(api.OpenAPI), &spec))
+
+	operation, ok := lookup(spec, "paths", "/RunnerService.Events", "get").(map[string]interface{})
+	is.True(ok) // the stream is a GET
+	var parameters []string
+	for _, p := range operation["parameters"].([]interface{}) {
+		parameter := p.(map[string]interface{})
+		parameters = append(parameters, parameter["in"].(string)+":"+parameter["name"].(string))
+	}
+	is.Equal(parameters, []string{"query:runner", "query:type", "query:since", "query:follow", "header:Last-Event-ID"})
+	is.Equal(lookup(operation, "responses", "200", "content", "text/event-stream", "x-data", "$ref"), "#/components/schemas/Event")
+
+	for _, value := range []reflect.Value{reflect.New(reflect.TypeOf(events.Event{})).Elem(), fill(reflect.TypeOf(events.Event{}), 0)} {
+		b, err := json.Marshal(value.Interface())
+		is.NoErr(err)
+		var body interface{}
+		is.NoErr(json.Unmarshal(b, &body))
+		v := validator{spec: spec}
+		v.validate(ref("Event"), body, "Event")
+		for _, err := range v.errs {
+			t.Error(err)
+		}
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(api.OpenAPIHandler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + api.OpenAPIPath)
+	is.NoErr(err)
+	defer resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusOK)
+	is.Equal(resp.Header.Get("Content-Type"), "application/json")
+	var spec map[string]interface{}
+	is.NoErr(json.NewDecoder(resp.Body).Decode(&spec))
+
+	resp, err = http.Post(srv.URL+api.OpenAPIPath, "application/json", nil)
+	is.NoErr(err)
+	resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusMethodNotAllowed)
+}
+
+// validator validates JSON-values against the schemas in
+// the OpenAPI-document (the parts of it that is generated),
+// properties that are not in the schema are errors
+type validator struct {
+	spec map[string]interface{}
+	errs []string
+}
+
+func (v *validator) errorf(path, format string, a ...interface{}) {
+	v.errs = append(v.errs, path+": "+fmt.Sprintf(format, a...))
+}
+
+// resolve returns the schema for a $ref
+func (v *validator) resolve(schema map[string]interface{}) map[string]interface{} {
+	r, ok := schema["$ref"].(string)
+	if !ok {
+		return schema
+	}
+	resolved, ok := lookup(v.spec, strings.Split(strings.TrimPrefix(r, "#/"), "/")...).(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{"x-missing": r}
+	}
+	return resolved
+}
+
+// properties returns the properties for the schema and its allOf
+func (v *validator) properties(schema map[string]interface{}) map[string]interface{} {
+	schema = v.resolve(schema)
+	props := make(map[string]interface{})
+	if p, ok := schema["properties"].(map[string]interface{}); ok {
+		for name, prop := range p {
+			props[name] = prop
+		}
+	}
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, s := range all {
+			for name, prop := range v.properties(s.(map[string]interface{})) {
+				props[name] = prop
+			}
+		}
+	}
+	return props
+}
+
+func (v *validator) validate(schema map[string]interface{}, value interface{}, path string) {
+	schema = v.resolve(schema)
+	if missing, ok := schema["x-missing"]; ok {
+		v.errorf(path, "missing schema: %v", missing)
+		return
+	}
+	if value == nil {
+		if schema["nullable"] != true {
+			v.errorf(path, "null is not nullable")
+		}
+		return
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok && schema["type"] == nil {
+		if object, ok := value.(map[string]interface{}); ok {
+			v.validateObject(v.properties(schema), object, path)
+			return
+		}
+		for _, s := range all {
+			v.validate(s.(map[string]interface{}), value, path)
+		}
+		return
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.errorf(path, "expected object, got %T", value)
+			return
+		}
+		v.validateObject(v.properties(schema), object, path)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			v.errorf(path, "expected array, got %T", value)
+			return
+		}
+		for i, item := range array {
+			v.validate(schema["items"].(map[string]interface{}), item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			v.errorf(path, "expected string, got %T", value)
+			return
+		}
+		switch schema["format"] {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				v.errorf(path, "invalid date-time: %v", err)
+			}
+		case "byte":
+			if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+				v.errorf(path, "invalid base64: %v", err)
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			v.errorf(path, "expected integer, got %v", value)
+			return
+		}
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			v.errorf(path, "%v is less than the minimum %v", n, min)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.errorf(path, "expected boolean, got %T", value)
+		}
+	default:
+		v.errorf(path, "unknown schema: %v", schema)
+	}
+}
+
+func (v *validator) validateObject(props map[string]interface{}, object map[string]interface{}, path string) {
+	for name, value := range object {
+		prop, ok := props[name].(map[string]interface{})
+		if !ok {
+			v.errorf(path, "property is not in the schema: %s", name)
+			continue
+		}
+		v.validate(prop, value, path+"."+name)
+	}
+}
+
+// fill returns a value for the type with every field set
+func fill(typ reflect.Type, depth int) reflect.Value {
+	value := reflect.New(typ).Elem()
+	if depth > 8 {
+		return value
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		value.SetString("value")
+	case reflect.Bool:
+		value.SetBool(true)
+	case reflect.Int, reflect.Int64:
+		value.SetInt(42)
+	case reflect.Uint, reflect.Uint8:
+		value.SetUint(7)
+	case reflect.Ptr:
+		value.Set(fill(typ.Elem(), depth+1).Addr())
+	case reflect.Slice:
+		value.Set(reflect.Append(reflect.MakeSlice(typ, 0, 1), fill(typ.Elem(), depth+1)))
+	case reflect.Struct:
+		if typ == reflect.TypeOf(time.Time{}) {
+			value.Set(reflect.ValueOf(time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)))
+			break
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			if typ.Field(i).PkgPath == "" {
+				value.Field(i).Set(fill(typ.Field(i).Type, depth+1))
+			}
+		}
+	}
+	return value
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+// lookup returns the value at the keys in nested maps
+func lookup(m map[string]interface{}, keys ...string) interface{} {
+	var value interface{} = m
+	for _, key := range keys {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
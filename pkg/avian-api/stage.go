@@ -0,0 +1,74 @@
+package api
+
+// StageKind is a type of stage for a runner (process, ocr, archive etc.),
+// every stage-type implements it in its own stage_*.go-file and
+// registers itself with RegisterStageKind in init.
+//
+// Everything that differs between the stage-types (validation, paths,
+// statuses, preloading and the ruby stage-handler) is looked up through
+// the registry instead of switching on the fields of the Stage.
+type StageKind interface {
+	// Name returns the name of the stage, used in logs and the CLI
+	Name(s *Stage) string
+	// Key returns the key of the stage-type in the Stage (e.g searchAndTag),
+	// the runner-script executes the stage with the stage-handler for the key
+	Key() string
+	// Is returns true if the stage is of this stage-type
+	Is(s *Stage) bool
+	// Status returns the status of the stage
+	Status(s *Stage) int64
+	// SetStatus sets the status of the stage
+	SetStatus(s *Stage, status int64)
+	// Validate validates the stage
+	Validate(s *Stage) error
+	// Paths returns the paths for the stage that the server
+	// needs access to before the runner is started
+	Paths(s *Stage) []string
+	// Preload returns the association to preload for
+	// the stage-type relative to the Stage (e.g Process.EvidenceStore)
+	Preload() string
+	// Models returns the db-models for the stage-type
+	Models() []interface{}
+	// Script returns the ruby stage-handler for the stage-type,
+	// the handlers are added to the library for the runner-script
+	// and gets the stage from the plan (see generate/ruby)
+	Script() string
+}
+
+var stageKinds []StageKind
+
+// RegisterStageKind registers a stage-type
+func RegisterStageKind(kind StageKind) { stageKinds = append(stageKinds, kind) }
+
+// StageKinds returns all the registered stage-types
+func StageKinds() []StageKind { return stageKinds }
+
+// Kind returns the stage-type of the stage,
+// nil if the stage has no registered stage-type
+func (s *Stage) Kind() StageKind {
+	for _, kind := range stageKinds {
+		if kind.Is(s) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// StagePreloads returns the associations to preload
+// for all the stage-types with the specified prefix (e.g Stages.)
+func StagePreloads(prefix string) []string {
+	var preloads []string
+	for _, kind := range stageKinds {
+		preloads = append(preloads, prefix+kind.Preload())
+	}
+	return preloads
+}
+
+// StageModels returns the db-models for all the stage-types
+func StageModels() []interface{} {
+	var models []interface{}
+	for _, kind := range stageKinds {
+		models = append(models, kind.Models()...)
+	}
+	return models
+}
@@ -0,0 +1,87 @@
+package api
+
+import "errors"
+
+func init() { RegisterStageKind(archiveKind{}) }
+
+// archiveKind closes the cases, copies the single-, compound-
+// and review-cases to an archive-share and verifies the copies
+type archiveKind struct{}
+
+func (archiveKind) Name(s *Stage) string             { return "Archive" }
+func (archiveKind) Key() string                      { return "archive" }
+func (archiveKind) Is(s *Stage) bool                 { return s.Archive != nil }
+func (archiveKind) Status(s *Stage) int64            { return s.Archive.Status }
+func (archiveKind) SetStatus(s *Stage, status int64) { s.Archive.Status = status }
+func (archiveKind) Paths(s *Stage) []string          { return []string{s.Archive.Destination} }
+func (archiveKind) Preload() string                  { return "Archive" }
+func (archiveKind) Models() []interface{}            { return []interface{}{&Archive{}} }
+func (archiveKind) Script() string                   { return archiveScript }
+
+func (archiveKind) Validate(s *Stage) error {
+	if emptyString(s.Archive.Destination) {
+		return errors.New("must specify a destination for archive-stage")
+	}
+	return nil
+}
+
+const archiveScript = `# Closes the cases, copies the single-, compound- and
+# review-cases to an archive-share and verifies the copies
+stage_handler('archive') do |stage, archive, context|
+  id = stage['id']
+  name = stage['name']
+
+  # Close the cases before they are archived
+  log_info(name, id, 'Closing cases before archiving')
+  tear_down(context[:single_case], context[:compound_case], context[:review_compound])
+  context[:cases_closed] = true
+
+  require 'digest'
+  locations = []
+  archived_files = 0
+  mismatches = 0
+  source = nil
+  # The cases are copied to a folder for their kind, so cases
+  # with the same directory-name are not merged in the archive
+  { 'case' => 'single', 'compoundCase' => 'compound', 'reviewCompound' => 'review' }.each do |key, kind|
+    settings = context[:case_settings][key]
+    next if settings.nil? || settings['directory'].to_s.empty?
+    case_dir = settings['directory'].tr('\\', '/')
+    source = case_dir if key == 'case'
+    destination = File.join(archive['destination'].tr('\\', '/'), kind, File.basename(case_dir))
+
+    # Copy the case-directory to the archive
+    log_info(name, id, 'Copying case to archive: ' + destination)
+    FileUtils.mkdir_p(destination)
+    FileUtils.cp_r(File.join(case_dir, '.'), destination)
+    log_debug(name, id, 'Copied case to archive')
+
+    # Verify the archived case with per-file hashes
+    log_info(name, id, 'Verifying archived case: ' + destination)
+    Dir.glob(File.join(case_dir, '**', '*'), File::FNM_DOTMATCH).each do |path|
+      next unless File.file?(path)
+      archived_files += 1
+      archived = File.join(destination, path[case_dir.length..-1])
+      unless File.file?(archived) && Digest::SHA256.file(path).hexdigest == Digest::SHA256.file(archived).hexdigest
+        mismatches += 1
+        log_error(name, id, 'Archived file does not match the source: ' + archived, '')
+      end
+    end
+    locations << destination
+  end
+  archive_result(id, locations.join(','), archived_files, mismatches)
+
+  if mismatches > 0
+    raise "verification of archived cases failed for #{mismatches} of #{archived_files} files"
+  end
+  log_info(name, id, "Verified #{archived_files} files in #{locations.length} archived cases")
+
+  # Only the single-case is removed - removeSource is refused
+  # for runners with a compound- or review-case (that lists it)
+  if archive['removeSource'] && !source.nil?
+    log_info(name, id, 'Removing source case-directory: ' + source)
+    FileUtils.rm_rf(source)
+    log_debug(name, id, 'Removed source case-directory')
+  end
+end
+`
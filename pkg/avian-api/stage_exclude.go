@@ -0,0 +1,45 @@
+package api
+
+import "errors"
+
+func init() { RegisterStageKind(excludeKind{}) }
+
+// excludeKind excludes the items from a search
+type excludeKind struct{}
+
+func (excludeKind) Name(s *Stage) string             { return "Exclude" }
+func (excludeKind) Key() string                      { return "exclude" }
+func (excludeKind) Is(s *Stage) bool                 { return s.Exclude != nil }
+func (excludeKind) Status(s *Stage) int64            { return s.Exclude.Status }
+func (excludeKind) SetStatus(s *Stage, status int64) { s.Exclude.Status = status }
+func (excludeKind) Preload() string                  { return "Exclude" }
+func (excludeKind) Models() []interface{}            { return []interface{}{&Exclude{}} }
+func (excludeKind) Paths(s *Stage) []string          { return nil }
+func (excludeKind) Script() string                   { return excludeScript }
+
+func (excludeKind) Validate(s *Stage) error {
+	if emptyString(s.Exclude.Search) {
+		return errors.New("must specify a search-query for exclude-stage")
+	}
+	if emptyString(s.Exclude.Reason) {
+		return errors.New("must specify a reason for exclude-stage")
+	}
+	return nil
+}
+
+const excludeScript = `# Excludes the items from a search
+stage_handler('exclude') do |stage, exclude, context|
+  id = stage['id']
+  name = stage['name']
+  single_case = context[:single_case]
+
+  items = single_case.search(exclude['search'])
+  log_debug(name, id, "Found #{items.length} from search #{exclude['search']} - starts excluding")
+  item_count = 0
+  for item in items
+    item.exclude(exclude['reason'])
+    item_count += 1
+    log_item(name, id, 'Excluded item', item_count, item.type.name, item.guid, '')
+  end
+end
+`
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+)
+
+func init() { RegisterStageKind(hashSetKind{}) }
+
+// hashSetKind tags or excludes the items matching lists of known hashes
+type hashSetKind struct{}
+
+func (hashSetKind) Name(s *Stage) string             { return "HashSet" }
+func (hashSetKind) Key() string                      { return "hashSet" }
+func (hashSetKind) Is(s *Stage) bool                 { return s.HashSet != nil }
+func (hashSetKind) Status(s *Stage) int64            { return s.HashSet.Status }
+func (hashSetKind) SetStatus(s *Stage, status int64) { s.HashSet.Status = status }
+func (hashSetKind) Preload() string                  { return "HashSet.Lists" }
+func (hashSetKind) Models() []interface{}            { return []interface{}{&HashSet{}, &HashList{}} }
+func (hashSetKind) Script() string                   { return hashSetScript }
+
+func (hashSetKind) Validate(s *Stage) error {
+	if len(s.HashSet.Lists) == 0 {
+		return errors.New("must specify lists for hash-set stage")
+	}
+
+	for i, list := range s.HashSet.Lists {
+		if emptyString(list.Path) {
+			return fmt.Errorf("must specify path to list for hash-set #%d", i)
+		}
+		if list.Algorithm != "md5" && list.Algorithm != "sha1" {
+			return fmt.Errorf("invalid algorithm for hash-set list: %s - must be 'md5' or 'sha1'", list.Path)
+		}
+	}
+
+	switch s.HashSet.Action {
+	case "tag":
+		if emptyString(s.HashSet.Tag) {
+			return errors.New("must specify a tag for hash-set stage")
+		}
+	case "exclude":
+		if emptyString(s.HashSet.Reason) {
+			return errors.New("must specify a reason for hash-set stage")
+		}
+	default:
+		return errors.New("must specify action for hash-set stage - 'tag' or 'exclude'")
+	}
+	return nil
+}
+
+func (hashSetKind) Paths(s *Stage) []string {
+	var paths []string
+	for _, list := range s.HashSet.Lists {
+		paths = append(paths, list.Path)
+	}
+	return paths
+}
+
+const hashSetScript = `# Tags or excludes the items matching lists of known hashes
+stage_handler('hashSet') do |stage, hash_set, context|
+  id = stage['id']
+  name = stage['name']
+  single_case = context[:single_case]
+
+  hash_set['lists'].each do |list|
+    # Read the hashes from the hash-list
+    log_info(name, id, 'Reading hash-list: ' + list['path'])
+    hash_length = list['algorithm'] == 'sha1' ? 40 : 32
+    hashes = []
+    File.foreach(list['path']) do |line|
+      line.scan(/\b[0-9a-fA-F]{#{hash_length}}\b/) { |hash| hashes << hash.downcase }
+    end
+    hashes.uniq!
+    log_debug(name, id, "Found #{hashes.length} unique hashes in hash-list: #{list['path']}")
+
+    # Search for the hashes in batches to keep the queries short
+    matches = 0
+    hashes.each_slice(500) do |batch|
+      items = single_case.search("#{list['algorithm']}:(#{batch.join(' OR ')})")
+      for item in items
+        if hash_set['action'] == 'tag'
+          item.add_tag(hash_set['tag'])
+        else
+          item.exclude(hash_set['reason'])
+        end
+        matches += 1
+        log_item(name, id, 'Matched item', matches, item.type.name, item.guid, '')
+      end
+    end
+
+    log_info(name, id, "Matched #{matches} items from hash-list: #{list['path']}")
+    hash_set_matches(id, list['id'], matches)
+  end
+end
+`
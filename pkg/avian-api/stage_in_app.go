@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/inapp"
+)
+
+func init() { RegisterStageKind(inAppKind{}) }
+
+// inAppKind runs one of the avian in-app scripts
+type inAppKind struct{}
+
+func (inAppKind) Name(s *Stage) string             { return "InApp-" + s.InApp.Name }
+func (inAppKind) Key() string                      { return "inApp" }
+func (inAppKind) Is(s *Stage) bool                 { return s.InApp != nil }
+func (inAppKind) Status(s *Stage) int64            { return s.InApp.Status }
+func (inAppKind) SetStatus(s *Stage, status int64) { s.InApp.Status = status }
+func (inAppKind) Paths(s *Stage) []string          { return []string{s.InApp.Config} }
+func (inAppKind) Preload() string                  { return "InApp" }
+func (inAppKind) Models() []interface{}            { return []interface{}{&InApp{}} }
+func (inAppKind) Script() string                   { return inAppScript }
+
+func (inAppKind) Validate(s *Stage) error {
+	if emptyString(s.InApp.Name) {
+		return errors.New("must specify a name for in-app script")
+	}
+	if emptyString(s.InApp.Config) {
+		return errors.New("must specify a config for in-app script")
+	}
+
+	var settings inapp.Settings
+	if err := inapp.Config(s.InApp.Config, &settings); err != nil {
+		return fmt.Errorf("failed to decode config for in-app script: %s - %v", s.InApp.Name, err)
+	}
+	return nil
+}
+
+const inAppScript = `# Converts a script name to a module name.
+# There may very well be easier ways of doing this.
+def find_module_name(script_name)
+  module_name = ''
+  capitalize = true
+  for i in 0..script_name.length-1
+    if script_name[i] == '_'
+      capitalize = true
+    elsif capitalize
+      capitalize = false
+      module_name += script_name[i].capitalize
+    else
+      module_name += script_name[i]
+    end
+  end
+  # Chomp '.rb' just in case.
+  return module_name.chomp('.rb')
+end
+
+def load_script(script_dir, script_name)
+  script_path = File.join(script_dir, '_root', 'inapp-scripts', 'automation-scripts', "#{script_name}.rb")
+  module_name = find_module_name(script_name)
+  # Chomp '.rb' just in case.
+  require script_path.chomp('.rb')
+  # Log error if no such module exists
+  unless Object.const_defined?(module_name)
+    STDERR.puts('No module with name "' + module_name + '" exists. Make sure script files and modules have matching names.')
+  end
+  # Returns the script module as an object.
+  return Object.const_get(module_name)
+end
+
+# Runs one of the avian in-app scripts
+stage_handler('inApp') do |stage, in_app, context|
+  script_dir = context[:script_dir]
+
+  # ProgressHandler class
+  require File.join(script_dir, '_root', 'utils', 'progress_handler')
+
+  # Load the InApp-script
+  script = load_script(script_dir, in_app['name'])
+
+  # Setup the progress-handler
+  progress_handler = ProgressHandler::ProgressHandler.new { |message|
+    puts(message)
+  }
+
+  # Set settings for the script
+  require 'yaml'
+  read_settings = YAML.load(in_app['settings']['settingsFile'])
+  settings = {}
+  for key,value in read_settings
+    case key
+      when Symbol
+        settings[key] = value
+      else
+        settings[key.to_sym] = value
+    end
+  end
+  settings[:root_directory] = File.join(script_dir, '_root')
+
+  # run the script
+  script.run(context[:single_case], $utilities, settings, progress_handler)
+end
+`
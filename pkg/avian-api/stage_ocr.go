@@ -0,0 +1,79 @@
+package api
+
+import "errors"
+
+func init() { RegisterStageKind(ocrKind{}) }
+
+// ocrKind performs OCR in batches on the items from a search
+type ocrKind struct{}
+
+func (ocrKind) Name(s *Stage) string             { return "OCR" }
+func (ocrKind) Key() string                      { return "ocr" }
+func (ocrKind) Is(s *Stage) bool                 { return s.Ocr != nil }
+func (ocrKind) Status(s *Stage) int64            { return s.Ocr.Status }
+func (ocrKind) SetStatus(s *Stage, status int64) { s.Ocr.Status = status }
+func (ocrKind) Preload() string                  { return "Ocr" }
+func (ocrKind) Models() []interface{}            { return []interface{}{&Ocr{}} }
+func (ocrKind) Paths(s *Stage) []string          { return []string{s.Ocr.ProfilePath} }
+func (ocrKind) Script() string                   { return ocrScript }
+
+func (ocrKind) Validate(s *Stage) error {
+	if emptyString(s.Ocr.Profile) {
+		return errors.New("must specify a processing-profile for OCR-stage")
+	}
+	if emptyString(s.Ocr.Search) {
+		return errors.New("must specify a search-query for OCR-stage")
+	}
+	if s.Ocr.BatchSize == 0 {
+		return errors.New("must specify a batchSize for OCR-stage")
+	}
+	return nil
+}
+
+const ocrScript = `# Performs OCR in batches on the items from a search
+stage_handler('ocr') do |stage, ocr, context|
+  id = stage['id']
+  name = stage['name']
+  single_case = context[:single_case]
+
+  ocr_processor = $utilities.createOcrProcessor
+  # Check if the profile exists in the store
+  unless $utilities.get_ocr_profile_store.contains_profile(ocr['profile'])
+    # Import the profile
+    log_debug(name, id, 'Did not find the requested ocr-profile in the profile-store')
+    log_info(name, id, 'Importing new ocr-profile from path ' + ocr['profilePath'])
+    $utilities.get_ocr_profile_store.import_profile(ocr['profilePath'], ocr['profile'])
+    log_debug(name, id, 'OCR-profile has been imported')
+  end
+
+  ocr_profile = $utilities.get_ocr_profile_store.get_profile(ocr['profile'])
+  # the items are sorted so the batches are the same when the stage is resumed
+  ocr_items = single_case.search(ocr['search']).sort_by { |item| item.guid }
+  log_debug(name, id, "Found #{ocr_items.length} from search: #{ocr['search']} - starts ocr")
+  if ocr_items.length == 0
+    log_info(name, id, 'No OCR items to process - skipping stage')
+  else
+    # Log the info for the items
+    ocr_semaphore = Mutex.new
+    ocr_processor.when_item_event_occurs do |info|
+      ocr_semaphore.synchronize {
+        log_item(name, id, 'OCR item', info.stage_count, info.item.type.name, info.item.guid, info.stage)
+      }
+    end
+
+    # variables to use for batched ocr
+    target_batch_size = ocr['batchSize']
+    total_batches = (ocr_items.size.to_f / target_batch_size.to_f).ceil
+    position = resume_position(stage, ocr_items.length)
+    batch_index = position / target_batch_size
+
+    ocr_items.drop(position).each_slice(target_batch_size) do |slice_items|
+      log_info(name, id, "Start ocr-processing batch : #{batch_index+1}/#{total_batches}")
+      ocr_processor.process(slice_items, ocr_profile)
+      batch_index += 1
+      position += slice_items.length
+      checkpoint(id, {position: position, items: ocr_items.length})
+    end
+  end
+end
+`
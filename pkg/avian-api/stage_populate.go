@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+)
+
+func init() { RegisterStageKind(populateKind{}) }
+
+// populateKind exports the specified types for the items
+// from a search, so they are stored in the case
+type populateKind struct{}
+
+func (populateKind) Name(s *Stage) string             { return "Populate" }
+func (populateKind) Key() string                      { return "populate" }
+func (populateKind) Is(s *Stage) bool                 { return s.Populate != nil }
+func (populateKind) Status(s *Stage) int64            { return s.Populate.Status }
+func (populateKind) SetStatus(s *Stage, status int64) { s.Populate.Status = status }
+func (populateKind) Preload() string                  { return "Populate.Types" }
+func (populateKind) Models() []interface{}            { return []interface{}{&Populate{}, &Type{}} }
+func (populateKind) Paths(s *Stage) []string          { return nil }
+func (populateKind) Script() string                   { return populateScript }
+
+func (populateKind) Validate(s *Stage) error {
+	if emptyString(s.Populate.Search) {
+		return errors.New("must specify a search-query for populate-stage")
+	}
+
+	if len(s.Populate.Types) == 0 {
+		return errors.New("must specify types for populate-stage")
+	}
+
+	for i, t := range s.Populate.Types {
+		if emptyString(t.Type) {
+			return fmt.Errorf("must specify type for populate-stage type #%d", i)
+		}
+	}
+	return nil
+}
+
+const populateScript = `# Exports the natives and/or pdfs for the items
+# from a search, so they are stored in the case
+stage_handler('populate') do |stage, populate, context|
+  id = stage['id']
+  name = stage['name']
+  single_case = context[:single_case]
+
+  dir = File.join(Dir.tmpdir, 'populate')
+  unless Dir.exist?(dir)
+    log_info(name, id, "Creating tmp-dir: #{dir} for export")
+    FileUtils.mkdir_p(dir)
+  end
+
+  # Creates a batch-exporter for the products to populate,
+  # the items are exported in batches to report checkpoints
+  semaphore = Mutex.new
+  create_exporter = lambda do
+    exporter = $utilities.create_batch_exporter(dir)
+    populate['types'].each do |t|
+      case t['type']
+      when 'native'
+        exporter.addProduct('native', {
+          'naming' => 'guid',
+          'path' => 'Natives',
+          'regenerateStored' => true,
+        })
+      when 'pdf'
+        exporter.addProduct('pdf', {
+          'naming' => 'guid',
+          'path' => 'PDFs',
+          'regenerateStored' => true,
+        })
+      end
+    end
+
+    # Setup batch exporter callback
+    exporter.when_item_event_occurs do |info|
+      unless info.failure.nil?
+        log_error(name, id, "Export failure for item: #{info.item.guid} : #{info.item.localised_name}", '')
+      end
+      # Make the progress reporting have some thread safety
+      semaphore.synchronize {
+        log_item(name, id, 'Exporting item', info.stage_count, info.item.type.name, info.item.guid, info.stage)
+      }
+    end
+    exporter
+  end
+
+  # the items are sorted so the batches are the same when the stage is resumed
+  items = single_case.search(populate['search']).sort_by { |item| item.guid }
+  log_debug(name, id, "Found #{items.length} items from search: #{populate['search']} - starts export for populate")
+  position = resume_position(stage, items.length)
+
+  log_info(name, id, 'Starting export of items')
+  items.drop(position).each_slice(CHECKPOINT_INTERVAL) do |batch|
+    create_exporter.call.export_items(batch)
+    position += batch.length
+    checkpoint(id, {position: position, items: items.length})
+  end
+  log_debug(name, id, 'Finished export of items')
+
+  log_info(name, id, 'Removing tmp-dir')
+  FileUtils.rm_rf(dir)
+  log_debug(name, id, 'Removed tmp-dir')
+end
+`
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+func init() { RegisterStageKind(processKind{}) }
+
+// processKind processes the evidence into the single-case
+type processKind struct{}
+
+func (processKind) Name(s *Stage) string             { return "Process" }
+func (processKind) Key() string                      { return "process" }
+func (processKind) Is(s *Stage) bool                 { return s.Process != nil }
+func (processKind) Status(s *Stage) int64            { return s.Process.Status }
+func (processKind) SetStatus(s *Stage, status int64) { s.Process.Status = status }
+func (processKind) Preload() string                  { return "Process.EvidenceStore" }
+func (processKind) Models() []interface{}            { return []interface{}{&Process{}, &Evidence{}} }
+
+func (processKind) Validate(s *Stage) error {
+	if emptyString(s.Process.Profile) {
+		return errors.New("must specify processing-profile for process-stage")
+	}
+
+	if len(s.Process.EvidenceStore) == 0 {
+		return errors.New("must specify evidence for the process-stage")
+	}
+
+	for i, evidence := range s.Process.EvidenceStore {
+		if emptyString(evidence.Name) {
+			return fmt.Errorf("must specify name for evidence: #%d", i)
+		}
+		if emptyString(evidence.Directory) {
+			return fmt.Errorf("must specify directory for evidence: #%d", i)
+		}
+		if !emptyString(evidence.Locale) {
+			// Validate locale somewhat according to https://tools.ietf.org/html/rfc5646#section-2.1.1.
+			matchRegex, err := regexp.MatchString("^(?:[a-zA-Z0-9]{1,8}-)[a-zA-Z0-9]{1,8}$", evidence.Locale)
+			if err != nil {
+				panic("Invalid regex in code.")
+			}
+			if !matchRegex {
+				return fmt.Errorf("Invalid value for locale: %s. Must be alphanumeric with segments of maximum 8 length seperated by hyphens.", evidence.Locale)
+			}
+		}
+	}
+	return nil
+}
+
+func (processKind) Paths(s *Stage) []string {
+	paths := []string{s.Process.ProfilePath}
+	for _, evidence := range s.Process.EvidenceStore {
+		paths = append(paths, evidence.Directory)
+	}
+	return paths
+}
+
+func (processKind) Script() string { return processScript }
+
+const processScript = `# Processes the evidence into the single-case, the compound-cases
+# are opened so the single-case is added to them when it is closed
+stage_handler('process') do |stage, process, context|
+  id = stage['id']
+  name = stage['name']
+  single_case = context[:single_case]
+  case_settings = context[:case_settings]
+  rescan = [STATUS_FAILED, STATUS_TIMEOUT].include?(stage['status'])
+
+  if !rescan && case_settings['case']['elasticSearch'].nil?
+    # Create or open the compound-case
+    log_info('', 0, 'Opening compound-case: ' + case_settings['compoundCase']['name'])
+    context[:compound_case] = open_case(case_factory_settings(case_settings['compoundCase'], true))
+
+    # Create or open the review-compound
+    log_info('', 0, 'Opening review-compound: ' + case_settings['reviewCompound']['name'])
+    context[:review_compound] = open_case(case_factory_settings(case_settings['reviewCompound'], true))
+  end
+
+  # Check if the profile exists in the profile-store
+  unless $utilities.get_processing_profile_store.contains_profile(process['profile'])
+    # Import the profile
+    log_debug(name, id, 'Did not find the requested processing-profile in the profile-store')
+    log_info(name, id, 'Importing new processing-profile from ' + process['profilePath'])
+    $utilities.get_processing_profile_store.import_profile(process['profilePath'], process['profile'])
+    log_debug(name, id, 'Processing-profile has been imported')
+  end
+
+  # Create a processor to process the evidence for the case
+  log_info(name, id, 'Creating processor for case-processing')
+  case_processor = single_case.create_processor
+  case_processor.set_processing_profile(process['profile'])
+  if rescan
+    case_processor.rescan_evidence_repositories(true)
+  else
+    process['evidenceStore'].each do |evidence|
+      # Create container for the evidence
+      log_info(name, id, 'Adding evidence-container to case: ' + evidence['name'])
+      container = case_processor.new_evidence_container(evidence['name'])
+      container.add_file(evidence['directory'])
+      container.set_description(evidence['description'])
+      container.set_encoding(evidence['encoding'])
+      container.set_time_zone(evidence['timeZone'])
+      container.set_initial_custodian(evidence['custodian'])
+      container.set_locale(evidence['locale'])
+      container.save
+    end
+  end
+
+  # Handle the items being processed
+  semaphore = Mutex.new
+  processed_count = 0
+  case_processor.when_item_processed do |info|
+    semaphore.synchronize {
+      processed_count += 1
+      log_processed_item(
+        name,
+        id,
+        'Processed item',
+        processed_count,
+        info.mime_type,
+        info.guid_path,
+        '',
+        info.is_corrupted,
+        info.is_deleted,
+        info.is_encrypted,
+      )
+    }
+  end
+
+  if process['hashEvidence']
+    # Hash the evidence before processing (chain of custody)
+    require 'digest'
+    process['evidenceStore'].each do |evidence|
+      log_info(name, id, 'Hashing evidence: ' + evidence['name'])
+      evidence_path = evidence['directory'].tr('\\', '/')
+      evidence_files = File.file?(evidence_path) ? [evidence_path] : Dir.glob(File.join(evidence_path, '**', '*'), File::FNM_DOTMATCH)
+      custody = []
+      hashed_count = 0
+      evidence_files.each do |path|
+        next unless File.file?(path)
+        custody << {
+          path: path,
+          size: File.size(path),
+          algorithm: 'sha256',
+          hash: Digest::SHA256.file(path).hexdigest,
+          modifiedAt: File.mtime(path).to_i,
+          hashedAt: Time.now.to_i,
+        }
+        hashed_count += 1
+        if custody.length >= 500
+          custody_records(id, evidence['id'], custody)
+          custody = []
+        end
+      end
+      custody_records(id, evidence['id'], custody) unless custody.empty?
+      log_info(name, id, "Hashed #{hashed_count} files for evidence: #{evidence['name']}")
+    end
+  end
+
+  log_info(name, id, 'Start case-processing')
+  case_processor.process
+  log_info(name, id, 'Finished case-processing')
+end
+`
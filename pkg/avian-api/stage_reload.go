@@ -0,0 +1,62 @@
+package api
+
+func init() { RegisterStageKind(reloadKind{}) }
+
+// reloadKind reloads the items from a search from their source-data
+type reloadKind struct{}
+
+func (reloadKind) Name(s *Stage) string             { return "Reload" }
+func (reloadKind) Key() string                      { return "reload" }
+func (reloadKind) Is(s *Stage) bool                 { return s.Reload != nil }
+func (reloadKind) Status(s *Stage) int64            { return s.Reload.Status }
+func (reloadKind) SetStatus(s *Stage, status int64) { s.Reload.Status = status }
+func (reloadKind) Validate(s *Stage) error          { return nil }
+func (reloadKind) Paths(s *Stage) []string          { return []string{s.Reload.ProfilePath} }
+func (reloadKind) Preload() string                  { return "Reload" }
+func (reloadKind) Models() []interface{}            { return []interface{}{&Reload{}} }
+func (reloadKind) Script() string                   { return reloadScript }
+
+const reloadScript = `# Reloads the items from a search from their source-data
+stage_handler('reload') do |stage, reload, context|
+  id = stage['id']
+  name = stage['name']
+  single_case = context[:single_case]
+
+  # Check if the profile exists in the profile-store
+  unless $utilities.get_processing_profile_store.contains_profile(reload['profile'])
+    # Import the profile
+    log_debug(name, id, 'Did not find the requested processing-profile for reload in the profile-store')
+    log_info(name, id, 'Importing new processing-profile from ' + reload['profilePath'])
+    $utilities.get_processing_profile_store.import_profile(reload['profilePath'], reload['profile'])
+    log_debug(name, id, 'Processing-profile has been imported')
+  end
+
+  items = single_case.search(reload['search'])
+  log_debug(name, id, "Found #{items.length} items from search: #{reload['search']}")
+
+  log_info(name, id, 'Creating reload_processor')
+  reload_processor = single_case.create_processor
+  log_debug(name, id, 'Created reload_processor')
+  reload_processor.set_processing_profile(reload['profile'])
+  reload_processor.reload_items_from_source_data(items)
+
+  # Handle item-information from reload-processor
+  semaphore = Mutex.new
+  reload_count = 0
+  reload_processor.when_item_processed do |info|
+    semaphore.synchronize {
+      reload_count += 1
+      log_item(name, id, 'Reloaded item', reload_count, info.mime_type, info.guid_path, '')
+    }
+  end
+
+  # Start the processing
+  if items.length > 0
+    log_info(name, id, 'Starts the reload-processing')
+    reload_processor.process
+    log_debug(name, id, 'Finished the reload-processing')
+  else
+    log_debug(name, id, 'No items to process for reload')
+  end
+end
+`
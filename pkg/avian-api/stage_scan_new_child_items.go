@@ -0,0 +1,58 @@
+package api
+
+func init() { RegisterStageKind(scanNewChildItemsKind{}) }
+
+// scanNewChildItemsKind scans the items from a search for new child-items
+type scanNewChildItemsKind struct{}
+
+func (scanNewChildItemsKind) Name(s *Stage) string             { return "ScanNewChildItems" }
+func (scanNewChildItemsKind) Key() string                      { return "scanNewChildItems" }
+func (scanNewChildItemsKind) Is(s *Stage) bool                 { return s.ScanNewChildItems != nil }
+func (scanNewChildItemsKind) Status(s *Stage) int64            { return s.ScanNewChildItems.Status }
+func (scanNewChildItemsKind) SetStatus(s *Stage, status int64) { s.ScanNewChildItems.Status = status }
+func (scanNewChildItemsKind) Validate(s *Stage) error          { return nil }
+func (scanNewChildItemsKind) Paths(s *Stage) []string          { return nil }
+func (scanNewChildItemsKind) Preload() string                  { return "ScanNewChildItems" }
+func (scanNewChildItemsKind) Models() []interface{}            { return []interface{}{&ScanNewChildItems{}} }
+func (scanNewChildItemsKind) Script() string                   { return scanNewChildItemsScript }
+
+const scanNewChildItemsScript = `# Scans the items from a search for new child-items
+stage_handler('scanNewChildItems') do |stage, scan_new_child_items, context|
+  id = stage['id']
+  name = stage['name']
+  single_case = context[:single_case]
+
+  scan_processor = single_case.create_processor
+  scan_processor.set_processing_profile(scan_new_child_items['profile'])
+  semaphore = Mutex.new
+  processed_count = 0
+  scan_processor.when_item_processed do |info|
+    semaphore.synchronize {
+      processed_count += 1
+      log_processed_item(
+        name,
+        id,
+        'Processed item',
+        processed_count,
+        info.mime_type,
+        info.guid_path,
+        '',
+        info.is_corrupted,
+        info.is_deleted,
+        info.is_encrypted,
+      )
+    }
+  end
+
+  log_info(name, id, 'Searching for items to scan with query: ' + scan_new_child_items['search'])
+  scan_items = single_case.search(scan_new_child_items['search'])
+  log_debug(name, id, "Found #{scan_items.length} items to scan.")
+
+  log_info(name, id, 'Set scan-items')
+  scan_processor.scan_for_new_child_items(scan_items)
+
+  log_info(name, id, 'Start scanning items')
+  scan_processor.process
+  log_debug(name, id, 'Finished scanning items')
+end
+`
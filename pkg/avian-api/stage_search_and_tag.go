@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+)
+
+func init() { RegisterStageKind(searchAndTagKind{}) }
+
+// searchAndTagKind tags the items from a search-query or bulk-search files
+type searchAndTagKind struct{}
+
+func (searchAndTagKind) Name(s *Stage) string             { return "SearchAndTag" }
+func (searchAndTagKind) Key() string                      { return "searchAndTag" }
+func (searchAndTagKind) Is(s *Stage) bool                 { return s.SearchAndTag != nil }
+func (searchAndTagKind) Status(s *Stage) int64            { return s.SearchAndTag.Status }
+func (searchAndTagKind) SetStatus(s *Stage, status int64) { s.SearchAndTag.Status = status }
+func (searchAndTagKind) Preload() string                  { return "SearchAndTag.Files" }
+func (searchAndTagKind) Models() []interface{}            { return []interface{}{&SearchAndTag{}, &File{}} }
+func (searchAndTagKind) Script() string                   { return searchAndTagScript }
+
+func (searchAndTagKind) Validate(s *Stage) error {
+	if emptyString(s.SearchAndTag.Search) {
+		if len(s.SearchAndTag.Files) == 0 {
+			return errors.New("must specify a search-query or files for search and tag-stage")
+		}
+		for i, file := range s.SearchAndTag.Files {
+			if emptyString(file.Path) {
+				return fmt.Errorf("must specify path to file for search and tag #%d", i)
+			}
+		}
+	} else {
+		if emptyString(s.SearchAndTag.Tag) {
+			return errors.New("must specify a tag for search and tag")
+		}
+	}
+	return nil
+}
+
+func (searchAndTagKind) Paths(s *Stage) []string {
+	var paths []string
+	for _, file := range s.SearchAndTag.Files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+const searchAndTagScript = `# Tags the items from a search-query or from bulk-search files
+stage_handler('searchAndTag') do |stage, search_and_tag, context|
+  id = stage['id']
+  name = stage['name']
+  single_case = context[:single_case]
+  files = search_and_tag['files'] || []
+
+  if files.empty?
+    # Search And Tag with search-query, the items are
+    # sorted so the stage can be resumed from a checkpoint
+    items = single_case.search(search_and_tag['search']).sort_by { |item| item.guid }
+    log_debug(name, id, "Found #{items.length} from search #{search_and_tag['search']} - starts tagging")
+    item_count = resume_position(stage, items.length)
+    for item in items.drop(item_count)
+      item.add_tag(search_and_tag['tag'])
+      item_count += 1
+      log_item(name, id, 'Tagged item', item_count, item.type.name, item.guid, '')
+      checkpoint(id, {position: item_count, items: items.length}) if item_count % CHECKPOINT_INTERVAL == 0
+    end
+  else
+    # Search And Tag with files - with a bulk-searcher for each
+    # file, so the stage can be resumed from the last searched file
+    file_count = resume_position(stage, files.length)
+    files.drop(file_count).each do |file|
+      log_info(name, id, 'Creating bulk-searcher for file: ' + file['path'])
+      bulk_searcher = single_case.create_bulk_searcher
+      bulk_searcher.import_file(file['path'])
+      row_num = 0
+      # Perform search and handle info
+      log_info(name, id, 'Starting search')
+      bulk_searcher.run do |info|
+        row_num += 1
+        log_item(name, id, "Searching through row - current size: #{info.current_size} - total size: #{info.total_size}", row_num, '', '', '')
+      end
+      file_count += 1
+      checkpoint(id, {position: file_count, items: files.length})
+    end
+  end
+end
+`
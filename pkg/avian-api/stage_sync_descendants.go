@@ -0,0 +1,50 @@
+package api
+
+func init() { RegisterStageKind(syncDescendantsKind{}) }
+
+// syncDescendantsKind syncs the descendants for the items from a search
+type syncDescendantsKind struct{}
+
+func (syncDescendantsKind) Name(s *Stage) string             { return "SyncDescendants" }
+func (syncDescendantsKind) Key() string                      { return "syncDescendants" }
+func (syncDescendantsKind) Is(s *Stage) bool                 { return s.SyncDescendants != nil }
+func (syncDescendantsKind) Status(s *Stage) int64            { return s.SyncDescendants.Status }
+func (syncDescendantsKind) SetStatus(s *Stage, status int64) { s.SyncDescendants.Status = status }
+func (syncDescendantsKind) Validate(s *Stage) error          { return nil }
+func (syncDescendantsKind) Paths(s *Stage) []string          { return nil }
+func (syncDescendantsKind) Preload() string                  { return "SyncDescendants" }
+func (syncDescendantsKind) Models() []interface{}            { return []interface{}{&SyncDescendants{}} }
+func (syncDescendantsKind) Script() string                   { return syncDescendantsScript }
+
+const syncDescendantsScript = `# Syncs the descendants for the items from a search
+stage_handler('syncDescendants') do |stage, sync_descendants, context|
+  id = stage['id']
+  name = stage['name']
+  single_case = context[:single_case]
+
+  sync_processor = single_case.create_processor
+  semaphore = Mutex.new
+  processed_count = 0
+  sync_processor.when_item_processed do |info|
+    semaphore.synchronize {
+      processed_count += 1
+      log_processed_item(
+        name,
+        id,
+        'Processed item',
+        processed_count,
+        info.mime_type,
+        info.guid_path,
+        '',
+        info.is_corrupted,
+        info.is_deleted,
+        info.is_encrypted,
+      )
+    }
+  end
+
+  sync_items = single_case.search(sync_descendants['search'])
+  sync_processor.sync(sync_items, nil, nil)
+  sync_processor.process
+end
+`
@@ -0,0 +1,42 @@
+package api_test
+
+import (
+	"reflect"
+	"testing"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/matryer/is"
+)
+
+// TestStageKinds makes sure that every stage-type
+// in the Stage has a single registered stage-kind
+func TestStageKinds(t *testing.T) {
+	is := is.New(t)
+	var stage api.Stage
+	is.True(stage.Nil()) // stage without type has no kind
+
+	typ := reflect.TypeOf(stage)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type.Kind() != reflect.Ptr || field.Type.Elem().Kind() != reflect.Struct || field.Name == "StartedAt" {
+			continue
+		}
+
+		var s api.Stage
+		reflect.ValueOf(&s).Elem().Field(i).Set(reflect.New(field.Type.Elem()))
+		var kinds []api.StageKind
+		for _, kind := range api.StageKinds() {
+			if kind.Is(&s) {
+				kinds = append(kinds, kind)
+			}
+		}
+		if len(kinds) != 1 {
+			t.Fatalf("stage-type %s has %d registered kinds", field.Name, len(kinds))
+		}
+
+		kind := s.Kind()
+		is.True(kind.Name(&s) != "")
+		kind.SetStatus(&s, 3)
+		is.Equal(kind.Status(&s), int64(3))
+	}
+}
@@ -2,10 +2,11 @@ package api
 
 import (
 	"fmt"
+	"net/mail"
 	"regexp"
 	"strings"
+	"time"
 
-	"github.com/avian-digital-forensics/auto-processing/pkg/inapp"
 	"github.com/pkg/errors"
 )
 
@@ -47,6 +48,25 @@ func (runner *Runner) Validate() error {
 		return errors.New("must specify amount of workers")
 	}
 
+	if !emptyString(runner.MaxRuntime) {
+		if _, err := time.ParseDuration(runner.MaxRuntime); err != nil {
+			return fmt.Errorf("Invalid value for maxRuntime: %s. Must be a duration (e.g 12h or 90m).", runner.MaxRuntime)
+		}
+	}
+
+	// Validate the recipients for the emails, names without a
+	// domain gets the domain that is configured for the service
+	if !emptyString(runner.Notify) {
+		for _, recipient := range strings.Split(runner.Notify, ",") {
+			if !strings.Contains(recipient, "@") {
+				continue
+			}
+			if _, err := mail.ParseAddress(recipient); err != nil {
+				return fmt.Errorf("Invalid recipient in notify: %s. Must be an email-address or a name.", recipient)
+			}
+		}
+	}
+
 	if err := runner.CaseSettings.Validate(); err != nil {
 		return err
 	}
@@ -60,6 +80,21 @@ func (runner *Runner) Validate() error {
 		if stage.Nil() {
 			return fmt.Errorf("Stage: %d - unable to parse what stage it is - check syntax", i+1)
 		}
+		if !emptyString(stage.Timeout) {
+			if _, err := time.ParseDuration(stage.Timeout); err != nil {
+				return fmt.Errorf("Stage: %d - invalid value for timeout: %s. Must be a duration (e.g 2h or 90m).", i+1, stage.Timeout)
+			}
+		}
+		// Ensure that the archive-stage is the last stage,
+		// since the cases are closed when they are archived.
+		if stage.Archive != nil && i != len(runner.Stages)-1 {
+			return fmt.Errorf("Stage: %d - archive must be the last stage for the runner", i+1)
+		}
+		// The compound- and review-cases lists the single-case as a
+		// child, so it cannot be removed while they reference it.
+		if stage.Archive != nil && stage.Archive.RemoveSource && runner.CaseSettings.HasCompound() {
+			return fmt.Errorf("Stage: %d - archive cannot remove the source while a compound- or review-case is configured", i+1)
+		}
 		// Ensure that the runner only has a single processing stage.
 		if stage.Process != nil {
 			if hasProcessingStage {
@@ -100,115 +135,13 @@ func (runner *Runner) Validate() error {
 	return nil
 }
 
-func (s *Stage) Nil() bool {
-	return (s.Process == nil &&
-		s.SearchAndTag == nil &&
-		s.Exclude == nil &&
-		s.Reload == nil &&
-		s.Populate == nil &&
-		s.Ocr == nil &&
-		s.InApp == nil &&
-		s.SyncDescendants == nil &&
-		s.ScanNewChildItems == nil)
-}
+// Nil returns true if the stage has no registered stage-type
+func (s *Stage) Nil() bool { return s.Kind() == nil }
 
-// Validate validates a Stage
+// Validate validates a Stage with its stage-type
 func (s *Stage) Validate() error {
-	if s.Process != nil {
-		if emptyString(s.Process.Profile) {
-			return errors.New("must specify processing-profile for process-stage")
-		}
-
-		if len(s.Process.EvidenceStore) == 0 {
-			return errors.New("must specify evidence for the process-stage")
-		}
-
-		for i, evidence := range s.Process.EvidenceStore {
-			if emptyString(evidence.Name) {
-				return fmt.Errorf("must specify name for evidence: #%d", i)
-			}
-			if emptyString(evidence.Directory) {
-				return fmt.Errorf("must specify directory for evidence: #%d", i)
-			}
-			if !emptyString(evidence.Locale) {
-				// Validate locale somewhat according to https://tools.ietf.org/html/rfc5646#section-2.1.1.
-				matchRegex, err := regexp.MatchString("^(?:[a-zA-Z0-9]{1,8}-)[a-zA-Z0-9]{1,8}$", evidence.Locale)
-				if err != nil {
-					panic("Invalid regex in code.")
-				}
-				if !matchRegex {
-					return fmt.Errorf("Invalid value for locale: %s. Must be alphanumeric with segments of maximum 8 length seperated by hyphens.", evidence.Locale)
-				}
-			}
-		}
-	}
-
-	if s.SearchAndTag != nil {
-		if emptyString(s.SearchAndTag.Search) {
-			if len(s.SearchAndTag.Files) == 0 {
-				return errors.New("must specify a search-query or files for search and tag-stage")
-			}
-			for i, file := range s.SearchAndTag.Files {
-				if emptyString(file.Path) {
-					return fmt.Errorf("must specify path to file for search and tag #%d", i)
-				}
-			}
-		} else {
-			if emptyString(s.SearchAndTag.Tag) {
-				return errors.New("must specify a tag for search and tag")
-			}
-		}
-	}
-
-	if s.Populate != nil {
-		if emptyString(s.Populate.Search) {
-			return errors.New("must specify a search-query for populate-stage")
-		}
-
-		if len(s.Populate.Types) == 0 {
-			return errors.New("must specify types for populate-stage")
-		}
-
-		for i, t := range s.Populate.Types {
-			if emptyString(t.Type) {
-				return fmt.Errorf("must specify type for populate-stage type #%d", i)
-			}
-		}
-	}
-
-	if s.Ocr != nil {
-		if emptyString(s.Ocr.Profile) {
-			return errors.New("must specify a processing-profile for OCR-stage")
-		}
-		if emptyString(s.Ocr.Search) {
-			return errors.New("must specify a search-query for OCR-stage")
-		}
-		if s.Ocr.BatchSize == 0 {
-			return errors.New("must specify a batchSize for OCR-stage")
-		}
-	}
-
-	if s.Exclude != nil {
-		if emptyString(s.Exclude.Search) {
-			return errors.New("must specify a search-query for exclude-stage")
-		}
-		if emptyString(s.Exclude.Reason) {
-			return errors.New("must specify a reason for exclude-stage")
-		}
-	}
-
-	if s.InApp != nil {
-		if emptyString(s.InApp.Name) {
-			return errors.New("must specify a name for in-app script")
-		}
-		if emptyString(s.InApp.Config) {
-			return errors.New("must specify a config for in-app script")
-		}
-
-		var settings inapp.Settings
-		if err := inapp.Config(s.InApp.Config, &settings); err != nil {
-			return fmt.Errorf("failed to decode config for in-app script: %s - %v", s.InApp.Name, err)
-		}
+	if kind := s.Kind(); kind != nil {
+		return kind.Validate(s)
 	}
 	return nil
 }
@@ -224,6 +157,13 @@ func (s *CaseSettings) Validate() error {
 	return nil
 }
 
+// HasCompound returns true if a compound- or
+// review-case is configured for the runner
+func (s *CaseSettings) HasCompound() bool {
+	return (s.CompoundCase != nil && !emptyString(s.CompoundCase.Directory)) ||
+		(s.ReviewCompound != nil && !emptyString(s.ReviewCompound.Directory))
+}
+
 // Paths returns all the specified-paths for the runner
 func (r *Runner) Paths() []string {
 	var paths []string
@@ -232,29 +172,8 @@ func (r *Runner) Paths() []string {
 	}
 
 	for _, stage := range r.Stages {
-		if stage.Process != nil {
-			paths = append(paths, stage.Process.ProfilePath)
-			for _, evidence := range stage.Process.EvidenceStore {
-				paths = append(paths, evidence.Directory)
-			}
-		}
-
-		if stage.SearchAndTag != nil {
-			for _, file := range stage.SearchAndTag.Files {
-				paths = append(paths, file.Path)
-			}
-		}
-
-		if stage.Ocr != nil {
-			paths = append(paths, stage.Ocr.ProfilePath)
-		}
-
-		if stage.Reload != nil {
-			paths = append(paths, stage.Reload.ProfilePath)
-		}
-
-		if stage.InApp != nil {
-			paths = append(paths, stage.InApp.Config)
+		if kind := stage.Kind(); kind != nil {
+			paths = append(paths, kind.Paths(stage)...)
 		}
 	}
 
@@ -0,0 +1,26 @@
+package avian
+
+import "net/http"
+
+// KeyEnv is the env-variable with the api-key for the CLI
+const KeyEnv = "AVIAN_API_KEY"
+
+// NewWithKey makes a new Client that authenticates
+// with the api-key in the Authorization-header
+func NewWithKey(remoteHost, key string) *Client {
+	client := New(remoteHost, "")
+	client.HTTPClient.Transport = keyTransport{key: key, base: http.DefaultTransport}
+	return client
+}
+
+// keyTransport sets the api-key for the requests
+type keyTransport struct {
+	key  string
+	base http.RoundTripper
+}
+
+func (t keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.key)
+	return t.base.RoundTrip(req)
+}
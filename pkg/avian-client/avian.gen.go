@@ -48,33 +48,35 @@ func New(remoteHost, secret string) *Client {
 	}
 }
 
-// NmsService handles the Nuix Management Servers.
-type NmsService struct {
+// AuditService returns the audit-trail for the configuration- and
+// control-actions
+type AuditService struct {
 	client *Client
 }
 
-// NewNmsService makes a new client for accessing NmsService services.
-func NewNmsService(client *Client) *NmsService {
-	return &NmsService{
+// NewAuditService makes a new client for accessing AuditService services.
+func NewAuditService(client *Client) *AuditService {
+	return &AuditService{
 		client: client,
 	}
 }
 
-func (s *NmsService) Apply(ctx context.Context, r NmsApplyRequests) (*NmsApplyResponse, error) {
+// List returns the entries in the audit-trail (the latest first)
+func (s *AuditService) List(ctx context.Context, r AuditListRequest) (*AuditListResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.Apply: marshal NmsApplyRequests")
+		return nil, errors.Wrap(err, "AuditService.List: marshal AuditListRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.Apply: generate signature NmsApplyRequests")
+		return nil, errors.Wrap(err, "AuditService.List: generate signature AuditListRequest")
 	}
-	url := s.client.RemoteHost + "NmsService.Apply"
+	url := s.client.RemoteHost + "AuditService.List"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.Apply: NewRequest")
+		return nil, errors.Wrap(err, "AuditService.List: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -82,53 +84,65 @@ func (s *NmsService) Apply(ctx context.Context, r NmsApplyRequests) (*NmsApplyRe
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.Apply")
+		return nil, errors.Wrap(err, "AuditService.List")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		NmsApplyResponse
+		AuditListResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "NmsService.Apply: new gzip reader")
+			return nil, errors.Wrap(err, "AuditService.List: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.Apply: read response body")
+		return nil, errors.Wrap(err, "AuditService.List: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("NmsService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("AuditService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.NmsApplyResponse, nil
+	return &response.AuditListResponse, nil
 }
 
-func (s *NmsService) List(ctx context.Context, r NmsListRequest) (*NmsListResponse, error) {
+// NmsService handles the Nuix Management Servers.
+type NmsService struct {
+	client *Client
+}
+
+// NewNmsService makes a new client for accessing NmsService services.
+func NewNmsService(client *Client) *NmsService {
+	return &NmsService{
+		client: client,
+	}
+}
+
+func (s *NmsService) Apply(ctx context.Context, r NmsApplyRequests) (*NmsApplyResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.List: marshal NmsListRequest")
+		return nil, errors.Wrap(err, "NmsService.Apply: marshal NmsApplyRequests")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.List: generate signature NmsListRequest")
+		return nil, errors.Wrap(err, "NmsService.Apply: generate signature NmsApplyRequests")
 	}
-	url := s.client.RemoteHost + "NmsService.List"
+	url := s.client.RemoteHost + "NmsService.Apply"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.List: NewRequest")
+		return nil, errors.Wrap(err, "NmsService.Apply: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -136,53 +150,54 @@ func (s *NmsService) List(ctx context.Context, r NmsListRequest) (*NmsListRespon
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.List")
+		return nil, errors.Wrap(err, "NmsService.Apply")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		NmsListResponse
+		NmsApplyResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "NmsService.List: new gzip reader")
+			return nil, errors.Wrap(err, "NmsService.Apply: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.List: read response body")
+		return nil, errors.Wrap(err, "NmsService.Apply: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("NmsService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("NmsService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.NmsListResponse, nil
+	return &response.NmsApplyResponse, nil
 }
 
-func (s *NmsService) ListLicences(ctx context.Context, r NmsListLicencesRequest) (*NmsListLicencesResponse, error) {
+// Delete deletes the NMS (and its licences), refused while unfinished runners uses the NMS (unless forced)
+func (s *NmsService) Delete(ctx context.Context, r NmsDeleteRequest) (*NmsDeleteResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.ListLicences: marshal NmsListLicencesRequest")
+		return nil, errors.Wrap(err, "NmsService.Delete: marshal NmsDeleteRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.ListLicences: generate signature NmsListLicencesRequest")
+		return nil, errors.Wrap(err, "NmsService.Delete: generate signature NmsDeleteRequest")
 	}
-	url := s.client.RemoteHost + "NmsService.ListLicences"
+	url := s.client.RemoteHost + "NmsService.Delete"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.ListLicences: NewRequest")
+		return nil, errors.Wrap(err, "NmsService.Delete: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -190,66 +205,54 @@ func (s *NmsService) ListLicences(ctx context.Context, r NmsListLicencesRequest)
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.ListLicences")
+		return nil, errors.Wrap(err, "NmsService.Delete")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		NmsListLicencesResponse
+		NmsDeleteResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "NmsService.ListLicences: new gzip reader")
+			return nil, errors.Wrap(err, "NmsService.Delete: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.ListLicences: read response body")
+		return nil, errors.Wrap(err, "NmsService.Delete: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("NmsService.ListLicences: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("NmsService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.NmsListLicencesResponse, nil
-}
-
-// RunnerService handles all the runners.
-type RunnerService struct {
-	client *Client
-}
-
-// NewRunnerService makes a new client for accessing RunnerService services.
-func NewRunnerService(client *Client) *RunnerService {
-	return &RunnerService{
-		client: client,
-	}
+	return &response.NmsDeleteResponse, nil
 }
 
-// Apply applies the configuration to the backend.
-func (s *RunnerService) Apply(ctx context.Context, r RunnerApplyRequest) (*RunnerApplyResponse, error) {
+// Get returns the NMS with its licences and the runners that uses it
+func (s *NmsService) Get(ctx context.Context, r NmsGetRequest) (*NmsGetResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Apply: marshal RunnerApplyRequest")
+		return nil, errors.Wrap(err, "NmsService.Get: marshal NmsGetRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Apply: generate signature RunnerApplyRequest")
+		return nil, errors.Wrap(err, "NmsService.Get: generate signature NmsGetRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Apply"
+	url := s.client.RemoteHost + "NmsService.Get"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Apply: NewRequest")
+		return nil, errors.Wrap(err, "NmsService.Get: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -257,54 +260,53 @@ func (s *RunnerService) Apply(ctx context.Context, r RunnerApplyRequest) (*Runne
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Apply")
+		return nil, errors.Wrap(err, "NmsService.Get")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerApplyResponse
+		NmsGetResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Apply: new gzip reader")
+			return nil, errors.Wrap(err, "NmsService.Get: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Apply: read response body")
+		return nil, errors.Wrap(err, "NmsService.Get: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("NmsService.Get: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerApplyResponse, nil
+	return &response.NmsGetResponse, nil
 }
 
-// Delete deletes the requested Runner
-func (s *RunnerService) Delete(ctx context.Context, r RunnerDeleteRequest) (*RunnerDeleteResponse, error) {
+func (s *NmsService) List(ctx context.Context, r NmsListRequest) (*NmsListResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Delete: marshal RunnerDeleteRequest")
+		return nil, errors.Wrap(err, "NmsService.List: marshal NmsListRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Delete: generate signature RunnerDeleteRequest")
+		return nil, errors.Wrap(err, "NmsService.List: generate signature NmsListRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Delete"
+	url := s.client.RemoteHost + "NmsService.List"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Delete: NewRequest")
+		return nil, errors.Wrap(err, "NmsService.List: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -312,54 +314,53 @@ func (s *RunnerService) Delete(ctx context.Context, r RunnerDeleteRequest) (*Run
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Delete")
+		return nil, errors.Wrap(err, "NmsService.List")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerDeleteResponse
+		NmsListResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Delete: new gzip reader")
+			return nil, errors.Wrap(err, "NmsService.List: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Delete: read response body")
+		return nil, errors.Wrap(err, "NmsService.List: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("NmsService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerDeleteResponse, nil
+	return &response.NmsListResponse, nil
 }
 
-// Failed sets a runner to failed
-func (s *RunnerService) Failed(ctx context.Context, r RunnerFailedRequest) (*RunnerFailedResponse, error) {
+func (s *NmsService) ListLicences(ctx context.Context, r NmsListLicencesRequest) (*NmsListLicencesResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Failed: marshal RunnerFailedRequest")
+		return nil, errors.Wrap(err, "NmsService.ListLicences: marshal NmsListLicencesRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Failed: generate signature RunnerFailedRequest")
+		return nil, errors.Wrap(err, "NmsService.ListLicences: generate signature NmsListLicencesRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Failed"
+	url := s.client.RemoteHost + "NmsService.ListLicences"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Failed: NewRequest")
+		return nil, errors.Wrap(err, "NmsService.ListLicences: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -367,54 +368,66 @@ func (s *RunnerService) Failed(ctx context.Context, r RunnerFailedRequest) (*Run
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Failed")
+		return nil, errors.Wrap(err, "NmsService.ListLicences")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerFailedResponse
+		NmsListLicencesResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Failed: new gzip reader")
+			return nil, errors.Wrap(err, "NmsService.ListLicences: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Failed: read response body")
+		return nil, errors.Wrap(err, "NmsService.ListLicences: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Failed: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("NmsService.ListLicences: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerFailedResponse, nil
+	return &response.NmsListLicencesResponse, nil
 }
 
-// FailedStage sets a stage to Failed
-func (s *RunnerService) FailedStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
+// RunnerService handles all the runners.
+type RunnerService struct {
+	client *Client
+}
+
+// NewRunnerService makes a new client for accessing RunnerService services.
+func NewRunnerService(client *Client) *RunnerService {
+	return &RunnerService{
+		client: client,
+	}
+}
+
+// Apply applies the configuration to the backend.
+func (s *RunnerService) Apply(ctx context.Context, r RunnerApplyRequest) (*RunnerApplyResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FailedStage: marshal StageRequest")
+		return nil, errors.Wrap(err, "RunnerService.Apply: marshal RunnerApplyRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FailedStage: generate signature StageRequest")
+		return nil, errors.Wrap(err, "RunnerService.Apply: generate signature RunnerApplyRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.FailedStage"
+	url := s.client.RemoteHost + "RunnerService.Apply"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FailedStage: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.Apply: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -422,54 +435,54 @@ func (s *RunnerService) FailedStage(ctx context.Context, r StageRequest) (*Stage
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FailedStage")
+		return nil, errors.Wrap(err, "RunnerService.Apply")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		StageResponse
+		RunnerApplyResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.FailedStage: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.Apply: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FailedStage: read response body")
+		return nil, errors.Wrap(err, "RunnerService.Apply: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.FailedStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.StageResponse, nil
+	return &response.RunnerApplyResponse, nil
 }
 
-// Finish sets a runner to finished
-func (s *RunnerService) Finish(ctx context.Context, r RunnerFinishRequest) (*RunnerFinishResponse, error) {
+// ArchiveResult reports the result of an archived case
+func (s *RunnerService) ArchiveResult(ctx context.Context, r ArchiveResultRequest) (*ArchiveResultResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Finish: marshal RunnerFinishRequest")
+		return nil, errors.Wrap(err, "RunnerService.ArchiveResult: marshal ArchiveResultRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Finish: generate signature RunnerFinishRequest")
+		return nil, errors.Wrap(err, "RunnerService.ArchiveResult: generate signature ArchiveResultRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Finish"
+	url := s.client.RemoteHost + "RunnerService.ArchiveResult"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Finish: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.ArchiveResult: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -477,54 +490,54 @@ func (s *RunnerService) Finish(ctx context.Context, r RunnerFinishRequest) (*Run
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Finish")
+		return nil, errors.Wrap(err, "RunnerService.ArchiveResult")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerFinishResponse
+		ArchiveResultResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Finish: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.ArchiveResult: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Finish: read response body")
+		return nil, errors.Wrap(err, "RunnerService.ArchiveResult: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Finish: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.ArchiveResult: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerFinishResponse, nil
+	return &response.ArchiveResultResponse, nil
 }
 
-// FinishStage sets a stage to Finished
-func (s *RunnerService) FinishStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
+// Cancel cancels a waiting or running Runner
+func (s *RunnerService) Cancel(ctx context.Context, r RunnerCancelRequest) (*RunnerCancelResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FinishStage: marshal StageRequest")
+		return nil, errors.Wrap(err, "RunnerService.Cancel: marshal RunnerCancelRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FinishStage: generate signature StageRequest")
+		return nil, errors.Wrap(err, "RunnerService.Cancel: generate signature RunnerCancelRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.FinishStage"
+	url := s.client.RemoteHost + "RunnerService.Cancel"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FinishStage: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.Cancel: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -532,54 +545,54 @@ func (s *RunnerService) FinishStage(ctx context.Context, r StageRequest) (*Stage
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FinishStage")
+		return nil, errors.Wrap(err, "RunnerService.Cancel")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		StageResponse
+		RunnerCancelResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.FinishStage: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.Cancel: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FinishStage: read response body")
+		return nil, errors.Wrap(err, "RunnerService.Cancel: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.FinishStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.Cancel: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.StageResponse, nil
+	return &response.RunnerCancelResponse, nil
 }
 
-// Get returns the requested Runner
-func (s *RunnerService) Get(ctx context.Context, r RunnerGetRequest) (*RunnerGetResponse, error) {
+// Checkpoint stores the checkpoint for a stage (used by ruby script)
+func (s *RunnerService) Checkpoint(ctx context.Context, r CheckpointRequest) (*CheckpointResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Get: marshal RunnerGetRequest")
+		return nil, errors.Wrap(err, "RunnerService.Checkpoint: marshal CheckpointRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Get: generate signature RunnerGetRequest")
+		return nil, errors.Wrap(err, "RunnerService.Checkpoint: generate signature CheckpointRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Get"
+	url := s.client.RemoteHost + "RunnerService.Checkpoint"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Get: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.Checkpoint: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -587,54 +600,54 @@ func (s *RunnerService) Get(ctx context.Context, r RunnerGetRequest) (*RunnerGet
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Get")
+		return nil, errors.Wrap(err, "RunnerService.Checkpoint")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerGetResponse
+		CheckpointResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Get: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.Checkpoint: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Get: read response body")
+		return nil, errors.Wrap(err, "RunnerService.Checkpoint: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Get: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.Checkpoint: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerGetResponse, nil
+	return &response.CheckpointResponse, nil
 }
 
-// Heartbeat sends a heartbeat for the api
-func (s *RunnerService) Heartbeat(ctx context.Context, r RunnerStartRequest) (*RunnerStartResponse, error) {
+// Custody returns the signed chain of custody for the runner
+func (s *RunnerService) Custody(ctx context.Context, r CustodyRequest) (*CustodyResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Heartbeat: marshal RunnerStartRequest")
+		return nil, errors.Wrap(err, "RunnerService.Custody: marshal CustodyRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Heartbeat: generate signature RunnerStartRequest")
+		return nil, errors.Wrap(err, "RunnerService.Custody: generate signature CustodyRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Heartbeat"
+	url := s.client.RemoteHost + "RunnerService.Custody"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Heartbeat: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.Custody: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -642,54 +655,54 @@ func (s *RunnerService) Heartbeat(ctx context.Context, r RunnerStartRequest) (*R
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Heartbeat")
+		return nil, errors.Wrap(err, "RunnerService.Custody")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerStartResponse
+		CustodyResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Heartbeat: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.Custody: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Heartbeat: read response body")
+		return nil, errors.Wrap(err, "RunnerService.Custody: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Heartbeat: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.Custody: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerStartResponse, nil
+	return &response.CustodyResponse, nil
 }
 
-// List returns the runners from the backend.
-func (s *RunnerService) List(ctx context.Context, r RunnerListRequest) (*RunnerListResponse, error) {
+// CustodyKey returns the public-key that signs the chain of custody
+func (s *RunnerService) CustodyKey(ctx context.Context, r CustodyKeyRequest) (*CustodyKeyResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.List: marshal RunnerListRequest")
+		return nil, errors.Wrap(err, "RunnerService.CustodyKey: marshal CustodyKeyRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.List: generate signature RunnerListRequest")
+		return nil, errors.Wrap(err, "RunnerService.CustodyKey: generate signature CustodyKeyRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.List"
+	url := s.client.RemoteHost + "RunnerService.CustodyKey"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.List: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.CustodyKey: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -697,54 +710,54 @@ func (s *RunnerService) List(ctx context.Context, r RunnerListRequest) (*RunnerL
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.List")
+		return nil, errors.Wrap(err, "RunnerService.CustodyKey")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerListResponse
+		CustodyKeyResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.List: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.CustodyKey: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.List: read response body")
+		return nil, errors.Wrap(err, "RunnerService.CustodyKey: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.CustodyKey: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerListResponse, nil
+	return &response.CustodyKeyResponse, nil
 }
 
-// LogDebug logs a debug-message
-func (s *RunnerService) LogDebug(ctx context.Context, r LogRequest) (*LogResponse, error) {
+// CustodyRecords stores the hashes for evidence-files
+func (s *RunnerService) CustodyRecords(ctx context.Context, r CustodyRecordsRequest) (*CustodyRecordsResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogDebug: marshal LogRequest")
+		return nil, errors.Wrap(err, "RunnerService.CustodyRecords: marshal CustodyRecordsRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogDebug: generate signature LogRequest")
+		return nil, errors.Wrap(err, "RunnerService.CustodyRecords: generate signature CustodyRecordsRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.LogDebug"
+	url := s.client.RemoteHost + "RunnerService.CustodyRecords"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogDebug: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.CustodyRecords: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -752,54 +765,54 @@ func (s *RunnerService) LogDebug(ctx context.Context, r LogRequest) (*LogRespons
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogDebug")
+		return nil, errors.Wrap(err, "RunnerService.CustodyRecords")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		LogResponse
+		CustodyRecordsResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.LogDebug: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.CustodyRecords: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogDebug: read response body")
+		return nil, errors.Wrap(err, "RunnerService.CustodyRecords: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.LogDebug: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.CustodyRecords: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.LogResponse, nil
+	return &response.CustodyRecordsResponse, nil
 }
 
-// LogError logs an error-message
-func (s *RunnerService) LogError(ctx context.Context, r LogRequest) (*LogResponse, error) {
+// Delete deletes the requested Runner
+func (s *RunnerService) Delete(ctx context.Context, r RunnerDeleteRequest) (*RunnerDeleteResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogError: marshal LogRequest")
+		return nil, errors.Wrap(err, "RunnerService.Delete: marshal RunnerDeleteRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogError: generate signature LogRequest")
+		return nil, errors.Wrap(err, "RunnerService.Delete: generate signature RunnerDeleteRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.LogError"
+	url := s.client.RemoteHost + "RunnerService.Delete"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogError: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.Delete: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -807,54 +820,1001 @@ func (s *RunnerService) LogError(ctx context.Context, r LogRequest) (*LogRespons
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogError")
+		return nil, errors.Wrap(err, "RunnerService.Delete")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		LogResponse
+		RunnerDeleteResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.LogError: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.Delete: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Delete: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerDeleteResponse, nil
+}
+
+// Failed sets a runner to failed
+func (s *RunnerService) Failed(ctx context.Context, r RunnerFailedRequest) (*RunnerFailedResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed: marshal RunnerFailedRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed: generate signature RunnerFailedRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Failed"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerFailedResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Failed: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Failed: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerFailedResponse, nil
+}
+
+// FailedStage sets a stage to Failed
+func (s *RunnerService) FailedStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage: marshal StageRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage: generate signature StageRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.FailedStage"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		StageResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.FailedStage: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.FailedStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.StageResponse, nil
+}
+
+// Finish sets a runner to finished
+func (s *RunnerService) Finish(ctx context.Context, r RunnerFinishRequest) (*RunnerFinishResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish: marshal RunnerFinishRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish: generate signature RunnerFinishRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Finish"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerFinishResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Finish: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Finish: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerFinishResponse, nil
+}
+
+// FinishStage sets a stage to Finished
+func (s *RunnerService) FinishStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage: marshal StageRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage: generate signature StageRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.FinishStage"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		StageResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.FinishStage: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.FinishStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.StageResponse, nil
+}
+
+// Get returns the requested Runner
+func (s *RunnerService) Get(ctx context.Context, r RunnerGetRequest) (*RunnerGetResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get: marshal RunnerGetRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get: generate signature RunnerGetRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Get"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerGetResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Get: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Get: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerGetResponse, nil
+}
+
+// HashSetMatches reports the matches for a hash-list
+func (s *RunnerService) HashSetMatches(ctx context.Context, r HashSetMatchesRequest) (*HashSetMatchesResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.HashSetMatches: marshal HashSetMatchesRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.HashSetMatches: generate signature HashSetMatchesRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.HashSetMatches"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.HashSetMatches: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.HashSetMatches")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		HashSetMatchesResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.HashSetMatches: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.HashSetMatches: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.HashSetMatches: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.HashSetMatchesResponse, nil
+}
+
+// Heartbeat sends a heartbeat for the api
+func (s *RunnerService) Heartbeat(ctx context.Context, r RunnerStartRequest) (*RunnerStartResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat: marshal RunnerStartRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat: generate signature RunnerStartRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Heartbeat"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerStartResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Heartbeat: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Heartbeat: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerStartResponse, nil
+}
+
+// List returns the runners from the backend.
+func (s *RunnerService) List(ctx context.Context, r RunnerListRequest) (*RunnerListResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List: marshal RunnerListRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List: generate signature RunnerListRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.List"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerListResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.List: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerListResponse, nil
+}
+
+// LogDebug logs a debug-message
+func (s *RunnerService) LogDebug(ctx context.Context, r LogRequest) (*LogResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug: marshal LogRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug: generate signature LogRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.LogDebug"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		LogResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.LogDebug: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.LogDebug: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.LogResponse, nil
+}
+
+// LogError logs an error-message
+func (s *RunnerService) LogError(ctx context.Context, r LogRequest) (*LogResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError: marshal LogRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError: generate signature LogRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.LogError"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		LogResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.LogError: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.LogError: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.LogResponse, nil
+}
+
+// LogInfo logs an info-message
+func (s *RunnerService) LogInfo(ctx context.Context, r LogRequest) (*LogResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo: marshal LogRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo: generate signature LogRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.LogInfo"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		LogResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.LogInfo: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.LogInfo: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.LogResponse, nil
+}
+
+// LogItem logs an item
+func (s *RunnerService) LogItem(ctx context.Context, r LogItemRequest) (*LogResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem: marshal LogItemRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem: generate signature LogItemRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.LogItem"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		LogResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.LogItem: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.LogItem: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.LogResponse, nil
+}
+
+// Plan returns the stages for the runner-script to execute (used by ruby script)
+func (s *RunnerService) Plan(ctx context.Context, r RunnerPlanRequest) (*RunnerPlanResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Plan: marshal RunnerPlanRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Plan: generate signature RunnerPlanRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Plan"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Plan: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Plan")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerPlanResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Plan: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogError: read response body")
+		return nil, errors.Wrap(err, "RunnerService.Plan: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.LogError: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.Plan: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerPlanResponse, nil
+}
+
+// Script returns the script for the runner, or the archived script for an
+// earlier start of the runner
+func (s *RunnerService) Script(ctx context.Context, r RunnerScriptRequest) (*RunnerScriptResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Script: marshal RunnerScriptRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Script: generate signature RunnerScriptRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Script"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Script: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Script")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerScriptResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Script: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Script: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Script: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerScriptResponse, nil
+}
+
+// Start sets a runner to started
+func (s *RunnerService) Start(ctx context.Context, r RunnerStartRequest) (*RunnerStartResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Start: marshal RunnerStartRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Start: generate signature RunnerStartRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Start"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Start: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Start")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerStartResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Start: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Start: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Start: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerStartResponse, nil
+}
+
+// StartStage sets a stage to Active
+func (s *RunnerService) StartStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.StartStage: marshal StageRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.StartStage: generate signature StageRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.StartStage"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.StartStage: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.StartStage")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		StageResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.StartStage: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.StartStage: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.StartStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.StageResponse, nil
+}
+
+// UploadFile uploads a file to the uploads-folder in the data-path
+func (s *RunnerService) UploadFile(ctx context.Context, r UploadFileRequest) (*UploadFileResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.UploadFile: marshal UploadFileRequest")
+	}
+	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.UploadFile: generate signature UploadFileRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.UploadFile"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.UploadFile: NewRequest")
+	}
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.UploadFile")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		UploadFileResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.UploadFile: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.UploadFile: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.UploadFile: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.LogResponse, nil
+	return &response.UploadFileResponse, nil
+}
+
+// ServerService handles all the servers
+type ServerService struct {
+	client *Client
+}
+
+// NewServerService makes a new client for accessing ServerService services.
+func NewServerService(client *Client) *ServerService {
+	return &ServerService{
+		client: client,
+	}
 }
 
-// LogInfo logs an info-message
-func (s *RunnerService) LogInfo(ctx context.Context, r LogRequest) (*LogResponse, error) {
+func (s *ServerService) Apply(ctx context.Context, r ServerApplyRequest) (*ServerApplyResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogInfo: marshal LogRequest")
+		return nil, errors.Wrap(err, "ServerService.Apply: marshal ServerApplyRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogInfo: generate signature LogRequest")
+		return nil, errors.Wrap(err, "ServerService.Apply: generate signature ServerApplyRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.LogInfo"
+	url := s.client.RemoteHost + "ServerService.Apply"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogInfo: NewRequest")
+		return nil, errors.Wrap(err, "ServerService.Apply: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -862,54 +1822,54 @@ func (s *RunnerService) LogInfo(ctx context.Context, r LogRequest) (*LogResponse
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogInfo")
+		return nil, errors.Wrap(err, "ServerService.Apply")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		LogResponse
+		ServerApplyResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.LogInfo: new gzip reader")
+			return nil, errors.Wrap(err, "ServerService.Apply: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogInfo: read response body")
+		return nil, errors.Wrap(err, "ServerService.Apply: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.LogInfo: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("ServerService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.LogResponse, nil
+	return &response.ServerApplyResponse, nil
 }
 
-// LogItem logs an item
-func (s *RunnerService) LogItem(ctx context.Context, r LogItemRequest) (*LogResponse, error) {
+// Delete deletes the server, refused while unfinished runners uses the server (unless forced)
+func (s *ServerService) Delete(ctx context.Context, r ServerDeleteRequest) (*ServerDeleteResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogItem: marshal LogItemRequest")
+		return nil, errors.Wrap(err, "ServerService.Delete: marshal ServerDeleteRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogItem: generate signature LogItemRequest")
+		return nil, errors.Wrap(err, "ServerService.Delete: generate signature ServerDeleteRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.LogItem"
+	url := s.client.RemoteHost + "ServerService.Delete"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogItem: NewRequest")
+		return nil, errors.Wrap(err, "ServerService.Delete: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -917,54 +1877,54 @@ func (s *RunnerService) LogItem(ctx context.Context, r LogItemRequest) (*LogResp
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogItem")
+		return nil, errors.Wrap(err, "ServerService.Delete")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		LogResponse
+		ServerDeleteResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.LogItem: new gzip reader")
+			return nil, errors.Wrap(err, "ServerService.Delete: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogItem: read response body")
+		return nil, errors.Wrap(err, "ServerService.Delete: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.LogItem: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("ServerService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.LogResponse, nil
+	return &response.ServerDeleteResponse, nil
 }
 
-// Script returns the script for the runner
-func (s *RunnerService) Script(ctx context.Context, r RunnerGetRequest) (*RunnerScriptResponse, error) {
+// Get returns the server with the runners that uses it
+func (s *ServerService) Get(ctx context.Context, r ServerGetRequest) (*ServerGetResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Script: marshal RunnerGetRequest")
+		return nil, errors.Wrap(err, "ServerService.Get: marshal ServerGetRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Script: generate signature RunnerGetRequest")
+		return nil, errors.Wrap(err, "ServerService.Get: generate signature ServerGetRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Script"
+	url := s.client.RemoteHost + "ServerService.Get"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Script: NewRequest")
+		return nil, errors.Wrap(err, "ServerService.Get: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -972,54 +1932,53 @@ func (s *RunnerService) Script(ctx context.Context, r RunnerGetRequest) (*Runner
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Script")
+		return nil, errors.Wrap(err, "ServerService.Get")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerScriptResponse
+		ServerGetResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Script: new gzip reader")
+			return nil, errors.Wrap(err, "ServerService.Get: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Script: read response body")
+		return nil, errors.Wrap(err, "ServerService.Get: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Script: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("ServerService.Get: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerScriptResponse, nil
+	return &response.ServerGetResponse, nil
 }
 
-// Start sets a runner to started
-func (s *RunnerService) Start(ctx context.Context, r RunnerStartRequest) (*RunnerStartResponse, error) {
+func (s *ServerService) List(ctx context.Context, r ServerListRequest) (*ServerListResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Start: marshal RunnerStartRequest")
+		return nil, errors.Wrap(err, "ServerService.List: marshal ServerListRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Start: generate signature RunnerStartRequest")
+		return nil, errors.Wrap(err, "ServerService.List: generate signature ServerListRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Start"
+	url := s.client.RemoteHost + "ServerService.List"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Start: NewRequest")
+		return nil, errors.Wrap(err, "ServerService.List: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -1027,54 +1986,67 @@ func (s *RunnerService) Start(ctx context.Context, r RunnerStartRequest) (*Runne
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Start")
+		return nil, errors.Wrap(err, "ServerService.List")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerStartResponse
+		ServerListResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Start: new gzip reader")
+			return nil, errors.Wrap(err, "ServerService.List: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Start: read response body")
+		return nil, errors.Wrap(err, "ServerService.List: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Start: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("ServerService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerStartResponse, nil
+	return &response.ServerListResponse, nil
 }
 
-// StartStage sets a stage to Active
-func (s *RunnerService) StartStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
+// WebhookService handles the webhooks for the lifecycle-events of the runners
+// and the stages
+type WebhookService struct {
+	client *Client
+}
+
+// NewWebhookService makes a new client for accessing WebhookService services.
+func NewWebhookService(client *Client) *WebhookService {
+	return &WebhookService{
+		client: client,
+	}
+}
+
+// Add adds a webhook, the secret for the signatures is only returned here
+func (s *WebhookService) Add(ctx context.Context, r WebhookAddRequest) (*WebhookAddResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.StartStage: marshal StageRequest")
+		return nil, errors.Wrap(err, "WebhookService.Add: marshal WebhookAddRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.StartStage: generate signature StageRequest")
+		return nil, errors.Wrap(err, "WebhookService.Add: generate signature WebhookAddRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.StartStage"
+	url := s.client.RemoteHost + "WebhookService.Add"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.StartStage: NewRequest")
+		return nil, errors.Wrap(err, "WebhookService.Add: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -1082,53 +2054,54 @@ func (s *RunnerService) StartStage(ctx context.Context, r StageRequest) (*StageR
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.StartStage")
+		return nil, errors.Wrap(err, "WebhookService.Add")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		StageResponse
+		WebhookAddResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.StartStage: new gzip reader")
+			return nil, errors.Wrap(err, "WebhookService.Add: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.StartStage: read response body")
+		return nil, errors.Wrap(err, "WebhookService.Add: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.StartStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("WebhookService.Add: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.StageResponse, nil
+	return &response.WebhookAddResponse, nil
 }
 
-func (s *RunnerService) UploadFile(ctx context.Context, r UploadFileRequest) (*UploadFileResponse, error) {
+// Delete deletes a webhook and its deliveries
+func (s *WebhookService) Delete(ctx context.Context, r WebhookDeleteRequest) (*WebhookDeleteResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.UploadFile: marshal UploadFileRequest")
+		return nil, errors.Wrap(err, "WebhookService.Delete: marshal WebhookDeleteRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.UploadFile: generate signature UploadFileRequest")
+		return nil, errors.Wrap(err, "WebhookService.Delete: generate signature WebhookDeleteRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.UploadFile"
+	url := s.client.RemoteHost + "WebhookService.Delete"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.UploadFile: NewRequest")
+		return nil, errors.Wrap(err, "WebhookService.Delete: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -1136,65 +2109,54 @@ func (s *RunnerService) UploadFile(ctx context.Context, r UploadFileRequest) (*U
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.UploadFile")
+		return nil, errors.Wrap(err, "WebhookService.Delete")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		UploadFileResponse
+		WebhookDeleteResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.UploadFile: new gzip reader")
+			return nil, errors.Wrap(err, "WebhookService.Delete: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.UploadFile: read response body")
+		return nil, errors.Wrap(err, "WebhookService.Delete: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.UploadFile: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("WebhookService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.UploadFileResponse, nil
-}
-
-// ServerService handles all the servers
-type ServerService struct {
-	client *Client
-}
-
-// NewServerService makes a new client for accessing ServerService services.
-func NewServerService(client *Client) *ServerService {
-	return &ServerService{
-		client: client,
-	}
+	return &response.WebhookDeleteResponse, nil
 }
 
-func (s *ServerService) Apply(ctx context.Context, r ServerApplyRequest) (*ServerApplyResponse, error) {
+// Deliveries returns the delivery-log for a webhook
+func (s *WebhookService) Deliveries(ctx context.Context, r WebhookDeliveriesRequest) (*WebhookDeliveriesResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.Apply: marshal ServerApplyRequest")
+		return nil, errors.Wrap(err, "WebhookService.Deliveries: marshal WebhookDeliveriesRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.Apply: generate signature ServerApplyRequest")
+		return nil, errors.Wrap(err, "WebhookService.Deliveries: generate signature WebhookDeliveriesRequest")
 	}
-	url := s.client.RemoteHost + "ServerService.Apply"
+	url := s.client.RemoteHost + "WebhookService.Deliveries"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.Apply: NewRequest")
+		return nil, errors.Wrap(err, "WebhookService.Deliveries: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -1202,53 +2164,54 @@ func (s *ServerService) Apply(ctx context.Context, r ServerApplyRequest) (*Serve
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.Apply")
+		return nil, errors.Wrap(err, "WebhookService.Deliveries")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		ServerApplyResponse
+		WebhookDeliveriesResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "ServerService.Apply: new gzip reader")
+			return nil, errors.Wrap(err, "WebhookService.Deliveries: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.Apply: read response body")
+		return nil, errors.Wrap(err, "WebhookService.Deliveries: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("ServerService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("WebhookService.Deliveries: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.ServerApplyResponse, nil
+	return &response.WebhookDeliveriesResponse, nil
 }
 
-func (s *ServerService) List(ctx context.Context, r ServerListRequest) (*ServerListResponse, error) {
+// List returns the webhooks (without their secrets)
+func (s *WebhookService) List(ctx context.Context, r WebhookListRequest) (*WebhookListResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.List: marshal ServerListRequest")
+		return nil, errors.Wrap(err, "WebhookService.List: marshal WebhookListRequest")
 	}
 	signature, err := generateSignature(requestBodyBytes, s.client.secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.List: generate signature ServerListRequest")
+		return nil, errors.Wrap(err, "WebhookService.List: generate signature WebhookListRequest")
 	}
-	url := s.client.RemoteHost + "ServerService.List"
+	url := s.client.RemoteHost + "WebhookService.List"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.List: NewRequest")
+		return nil, errors.Wrap(err, "WebhookService.List: NewRequest")
 	}
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
@@ -1256,36 +2219,160 @@ func (s *ServerService) List(ctx context.Context, r ServerListRequest) (*ServerL
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.List")
+		return nil, errors.Wrap(err, "WebhookService.List")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		ServerListResponse
+		WebhookListResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "ServerService.List: new gzip reader")
+			return nil, errors.Wrap(err, "WebhookService.List: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.List: read response body")
+		return nil, errors.Wrap(err, "WebhookService.List: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("ServerService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("WebhookService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.ServerListResponse, nil
+	return &response.WebhookListResponse, nil
+}
+
+// Archive closes the cases and copies the single-, compound- and review-cases to
+// an archive-share, verified with per-file hashes
+type Archive struct {
+	datastore.Base
+
+	// StageID foreign-key for stage-table
+	StageID uint `json:"stageID" yaml:"stageID"`
+
+	// Destination is the archive-share to copy the case to
+	Destination string `json:"destination" yaml:"destination"`
+
+	// RemoveSource - if the single-case directory should be removed after the copies
+	// has been verified (refused if a compound- or review-case is configured, they
+	// list the single-case)
+	RemoveSource bool `json:"removeSource" yaml:"removeSource"`
+
+	// Location of the archived cases (comma-separated)
+	Location string `json:"location" yaml:"location"`
+
+	// Verified - if all files in the archived cases matched the hashes of the source
+	Verified bool `json:"verified" yaml:"verified"`
+
+	// Files is the amount of files that was archived
+	Files int64 `json:"files" yaml:"files"`
+
+	// Mismatches is the amount of files that did not match the hashes of the source
+	Mismatches int64 `json:"mismatches" yaml:"mismatches"`
+
+	// Status for the stage
+	Status int64 `json:"status" yaml:"status"`
+}
+
+// ArchiveResultRequest is the input-object for reporting the result of an archived
+// case
+type ArchiveResultRequest struct {
+	Runner string `json:"runner" yaml:"runner"`
+
+	StageID uint `json:"stageID" yaml:"stageID"`
+
+	Location string `json:"location" yaml:"location"`
+
+	Files int64 `json:"files" yaml:"files"`
+
+	Mismatches int64 `json:"mismatches" yaml:"mismatches"`
+}
+
+// ArchiveResultResponse is the output-object for reporting the result of an
+// archived case
+type ArchiveResultResponse struct {
+}
+
+// AuditEntry is an entry in the append-only audit-trail (the db refuses to update
+// or delete it), recorded for every mutating call to the api and for the api-keys
+// managed by the CLI
+type AuditEntry struct {
+	datastore.Base
+
+	// Time for the call
+	Time time.Time `json:"time" yaml:"time"`
+
+	// Identity is the name of the api-key for the call
+	Identity string `json:"identity" yaml:"identity"`
+
+	// Role for the api-key
+	Role string `json:"role" yaml:"role"`
+
+	// RemoteAddr is the source-ip for the call
+	RemoteAddr string `json:"remoteAddr" yaml:"remoteAddr"`
+
+	// ForwardedFor is the X-Forwarded-For header for the call (if set)
+	ForwardedFor string `json:"forwardedFor" yaml:"forwardedFor"`
+
+	// Method that was called (e.g RunnerService.Apply)
+	Method string `json:"method" yaml:"method"`
+
+	// Resource the method changes (runner, server, nms, webhook, file or api-key)
+	Resource string `json:"resource" yaml:"resource"`
+
+	// Target is the name of the resource (e.g the name of the runner)
+	Target string `json:"target" yaml:"target"`
+
+	// StatusCode for the response
+	StatusCode int64 `json:"statusCode" yaml:"statusCode"`
+
+	// Exception is the error for the call (if it failed)
+	Exception string `json:"exception" yaml:"exception"`
+
+	// Request is the JSON-body for the call (without passwords and secrets)
+	Request string `json:"request" yaml:"request"`
+
+	// Diff is the changes (JSON) from the request compared with the state in the db
+	// before the call
+	Diff string `json:"diff" yaml:"diff"`
+}
+
+// AuditListRequest is the input-object for listing the audit-trail
+type AuditListRequest struct {
+	// Identity to list the entries for
+	Identity string `json:"identity" yaml:"identity"`
+
+	// Method to list the entries for (e.g RunnerService.Delete)
+	Method string `json:"method" yaml:"method"`
+
+	// Resource to list the entries for (runner, server, nms, webhook or file)
+	Resource string `json:"resource" yaml:"resource"`
+
+	// Target to list the entries for (e.g the name of the runner)
+	Target string `json:"target" yaml:"target"`
+
+	// Since lists the entries from this time
+	Since *time.Time `json:"since" yaml:"since"`
+
+	// Until lists the entries before this time
+	Until *time.Time `json:"until" yaml:"until"`
+
+	// Limit for the amount of entries (the latest first), 100 if 0
+	Limit int64 `json:"limit" yaml:"limit"`
+}
+
+// AuditListResponse is the output-object for listing the audit-trail
+type AuditListResponse struct {
+	Entries []AuditEntry `json:"entries" yaml:"entries"`
 }
 
 // Case holds the information for a case
@@ -1333,6 +2420,92 @@ type CaseSettings struct {
 	ReviewCompound *Case `json:"reviewCompound" yaml:"reviewCompound"`
 }
 
+// CustodyRecord holds the hash for an evidence-file that was ingested by a
+// process-stage (chain of custody)
+type CustodyRecord struct {
+	datastore.Base
+
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint `json:"evidenceID" yaml:"evidenceID"`
+
+	// Path to the evidence-file
+	Path string `json:"path" yaml:"path"`
+
+	// Size of the evidence-file in bytes
+	Size int64 `json:"size" yaml:"size"`
+
+	// Algorithm used to hash the evidence-file
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+
+	// Hash for the evidence-file
+	Hash string `json:"hash" yaml:"hash"`
+
+	// ModifiedAt is when the evidence-file was last modified (unix-timestamp)
+	ModifiedAt int64 `json:"modifiedAt" yaml:"modifiedAt"`
+
+	// HashedAt is when the evidence-file was hashed (unix-timestamp)
+	HashedAt int64 `json:"hashedAt" yaml:"hashedAt"`
+}
+
+// CustodyRecordsRequest is the input-object for storing the hashes for
+// evidence-files
+type CustodyRecordsRequest struct {
+	Runner string `json:"runner" yaml:"runner"`
+
+	StageID uint `json:"stageID" yaml:"stageID"`
+
+	EvidenceID uint `json:"evidenceID" yaml:"evidenceID"`
+
+	Records []CustodyRecord `json:"records" yaml:"records"`
+}
+
+// CustodyRecordsResponse is the output-object for storing the hashes for
+// evidence-files
+type CustodyRecordsResponse struct {
+}
+
+// CustodyRequest is the input-object for exporting the chain of custody for a
+// runner
+type CustodyRequest struct {
+	// Name of the runner
+	Name string `json:"name" yaml:"name"`
+
+	// Format for the document - json or csv
+	Format string `json:"format" yaml:"format"`
+}
+
+// CustodyResponse is the output-object for exporting the chain of custody for a
+// runner
+type CustodyResponse struct {
+	// Document for the chain of custody
+	Document string `json:"document" yaml:"document"`
+
+	// Signature for the document (base64-encoded ed25519-signature)
+	Signature string `json:"signature" yaml:"signature"`
+
+	// PublicKey to verify the signature with (base64-encoded)
+	PublicKey string `json:"publicKey" yaml:"publicKey"`
+
+	// Fingerprint for the public-key (sha256), it must match the fingerprint that is
+	// pinned from CustodyKey or the service-log
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+}
+
+// CustodyKeyRequest is the input-object for getting the key for the chain of
+// custody
+type CustodyKeyRequest struct {
+}
+
+// CustodyKeyResponse is the output-object for getting the key for the chain of
+// custody
+type CustodyKeyResponse struct {
+	// PublicKey that verifies the signatures (PEM-encoded)
+	PublicKey string `json:"publicKey" yaml:"publicKey"`
+
+	// Fingerprint for the public-key (sha256)
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+}
+
 type Elasticsearch struct {
 	datastore.Base
 
@@ -1384,22 +2557,80 @@ type Exclude struct {
 	// Search query in the case
 	Search string `json:"search" yaml:"search"`
 
-	// Reason to exclude the items from the search
+	// Reason to exclude the items from the search
+	Reason string `json:"reason" yaml:"reason"`
+
+	// Status for the stage
+	Status int64 `json:"status" yaml:"status"`
+}
+
+// File holds information about a file
+type File struct {
+	datastore.Base
+
+	// SearchAndTagID foreign-key for searchandtag-table
+	SearchAndTagID uint `json:"searchAndTagID" yaml:"searchAndTagID"`
+
+	// Path for where the file is located at
+	Path string `json:"path" yaml:"path"`
+}
+
+// HashList holds information about a file of known hashes
+type HashList struct {
+	datastore.Base
+
+	// HashSetID foreign-key for hashset-table
+	HashSetID uint `json:"hashSetID" yaml:"hashSetID"`
+
+	// Path for where the list is located at
+	Path string `json:"path" yaml:"path"`
+
+	// Algorithm for the hashes in the list (md5 or sha1)
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+
+	// Matches is the amount of items that matched the list
+	Matches int64 `json:"matches" yaml:"matches"`
+}
+
+// HashSet tags or excludes items in a Nuix-case that match one or more lists of
+// known hashes
+type HashSet struct {
+	datastore.Base
+
+	// StageID foreign-key for stage-table
+	StageID uint `json:"stageID" yaml:"stageID"`
+
+	// Lists of known hashes to match the items against
+	Lists []*HashList `json:"lists" yaml:"lists"`
+
+	// Action for the matching items (tag or exclude)
+	Action string `json:"action" yaml:"action"`
+
+	// Tag for the matching items if the action is tag
+	Tag string `json:"tag" yaml:"tag"`
+
+	// Reason to exclude the matching items if the action is exclude
 	Reason string `json:"reason" yaml:"reason"`
 
 	// Status for the stage
 	Status int64 `json:"status" yaml:"status"`
 }
 
-// File holds information about a file
-type File struct {
-	datastore.Base
+// HashSetMatchesRequest is the input-object for reporting the matches for a
+// hash-list
+type HashSetMatchesRequest struct {
+	Runner string `json:"runner" yaml:"runner"`
 
-	// SearchAndTagID foreign-key for searchandtag-table
-	SearchAndTagID uint `json:"searchAndTagID" yaml:"searchAndTagID"`
+	StageID uint `json:"stageID" yaml:"stageID"`
 
-	// Path for where the file is located at
-	Path string `json:"path" yaml:"path"`
+	ListID uint `json:"listID" yaml:"listID"`
+
+	Matches int64 `json:"matches" yaml:"matches"`
+}
+
+// HashSetMatchesResponse is the output-object for reporting the matches for a
+// hash-list
+type HashSetMatchesResponse struct {
 }
 
 // InApp script as a stage
@@ -1449,6 +2680,47 @@ type LicenceApplyRequest struct {
 	Amount int64 `json:"amount" yaml:"amount"`
 }
 
+// LicenceUsage compares a licence-type in the NMS with the licence-type in the
+// service.
+type LicenceUsage struct {
+	// Type of licence.
+	Type string `json:"type" yaml:"type"`
+
+	// Amount of licences for the type in the service.
+	Amount int64 `json:"amount" yaml:"amount"`
+
+	// InUse - licences reserved by the runners in the service.
+	InUse int64 `json:"inUse" yaml:"inUse"`
+
+	// Pool - the size of the licence-pool in the NMS.
+	Pool int64 `json:"pool" yaml:"pool"`
+
+	// Leased - licences leased from the pool in the NMS.
+	Leased int64 `json:"leased" yaml:"leased"`
+
+	// Mismatch describes how the NMS and the service differs for the type, empty if
+	// they match.
+	Mismatch string `json:"mismatch" yaml:"mismatch"`
+}
+
+// LicenceLease is a lease of a licence in the NMS.
+type LicenceLease struct {
+	// Type of licence.
+	Type string `json:"type" yaml:"type"`
+
+	// Workers leased with the licence.
+	Workers int64 `json:"workers" yaml:"workers"`
+
+	// User that has the lease.
+	User string `json:"user" yaml:"user"`
+
+	// Host that has the lease.
+	Host string `json:"host" yaml:"host"`
+
+	// LeasedAt is when the licence was leased.
+	LeasedAt *time.Time `json:"leasedAt" yaml:"leasedAt"`
+}
+
 // Licences is a holder for Licence.
 type Licences struct {
 	Licence LicenceApplyRequest `json:"licence" yaml:"licence"`
@@ -1520,11 +2792,29 @@ type Nms struct {
 
 	// Is NMS a CLS relay server.
 	IsRelay bool `json:"isRelay" yaml:"isRelay"`
+
+	// PoolsPath is the path for the licence-pools in the REST-api for the NMS
+	// (default: /api/v1/licences/pools).
+	PoolsPath string `json:"poolsPath" yaml:"poolsPath"`
+
+	// LeasesPath is the path for the leased licences in the REST-api for the NMS
+	// (default: /api/v1/licences/leases).
+	LeasesPath string `json:"leasesPath" yaml:"leasesPath"`
+
+	// Auth for the REST-api for the NMS - basic (default, with the username and
+	// password) or bearer (the password is the token).
+	Auth string `json:"auth" yaml:"auth"`
+
+	// Fingerprint (sha256) for the certificate for the NMS, pins the self-signed
+	// certificate instead of verifying its chain.
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+
+	// Insecure - skip verifying the certificate for the NMS.
+	Insecure bool `json:"insecure" yaml:"insecure"`
 }
 
 // NmsApplyRequest is the input-object for Apply in the NMS-service.
 type NmsApplyRequest struct {
-
 	// Address of the nms-server
 	Address string `json:"address" yaml:"address"`
 
@@ -1545,6 +2835,25 @@ type NmsApplyRequest struct {
 
 	// Whether the NMS is a CLS relay server.
 	IsRelay bool `json:"isRelay" yaml:"isRelay"`
+
+	// PoolsPath is the path for the licence-pools in the REST-api for the NMS
+	// (default: /api/v1/licences/pools).
+	PoolsPath string `json:"poolsPath" yaml:"poolsPath"`
+
+	// LeasesPath is the path for the leased licences in the REST-api for the NMS
+	// (default: /api/v1/licences/leases).
+	LeasesPath string `json:"leasesPath" yaml:"leasesPath"`
+
+	// Auth for the REST-api for the NMS - basic (default, with the username and
+	// password) or bearer (the password is the token).
+	Auth string `json:"auth" yaml:"auth"`
+
+	// Fingerprint (sha256) for the certificate for the NMS, pins the self-signed
+	// certificate instead of verifying its chain.
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+
+	// Insecure - skip verifying the certificate for the NMS.
+	Insecure bool `json:"insecure" yaml:"insecure"`
 }
 
 type NmsApplyRequests struct {
@@ -1559,24 +2868,96 @@ type NmsApplyResponse struct {
 // NmsListLicencesRequest is the input-object for listing licences for a specific
 // NMS.
 type NmsListLicencesRequest struct {
-
 	// ID for the nms-server to list the licences for.
 	NmsID uint `json:"nmsID" yaml:"nmsID"`
+
+	// Address for the nms-server to list the licences for (if the ID is not
+	// specified).
+	Address string `json:"address" yaml:"address"`
 }
 
 // NmsListLicencesResponse is the output-object for listing licences for a specific
 // NMS.
 type NmsListLicencesResponse struct {
+	// Nms the licences are listed for.
+	Nms Nms `json:"nms" yaml:"nms"`
+
+	// Licences configured for the NMS in the service, with the licences reserved by
+	// the runners (InUse).
 	Licences []Licence `json:"licences" yaml:"licences"`
+
+	// Usage compares the licence-pools and the leases in the NMS with the licences in
+	// the service by type.
+	Usage []LicenceUsage `json:"usage" yaml:"usage"`
+
+	// Leases - the current leases in the NMS.
+	Leases []LicenceLease `json:"leases" yaml:"leases"`
+
+	// Exception is the error from the NMS if it could not be queried (only the
+	// licences in the service are listed).
+	Exception string `json:"exception" yaml:"exception"`
 }
 
 // NmsListRequest is the input-object for List in the NMS-service.
 type NmsListRequest struct {
+	// Address - only list the nms-servers with addresses matching the pattern (*
+	// matches any characters).
+	Address string `json:"address" yaml:"address"`
+
+	// Licence - only list the nms-servers with the licence-type.
+	Licence string `json:"licence" yaml:"licence"`
+
+	// Sort - the field to sort the nms-servers by (id, address or created), prefixed
+	// with - for descending.
+	Sort string `json:"sort" yaml:"sort"`
+
+	// Limit - the amount of nms-servers to list (every nms-server if 0).
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// Cursor - the NextCursor from the previous response to list the next page.
+	Cursor string `json:"cursor" yaml:"cursor"`
 }
 
 // NmsListResponse is the output-object for List in the NMS-service.
 type NmsListResponse struct {
 	Nms []Nms `json:"nms" yaml:"nms"`
+
+	// NextCursor - the cursor for the next page, empty if there are no more
+	// nms-servers.
+	NextCursor string `json:"nextCursor" yaml:"nextCursor"`
+}
+
+// NmsGetRequest is the input-object for Get in the NMS-service.
+type NmsGetRequest struct {
+	// Address for the nms-server.
+	Address string `json:"address" yaml:"address"`
+
+	// Limit - the amount of runners to get, the latest first (defaults to 20).
+	Limit int64 `json:"limit" yaml:"limit"`
+}
+
+// NmsGetResponse is the output-object for Get in the NMS-service.
+type NmsGetResponse struct {
+	Nms Nms `json:"nms" yaml:"nms"`
+
+	// Runners that uses the nms-server (the latest first).
+	Runners []Runner `json:"runners" yaml:"runners"`
+
+	// TotalRunners - the amount of runners that uses the nms-server.
+	TotalRunners int64 `json:"totalRunners" yaml:"totalRunners"`
+}
+
+// NmsDeleteRequest is the input-object for Delete in the NMS-service.
+type NmsDeleteRequest struct {
+	// Address for the nms-server.
+	Address string `json:"address" yaml:"address"`
+
+	// Force - delete the nms-server even if unfinished runners uses it.
+	Force bool `json:"force" yaml:"force"`
+}
+
+// NmsDeleteResponse is the output-object for Delete in the NMS-service.
+type NmsDeleteResponse struct {
 }
 
 // NuixSwitch is a command argument for nuix-console
@@ -1642,6 +3023,9 @@ type Process struct {
 	// EvidenceStore to process to the nuix-case
 	EvidenceStore []*Evidence `json:"evidenceStore" yaml:"evidenceStore"`
 
+	// HashEvidence hashes every evidence-file before processing (chain of custody)
+	HashEvidence bool `json:"hashEvidence" yaml:"hashEvidence"`
+
 	// Status for the stage
 	Status int64 `json:"status" yaml:"status"`
 }
@@ -1696,6 +3080,28 @@ type Runner struct {
 	// HealthyAt - last time the runner was healthy
 	HealthyAt *time.Time `json:"healthyAt" yaml:"healthyAt"`
 
+	// MaxRuntime for the runner (e.g 12h) - the runner is stopped and set to timed out
+	// if it runs longer
+	MaxRuntime string `json:"maxRuntime" yaml:"maxRuntime"`
+
+	// StartedAt - when the runner was started
+	StartedAt *time.Time `json:"startedAt" yaml:"startedAt"`
+
+	// TemplateVersion - version of the script-templates used for the last run of the
+	// runner
+	TemplateVersion string `json:"templateVersion" yaml:"templateVersion"`
+
+	// Owner - the name of the api-key that applied the runner, investigators can only
+	// update and delete their own runners
+	Owner string `json:"owner" yaml:"owner"`
+
+	// Notify - comma-separated recipients for the emails when the runner has finished,
+	// failed or timed out (defaults to the investigator)
+	Notify string `json:"notify" yaml:"notify"`
+
+	// Exception for the last run if the runner failed or timed out
+	Exception string `json:"exception" yaml:"exception"`
+
 	// CaseSettings for the cases to use
 	CaseSettingsID uint `json:"caseSettingsID" yaml:"caseSettingsID"`
 
@@ -1713,7 +3119,6 @@ type Runner struct {
 // RunnerApplyRequest is the input-object for applying a runner-configuration to
 // the Runner-service
 type RunnerApplyRequest struct {
-
 	// Name for the runner
 	Name string `json:"name" yaml:"name"`
 
@@ -1732,6 +3137,9 @@ type RunnerApplyRequest struct {
 	// Amount of workers to use for the runner
 	Workers int64 `json:"workers" yaml:"workers"`
 
+	// MaxRuntime for the runner (e.g 12h)
+	MaxRuntime string `json:"maxRuntime" yaml:"maxRuntime"`
+
 	// CaseSettings is the settings for the cases that should be processed if
 	// Process-stage is used
 	CaseSettings *CaseSettings `json:"caseSettings" yaml:"caseSettings"`
@@ -1742,6 +3150,10 @@ type RunnerApplyRequest struct {
 	// Switches to use for nuix-console
 	Switches []string `json:"switches" yaml:"switches"`
 
+	// Notify - recipients for the emails when the runner has finished, failed or timed
+	// out (defaults to the investigator for the case)
+	Notify []string `json:"notify" yaml:"notify"`
+
 	// Update - if the runner should be updated
 	Update bool `json:"update" yaml:"update"`
 }
@@ -1772,6 +3184,21 @@ type RunnerDeleteRequest struct {
 type RunnerDeleteResponse struct {
 }
 
+// RunnerCancelRequest is the input-object for cancelling a runner by name
+type RunnerCancelRequest struct {
+	// Name of the runner
+	Name string `json:"name" yaml:"name"`
+
+	// Reason for the cancel (optional), it is set as the exception for the runner
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// RunnerCancelResponse is the output-object for cancelling a runner by name
+type RunnerCancelResponse struct {
+	// Runner that was cancelled
+	Runner Runner `json:"runner" yaml:"runner"`
+}
+
 // RunnerFailedRequest is the input-object for failing a runner by id
 type RunnerFailedRequest struct {
 	ID uint `json:"id" yaml:"id"`
@@ -1808,16 +3235,190 @@ type RunnerGetResponse struct {
 
 // RunnerListRequest is the input-object for listing the runners from the backend
 type RunnerListRequest struct {
+	// Status - only list the runners with the statuses (waiting, running, failed,
+	// finished or timeout).
+	Status []string `json:"status" yaml:"status"`
+
+	// Hostname - only list the runners for the server.
+	Hostname string `json:"hostname" yaml:"hostname"`
+
+	// Nms - only list the runners for the nms-server.
+	Nms string `json:"nms" yaml:"nms"`
+
+	// Owner - only list the runners owned by the api-key (the name of the key that
+	// applied them).
+	Owner string `json:"owner" yaml:"owner"`
+
+	// Investigator - only list the runners with the investigator for their
+	// single-case.
+	Investigator string `json:"investigator" yaml:"investigator"`
+
+	// CreatedAfter - only list the runners created after the time (approximate for the
+	// runners saved before the creation-time was kept - their creation-time is the
+	// time for their latest save).
+	CreatedAfter *time.Time `json:"createdAfter" yaml:"createdAfter"`
+
+	// Name - only list the runners with names matching the pattern (* matches any
+	// characters).
+	Name string `json:"name" yaml:"name"`
+
+	// Sort - the field to sort the runners by (id, name, created or status), prefixed
+	// with - for descending.
+	Sort string `json:"sort" yaml:"sort"`
+
+	// Limit - the amount of runners to list (defaults to 100).
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// Cursor - the NextCursor from the previous response to list the next page.
+	Cursor string `json:"cursor" yaml:"cursor"`
 }
 
 // RunnerListResponse is the input-object for listing the runners from the backend
 type RunnerListResponse struct {
 	Runners []Runner `json:"runners" yaml:"runners"`
+
+	// NextCursor - the cursor for the next page, empty if there are no more runners.
+	NextCursor string `json:"nextCursor" yaml:"nextCursor"`
+}
+
+// RunnerScriptRequest is the input-object for getting the script for a runner
+type RunnerScriptRequest struct {
+	// Name of the runner
+	Name string `json:"name" yaml:"name"`
+
+	// Attempt - the start of the runner to get the archived script for (1 for the
+	// first start), 0 generates the script
+	Attempt int64 `json:"attempt" yaml:"attempt"`
 }
 
 // RunnerScriptResponse is the output-object for GetScript
 type RunnerScriptResponse struct {
 	Script string `json:"script" yaml:"script"`
+
+	// Library holds the stage-handlers for the script
+	Library string `json:"library" yaml:"library"`
+
+	// Attempt the script was archived for, 0 if the script was generated for the
+	// request
+	Attempt int64 `json:"attempt" yaml:"attempt"`
+
+	// Attempts - the amount of archived scripts for the runner
+	Attempts int64 `json:"attempts" yaml:"attempts"`
+
+	// Hash for the script (sha256)
+	Hash string `json:"hash" yaml:"hash"`
+
+	// LibraryHash - hash for the library (sha256)
+	LibraryHash string `json:"libraryHash" yaml:"libraryHash"`
+
+	// Plan the script got from the service for the attempt (JSON), empty if the script
+	// was generated for the request or the script never got the plan
+	Plan string `json:"plan" yaml:"plan"`
+
+	// PlanHash - hash for the plan (sha256)
+	PlanHash string `json:"planHash" yaml:"planHash"`
+
+	// TemplateVersion - version of the script-templates
+	TemplateVersion string `json:"templateVersion" yaml:"templateVersion"`
+
+	// Arguments for nuix_console when the script was started
+	Arguments []string `json:"arguments" yaml:"arguments"`
+
+	// ArchivedAt is when the script was archived - the runner was started
+	// (unix-timestamp)
+	ArchivedAt int64 `json:"archivedAt" yaml:"archivedAt"`
+}
+
+// ScriptArchive holds the generated script for a start of a runner, so it can be
+// shown exactly what was executed
+type ScriptArchive struct {
+	datastore.Base
+
+	// RunnerID foreign-key for runner-table
+	RunnerID uint `json:"runnerID" yaml:"runnerID"`
+
+	// Attempt - the start of the runner (1 for the first start)
+	Attempt int64 `json:"attempt" yaml:"attempt"`
+
+	// Script that was generated for the runner
+	Script string `json:"script" yaml:"script"`
+
+	// Library with the stage-handlers for the script
+	Library string `json:"library" yaml:"library"`
+
+	// Hash for the script (sha256)
+	Hash string `json:"hash" yaml:"hash"`
+
+	// LibraryHash - hash for the library (sha256)
+	LibraryHash string `json:"libraryHash" yaml:"libraryHash"`
+
+	// Plan the script got from the service (JSON), stored when the script requests the
+	// plan
+	Plan string `json:"plan" yaml:"plan"`
+
+	// PlanHash - hash for the plan (sha256)
+	PlanHash string `json:"planHash" yaml:"planHash"`
+
+	// TemplateVersion - version of the script-templates
+	TemplateVersion string `json:"templateVersion" yaml:"templateVersion"`
+
+	// Arguments for nuix_console in the order they were used
+	Arguments []ScriptArgument `json:"arguments" yaml:"arguments"`
+}
+
+// ScriptArgument is a command argument for nuix_console when a script was started
+type ScriptArgument struct {
+	datastore.Base
+
+	ScriptArchiveID uint `json:"scriptArchiveID" yaml:"scriptArchiveID"`
+
+	Value string `json:"value" yaml:"value"`
+}
+
+// RunnerPlanRequest is the input-object for getting the plan for a runner
+type RunnerPlanRequest struct {
+	// Runner - name of the runner
+	Runner string `json:"runner" yaml:"runner"`
+}
+
+// RunnerPlanResponse is the output-object for getting the plan for a runner
+type RunnerPlanResponse struct {
+	// ID of the runner
+	ID uint `json:"id" yaml:"id"`
+
+	// Runner - name of the runner
+	Runner string `json:"runner" yaml:"runner"`
+
+	// ScriptDir is the path to the avian-scripts on the server
+	ScriptDir string `json:"scriptDir" yaml:"scriptDir"`
+
+	// CaseSettings for the cases to open
+	CaseSettings *CaseSettings `json:"caseSettings" yaml:"caseSettings"`
+
+	// Stages to execute - in the order to execute them
+	Stages []*PlanStage `json:"stages" yaml:"stages"`
+}
+
+// PlanStage is a stage in the plan for a runner
+type PlanStage struct {
+	// ID of the stage
+	ID uint `json:"id" yaml:"id"`
+
+	// Kind of the stage - the name of the stage-handler to execute the stage with
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Name of the stage
+	Name string `json:"name" yaml:"name"`
+
+	// Status of the stage
+	Status int64 `json:"status" yaml:"status"`
+
+	// Checkpoint to resume the stage from (JSON) - empty if the stage should start
+	// from the beginning
+	Checkpoint string `json:"checkpoint" yaml:"checkpoint"`
+
+	// Stage with the settings for the stage
+	Stage *Stage `json:"stage" yaml:"stage"`
 }
 
 type StageRequest struct {
@@ -1836,6 +3437,21 @@ type Stage struct {
 	// Index for where the stage where indexed in the yaml
 	Index uint `json:"index" yaml:"index"`
 
+	// Timeout for the stage (e.g 2h30m) - the runner is stopped and set to timed out
+	// if the stage runs longer
+	Timeout string `json:"timeout" yaml:"timeout"`
+
+	// StartedAt - when the stage was started
+	StartedAt *time.Time `json:"startedAt" yaml:"startedAt"`
+
+	// Checkpoint reported by the runner-script (JSON) - used to resume the stage from
+	// where it failed in the next run
+	Checkpoint string `json:"checkpoint" yaml:"checkpoint"`
+
+	// Items - the amount of items the stage has handled (the highest count logged by
+	// the runner-script)
+	Items int64 `json:"items" yaml:"items"`
+
 	// Process-stage processes data into a Nuix-case
 	Process *Process `json:"process" yaml:"process"`
 
@@ -1862,12 +3478,32 @@ type Stage struct {
 
 	// ScanNewChildItems scans for new child items based on a search
 	ScanNewChildItems *ScanNewChildItems `json:"scanNewChildItems" yaml:"scanNewChildItems"`
+
+	// HashSet tags or excludes items matching lists of known hashes
+	HashSet *HashSet `json:"hashSet" yaml:"hashSet"`
+
+	// Archive copies and verifies the single-case to an archive-share
+	Archive *Archive `json:"archive" yaml:"archive"`
 }
 
 type StageResponse struct {
 	Stage Stage `json:"stage" yaml:"stage"`
 }
 
+// CheckpointRequest is the input-object for reporting a checkpoint for a stage
+type CheckpointRequest struct {
+	Runner string `json:"runner" yaml:"runner"`
+
+	StageID uint `json:"stageID" yaml:"stageID"`
+
+	// Checkpoint for the stage (JSON)
+	Checkpoint string `json:"checkpoint" yaml:"checkpoint"`
+}
+
+// CheckpointResponse is the output-object for reporting a checkpoint for a stage
+type CheckpointResponse struct {
+}
+
 // RunnerStartRequest is the input-object for starting a runner by id
 type RunnerStartRequest struct {
 	ID uint `json:"id" yaml:"id"`
@@ -1882,6 +3518,7 @@ type RunnerStartResponse struct {
 }
 
 type UploadFileRequest struct {
+	// Name of the file (without a path)
 	Name string `json:"name" yaml:"name"`
 
 	Description string `json:"description" yaml:"description"`
@@ -1893,6 +3530,118 @@ type UploadFileResponse struct {
 	Path string `json:"path" yaml:"path"`
 }
 
+// Webhook is a subscription for the lifecycle-events, the events are sent as
+// signed JSON-POSTs to the URL
+type Webhook struct {
+	datastore.Base
+
+	// Name for the webhook
+	Name string `json:"name" yaml:"name"`
+
+	// Endpoint is the URL to POST the events to
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+
+	// Events to send (comma-separated), all events if empty
+	Events string `json:"events" yaml:"events"`
+
+	// Runner to send the events for, all runners if empty
+	Runner string `json:"runner" yaml:"runner"`
+
+	// Secret to sign the events with (HMAC-SHA256)
+	Secret string `json:"secret" yaml:"secret"`
+}
+
+// WebhookDelivery is the delivery of an event to a webhook
+type WebhookDelivery struct {
+	datastore.Base
+
+	// WebhookID foreign-key for webhook-table
+	WebhookID uint `json:"webhookID" yaml:"webhookID"`
+
+	// Event that was delivered (e.g runner.started)
+	Event string `json:"event" yaml:"event"`
+
+	// Runner the event belongs to
+	Runner string `json:"runner" yaml:"runner"`
+
+	// Payload is the JSON-body for the event
+	Payload string `json:"payload" yaml:"payload"`
+
+	// Attempts to deliver the event
+	Attempts int64 `json:"attempts" yaml:"attempts"`
+
+	// StatusCode for the last attempt (0 if there was no response)
+	StatusCode int64 `json:"statusCode" yaml:"statusCode"`
+
+	// Exception for the last attempt
+	Exception string `json:"exception" yaml:"exception"`
+
+	// Delivered is true when the webhook responded with 2xx
+	Delivered bool `json:"delivered" yaml:"delivered"`
+
+	// NextAttemptAt is when the delivery is retried, nil when it is delivered or has
+	// no attempts left
+	NextAttemptAt *time.Time `json:"nextAttemptAt" yaml:"nextAttemptAt"`
+}
+
+// WebhookAddRequest is the input-object for adding a webhook
+type WebhookAddRequest struct {
+	// Name for the webhook
+	Name string `json:"name" yaml:"name"`
+
+	// Endpoint is the URL to POST the events to (http or https)
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+
+	// Events to send (e.g runner.started, stage.failed), all events if empty
+	Events []string `json:"events" yaml:"events"`
+
+	// Runner to send the events for, all runners if empty
+	Runner string `json:"runner" yaml:"runner"`
+
+	// Secret to sign the events with, generated if empty
+	Secret string `json:"secret" yaml:"secret"`
+}
+
+// WebhookAddResponse is the output-object for adding a webhook
+type WebhookAddResponse struct {
+	// Webhook that was added (with its secret)
+	Webhook Webhook `json:"webhook" yaml:"webhook"`
+}
+
+// WebhookListRequest is the input-object for listing the webhooks
+type WebhookListRequest struct {
+}
+
+// WebhookListResponse is the output-object for listing the webhooks
+type WebhookListResponse struct {
+	Webhooks []Webhook `json:"webhooks" yaml:"webhooks"`
+}
+
+// WebhookDeleteRequest is the input-object for deleting a webhook
+type WebhookDeleteRequest struct {
+	// Name of the webhook
+	Name string `json:"name" yaml:"name"`
+}
+
+// WebhookDeleteResponse is the output-object for deleting a webhook
+type WebhookDeleteResponse struct {
+}
+
+// WebhookDeliveriesRequest is the input-object for the delivery-log for a webhook
+type WebhookDeliveriesRequest struct {
+	// Name of the webhook
+	Name string `json:"name" yaml:"name"`
+
+	// Limit for the amount of deliveries (the latest first), 50 if 0
+	Limit int64 `json:"limit" yaml:"limit"`
+}
+
+// WebhookDeliveriesResponse is the output-object for the delivery-log for a
+// webhook
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries" yaml:"deliveries"`
+}
+
 type ScanNewChildItems struct {
 	datastore.Base
 
@@ -1982,11 +3731,63 @@ type ServerApplyResponse struct {
 
 // ServerListRequest is the input-object for List in the server-service.
 type ServerListRequest struct {
+	// Hostname - only list the servers with hostnames matching the pattern (* matches
+	// any characters).
+	Hostname string `json:"hostname" yaml:"hostname"`
+
+	// Active - only list the active (or inactive) servers.
+	Active *bool `json:"active" yaml:"active"`
+
+	// Sort - the field to sort the servers by (id, hostname or created), prefixed with
+	// - for descending.
+	Sort string `json:"sort" yaml:"sort"`
+
+	// Limit - the amount of servers to list (every server if 0).
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// Cursor - the NextCursor from the previous response to list the next page.
+	Cursor string `json:"cursor" yaml:"cursor"`
 }
 
 // ServerListResponse is the output-object for List in the server-service.
 type ServerListResponse struct {
 	Servers []Server `json:"servers" yaml:"servers"`
+
+	// NextCursor - the cursor for the next page, empty if there are no more servers.
+	NextCursor string `json:"nextCursor" yaml:"nextCursor"`
+}
+
+// ServerGetRequest is the input-object for Get in the server-service.
+type ServerGetRequest struct {
+	// Hostname of the server.
+	Hostname string `json:"hostname" yaml:"hostname"`
+
+	// Limit - the amount of runners to get, the latest first (defaults to 20).
+	Limit int64 `json:"limit" yaml:"limit"`
+}
+
+// ServerGetResponse is the output-object for Get in the server-service.
+type ServerGetResponse struct {
+	Server Server `json:"server" yaml:"server"`
+
+	// Runners that uses the server (the latest first).
+	Runners []Runner `json:"runners" yaml:"runners"`
+
+	// TotalRunners - the amount of runners that uses the server.
+	TotalRunners int64 `json:"totalRunners" yaml:"totalRunners"`
+}
+
+// ServerDeleteRequest is the input-object for Delete in the server-service.
+type ServerDeleteRequest struct {
+	// Hostname of the server.
+	Hostname string `json:"hostname" yaml:"hostname"`
+
+	// Force - delete the server even if unfinished runners uses it.
+	Force bool `json:"force" yaml:"force"`
+}
+
+// ServerDeleteResponse is the output-object for Delete in the server-service.
+type ServerDeleteResponse struct {
 }
 
 type SyncDescendants struct {
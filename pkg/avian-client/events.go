@@ -0,0 +1,139 @@
+package avian
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+)
+
+// reconnectDelay is the delay before the event-stream is reconnected
+const reconnectDelay = 2 * time.Second
+
+// EventsRequest is the request for the event-stream
+type EventsRequest struct {
+	// Runner to get the events for, all runners if empty
+	Runner string
+
+	// Types of the events to get (see events.TypeRunner,
+	// events.TypeStage and events.TypeLog), all types if empty
+	Types []string
+
+	// History sends the events kept by the service before the new events
+	History bool
+
+	// Follow keeps the stream open, it is reconnected if it closes
+	Follow bool
+}
+
+// Events streams the events for the runners (Server-Sent Events) to
+// fn until the context is done or fn returns an error. When Follow
+// is false the stream ends after the history. The stream resumes
+// from the last event when it is reconnected.
+func (s *RunnerService) Events(ctx context.Context, r EventsRequest, fn func(events.Event) error) error {
+	since := int64(-1)
+	if r.History {
+		since = 0
+	}
+	for {
+		var (
+			retry bool
+			err   error
+		)
+		since, retry, err = s.streamEvents(ctx, r, since, fn)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !retry || !r.Follow {
+			return err
+		}
+		s.client.Debug(fmt.Sprintf("reconnecting event-stream after: %v", err))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// streamEvents reads the event-stream after the id since, it returns the id
+// for the last event and if the stream can be reconnected (after an error
+// or when the service closed the stream)
+func (s *RunnerService) streamEvents(ctx context.Context, r EventsRequest, since int64, fn func(events.Event) error) (int64, bool, error) {
+	query := url.Values{}
+	if r.Runner != "" {
+		query.Set("runner", r.Runner)
+	}
+	if len(r.Types) != 0 {
+		query.Set("type", strings.Join(r.Types, ","))
+	}
+	if since >= 0 {
+		query.Set("since", strconv.FormatInt(since, 10))
+	}
+	if !r.Follow {
+		query.Set("follow", "false")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.client.RemoteHost+"RunnerService.Events?"+query.Encode(), nil)
+	if err != nil {
+		return since, false, fmt.Errorf("RunnerService.Events: NewRequest: %w", err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return since, true, fmt.Errorf("RunnerService.Events: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		var response struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(body, &response); err == nil && response.Error != "" {
+			return since, false, errors.New(response.Error)
+		}
+		return since, false, fmt.Errorf("RunnerService.Events: %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data:") {
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			continue
+		}
+		if line != "" || len(data) == 0 {
+			// the id and the event-type are also in the data, comments are ignored
+			continue
+		}
+
+		var e events.Event
+		if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &e); err != nil {
+			return since, false, fmt.Errorf("RunnerService.Events: invalid event: %w", err)
+		}
+		data = data[:0]
+		since = e.ID
+		if err := fn(e); err != nil {
+			return since, false, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return since, true, fmt.Errorf("RunnerService.Events: %w", err)
+	}
+	return since, true, nil
+}
@@ -1,13 +1,20 @@
 package avian
 
-import api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+)
 
 const (
 	StatusWaiting  int64 = 0
 	StatusRunning  int64 = 1
 	StatusFailed   int64 = 2
 	StatusFinished int64 = 3
-	StatusTimeout        = 4
+	StatusTimeout  int64 = 4
 )
 
 func Status(status int64) string { return getStatus(status) }
@@ -31,277 +38,94 @@ func getStatus(status int64) string {
 	return "Unknown"
 }
 
-func StageState(s *api.Stage) int64 {
-	if s.Process != nil {
-		return s.Process.Status
-	}
-
-	if s.SearchAndTag != nil {
-		return s.SearchAndTag.Status
-	}
-
-	if s.Ocr != nil {
-		return s.Ocr.Status
-	}
-
-	if s.Exclude != nil {
-		return s.Exclude.Status
-	}
-
-	if s.Reload != nil {
-		return s.Reload.Status
-	}
-
-	if s.Populate != nil {
-		return s.Populate.Status
-	}
-
-	if s.InApp != nil {
-		return s.InApp.Status
-	}
-
-	if s.SyncDescendants != nil {
-		return s.SyncDescendants.Status
+// ParseStatus returns the status for the name
+// (case-insensitive) - the reverse of Status
+func ParseStatus(name string) (int64, error) {
+	for _, status := range []int64{StatusWaiting, StatusRunning, StatusFailed, StatusFinished, StatusTimeout} {
+		if strings.EqualFold(name, getStatus(status)) {
+			return status, nil
+		}
 	}
+	return 0, fmt.Errorf("unknown status: %s - use waiting, running, failed, finished or timeout", name)
+}
 
-	if s.ScanNewChildItems != nil {
-		return s.ScanNewChildItems.Status
+// StageState returns the status of the stage
+func StageState(s *api.Stage) int64 {
+	if kind := s.Kind(); kind != nil {
+		return kind.Status(s)
 	}
-
 	return 0
 }
 
-func (s *Stage) Status() string {
-	if s.Process != nil {
-		return getStatus(s.Process.Status)
+// Status returns the status of the stage as a string
+func (s *Stage) Status() (string, error) {
+	stage, err := s.api()
+	if err != nil {
+		return "", err
 	}
-
-	if s.SearchAndTag != nil {
-		return getStatus(s.SearchAndTag.Status)
-	}
-
-	if s.Ocr != nil {
-		return getStatus(s.Ocr.Status)
-	}
-
-	if s.Exclude != nil {
-		return getStatus(s.Exclude.Status)
+	if kind := stage.Kind(); kind != nil {
+		return getStatus(kind.Status(stage)), nil
 	}
-
-	if s.Reload != nil {
-		return getStatus(s.Reload.Status)
-	}
-
-	if s.Populate != nil {
-		return getStatus(s.Populate.Status)
-	}
-
-	if s.InApp != nil {
-		return getStatus(s.InApp.Status)
-	}
-
-	if s.SyncDescendants != nil {
-		return getStatus(s.SyncDescendants.Status)
-	}
-
-	if s.ScanNewChildItems != nil {
-		return getStatus(s.ScanNewChildItems.Status)
-	}
-
-	return "Unknown"
+	return "Unknown", nil
 }
 
+// Name returns the name of the stage
 func Name(s *api.Stage) string {
-	if s.Process != nil {
-		return "Process"
-	}
-
-	if s.SearchAndTag != nil {
-		return "SearchAndTag"
+	if kind := s.Kind(); kind != nil {
+		return kind.Name(s)
 	}
-
-	if s.Ocr != nil {
-		return "OCR"
-	}
-
-	if s.Exclude != nil {
-		return "Exclude"
-	}
-
-	if s.Reload != nil {
-		return "Reload"
-	}
-
-	if s.Populate != nil {
-		return "Populate"
-	}
-
-	if s.InApp != nil {
-		return "InApp-" + s.InApp.Name
-	}
-
-	if s.SyncDescendants != nil {
-		return "SyncDescendants"
-	}
-
-	if s.ScanNewChildItems != nil {
-		return "ScanNewChildItems"
-	}
-
 	return "Unknown"
 }
 
-func (s *Stage) Name() string {
-	if s.Process != nil {
-		return "Process"
-	}
-
-	if s.SearchAndTag != nil {
-		return "SearchAndTag"
-	}
-
-	if s.Ocr != nil {
-		return "OCR"
-	}
-
-	if s.Exclude != nil {
-		return "Exclude"
+// Name returns the name of the stage
+func (s *Stage) Name() (string, error) {
+	stage, err := s.api()
+	if err != nil {
+		return "", err
 	}
+	return Name(stage), nil
+}
 
-	if s.Reload != nil {
-		return "Reload"
-	}
+func Finished(status int64) bool { return status == StatusFinished }
 
-	if s.Populate != nil {
-		return "Populate"
-	}
+func SetStatusRunning(stage *api.Stage) { setStatus(stage, StatusRunning) }
 
-	if s.InApp != nil {
-		return "InApp-" + s.InApp.Name
-	}
+func SetStatusFailed(stage *api.Stage) { setStatus(stage, StatusFailed) }
 
-	if s.SyncDescendants != nil {
-		return "SyncDescendants"
-	}
+func SetStatusFinished(stage *api.Stage) { setStatus(stage, StatusFinished) }
 
-	if s.ScanNewChildItems != nil {
-		return "ScanNewChildItems"
-	}
+func SetStatusTimeout(stage *api.Stage) { setStatus(stage, StatusTimeout) }
 
-	return "Unknown"
-}
-
-func Finished(status int64) bool { return status == StatusFinished }
-
-func SetStatusRunning(stage *api.Stage) {
-	if stage.Process != nil {
-		stage.Process.Status = StatusRunning
-	} else if stage.SearchAndTag != nil {
-		stage.SearchAndTag.Status = StatusRunning
-	} else if stage.Reload != nil {
-		stage.Reload.Status = StatusRunning
-	} else if stage.Exclude != nil {
-		stage.Exclude.Status = StatusRunning
-	} else if stage.Populate != nil {
-		stage.Populate.Status = StatusRunning
-	} else if stage.Ocr != nil {
-		stage.Ocr.Status = StatusRunning
-	} else if stage.InApp != nil {
-		stage.InApp.Status = StatusRunning
-	} else if stage.SyncDescendants != nil {
-		stage.SyncDescendants.Status = StatusRunning
-	} else if stage.ScanNewChildItems != nil {
-		stage.ScanNewChildItems.Status = StatusRunning
+func setStatus(stage *api.Stage, status int64) {
+	if kind := stage.Kind(); kind != nil {
+		kind.SetStatus(stage, status)
 	}
-	return
 }
 
-func SetStatusFailed(stage *api.Stage) {
-	if stage.Process != nil {
-		stage.Process.Status = StatusFailed
-	} else if stage.SearchAndTag != nil {
-		stage.SearchAndTag.Status = StatusFailed
-	} else if stage.Reload != nil {
-		stage.Reload.Status = StatusFailed
-	} else if stage.Exclude != nil {
-		stage.Exclude.Status = StatusFailed
-	} else if stage.Populate != nil {
-		stage.Populate.Status = StatusFailed
-	} else if stage.Ocr != nil {
-		stage.Ocr.Status = StatusFailed
-	} else if stage.InApp != nil {
-		stage.InApp.Status = StatusFailed
-	} else if stage.SyncDescendants != nil {
-		stage.SyncDescendants.Status = StatusFailed
-	} else if stage.ScanNewChildItems != nil {
-		stage.ScanNewChildItems.Status = StatusFailed
-	}
-}
+func HasFinished(s *api.Stage) bool { return Finished(StageState(s)) }
 
-func SetStatusFinished(stage *api.Stage) {
-	if stage.Process != nil {
-		stage.Process.Status = StatusFinished
-	} else if stage.SearchAndTag != nil {
-		stage.SearchAndTag.Status = StatusFinished
-	} else if stage.Reload != nil {
-		stage.Reload.Status = StatusFinished
-	} else if stage.Exclude != nil {
-		stage.Exclude.Status = StatusFinished
-	} else if stage.Populate != nil {
-		stage.Populate.Status = StatusFinished
-	} else if stage.Ocr != nil {
-		stage.Ocr.Status = StatusFinished
-	} else if stage.InApp != nil {
-		stage.InApp.Status = StatusFinished
-	} else if stage.SyncDescendants != nil {
-		stage.SyncDescendants.Status = StatusFinished
-	} else if stage.ScanNewChildItems != nil {
-		stage.ScanNewChildItems.Status = StatusFinished
+// Nil returns true if the stage has no registered stage-type
+func (s *Stage) Nil() (bool, error) {
+	stage, err := s.api()
+	if err != nil {
+		return false, err
 	}
+	return stage.Kind() == nil, nil
 }
 
-func HasFinished(s *api.Stage) bool {
-	if s.Process != nil {
-		return Finished(s.Process.Status)
-	} else if s.SearchAndTag != nil {
-		return Finished(s.SearchAndTag.Status)
-	} else if s.Reload != nil {
-		return Finished(s.Reload.Status)
-	} else if s.Exclude != nil {
-		return Finished(s.Exclude.Status)
-	} else if s.Populate != nil {
-		return Finished(s.Populate.Status)
-	} else if s.Ocr != nil {
-		return Finished(s.Ocr.Status)
-	} else if s.InApp != nil {
-		return Finished(s.InApp.Status)
-	} else if s.SyncDescendants != nil {
-		return Finished(s.SyncDescendants.Status)
-	} else if s.ScanNewChildItems != nil {
-		return Finished(s.ScanNewChildItems.Status)
-	}
-	return false
-}
-
-func (s *Stage) Nil() bool {
-	if s.Process != nil {
-		return false
-	} else if s.SearchAndTag != nil {
-		return false
-	} else if s.Reload != nil {
-		return false
-	} else if s.Exclude != nil {
-		return false
-	} else if s.Populate != nil {
-		return false
-	} else if s.Ocr != nil {
-		return false
-	} else if s.InApp != nil {
-		return false
-	} else if s.SyncDescendants != nil {
-		return false
-	} else if s.ScanNewChildItems != nil {
-		return false
-	}
-	return true
+// api converts the stage to the api-type, to look up its stage-type
+// in the registry - a field that the api-type doesn't have is an
+// error, so the types cannot drift apart without it being noticed
+func (s *Stage) api() (*api.Stage, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode stage: %d - %v", s.ID, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	var stage api.Stage
+	if err := decoder.Decode(&stage); err != nil {
+		return nil, fmt.Errorf("failed to convert stage: %d - %v", s.ID, err)
+	}
+	return &stage, nil
 }
@@ -0,0 +1,40 @@
+package avian_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/matryer/is"
+)
+
+func TestStage(t *testing.T) {
+	is := is.New(t)
+
+	stage := avian.Stage{Ocr: &avian.Ocr{Status: avian.StatusRunning}}
+	name, err := stage.Name()
+	is.NoErr(err)
+	is.Equal(name, "OCR")
+	status, err := stage.Status()
+	is.NoErr(err)
+	is.Equal(status, "Running")
+	unknown, err := stage.Nil()
+	is.NoErr(err)
+	is.True(!unknown)
+
+	unknown, err = (&avian.Stage{}).Nil()
+	is.NoErr(err)
+	is.True(unknown)
+
+	// every field for the stage and its stage-types
+	// in the client is known by the api-type
+	stage = avian.Stage{}
+	v := reflect.ValueOf(&stage).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Field(i); f.Kind() == reflect.Ptr && f.Type().Elem().Kind() == reflect.Struct {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+	}
+	_, err = stage.Nil()
+	is.NoErr(err)
+}
@@ -0,0 +1,32 @@
+package avian
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+)
+
+// CABundleEnv is the env-variable with the path to the
+// CA-bundle (PEM) the CLI verifies the service against
+const CABundleEnv = "AVIAN_CA_BUNDLE"
+
+// SetCA makes the client verify the service
+// against the certificates in the CA-bundle (PEM)
+func (c *Client) SetCA(caPEM []byte) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return errors.New("no certificates found in CA-bundle")
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	if t, ok := c.HTTPClient.Transport.(keyTransport); ok {
+		t.base = base
+		c.HTTPClient.Transport = t
+		return nil
+	}
+	c.HTTPClient.Transport = base
+	return nil
+}
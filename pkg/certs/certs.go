@@ -0,0 +1,220 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	caName      = "avian-ca.pem"
+	caKeyName   = "avian-ca-key.pem"
+	certName    = "avian-cert.pem"
+	certKeyName = "avian-key.pem"
+
+	caValidFor   = 10 * 365 * 24 * time.Hour
+	certValidFor = 2 * 365 * 24 * time.Hour
+
+	// renewBefore is when the server-certificate is renewed before it expires
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// Files holds the paths to the certificates for the service
+type Files struct {
+	// Cert is the server-certificate (PEM)
+	Cert string
+
+	// Key is the private key for the server-certificate (PEM)
+	Key string
+
+	// CA is the certificate that signed the server-certificate (PEM),
+	// the servers and the CLI verifies the service against it
+	CA string
+}
+
+// SelfSigned returns a self-signed CA and a server-certificate
+// signed by it for the hosts (hostnames or ip-addresses) in dir.
+// The CA is created once and kept, so the servers and the CLIs
+// keep trusting the service. The server-certificate is renewed when
+// it is about to expire or when it does not cover the hosts.
+func SelfSigned(dir string, hosts []string) (Files, error) {
+	files := Files{
+		Cert: filepath.Join(dir, certName),
+		Key:  filepath.Join(dir, certKeyName),
+		CA:   filepath.Join(dir, caName),
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return files, fmt.Errorf("failed to create dir for certificates: %v", err)
+	}
+
+	ca, caKey, err := loadOrCreateCA(files.CA, filepath.Join(dir, caKeyName))
+	if err != nil {
+		return files, err
+	}
+
+	if valid(files, ca, hosts, time.Now()) {
+		return files, nil
+	}
+
+	if err := createCert(files, ca, caKey, hosts); err != nil {
+		return files, err
+	}
+	return files, nil
+}
+
+// Pool returns a cert-pool with the certificates in the PEM
+func Pool(caPEM []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("no certificates found in CA-bundle")
+	}
+	return pool, nil
+}
+
+// loadOrCreateCA loads the CA from the files, it is created if it does not exist
+func loadOrCreateCA(certFile, keyFile string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	if pair, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+		ca, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse CA-certificate: %s - %v", certFile, err)
+		}
+		key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported key for CA-certificate: %s", keyFile)
+		}
+		if time.Now().After(ca.NotAfter) {
+			return nil, nil, fmt.Errorf("CA-certificate: %s has expired - remove it to create a new", certFile)
+		}
+		return ca, key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to load CA-certificate: %s - %v", certFile, err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key for CA: %v", err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Avian"}, CommonName: "Avian CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidFor),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create CA-certificate: %v", err)
+	}
+	if err := writePEM(certFile, keyFile, der, key); err != nil {
+		return nil, nil, err
+	}
+
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CA-certificate: %v", err)
+	}
+	return ca, key, nil
+}
+
+// valid returns true if the server-certificate in the files is signed
+// by the CA, covers the hosts and does not expire within renewBefore
+func valid(files Files, ca *x509.Certificate, hosts []string, now time.Time) bool {
+	pair, err := tls.LoadX509KeyPair(files.Cert, files.Key)
+	if err != nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false
+	}
+	if now.Add(renewBefore).After(cert.NotAfter) || cert.CheckSignatureFrom(ca) != nil {
+		return false
+	}
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// createCert creates a server-certificate for the hosts signed by the CA
+func createCert(files Files, ca *x509.Certificate, caKey *ecdsa.PrivateKey, hosts []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key for certificate: %v", err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"Avian"}, CommonName: "avian-service"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidFor),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("failed to create certificate: %v", err)
+	}
+	return writePEM(files.Cert, files.Key, der, key)
+}
+
+// writePEM writes the certificate and the key to the files
+func writePEM(certFile, keyFile string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %v", err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return fmt.Errorf("failed to write key: %s - %v", keyFile, err)
+	}
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return fmt.Errorf("failed to write certificate: %s - %v", certFile, err)
+	}
+	return nil
+}
+
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial-number: %v", err)
+	}
+	return serial, nil
+}
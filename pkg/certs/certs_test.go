@@ -0,0 +1,83 @@
+package certs_test
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/certs"
+	"github.com/matryer/is"
+)
+
+func TestSelfSigned(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "avian-certs")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+
+	files, err := certs.SelfSigned(dir, []string{"localhost", "127.0.0.1"})
+	is.NoErr(err)
+	ca := read(t, files.CA)
+	cert := read(t, files.Cert)
+
+	// the certificates are reused
+	again, err := certs.SelfSigned(dir, []string{"localhost", "127.0.0.1"})
+	is.NoErr(err)
+	is.Equal(files, again)
+	is.Equal(read(t, again.Cert), cert)
+
+	// a new host renews the certificate but keeps the CA
+	renewed, err := certs.SelfSigned(dir, []string{"localhost", "127.0.0.1", "avian.local"})
+	is.NoErr(err)
+	is.Equal(read(t, renewed.CA), ca)
+	is.True(read(t, renewed.Cert) != cert)
+
+	_, err = certs.Pool([]byte("not a certificate"))
+	is.True(err != nil)
+}
+
+func TestClientWithCA(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "avian-certs")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+
+	files, err := certs.SelfSigned(dir, []string{"127.0.0.1"})
+	is.NoErr(err)
+	pair, err := tls.LoadX509KeyPair(files.Cert, files.Key)
+	is.NoErr(err)
+
+	var authorization string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"nms": []}`))
+	}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{pair}}
+	srv.StartTLS()
+	defer srv.Close()
+
+	// without the CA the certificate is not trusted
+	client := avian.NewWithKey(srv.URL+"/oto/", "avian_key")
+	_, err = avian.NewNmsService(client).List(context.Background(), avian.NmsListRequest{})
+	is.True(err != nil)
+
+	// with the CA the service is verified (and the key is still sent)
+	is.NoErr(client.SetCA([]byte(read(t, files.CA))))
+	_, err = avian.NewNmsService(client).List(context.Background(), avian.NmsListRequest{})
+	is.NoErr(err)
+	is.Equal(authorization, "Bearer avian_key")
+}
+
+func read(t *testing.T, path string) string {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
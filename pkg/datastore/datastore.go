@@ -20,9 +20,9 @@ func (b *Base) BeforeCreate(scope *gorm.Scope) error {
 	return nil
 }
 
-// BeforeSave sets CTime and MTime to current unix-timestamp
+// BeforeSave sets MTime to current unix-timestamp
+// (CTime is only set when the record is created)
 func (b *Base) BeforeSave(scope *gorm.Scope) (err error) {
-	scope.SetColumn("CTime", time.Now().Unix())
 	scope.SetColumn("MTime", time.Now().Unix())
 	return nil
 }
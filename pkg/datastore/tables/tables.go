@@ -2,14 +2,16 @@ package tables
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
 	"github.com/jinzhu/gorm"
 )
 
 // Migrate the db-tables
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	models := []interface{}{
 		&api.Nms{},
 		&api.Licence{},
 		&api.Server{},
@@ -18,20 +20,25 @@ func Migrate(db *gorm.DB) error {
 		&api.CaseSettings{},
 		&api.Case{},
 		&api.Elasticsearch{},
-		&api.Evidence{},
 		&api.Stage{},
-		&api.Process{},
-		&api.SearchAndTag{},
-		&api.Exclude{},
-		&api.Populate{},
-		&api.Reload{},
-		&api.Ocr{},
-		&api.File{},
-		&api.Type{},
-		&api.InApp{},
-		&api.SyncDescendants{},
-		&api.ScanNewChildItems{},
-	).Error
+		&api.CustodyRecord{},
+		&api.ScriptArchive{},
+		&api.ScriptArgument{},
+		&api.Webhook{},
+		&api.WebhookDelivery{},
+		&api.AuditEntry{},
+		&auth.Key{},
+	}
+	return db.AutoMigrate(append(models, api.StageModels()...)...).Error
+}
+
+// PreloadStages preloads the stages for all the
+// stage-types, prefix is the path to the stage (e.g Stages.)
+func PreloadStages(db *gorm.DB, prefix string) *gorm.DB {
+	for _, preload := range api.StagePreloads(prefix) {
+		db = db.Preload(preload)
+	}
+	return db
 }
 
 // Index the tables
@@ -50,5 +57,35 @@ func Index(db *gorm.DB) error {
 	if err := db.Model(&api.Runner{}).AddIndex("idx_runner_name", "name").Error; err != nil {
 		return fmt.Errorf("unable to add index to server-hostname")
 	}
+
+	// add index to custody-record evidence
+	if err := db.Model(&api.CustodyRecord{}).AddIndex("idx_custody_record_evidence", "evidence_id").Error; err != nil {
+		return fmt.Errorf("unable to add index to custody-record evidence")
+	}
+
+	// add index to webhook-name
+	if err := db.Model(&api.Webhook{}).AddIndex("idx_webhook_name", "name").Error; err != nil {
+		return fmt.Errorf("unable to add index to webhook-name")
+	}
+
+	// add index to the webhook for the deliveries
+	if err := db.Model(&api.WebhookDelivery{}).AddIndex("idx_webhook_delivery_webhook", "webhook_id").Error; err != nil {
+		return fmt.Errorf("unable to add index to webhook-delivery webhook")
+	}
+
+	// add index to the time for the audit-trail
+	if err := db.Model(&api.AuditEntry{}).AddIndex("idx_audit_entry_time", "time").Error; err != nil {
+		return fmt.Errorf("unable to add index to audit-entry time")
+	}
+
+	// the audit-trail is append-only, the db refuses
+	// to update or delete the entries
+	for _, op := range []string{"UPDATE", "DELETE"} {
+		trigger := fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS audit_entries_no_%s BEFORE %s ON audit_entries
+BEGIN SELECT RAISE(ABORT, 'the audit-trail is append-only'); END`, strings.ToLower(op), op)
+		if err := db.Exec(trigger).Error; err != nil {
+			return fmt.Errorf("unable to make the audit-trail append-only: %v", err)
+		}
+	}
 	return nil
 }
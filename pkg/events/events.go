@@ -0,0 +1,200 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Types for the events
+const (
+	// TypeRunner is a state-change for a runner
+	TypeRunner = "runner"
+
+	// TypeStage is a state-change for a stage
+	TypeStage = "stage"
+
+	// TypeLog is a log-line from a runner-script
+	TypeLog = "log"
+)
+
+// bufferSize is the amount of events that
+// can be queued for a subscriber before it is dropped
+const bufferSize = 256
+
+// Event is a state-change or a log-line for a runner
+type Event struct {
+	// ID for the event, increases for every event
+	ID int64 `json:"id"`
+
+	// Type for the event (runner, stage or log)
+	Type string `json:"type"`
+
+	// Time when the event was published
+	Time time.Time `json:"time"`
+
+	// Runner the event belongs to
+	Runner string `json:"runner"`
+
+	// Status for the runner or the stage
+	Status string `json:"status,omitempty"`
+
+	// StageID for the stage the event belongs to
+	StageID uint `json:"stageID,omitempty"`
+
+	// Stage is the name of the stage
+	Stage string `json:"stage,omitempty"`
+
+	// Level for the log-line (debug, info or error)
+	Level string `json:"level,omitempty"`
+
+	// Message for the log-line
+	Message string `json:"message,omitempty"`
+
+	// Exception for the log-line
+	Exception string `json:"exception,omitempty"`
+}
+
+// Filter for the events to a subscriber
+type Filter struct {
+	// Runner to get the events for, all runners if empty
+	Runner string
+
+	// Types to get, all types if empty
+	Types []string
+}
+
+// Match returns true if the event matches the filter
+func (f Filter) Match(e Event) bool {
+	if f.Runner != "" && f.Runner != e.Runner {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// Broker publishes the events to the subscribers, the latest
+// events are kept so the subscribers can resume after a reconnect
+type Broker struct {
+	mu      sync.Mutex
+	lastID  int64
+	history []Event
+	size    int
+	subs    map[chan Event]Filter
+}
+
+// NewBroker returns a broker that keeps the latest size events
+func NewBroker(size int) *Broker {
+	return &Broker{
+		size: size,
+		subs: make(map[chan Event]Filter),
+	}
+}
+
+// Publish sets the id and the time for the event and sends it to the
+// subscribers, a subscriber that does not keep up is dropped (its
+// channel is closed) - it can resume from the history with its last id
+func (b *Broker) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e.ID = b.lastID
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.history = append(b.history, e)
+	if len(b.history) > b.size {
+		b.history = b.history[len(b.history)-b.size:]
+	}
+
+	for ch, filter := range b.subs {
+		if !filter.Match(e) {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return e
+}
+
+// Subscribe returns the events in the history after the id since (none
+// if since is negative) and a channel for the new events that matches the
+// filter. cancel must be called when the subscriber is done.
+func (b *Broker) Subscribe(filter Filter, since int64) (backlog []Event, events <-chan Event, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the ids starts over when the service restarts,
+	// so the whole history is sent for a newer id
+	if since > b.lastID {
+		since = 0
+	}
+	if since >= 0 {
+		for _, e := range b.history {
+			if e.ID > since && filter.Match(e) {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+
+	ch := make(chan Event, bufferSize)
+	b.subs[ch] = filter
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return backlog, ch, cancel
+}
+
+// Follow calls fn for the events that matches the filter until the
+// context is done, the events are handled in order in the calling
+// goroutine. If the subscriber does not keep up with the broker it
+// subscribes again from the last handled event, so no event is missed
+// as long as it is in the history.
+func Follow(ctx context.Context, b *Broker, filter Filter, logger *zap.Logger, fn func(Event)) {
+	since := int64(-1)
+	for {
+		backlog, ch, cancel := b.Subscribe(filter, since)
+		for _, e := range backlog {
+			fn(e)
+			since = e.ID
+		}
+
+		closed := false
+		for !closed {
+			select {
+			case <-ctx.Done():
+				cancel()
+				return
+			case e, ok := <-ch:
+				if !ok {
+					logger.Warn("Resubscribing to the events, the subscriber did not keep up", zap.Int64("since", since))
+					closed = true
+					continue
+				}
+				fn(e)
+				since = e.ID
+			}
+		}
+		cancel()
+	}
+}
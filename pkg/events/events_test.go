@@ -0,0 +1,135 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestBroker(t *testing.T) {
+	is := is.New(t)
+	broker := events.NewBroker(3)
+
+	broker.Publish(events.Event{Type: events.TypeRunner, Runner: "a", Status: "Running"})
+	broker.Publish(events.Event{Type: events.TypeLog, Runner: "b", Message: "1"})
+
+	// the history is filtered by runner and type
+	backlog, ch, cancel := broker.Subscribe(events.Filter{Runner: "a"}, 0)
+	is.Equal(len(backlog), 1)
+	is.Equal(backlog[0].ID, int64(1))
+
+	e := broker.Publish(events.Event{Type: events.TypeLog, Runner: "a", Message: "2"})
+	is.Equal(e.ID, int64(3))
+	is.True(!e.Time.IsZero())
+	broker.Publish(events.Event{Type: events.TypeLog, Runner: "b", Message: "3"})
+	is.Equal((<-ch).Message, "2")
+	cancel()
+	_, ok := <-ch
+	is.True(!ok) // closed by cancel
+	cancel()     // and cancel can be called again
+
+	// only the latest events are kept
+	backlog, _, cancel = broker.Subscribe(events.Filter{Types: []string{events.TypeLog}}, 0)
+	defer cancel()
+	is.Equal(len(backlog), 3)
+	is.Equal(backlog[0].ID, int64(2))
+
+	// the history after an id
+	backlog, _, cancel = broker.Subscribe(events.Filter{}, 3)
+	defer cancel()
+	is.Equal(len(backlog), 1)
+	is.Equal(backlog[0].Message, "3")
+
+	// no history for a negative id
+	backlog, _, cancel = broker.Subscribe(events.Filter{}, -1)
+	defer cancel()
+	is.Equal(len(backlog), 0)
+
+	// an id from before a restart sends the history
+	backlog, _, cancel = broker.Subscribe(events.Filter{}, 100)
+	defer cancel()
+	is.Equal(len(backlog), 3)
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	is := is.New(t)
+	broker := events.NewBroker(1000)
+
+	_, ch, cancel := broker.Subscribe(events.Filter{}, -1)
+	defer cancel()
+	for i := 0; i < 300; i++ {
+		broker.Publish(events.Event{Type: events.TypeLog, Runner: "a"})
+	}
+
+	// the subscriber is dropped when its buffer is full
+	var received int
+	for range ch {
+		received++
+	}
+	is.True(received > 0 && received < 300)
+}
+
+func TestFollow(t *testing.T) {
+	is := is.New(t)
+	broker := events.NewBroker(1000)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the subscriber is blocked on the first event it handles
+	started := make(chan struct{})
+	block := make(chan struct{})
+	received := make(chan int64, 1000)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		first := true
+		events.Follow(ctx, broker, events.Filter{Types: []string{events.TypeRunner}}, zap.NewNop(), func(e events.Event) {
+			if first {
+				first = false
+				close(started)
+				<-block
+			}
+			received <- e.ID
+		})
+	}()
+	for subscribed := false; !subscribed; {
+		broker.Publish(events.Event{Type: events.TypeRunner, Runner: "a"})
+		select {
+		case <-started:
+			subscribed = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	// while the broker drops it, it resumes from its last event
+	var published []int64
+	for i := 0; i < 300; i++ {
+		published = append(published, broker.Publish(events.Event{Type: events.TypeRunner, Runner: "a"}).ID)
+		broker.Publish(events.Event{Type: events.TypeLog, Runner: "a"})
+	}
+	close(block)
+
+	// every runner-event is handled once and in order
+	var last int64
+	handled := make(map[int64]bool)
+	for last < published[len(published)-1] {
+		select {
+		case id := <-received:
+			is.True(id > last)
+			handled[id] = true
+			last = id
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the events after: %d were not handled", last)
+		}
+	}
+	for _, id := range published {
+		is.True(handled[id])
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// keepAlive is how often a comment is sent on an idle stream
+const keepAlive = 15 * time.Second
+
+// Handler returns a handler that streams the events as
+// Server-Sent Events (text/event-stream) for GET-requests.
+//
+// The query-parameters filters the events:
+//
+//	runner - name of the runner
+//	type   - types of the events (comma-separated)
+//	since  - send the events after the id from the history first
+//	         (the Last-Event-ID header is used when reconnecting)
+//	follow - false ends the stream after the history
+func Handler(b *Broker, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+		filter := Filter{Runner: query.Get("runner")}
+		if types := query.Get("type"); types != "" {
+			filter.Types = strings.Split(types, ",")
+		}
+
+		since := int64(-1)
+		if id := r.Header.Get("Last-Event-ID"); id != "" {
+			since, _ = strconv.ParseInt(id, 10, 64)
+		} else if id := query.Get("since"); id != "" {
+			var err error
+			if since, err = strconv.ParseInt(id, 10, 64); err != nil {
+				http.Error(w, fmt.Sprintf("invalid since: %s", id), http.StatusBadRequest)
+				return
+			}
+		}
+		follow := query.Get("follow") != "false"
+
+		backlog, events, cancel := b.Subscribe(filter, since)
+		defer cancel()
+
+		logger.Debug("Streaming events",
+			zap.String("runner", filter.Runner),
+			zap.Strings("types", filter.Types),
+			zap.Int64("since", since),
+			zap.Bool("follow", follow),
+			zap.String("remote", r.RemoteAddr),
+		)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		for _, e := range backlog {
+			if err := write(w, e); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+		if !follow {
+			return
+		}
+
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			case e, ok := <-events:
+				if !ok {
+					// the subscriber did not keep up,
+					// the client resumes with its last id
+					logger.Debug("Dropped slow subscriber for events", zap.String("remote", r.RemoteAddr))
+					return
+				}
+				if err := write(w, e); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	})
+}
+
+// write writes the event in the format for Server-Sent Events
+func write(w http.ResponseWriter, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
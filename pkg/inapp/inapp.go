@@ -5,7 +5,7 @@ import (
 )
 
 type Settings struct {
-	SettingsFile string `yaml:"settings_file" json:"settings_file,omitempty"`
+	SettingsFile string `yaml:"settings_file" json:"settingsFile,omitempty"`
 }
 
 func Config(path string, cfg *Settings) error {
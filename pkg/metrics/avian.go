@@ -0,0 +1,201 @@
+package metrics
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/jinzhu/gorm"
+)
+
+// The reasons for the heartbeat-timeouts
+const (
+	ReasonHeartbeat    = "heartbeat"
+	ReasonMaxRuntime   = "max_runtime"
+	ReasonStageTimeout = "stage_timeout"
+)
+
+// The metrics for the service that are updated as they happen,
+// the state of the runners, servers and nms is read from the
+// db when the metrics are scraped (see RegisterDB)
+var (
+	// ItemsLogged is the amount of items logged by the runner-scripts
+	// with LogItem (rate(avian_items_logged_total[1m]) is items/second)
+	ItemsLogged = NewCounter("avian_items_logged_total",
+		"Items logged by the runner-scripts (LogItem).")
+
+	// StageDuration is the duration for the stages that
+	// has finished, failed or timed out by stage-type
+	StageDuration = NewHistogram("avian_stage_duration_seconds",
+		"Duration for the stages that has finished, failed or timed out.",
+		[]float64{60, 300, 900, 1800, 3600, 2 * 3600, 4 * 3600, 8 * 3600, 16 * 3600, 24 * 3600, 48 * 3600},
+		"stage", "status")
+
+	// CallbackDuration is the latency for the oto-methods
+	// (the callbacks from the runner-scripts and the CLI)
+	CallbackDuration = NewHistogram("avian_callback_duration_seconds",
+		"Latency for the oto-methods.",
+		DefaultBuckets,
+		"method")
+
+	// HeartbeatTimeouts is the amount of runners the
+	// heartbeat has set to timed out by the reason
+	HeartbeatTimeouts = NewCounter("avian_heartbeat_timeouts_total",
+		"Runners set to timed out by the heartbeat.",
+		"reason")
+
+	// PwshSessionErrors is the amount of powershell-
+	// sessions that could not be created by the server
+	PwshSessionErrors = NewCounter("avian_pwsh_session_errors_total",
+		"Powershell-sessions that could not be created.",
+		"server")
+)
+
+func init() {
+	Default.Register(ItemsLogged, StageDuration, CallbackDuration, HeartbeatTimeouts, PwshSessionErrors)
+}
+
+// ObserveStage observes the duration for the stage if it has
+// finished, failed or timed out (from when it was started)
+func ObserveStage(stage *api.Stage, now time.Time) {
+	kind := stage.Kind()
+	if kind == nil || stage.StartedAt == nil {
+		return
+	}
+	status := avian.StageState(stage)
+	if status != avian.StatusFinished && status != avian.StatusFailed && status != avian.StatusTimeout {
+		return
+	}
+	StageDuration.Observe(now.Sub(*stage.StartedAt).Seconds(), kind.Key(), avian.Status(status))
+}
+
+// otoMethod matches the path for an oto-method
+var otoMethod = regexp.MustCompile(`^/oto/(\w+Service\.\w+)$`)
+
+// Instrument observes the latency for the oto-methods in the handler,
+// requests for unknown methods are not observed (they are not found)
+func Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		match := otoMethod.FindStringSubmatch(r.URL.Path)
+		if match == nil || rec.status == http.StatusNotFound {
+			return
+		}
+		CallbackDuration.Observe(time.Since(start).Seconds(), match[1])
+	})
+}
+
+// statusRecorder records the status for the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// RegisterDB registers the gauges for the runners,
+// servers and nms that are read from the db
+func RegisterDB(r *Registry, db *gorm.DB) {
+	r.Register(
+		NewGaugeFunc("avian_runners",
+			"Runners by status (the queue is the Waiting runners).",
+			[]string{"status"},
+			func() ([]Sample, error) { return runners(db) }),
+		NewGaugeFunc("avian_active_runners",
+			"Active runners by server.",
+			[]string{"server"},
+			func() ([]Sample, error) { return activeRunners(db) }),
+		NewGaugeFunc("avian_licences",
+			"Licence seats by nms and type.",
+			[]string{"nms", "type"},
+			func() ([]Sample, error) { return licences(db, false) }),
+		NewGaugeFunc("avian_licences_in_use",
+			"Licence seats in use by nms and type.",
+			[]string{"nms", "type"},
+			func() ([]Sample, error) { return licences(db, true) }),
+	)
+}
+
+// runners returns the amount of runners by status
+// (every status is returned, also without runners)
+func runners(db *gorm.DB) ([]Sample, error) {
+	var rows []struct {
+		Status int64
+		Amount int64
+	}
+	if err := db.Model(&api.Runner{}).Select("status, count(*) as amount").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	amounts := make(map[string]float64)
+	for _, status := range []int64{avian.StatusWaiting, avian.StatusRunning, avian.StatusFailed, avian.StatusFinished, avian.StatusTimeout} {
+		amounts[avian.Status(status)] = 0
+	}
+	for _, row := range rows {
+		amounts[avian.Status(row.Status)] += float64(row.Amount)
+	}
+
+	var samples []Sample
+	for status, amount := range amounts {
+		samples = append(samples, Sample{Labels: []string{status}, Value: amount})
+	}
+	return samples, nil
+}
+
+// activeRunners returns the amount of active runners by
+// server (every server is returned, also without runners)
+func activeRunners(db *gorm.DB) ([]Sample, error) {
+	var servers []api.Server
+	if err := db.Select("hostname").Find(&servers).Error; err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Hostname string
+		Amount   int64
+	}
+	if err := db.Model(&api.Runner{}).Select("hostname, count(*) as amount").Where("active = ?", true).Group("hostname").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	amounts := make(map[string]float64)
+	for _, server := range servers {
+		amounts[server.Hostname] = 0
+	}
+	for _, row := range rows {
+		amounts[row.Hostname] += float64(row.Amount)
+	}
+
+	var samples []Sample
+	for server, amount := range amounts {
+		samples = append(samples, Sample{Labels: []string{server}, Value: amount})
+	}
+	return samples, nil
+}
+
+// licences returns the amount of seats (or the seats in
+// use) for the licences by the nms and the licence-type
+func licences(db *gorm.DB, inUse bool) ([]Sample, error) {
+	var list []api.Nms
+	if err := db.Preload("Licences").Find(&list).Error; err != nil {
+		return nil, err
+	}
+
+	var samples []Sample
+	for _, nms := range list {
+		for _, licence := range nms.Licences {
+			value := licence.Amount
+			if inUse {
+				value = licence.InUse
+			}
+			samples = append(samples, Sample{Labels: []string{nms.Address, licence.Type}, Value: float64(value)})
+		}
+	}
+	return samples, nil
+}
@@ -0,0 +1,320 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType for the text-format that Prometheus scrapes
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metric is a metric-family that is written in the text-format
+type Metric interface {
+	// Write writes the HELP, the TYPE and the samples for the metric
+	Write(w io.Writer) error
+}
+
+// Registry holds the metrics that are exposed by the Handler
+type Registry struct {
+	mu      sync.Mutex
+	metrics []Metric
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry { return &Registry{} }
+
+// Default is the registry for the metrics of the service
+var Default = NewRegistry()
+
+// Register adds the metrics to the registry
+func (r *Registry) Register(metrics ...Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, metrics...)
+}
+
+// Write writes the metrics in the registry in the text-format
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]Metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		if err := m.Write(bw); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Handler serves the metrics in the registry for Prometheus
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var buf bytes.Buffer
+		if err := r.Write(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		buf.WriteTo(w)
+	})
+}
+
+// Sample is a value for the label-values of a metric
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// desc describes a metric-family
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+// header writes the HELP and the TYPE for the metric
+func (d desc) header(w io.Writer, kind string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escape(d.help, false), d.name, kind)
+	return err
+}
+
+// sample writes a sample for the metric with the label-values
+// (and the extra label for the buckets of a histogram)
+func (d desc) sample(w io.Writer, suffix string, values []string, extra string, value float64) error {
+	var pairs []string
+	for i, label := range d.labels {
+		pairs = append(pairs, label+`="`+escape(values[i], true)+`"`)
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+	labels := ""
+	if len(pairs) > 0 {
+		labels = "{" + strings.Join(pairs, ",") + "}"
+	}
+	_, err := fmt.Fprintf(w, "%s%s%s %s\n", d.name, suffix, labels, format(value))
+	return err
+}
+
+// check panics if the label-values does not match the labels,
+// it is a programming error (like in the Prometheus-client)
+func (d desc) check(values []string) {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", d.name, len(d.labels), len(values)))
+	}
+}
+
+// Counter is a counter with labels
+type Counter struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*Sample
+}
+
+// NewCounter returns a counter with the labels
+func NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{desc: desc{name, help, labels}, values: make(map[string]*Sample)}
+}
+
+// Inc increments the counter for the label-values
+func (c *Counter) Inc(values ...string) { c.Add(1, values...) }
+
+// Add adds v to the counter for the label-values
+func (c *Counter) Add(v float64, values ...string) {
+	c.check(values)
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: %s cannot decrease", c.name))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := key(values)
+	s, ok := c.values[k]
+	if !ok {
+		s = &Sample{Labels: append([]string(nil), values...)}
+		c.values[k] = s
+	}
+	s.Value += v
+}
+
+// Write writes the counter in the text-format
+func (c *Counter) Write(w io.Writer) error {
+	c.mu.Lock()
+	samples := make([]Sample, 0, len(c.values))
+	for _, s := range c.values {
+		samples = append(samples, *s)
+	}
+	c.mu.Unlock()
+	sortSamples(samples)
+
+	if err := c.header(w, "counter"); err != nil {
+		return err
+	}
+	if len(samples) == 0 && len(c.labels) == 0 {
+		return c.sample(w, "", nil, "", 0)
+	}
+	for _, s := range samples {
+		if err := c.sample(w, "", s.Labels, "", s.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GaugeFunc is a gauge with the values from a
+// function that is called when the metrics are scraped
+type GaugeFunc struct {
+	desc
+	fn func() ([]Sample, error)
+}
+
+// NewGaugeFunc returns a gauge with the
+// labels and the samples from the function
+func NewGaugeFunc(name, help string, labels []string, fn func() ([]Sample, error)) *GaugeFunc {
+	return &GaugeFunc{desc: desc{name, help, labels}, fn: fn}
+}
+
+// Write writes the gauge in the text-format
+func (g *GaugeFunc) Write(w io.Writer) error {
+	samples, err := g.fn()
+	if err != nil {
+		return fmt.Errorf("metrics: cannot collect %s: %v", g.name, err)
+	}
+	sortSamples(samples)
+
+	if err := g.header(w, "gauge"); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		g.check(s.Labels)
+		if err := g.sample(w, "", s.Labels, "", s.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Histogram is a histogram with labels
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogram
+}
+
+// histogram is the values for the label-values of a Histogram
+type histogram struct {
+	labels []string
+	counts []uint64 // per bucket (not cumulative)
+	count  uint64
+	sum    float64
+}
+
+// DefaultBuckets are the buckets for latencies in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// NewHistogram returns a histogram with the
+// upper bounds for the buckets and the labels
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{desc: desc{name, help, labels}, buckets: buckets, values: make(map[string]*histogram)}
+}
+
+// Observe adds the value to the histogram for the label-values
+func (h *Histogram) Observe(v float64, values ...string) {
+	h.check(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	k := key(values)
+	hv, ok := h.values[k]
+	if !ok {
+		hv = &histogram{labels: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.values[k] = hv
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		hv.counts[i]++
+	}
+	hv.count++
+	hv.sum += v
+}
+
+// Write writes the histogram in the text-format
+func (h *Histogram) Write(w io.Writer) error {
+	h.mu.Lock()
+	values := make([]histogram, 0, len(h.values))
+	for _, hv := range h.values {
+		c := *hv
+		c.counts = append([]uint64(nil), hv.counts...)
+		values = append(values, c)
+	}
+	h.mu.Unlock()
+	sort.Slice(values, func(i, j int) bool { return key(values[i].labels) < key(values[j].labels) })
+
+	if err := h.header(w, "histogram"); err != nil {
+		return err
+	}
+	for _, hv := range values {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += hv.counts[i]
+			if err := h.sample(w, "_bucket", hv.labels, `le="`+format(bound)+`"`, float64(cumulative)); err != nil {
+				return err
+			}
+		}
+		if err := h.sample(w, "_bucket", hv.labels, `le="+Inf"`, float64(hv.count)); err != nil {
+			return err
+		}
+		if err := h.sample(w, "_sum", hv.labels, "", hv.sum); err != nil {
+			return err
+		}
+		if err := h.sample(w, "_count", hv.labels, "", float64(hv.count)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// key returns the key for the label-values
+func key(values []string) string { return strings.Join(values, "\xff") }
+
+func sortSamples(samples []Sample) {
+	sort.Slice(samples, func(i, j int) bool { return key(samples[i].Labels) < key(samples[j].Labels) })
+}
+
+// format returns the value in the text-format
+func format(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escape escapes the help-text or the label-value
+func escape(s string, quote bool) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	if quote {
+		s = strings.Replace(s, `"`, `\"`, -1)
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+package metrics_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/metrics"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/matryer/is"
+)
+
+// scrape returns the metrics from the handler
+func scrape(t *testing.T, h http.Handler) string {
+	is := is.New(t)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	is.NoErr(err)
+	defer resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusOK)
+	is.Equal(resp.Header.Get("Content-Type"), metrics.ContentType)
+	b, err := ioutil.ReadAll(resp.Body)
+	is.NoErr(err)
+	return string(b)
+}
+
+func TestRegistry(t *testing.T) {
+	is := is.New(t)
+	counter := metrics.NewCounter("test_total", "A counter.", "server")
+	counter.Inc("b")
+	counter.Add(2, `a"\`)
+	counter.Inc("b")
+	plain := metrics.NewCounter("test_plain_total", "A counter\nwithout labels.")
+	histogram := metrics.NewHistogram("test_seconds", "A histogram.", []float64{1, 0.5}, "method")
+	for _, v := range []float64{0.2, 0.5, 0.7, 3} {
+		histogram.Observe(v, "Service.Method")
+	}
+	gauge := metrics.NewGaugeFunc("test_gauge", "A gauge.", []string{"nms", "type"}, func() ([]metrics.Sample, error) {
+		return []metrics.Sample{{Labels: []string{"nms", "b"}, Value: 1.5}, {Labels: []string{"nms", "a"}, Value: 0}}, nil
+	})
+
+	r := metrics.NewRegistry()
+	r.Register(counter, plain, histogram, gauge)
+	is.Equal(scrape(t, r.Handler()), `# HELP test_total A counter.
+# TYPE test_total counter
+test_total{server="a\"\\"} 2
+test_total{server="b"} 2
+# HELP test_plain_total A counter\nwithout labels.
+# TYPE test_plain_total counter
+test_plain_total 0
+# HELP test_seconds A histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{method="Service.Method",le="0.5"} 2
+test_seconds_bucket{method="Service.Method",le="1"} 3
+test_seconds_bucket{method="Service.Method",le="+Inf"} 4
+test_seconds_sum{method="Service.Method"} 4.4
+test_seconds_count{method="Service.Method"} 4
+# HELP test_gauge A gauge.
+# TYPE test_gauge gauge
+test_gauge{nms="nms",type="a"} 0
+test_gauge{nms="nms",type="b"} 1.5
+`)
+
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+	resp, err := http.Post(srv.URL, "text/plain", nil)
+	is.NoErr(err)
+	resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusMethodNotAllowed)
+}
+
+func TestRegisterDB(t *testing.T) {
+	is := is.New(t)
+	db, err := gorm.Open("sqlite3", ":memory:")
+	is.NoErr(err)
+	defer db.Close()
+	is.NoErr(tables.Migrate(db))
+
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01"}).Error)
+	is.NoErr(db.Create(&api.Server{Hostname: "dev02"}).Error)
+	for _, runner := range []api.Runner{
+		{Name: "runner-1", Hostname: "dev01", Status: avian.StatusWaiting},
+		{Name: "runner-2", Hostname: "dev01", Status: avian.StatusWaiting},
+		{Name: "runner-3", Hostname: "dev01", Status: avian.StatusRunning, Active: true},
+		{Name: "runner-4", Hostname: "dev02", Status: avian.StatusFailed},
+	} {
+		is.NoErr(db.Create(&runner).Error)
+	}
+	is.NoErr(db.Create(&api.Nms{Address: "license.avian.dk", Licences: []api.Licence{{Type: "enterprise-workstation", Amount: 4, InUse: 1}}}).Error)
+
+	r := metrics.NewRegistry()
+	metrics.RegisterDB(r, db)
+	body := scrape(t, r.Handler())
+	for _, line := range []string{
+		`avian_runners{status="Waiting"} 2`,
+		`avian_runners{status="Running"} 1`,
+		`avian_runners{status="Failed"} 1`,
+		`avian_runners{status="Timeout"} 0`,
+		`avian_active_runners{server="dev01"} 1`,
+		`avian_active_runners{server="dev02"} 0`,
+		`avian_licences{nms="license.avian.dk",type="enterprise-workstation"} 4`,
+		`avian_licences_in_use{nms="license.avian.dk",type="enterprise-workstation"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing: %s", line)
+		}
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	is := is.New(t)
+	h := metrics.Instrument(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oto/RunnerService.LogItem" {
+			http.NotFound(w, r)
+		}
+	}))
+	for _, path := range []string{"/oto/RunnerService.LogItem", "/oto/RunnerService.LogItem", "/oto/Unknown.Method", "/other"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+	}
+
+	// the latencies for the known methods are observed in the default registry
+	body := scrape(t, metrics.Default.Handler())
+	is.True(strings.Contains(body, `avian_callback_duration_seconds_count{method="RunnerService.LogItem"} 2`+"\n"))
+	is.True(!strings.Contains(body, "Unknown.Method"))
+
+	// and the durations for the stages that has ended
+	started := time.Now().Add(-2 * time.Hour)
+	stage := &api.Stage{StartedAt: &started, Ocr: &api.Ocr{}}
+	avian.SetStatusRunning(stage)
+	metrics.ObserveStage(stage, time.Now())
+	avian.SetStatusFinished(stage)
+	metrics.ObserveStage(stage, time.Now())
+	body = scrape(t, metrics.Default.Handler())
+	is.True(strings.Contains(body, `avian_stage_duration_seconds_count{stage="ocr",status="Finished"} 1`+"\n"))
+	is.True(strings.Contains(body, `avian_stage_duration_seconds_bucket{stage="ocr",status="Finished",le="3600"} 0`+"\n"))
+	is.True(strings.Contains(body, `avian_stage_duration_seconds_bucket{stage="ocr",status="Finished",le="14400"} 1`+"\n"))
+}
@@ -0,0 +1,250 @@
+package nms
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+)
+
+// The default paths for the licence-endpoints in the REST-api for the
+// NMS, they are set per NMS if the NMS serves the licences on other paths
+const (
+	DefaultPoolsPath  = "/api/v1/licences/pools"
+	DefaultLeasesPath = "/api/v1/licences/leases"
+)
+
+// The authentication for the REST-api for the NMS
+const (
+	// AuthBasic uses HTTP basic-auth with the username and password (default)
+	AuthBasic = "basic"
+
+	// AuthBearer sends the password for the NMS as a bearer-token
+	AuthBearer = "bearer"
+)
+
+// ValidAuth returns an error if the authentication for the NMS does not exist
+func ValidAuth(auth string) error {
+	if auth != "" && auth != AuthBasic && auth != AuthBearer {
+		return fmt.Errorf("unknown auth for the NMS: %s - use %s or %s", auth, AuthBasic, AuthBearer)
+	}
+	return nil
+}
+
+// ValidFingerprint returns an error if the fingerprint for the certificate
+// for the NMS is not a sha256 in hex (the colons are optional)
+func ValidFingerprint(fingerprint string) error {
+	if fingerprint == "" {
+		return nil
+	}
+	if b, err := hex.DecodeString(normalizeFingerprint(fingerprint)); err != nil || len(b) != sha256.Size {
+		return fmt.Errorf("invalid fingerprint for the NMS: %s - use the sha256 for the certificate in hex", fingerprint)
+	}
+	return nil
+}
+
+// normalizeFingerprint removes the colons and lowercases the fingerprint
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+}
+
+// TLSConfig returns the tls-config for the NMS, the certificate is verified
+// against the system-roots - unless it is pinned by the fingerprint or the
+// NMS is insecure
+func TLSConfig(fingerprint string, insecure bool) *tls.Config {
+	if insecure {
+		return &tls.Config{InsecureSkipVerify: true}
+	}
+	if fingerprint == "" {
+		return &tls.Config{}
+	}
+
+	// the chain for the pinned certificate is not verified
+	// (it is self-signed) - the fingerprint is verified instead
+	fingerprint = normalizeFingerprint(fingerprint)
+	return &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("the NMS did not send a certificate")
+			}
+			sum := sha256.Sum256(rawCerts[0])
+			if hex.EncodeToString(sum[:]) != fingerprint {
+				return fmt.Errorf("the certificate for the NMS does not match the fingerprint: %s", fingerprint)
+			}
+			return nil
+		},
+	}
+}
+
+// Pool is a licence-pool in the NMS
+type Pool struct {
+	// Type of the licences in the pool
+	Type string `json:"licenceType"`
+
+	// Total amount of licences in the pool
+	Total int64 `json:"total"`
+
+	// Available licences in the pool (not leased)
+	Available int64 `json:"available"`
+}
+
+// Lease is a leased licence in the NMS
+type Lease struct {
+	Type     string     `json:"licenceType"`
+	Workers  int64      `json:"workers"`
+	User     string     `json:"user"`
+	Host     string     `json:"host"`
+	LeasedAt *time.Time `json:"leasedAt"`
+}
+
+// Client for the licence-endpoints in the NMS, the requests are
+// authenticated with the username and password for the NMS
+type Client struct {
+	http       *http.Client
+	url        string
+	poolsPath  string
+	leasesPath string
+	auth       string
+	username   string
+	password   string
+}
+
+// New returns a client for the NMS (https on the port for the NMS), the
+// certificate is verified with the tls-config for the NMS (see TLSConfig).
+// The paths and the auth that are not set for the NMS gets the defaults.
+func New(nms api.Nms) *Client {
+	c := &Client{
+		http: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: TLSConfig(nms.Fingerprint, nms.Insecure),
+			},
+			Timeout: 10 * time.Second,
+		},
+		url:        fmt.Sprintf("https://%s:%d", nms.Address, nms.Port),
+		poolsPath:  nms.PoolsPath,
+		leasesPath: nms.LeasesPath,
+		auth:       nms.Auth,
+		username:   nms.Username,
+		password:   nms.Password,
+	}
+	if c.poolsPath == "" {
+		c.poolsPath = DefaultPoolsPath
+	}
+	if c.leasesPath == "" {
+		c.leasesPath = DefaultLeasesPath
+	}
+	if c.auth == "" {
+		c.auth = AuthBasic
+	}
+	return c
+}
+
+// Pools returns the licence-pools in the NMS
+func (c *Client) Pools(ctx context.Context) ([]Pool, error) {
+	var pools []Pool
+	if err := c.get(ctx, c.poolsPath, &pools); err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
+
+// Leases returns the leased licences in the NMS
+func (c *Client) Leases(ctx context.Context) ([]Lease, error) {
+	var leases []Lease
+	if err := c.get(ctx, c.leasesPath, &leases); err != nil {
+		return nil, err
+	}
+	return leases, nil
+}
+
+// get decodes the JSON-response for the path to v
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if c.auth == AuthBearer {
+		req.Header.Set("Authorization", "Bearer "+c.password)
+	} else {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot connect to NMS: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("NMS responded with status: %d for %s - %s", resp.StatusCode, path, strings.TrimSpace(string(b)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("cannot decode the response from NMS for %s: %v", path, err)
+	}
+	return nil
+}
+
+// Compare compares the licences in the service with the
+// licence-pools and the leases in the NMS by licence-type
+func Compare(licences []api.Licence, pools []Pool, leases []Lease) []api.LicenceUsage {
+	usage := make(map[string]*api.LicenceUsage)
+	configured := make(map[string]bool)
+	inNms := make(map[string]bool)
+	get := func(licenceType string) *api.LicenceUsage {
+		if u, ok := usage[licenceType]; ok {
+			return u
+		}
+		u := &api.LicenceUsage{Type: licenceType}
+		usage[licenceType] = u
+		return u
+	}
+
+	for _, licence := range licences {
+		u := get(licence.Type)
+		u.Amount += licence.Amount
+		u.InUse += licence.InUse
+		configured[licence.Type] = true
+	}
+	for _, pool := range pools {
+		u := get(pool.Type)
+		u.Pool += pool.Total
+		inNms[pool.Type] = true
+	}
+	for _, lease := range leases {
+		get(lease.Type).Leased++
+	}
+
+	var list []api.LicenceUsage
+	for licenceType, u := range usage {
+		var mismatches []string
+		switch {
+		case !inNms[licenceType]:
+			mismatches = append(mismatches, "not in the NMS")
+		case !configured[licenceType]:
+			mismatches = append(mismatches, "not configured in the service")
+		case u.Amount != u.Pool:
+			mismatches = append(mismatches, fmt.Sprintf("the service has %d licences, the NMS has %d", u.Amount, u.Pool))
+		}
+		if u.Leased != u.InUse {
+			mismatches = append(mismatches, fmt.Sprintf("%d leased in the NMS, %d reserved by the runners", u.Leased, u.InUse))
+		}
+		u.Mismatch = strings.Join(mismatches, " - ")
+		list = append(list, *u)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Type < list[j].Type })
+	return list
+}
@@ -0,0 +1,148 @@
+package nms_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/nms"
+	"github.com/matryer/is"
+)
+
+func TestCompare(t *testing.T) {
+	var tt = []struct {
+		name     string
+		licences []api.Licence
+		pools    []nms.Pool
+		leases   []nms.Lease
+		usage    []api.LicenceUsage
+	}{
+		{
+			name:     "match",
+			licences: []api.Licence{{Type: "enterprise-workstation", Amount: 2, InUse: 1}},
+			pools:    []nms.Pool{{Type: "enterprise-workstation", Total: 2, Available: 1}},
+			leases:   []nms.Lease{{Type: "enterprise-workstation", Workers: 4}},
+			usage:    []api.LicenceUsage{{Type: "enterprise-workstation", Amount: 2, InUse: 1, Pool: 2, Leased: 1}},
+		},
+		{
+			name:     "not-in-nms",
+			licences: []api.Licence{{Type: "enterprise-reviewer", Amount: 1}},
+			usage:    []api.LicenceUsage{{Type: "enterprise-reviewer", Amount: 1, Mismatch: "not in the NMS"}},
+		},
+		{
+			name:  "not-configured",
+			pools: []nms.Pool{{Type: "eDiscovery", Total: 3}},
+			usage: []api.LicenceUsage{{Type: "eDiscovery", Pool: 3, Mismatch: "not configured in the service"}},
+		},
+		{
+			name:     "amount",
+			licences: []api.Licence{{Type: "enterprise-workstation", Amount: 4}},
+			pools:    []nms.Pool{{Type: "enterprise-workstation", Total: 2}},
+			usage:    []api.LicenceUsage{{Type: "enterprise-workstation", Amount: 4, Pool: 2, Mismatch: "the service has 4 licences, the NMS has 2"}},
+		},
+		{
+			name:     "leased-and-amount",
+			licences: []api.Licence{{Type: "enterprise-workstation", Amount: 1, InUse: 1}},
+			pools:    []nms.Pool{{Type: "enterprise-workstation", Total: 3}},
+			leases:   []nms.Lease{{Type: "enterprise-workstation"}, {Type: "enterprise-workstation"}},
+			usage: []api.LicenceUsage{{Type: "enterprise-workstation", Amount: 1, InUse: 1, Pool: 3, Leased: 2,
+				Mismatch: "the service has 1 licences, the NMS has 3 - 2 leased in the NMS, 1 reserved by the runners"}},
+		},
+		{
+			name: "summed-and-sorted",
+			licences: []api.Licence{
+				{Type: "law-enforcement-desktop", Amount: 1},
+				{Type: "enterprise-workstation", Amount: 1},
+				{Type: "enterprise-workstation", Amount: 2},
+			},
+			pools: []nms.Pool{
+				{Type: "law-enforcement-desktop", Total: 1},
+				{Type: "enterprise-workstation", Total: 3},
+			},
+			usage: []api.LicenceUsage{
+				{Type: "enterprise-workstation", Amount: 3, Pool: 3},
+				{Type: "law-enforcement-desktop", Amount: 1, Pool: 1},
+			},
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(nms.Compare(tc.licences, tc.pools, tc.leases), tc.usage)
+		})
+	}
+}
+
+func TestTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]nms.Pool{{Type: "enterprise-workstation", Total: 1}})
+	}))
+	defer srv.Close()
+	host, p, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.ParseInt(p, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(srv.Certificate().Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	var tt = []struct {
+		name string
+		nms  api.Nms
+		err  string
+	}{
+		{name: "verified", err: "certificate signed by unknown authority"},
+		{name: "pinned", nms: api.Nms{Fingerprint: fingerprint}},
+		{name: "pinned-with-colons", nms: api.Nms{Fingerprint: strings.ToUpper(colons(fingerprint))}},
+		{name: "wrong-fingerprint", nms: api.Nms{Fingerprint: strings.Repeat("0", 64)}, err: "does not match the fingerprint"},
+		{name: "insecure", nms: api.Nms{Insecure: true}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			tc.nms.Address, tc.nms.Port = host, port
+			pools, err := nms.New(tc.nms).Pools(context.Background())
+			if tc.err != "" {
+				is.True(err != nil)
+				is.True(strings.Contains(err.Error(), tc.err))
+				return
+			}
+			is.NoErr(err)
+			is.Equal(len(pools), 1)
+		})
+	}
+}
+
+func TestValidFingerprint(t *testing.T) {
+	is := is.New(t)
+	is.NoErr(nms.ValidFingerprint(""))
+	is.NoErr(nms.ValidFingerprint(colons(strings.Repeat("AB", 32))))
+	is.Equal(nms.ValidFingerprint("ab:cd").Error(), "invalid fingerprint for the NMS: ab:cd - use the sha256 for the certificate in hex")
+	is.True(nms.ValidFingerprint(strings.Repeat("x", 64)) != nil)
+}
+
+// colons separates the bytes in the
+// fingerprint with colons (like openssl)
+func colons(fingerprint string) string {
+	var parts []string
+	for i := 0; i < len(fingerprint); i += 2 {
+		parts = append(parts, fingerprint[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
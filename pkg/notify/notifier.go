@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+)
+
+// Notifier sends an email when a runner has
+// finished, failed or timed out to its recipients
+type Notifier struct {
+	db        *gorm.DB
+	config    Config
+	templates *Templates
+	logger    *zap.Logger
+}
+
+// New returns a Notifier that sends the emails with the
+// smtp-server in the config, rendered with the templates
+func New(db *gorm.DB, config Config, templates *Templates, logger *zap.Logger) *Notifier {
+	if templates == nil {
+		templates = DefaultTemplates()
+	}
+	return &Notifier{
+		db:        db,
+		config:    config,
+		templates: templates,
+		logger:    logger,
+	}
+}
+
+// Run sends the emails for the runner-events
+// from the broker until the context is done
+func (n *Notifier) Run(ctx context.Context, b *events.Broker) {
+	filter := events.Filter{Types: []string{events.TypeRunner}}
+	events.Follow(ctx, b, filter, n.logger.With(zap.String("subscriber", "notifications")), n.handle)
+}
+
+// handle sends the email for the event if it
+// is for a runner that finished, failed or timed out
+func (n *Notifier) handle(e events.Event) {
+	if !Notified(e.Status) {
+		return
+	}
+	if err := n.Notify(e.Runner); err != nil {
+		n.logger.Error("Cannot send notification for runner",
+			zap.String("runner", e.Runner),
+			zap.String("status", e.Status),
+			zap.String("exception", err.Error()),
+		)
+	}
+}
+
+// Notify sends the email for the runner to its recipients
+func (n *Notifier) Notify(name string) error {
+	var runner api.Runner
+	err := tables.PreloadStages(n.db, "Stages.").
+		Preload("CaseSettings.Case").
+		First(&runner, "name = ?", name).Error
+	if err != nil {
+		return fmt.Errorf("cannot get runner: %v", err)
+	}
+
+	logger := n.logger.With(zap.String("runner", runner.Name))
+	recipients := Recipients(runner, n.config.Domain)
+	if len(recipients) == 0 {
+		logger.Warn("No recipients for the notification - specify notify for the runner or an investigator for the case")
+		return nil
+	}
+
+	now := time.Now()
+	subject, text, html, err := n.templates.Render(NewSummary(runner, now))
+	if err != nil {
+		return err
+	}
+	msg, err := message(n.config.From, recipients, subject, text, html, now)
+	if err != nil {
+		return fmt.Errorf("cannot create message: %v", err)
+	}
+	if err := send(n.config, recipients, msg); err != nil {
+		return err
+	}
+
+	logger.Info("Sent notification for runner", zap.Strings("recipients", recipients), zap.String("subject", subject))
+	return nil
+}
@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+)
+
+// PasswordEnv is the environment-variable for the password
+// to the smtp-server (so it is not visible in the process-list)
+const PasswordEnv = "AVIAN_SMTP_PASSWORD"
+
+// actions maps the statuses for the runners that
+// are notified to the action in the emails
+var actions = map[string]string{
+	"Finished": "finished",
+	"Failed":   "failed",
+	"Timeout":  "timed out",
+}
+
+// Config for the smtp-server the emails are sent with
+type Config struct {
+	// Host for the smtp-server, the
+	// emails are not sent if it is empty
+	Host string
+
+	// Port for the smtp-server (e.g 587 or 465)
+	Port int
+
+	// Username and Password to authenticate with (PLAIN),
+	// no authentication is used if Username is empty
+	Username string
+	Password string
+
+	// From is the sender of the emails
+	From string
+
+	// Domain is appended to the recipients without a
+	// domain (e.g the investigator simon -> simon@domain)
+	Domain string
+
+	// TLS connects with implicit TLS (smtps), else
+	// STARTTLS is used if the server supports it
+	TLS bool
+}
+
+// Enabled returns true if a smtp-server is configured
+func (c Config) Enabled() bool { return c.Host != "" }
+
+// Summary is the data for the templates
+type Summary struct {
+	// Runner is the name of the runner
+	Runner string
+
+	// Status for the runner (Finished, Failed or Timeout)
+	Status string
+
+	// Action for the status (finished, failed or timed out)
+	Action string
+
+	// Hostname for the server the runner ran on
+	Hostname string
+
+	// Case and Investigator for the case-settings
+	Case         string
+	Investigator string
+
+	// StartedAt and EndedAt for the run and its Duration
+	StartedAt *time.Time
+	EndedAt   *time.Time
+	Duration  string
+
+	// Exception for the failed or timed out runner
+	Exception string
+
+	// FailedStage is the name of the stage that
+	// failed or timed out (empty if there is none)
+	FailedStage string
+
+	// Stages for the runner in the order they run
+	Stages []Stage
+
+	// Items is the total amount of items for the stages
+	Items int64
+}
+
+// Stage is the summary for a stage
+type Stage struct {
+	ID     uint
+	Name   string
+	Status string
+	Items  int64
+}
+
+// Notified returns true if an email is sent for the status
+func Notified(status string) bool {
+	_, ok := actions[status]
+	return ok
+}
+
+// NewSummary returns the summary for the runner
+// (with its stages and case-settings preloaded)
+func NewSummary(runner api.Runner, now time.Time) Summary {
+	status := avian.Status(runner.Status)
+	s := Summary{
+		Runner:    runner.Name,
+		Status:    status,
+		Action:    actions[status],
+		Hostname:  runner.Hostname,
+		StartedAt: runner.StartedAt,
+		EndedAt:   &now,
+		Exception: runner.Exception,
+	}
+	if runner.StartedAt != nil {
+		s.Duration = now.Sub(*runner.StartedAt).Round(time.Second).String()
+	}
+	if runner.CaseSettings != nil && runner.CaseSettings.Case != nil {
+		s.Case = runner.CaseSettings.Case.Name
+		s.Investigator = runner.CaseSettings.Case.Investigator
+	}
+
+	stages := append([]*api.Stage(nil), runner.Stages...)
+	sort.SliceStable(stages, func(i, j int) bool { return stages[i].Index < stages[j].Index })
+	for _, stage := range stages {
+		state := avian.StageState(stage)
+		s.Stages = append(s.Stages, Stage{
+			ID:     stage.ID,
+			Name:   avian.Name(stage),
+			Status: avian.Status(state),
+			Items:  stage.Items,
+		})
+		if s.FailedStage == "" && (state == avian.StatusFailed || state == avian.StatusTimeout) {
+			s.FailedStage = avian.Name(stage)
+		}
+		s.Items += stage.Items
+	}
+	return s
+}
+
+// Recipients returns the recipients for the runner - the
+// notify-list or the investigator for the case if it is empty,
+// the domain is appended to the recipients without a domain
+func Recipients(runner api.Runner, domain string) []string {
+	list := strings.Split(runner.Notify, ",")
+	if strings.TrimSpace(runner.Notify) == "" {
+		list = nil
+		if runner.CaseSettings != nil && runner.CaseSettings.Case != nil {
+			list = []string{runner.CaseSettings.Case.Investigator}
+		}
+	}
+
+	var recipients []string
+	for _, recipient := range list {
+		recipient = strings.TrimSpace(recipient)
+		if recipient == "" {
+			continue
+		}
+		if !strings.Contains(recipient, "@") {
+			if domain == "" {
+				continue
+			}
+			recipient += "@" + strings.TrimPrefix(domain, "@")
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients
+}
@@ -0,0 +1,246 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/notify"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+// email is an email received by the smtpServer
+type email struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer is a local stand-in for a smtp-server
+// that accepts every email (without STARTTLS)
+type smtpServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	emails   []email
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP")
+
+	var e email
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			b, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			e.auth = string(b)
+			reply("235 OK")
+		case "MAIL":
+			e.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case "RCPT":
+			e.to = append(e.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			e.data = data.String()
+			s.mu.Lock()
+			s.emails = append(s.emails, e)
+			s.mu.Unlock()
+			e = email{}
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpServer) received() []email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]email(nil), s.emails...)
+}
+
+// parse returns the subject, the text-body and the html-body for the email
+func parse(t *testing.T, data string) (string, string, string) {
+	is := is.NewRelaxed(t)
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	is.NoErr(err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	is.NoErr(err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	is.NoErr(err)
+	is.Equal(mediaType, "multipart/alternative")
+
+	bodies := map[string]string{}
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err != nil {
+			break
+		}
+		b, _ := ioutil.ReadAll(part)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[contentType] = string(b)
+	}
+	return subject, bodies["text/plain"], bodies["text/html"]
+}
+
+func TestNotifier(t *testing.T) {
+	is := is.New(t)
+	db, err := gorm.Open("sqlite3", ":memory:")
+	is.NoErr(err)
+	defer db.Close()
+	db.DB().SetMaxOpenConns(1) // every connection gets its own in-memory db
+	is.NoErr(tables.Migrate(db))
+
+	srv := newSMTPServer(t)
+	defer srv.listener.Close()
+	addr := srv.listener.Addr().(*net.TCPAddr)
+	config := notify.Config{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		Username: "avian",
+		Password: "secret",
+		From:     "Avian <avian@example.com>",
+		Domain:   "example.com",
+	}
+
+	// a failed runner - notified to the investigator for the case
+	started := time.Now().Add(-90 * time.Minute)
+	process := &api.Stage{Index: 0, Items: 1200, Process: &api.Process{}}
+	ocr := &api.Stage{Index: 1, Items: 35, Ocr: &api.Ocr{}}
+	avian.SetStatusFinished(process)
+	avian.SetStatusFailed(ocr)
+	failed := api.Runner{
+		Name:         "runner-failed",
+		Hostname:     "dev01",
+		Status:       avian.StatusFailed,
+		StartedAt:    &started,
+		Exception:    "java.lang.OutOfMemoryError: <heap>",
+		CaseSettings: &api.CaseSettings{Case: &api.Case{Name: "case-1", Investigator: "simon"}},
+		Stages:       []*api.Stage{ocr, process},
+	}
+	is.NoErr(db.Create(&failed).Error)
+
+	notifier := notify.New(db, config, nil, zap.NewNop())
+	is.NoErr(notifier.Notify("runner-failed"))
+
+	emails := srv.received()
+	is.Equal(len(emails), 1)
+	is.Equal(emails[0].auth, "\x00avian\x00secret")
+	is.Equal(emails[0].from, "avian@example.com")
+	is.Equal(emails[0].to, []string{"simon@example.com"})
+
+	subject, text, html := parse(t, emails[0].data)
+	is.Equal(subject, "[avian] Runner runner-failed has failed")
+	for _, s := range []string{"Status:       Failed", "Case:         case-1", "Stage:        OCR", "java.lang.OutOfMemoryError: <heap>", "Process - Finished (1200 items)", "Items: 1235"} {
+		is.True(strings.Contains(text, s)) // text-body
+	}
+	is.True(strings.Index(text, "Process -") < strings.Index(text, "OCR -")) // in the order of the stages
+	is.True(strings.Contains(html, "java.lang.OutOfMemoryError: &lt;heap&gt;"))
+	is.True(strings.Contains(html, "<strong>1235</strong>"))
+
+	// a finished runner - notified from the events to its notify-list
+	finished := api.Runner{
+		Name:         "runner-finished",
+		Status:       avian.StatusFinished,
+		Notify:       "lead@example.com,analyst",
+		CaseSettings: &api.CaseSettings{Case: &api.Case{Name: "case-2", Investigator: "simon"}},
+	}
+	is.NoErr(db.Create(&finished).Error)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	broker := events.NewBroker(100)
+	go notifier.Run(ctx, broker)
+	time.Sleep(50 * time.Millisecond) // let the notifier subscribe
+	broker.Publish(events.Event{Type: events.TypeRunner, Runner: "runner-finished", Status: "Running"})
+	broker.Publish(events.Event{Type: events.TypeRunner, Runner: "runner-finished", Status: "Finished"})
+
+	deadline := time.Now().Add(10 * time.Second)
+	for len(srv.received()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("the email for the finished runner was not sent")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond) // no email for the running runner
+	emails = srv.received()
+	is.Equal(len(emails), 2)
+	is.Equal(emails[1].to, []string{"lead@example.com", "analyst@example.com"})
+	subject, text, _ = parse(t, emails[1].data)
+	is.Equal(subject, "[avian] Runner runner-finished has finished")
+	is.True(!strings.Contains(text, "Exception"))
+}
+
+func TestRecipients(t *testing.T) {
+	is := is.New(t)
+	runner := api.Runner{CaseSettings: &api.CaseSettings{Case: &api.Case{Investigator: "simon"}}}
+	is.Equal(notify.Recipients(runner, "example.com"), []string{"simon@example.com"})
+	is.Equal(notify.Recipients(runner, ""), nil) // no domain for the name
+
+	runner.Notify = "a@example.com, b"
+	is.Equal(notify.Recipients(runner, "example.com"), []string{"a@example.com", "b@example.com"})
+	is.Equal(notify.Recipients(api.Runner{}, "example.com"), nil)
+}
@@ -0,0 +1,197 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// The names of the files that overrides the templates in a template-dir
+const (
+	subjectTemplate = "subject.txt"
+	textTemplate    = "body.txt"
+	htmlTemplate    = "body.html"
+)
+
+// Templates holds the templates for the emails, the embedded
+// defaults can be overridden with the templates in a directory
+type Templates struct {
+	// Overrides lists the templates that overrides the defaults
+	Overrides []string
+
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+// funcs are the functions that can be used in the templates
+var funcs = map[string]interface{}{
+	"time": func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04:05 MST")
+	},
+}
+
+// DefaultTemplates returns the templates embedded in the binary
+func DefaultTemplates() *Templates {
+	return &Templates{
+		subject: template.Must(template.New(subjectTemplate).Funcs(funcs).Parse(defaultSubject)),
+		text:    template.Must(template.New(textTemplate).Funcs(funcs).Parse(defaultText)),
+		html:    htmltemplate.Must(htmltemplate.New(htmlTemplate).Funcs(funcs).Parse(defaultHTML)),
+	}
+}
+
+// LoadTemplates returns the default templates overridden by the templates in dir:
+//
+//	subject.txt - the subject (text/template)
+//	body.txt    - the text-body (text/template)
+//	body.html   - the html-body (html/template)
+//
+// The templates are checked when they are loaded, so
+// an invalid template is found when the service starts.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := DefaultTemplates()
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template-dir: %v", err)
+	}
+
+	for _, file := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %s - %v", file.Name(), err)
+		}
+		switch file.Name() {
+		case subjectTemplate:
+			t.subject, err = template.New(subjectTemplate).Funcs(funcs).Parse(string(b))
+		case textTemplate:
+			t.text, err = template.New(textTemplate).Funcs(funcs).Parse(string(b))
+		case htmlTemplate:
+			t.html, err = htmltemplate.New(htmlTemplate).Funcs(funcs).Parse(string(b))
+		default:
+			return nil, fmt.Errorf("unknown file in template-dir: %s - expected %s, %s or %s", file.Name(), subjectTemplate, textTemplate, htmlTemplate)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %s - %v", file.Name(), err)
+		}
+		t.Overrides = append(t.Overrides, file.Name())
+	}
+
+	// check the templates with an example
+	if _, _, _, err := t.Render(example()); err != nil {
+		return nil, err
+	}
+	sort.Strings(t.Overrides)
+	return t, nil
+}
+
+// Render returns the subject, the text-body
+// and the html-body for the summary
+func (t *Templates) Render(s Summary) (subject, text, html string, err error) {
+	var buf bytes.Buffer
+	if err := t.subject.Execute(&buf, s); err != nil {
+		return "", "", "", fmt.Errorf("invalid template: %s - %v", subjectTemplate, err)
+	}
+	subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err := t.text.Execute(&buf, s); err != nil {
+		return "", "", "", fmt.Errorf("invalid template: %s - %v", textTemplate, err)
+	}
+	text = buf.String()
+
+	buf.Reset()
+	if err := t.html.Execute(&buf, s); err != nil {
+		return "", "", "", fmt.Errorf("invalid template: %s - %v", htmlTemplate, err)
+	}
+	html = buf.String()
+	return subject, text, html, nil
+}
+
+// example returns a summary to check the templates with
+func example() Summary {
+	now := time.Now()
+	return Summary{
+		Runner:       "template-check",
+		Status:       "Failed",
+		Action:       "failed",
+		Hostname:     "localhost",
+		Case:         "template-check",
+		Investigator: "investigator",
+		StartedAt:    &now,
+		EndedAt:      &now,
+		Duration:     "0s",
+		Exception:    "exception",
+		FailedStage:  "Process",
+		Stages:       []Stage{{ID: 1, Name: "Process", Status: "Failed", Items: 1}},
+		Items:        1,
+	}
+}
+
+const defaultSubject = `[avian] Runner {{.Runner}} has {{.Action}}`
+
+const defaultText = `Runner {{.Runner}} has {{.Action}}.
+
+Status:       {{.Status}}
+Server:       {{.Hostname}}
+Case:         {{.Case}}
+Investigator: {{.Investigator}}
+Started:      {{time .StartedAt}}
+Ended:        {{time .EndedAt}}
+Duration:     {{.Duration}}
+{{- if .FailedStage}}
+Stage:        {{.FailedStage}}
+{{- end}}
+{{- if .Exception}}
+
+Exception:
+{{.Exception}}
+{{- end}}
+
+Stages:
+{{- range .Stages}}
+  {{.ID}}. {{.Name}} - {{.Status}} ({{.Items}} items)
+{{- end}}
+
+Items: {{.Items}}
+`
+
+const defaultHTML = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<h2>Runner {{.Runner}} has {{.Action}}</h2>
+<table>
+<tr><td>Status</td><td><strong>{{.Status}}</strong></td></tr>
+<tr><td>Server</td><td>{{.Hostname}}</td></tr>
+<tr><td>Case</td><td>{{.Case}}</td></tr>
+<tr><td>Investigator</td><td>{{.Investigator}}</td></tr>
+<tr><td>Started</td><td>{{time .StartedAt}}</td></tr>
+<tr><td>Ended</td><td>{{time .EndedAt}}</td></tr>
+<tr><td>Duration</td><td>{{.Duration}}</td></tr>
+{{- if .FailedStage}}
+<tr><td>Stage</td><td>{{.FailedStage}}</td></tr>
+{{- end}}
+</table>
+{{- if .Exception}}
+<h3>Exception</h3>
+<pre>{{.Exception}}</pre>
+{{- end}}
+<h3>Stages</h3>
+<table border="1" cellpadding="4" style="border-collapse: collapse;">
+<tr><th>ID</th><th>Stage</th><th>Status</th><th>Items</th></tr>
+{{- range .Stages}}
+<tr><td>{{.ID}}</td><td>{{.Name}}</td><td>{{.Status}}</td><td>{{.Items}}</td></tr>
+{{- end}}
+<tr><td colspan="3"><strong>Total</strong></td><td><strong>{{.Items}}</strong></td></tr>
+</table>
+</body>
+</html>
+`
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"regexp"
 	"strings"
 
+	"github.com/avian-digital-forensics/auto-processing/pkg/metrics"
 	"github.com/simonjanss/go-powershell"
 )
 
@@ -39,6 +41,7 @@ type Session interface {
 	Run(program string, args ...string) error
 	SetEnv(variable, arg string) error
 	SetLocation(path string) error
+	StopProcess(program, match string) error
 }
 
 type session struct {
@@ -50,6 +53,9 @@ type session struct {
 // NewSession creates a new remote session to the specified host with username and password
 func (s service) NewSession(host, username, password string) (Session, error) {
 	sess, err := s.shell.NewSession(host, powershell.WithUsernamePassword(username, password))
+	if err != nil {
+		metrics.PwshSessionErrors.Inc(host)
+	}
 	return session{sess, s.shell, host}, err
 }
 
@@ -59,6 +65,9 @@ func (s service) NewSessionCredSSP(host, username, password string) (Session, er
 		powershell.WithUsernamePassword(username, password),
 		powershell.WithAuthentication("CredSSP"),
 	)
+	if err != nil {
+		metrics.PwshSessionErrors.Inc(host)
+	}
 	return session{sess, s.shell, host}, err
 }
 
@@ -154,6 +163,33 @@ func (s session) SetLocation(path string) error {
 	return nil
 }
 
+// StopProcess stops the processes (with their child-processes) for the program
+// in the session that has the argument (a file-name or the end of a path) in
+// their command-line - "foo.gen.rb" does not match "xfoo.gen.rb"
+func (s session) StopProcess(program, argument string) error {
+	// the argument must follow a separator (a space, a quote or a
+	// path-separator) and end the argument in the command-line
+	pattern := `(^|[\s"'\\/])` + regexp.QuoteMeta(argument) + `($|[\s"'])`
+	stopCmd := fmt.Sprintf("Get-CimInstance Win32_Process -Filter \"Name = '%s'\" | "+
+		"Where-Object { $_.CommandLine -match '%s' } | "+
+		"ForEach-Object { taskkill.exe /PID $_.ProcessId /T /F }", wqlEscape(program), quote(pattern))
+	if _, err := s.session.Execute(stopCmd); err != nil {
+		return fmt.Errorf("unable to stop process: %s - %v", program, err)
+	}
+	return nil
+}
+
+// quote escapes the value for a single-quoted string in powershell
+func quote(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+// wqlEscape escapes the value for a single-quoted string in a
+// WQL-filter (that is in a double-quoted string in powershell)
+func wqlEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, `"`, "`\"", "`", "``", "$", "`$").Replace(value)
+}
+
 // EnableCredSSP for the remote server in the session
 func (s session) EnableCredSSP() error {
 	// Enable CredSSP for double-hops in session
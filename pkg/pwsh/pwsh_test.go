@@ -89,6 +89,32 @@ func TestRun(t *testing.T) {
 	time.Sleep(5 * time.Minute)
 }
 
+func TestStopProcess(t *testing.T) {
+	is := is.New(t)
+
+	shell, err := pwsh.New()
+	is.NoErr(err)
+
+	// Create a new remote-client with the config and the powershell-process
+	// a client holds the existing powershell-process and the remote-session
+	session, err := shell.NewSession(hostname, username, password)
+	is.NoErr(err)
+	defer session.Close()
+
+	err = session.SetLocation("C:\\Windows\\System32\\WindowsPowerShell\\v1.0")
+	is.NoErr(err)
+
+	err = session.Run("powershell.exe", "-Command Start-Sleep -Seconds 300 # avian-stop-test")
+	is.NoErr(err)
+
+	err = session.StopProcess("powershell.exe", "avian-stop-test")
+	is.NoErr(err)
+
+	// stopping without any matching process should not fail
+	err = session.StopProcess("powershell.exe", "avian-stop-test")
+	is.NoErr(err)
+}
+
 func TestSetEnv(t *testing.T) {
 	is := is.New(t)
 
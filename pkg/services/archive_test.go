@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/matryer/is"
+)
+
+func TestArchiveResult(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	runners := make([]api.Runner, 2)
+	for i, name := range []string{"runner-1", "runner-2"} {
+		runners[i] = api.Runner{Name: name, Stages: []*api.Stage{{Archive: &api.Archive{Destination: `\\archive\cases`}}}}
+		is.NoErr(db.Create(&runners[i]).Error)
+	}
+	key, err := auth.CreateRunnerKey(db, runners[0].ID, runners[0].Name)
+	is.NoErr(err)
+	client := avian.NewRunnerService(avian.NewWithKey(srv.URL+"/oto/", key))
+	stageID := runners[0].Stages[0].ID
+
+	// the location and the verification-result are recorded
+	_, err = client.ArchiveResult(ctx, avian.ArchiveResultRequest{Runner: "runner-1", StageID: stageID, Location: "//archive/cases/case-1,//archive/cases/compound", Files: 120})
+	is.NoErr(err)
+	var archive api.Archive
+	is.NoErr(db.First(&archive, "stage_id = ?", stageID).Error)
+	is.Equal(archive.Location, "//archive/cases/case-1,//archive/cases/compound")
+	is.Equal(archive.Files, int64(120))
+	is.True(archive.Verified)
+
+	_, err = client.ArchiveResult(ctx, avian.ArchiveResultRequest{Runner: "runner-1", StageID: stageID, Location: "//archive/cases/case-1", Files: 120, Mismatches: 2})
+	is.NoErr(err)
+	is.NoErr(db.First(&archive, "stage_id = ?", stageID).Error)
+	is.Equal(archive.Mismatches, int64(2))
+	is.True(!archive.Verified)
+
+	// but not for the stage of another runner
+	_, err = client.ArchiveResult(ctx, avian.ArchiveResultRequest{Runner: "runner-1", StageID: runners[1].Stages[0].ID, Files: 1})
+	is.True(err != nil && strings.Contains(err.Error(), "forbidden"))
+}
+
+func TestArchiveRemoveSource(t *testing.T) {
+	is := is.New(t)
+	runner := api.Runner{
+		Name:         "runner",
+		Hostname:     "dev01",
+		Nms:          "nms01",
+		Licence:      "enterprise-workstation",
+		Xmx:          "16g",
+		Workers:      2,
+		CaseSettings: &api.CaseSettings{CaseLocation: `C:\Cases`, Case: &api.Case{Directory: `C:\Cases\case-1`}},
+		Stages:       []*api.Stage{{Archive: &api.Archive{Destination: `\\archive\cases`, RemoveSource: true}}},
+	}
+	is.NoErr(runner.Validate())
+
+	// the single-case cannot be removed while a compound lists it
+	runner.CaseSettings.CompoundCase = &api.Case{Directory: `C:\Cases\compound`}
+	is.Equal(runner.Validate().Error(), "Stage: 1 - archive cannot remove the source while a compound- or review-case is configured")
+	runner.CaseSettings.CompoundCase = nil
+	runner.CaseSettings.ReviewCompound = &api.Case{Directory: `C:\Cases\review`}
+	is.True(runner.Validate() != nil)
+	runner.Stages[0].Archive.RemoveSource = false
+	is.NoErr(runner.Validate())
+}
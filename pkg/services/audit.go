@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+)
+
+// AuditService holds the dependencies
+// for the AuditService
+type AuditService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewAuditService creates a new audit-service
+func NewAuditService(db *gorm.DB, logger *zap.Logger) AuditService {
+	return AuditService{db: db, logger: logger}
+}
+
+// List returns the entries in the audit-trail
+// for the filters (the latest first)
+func (s AuditService) List(ctx context.Context, r api.AuditListRequest) (*api.AuditListResponse, error) {
+	limit := r.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := s.db.Order("time desc, id desc").Limit(limit)
+	if r.Identity != "" {
+		query = query.Where("identity = ?", r.Identity)
+	}
+	if r.Method != "" {
+		query = query.Where("method = ?", r.Method)
+	}
+	if r.Resource != "" {
+		query = query.Where("resource = ?", r.Resource)
+	}
+	if r.Target != "" {
+		query = query.Where("target = ?", r.Target)
+	}
+	if r.Since != nil {
+		query = query.Where("time >= ?", r.Since.UTC())
+	}
+	if r.Until != nil {
+		query = query.Where("time < ?", r.Until.UTC())
+	}
+
+	var entries []api.AuditEntry
+	if err := query.Find(&entries).Error; err != nil {
+		s.logger.Error("Cannot get the audit-trail", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	s.logger.Debug("Got audit-trail", zap.Int("amount", len(entries)))
+	return &api.AuditListResponse{Entries: entries}, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"go.uber.org/zap"
+)
+
+// The runner-scripts call the service with a key that is bound to
+// their runner (see auth.CreateRunnerKey), the callbacks checks
+// that the runner and the stage they modify belongs to the key.
+// Requests with other keys (and calls within the service) are not checked.
+
+// authorizeRunner returns an error if the
+// request is made with a key for another runner
+func (s RunnerService) authorizeRunner(ctx context.Context, runnerID uint) error {
+	keyRunnerID, ok := auth.RunnerID(ctx)
+	if !ok || keyRunnerID == runnerID {
+		return nil
+	}
+	s.logger.Warn("Rejected callback for another runner",
+		zap.Int("runner_id", int(runnerID)),
+		zap.Int("key_runner_id", int(keyRunnerID)),
+	)
+	return auth.Forbidden("the api-key is not for runner: %d", runnerID)
+}
+
+// authorizeRunnerName returns an error if the
+// request is made with a key for another runner
+func (s RunnerService) authorizeRunnerName(ctx context.Context, name string) error {
+	if _, ok := auth.RunnerID(ctx); !ok {
+		return nil
+	}
+	var runner api.Runner
+	if err := s.DB.Select("id").Where("name = ?", name).First(&runner).Error; err != nil {
+		return auth.Forbidden("cannot get runner: %s - %v", name, err)
+	}
+	return s.authorizeRunner(ctx, runner.ID)
+}
+
+// authorizeStage returns an error if the request is
+// made with a key for another runner than the stage's
+func (s RunnerService) authorizeStage(ctx context.Context, stageID uint) error {
+	if _, ok := auth.RunnerID(ctx); !ok {
+		return nil
+	}
+	var stage api.Stage
+	if err := s.DB.Select("runner_id").First(&stage, stageID).Error; err != nil {
+		return auth.Forbidden("cannot get stage: %d - %v", stageID, err)
+	}
+	return s.authorizeRunner(ctx, stage.RunnerID)
+}
+
+// authorizeLog returns an error if the request is made with a
+// key for another runner than the log's (and the log's stage)
+func (s RunnerService) authorizeLog(ctx context.Context, runner string, stageID int) error {
+	if err := s.authorizeRunnerName(ctx, runner); err != nil {
+		return err
+	}
+	if stageID == 0 {
+		return nil
+	}
+	return s.authorizeStage(ctx, uint(stageID))
+}
+
+// authorizeEvidence returns an error if the evidence does not
+// belong to the stage when the request is made with a runner-key
+func (s RunnerService) authorizeEvidence(ctx context.Context, stageID, evidenceID uint) error {
+	if _, ok := auth.RunnerID(ctx); !ok {
+		return nil
+	}
+	var process api.Process
+	query := s.DB.Joins("JOIN evidences ON evidences.process_id = processes.id").
+		Where("evidences.id = ? AND processes.stage_id = ?", evidenceID, stageID)
+	if query.First(&process).RecordNotFound() {
+		return auth.Forbidden("evidence: %d does not belong to stage: %d", evidenceID, stageID)
+	}
+	return nil
+}
+
+// authorizeHashList returns an error if the hash-list does not
+// belong to the stage when the request is made with a runner-key
+func (s RunnerService) authorizeHashList(ctx context.Context, stageID, listID uint) error {
+	if _, ok := auth.RunnerID(ctx); !ok {
+		return nil
+	}
+	var set api.HashSet
+	query := s.DB.Joins("JOIN hash_lists ON hash_lists.hash_set_id = hash_sets.id").
+		Where("hash_lists.id = ? AND hash_sets.stage_id = ?", listID, stageID)
+	if query.First(&set).RecordNotFound() {
+		return auth.Forbidden("hash-list: %d does not belong to stage: %d", listID, stageID)
+	}
+	return nil
+}
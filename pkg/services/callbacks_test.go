@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/logging"
+	"github.com/avian-digital-forensics/auto-processing/pkg/services"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/matryer/is"
+	"github.com/pacedotdev/oto/otohttp"
+	"go.uber.org/zap"
+)
+
+// newTestService returns a db and a http-server with the RunnerService,
+// the WebhookService and the event-stream behind the auth-middleware
+func newTestService(t *testing.T) (*gorm.DB, *httptest.Server) {
+	is := is.New(t)
+	db, err := gorm.Open("sqlite3", ":memory:")
+	is.NoErr(err)
+	t.Cleanup(func() { db.Close() })
+	is.NoErr(tables.Migrate(db))
+
+	logPath, err := ioutil.TempDir("", "avian-logs")
+	is.NoErr(err)
+	t.Cleanup(func() { os.RemoveAll(logPath) })
+
+	logger := zap.NewNop()
+	server := otohttp.NewServer()
+	server.OnErr = auth.OnErr
+	broker := events.NewBroker(100)
+	api.RegisterRunnerService(server, services.NewRunnerService(db, nil, logger, logging.New(logPath), "", "", ruby.DefaultTemplates(), broker))
+	api.RegisterWebhookService(server, services.NewWebhookService(db, logger))
+	api.RegisterNmsService(server, services.NewNmsService(db, logger))
+	api.RegisterServerService(server, services.NewServerService(db, nil, logger))
+	mux := http.NewServeMux()
+	mux.Handle("/oto/", server)
+	mux.Handle("/oto/RunnerService.Events", events.Handler(broker, logger))
+	srv := httptest.NewServer(auth.Middleware(db, logger, mux))
+	t.Cleanup(srv.Close)
+	return db, srv
+}
+
+func TestCallbacks(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+
+	// two runners with a stage each
+	var runners []api.Runner
+	for _, name := range []string{"runner-1", "runner-2"} {
+		runner := api.Runner{Name: name, Stages: []*api.Stage{{Ocr: &api.Ocr{}}}}
+		is.NoErr(db.Create(&runner).Error)
+		runners = append(runners, runner)
+	}
+
+	key, err := auth.CreateRunnerKey(db, runners[0].ID, runners[0].Name)
+	is.NoErr(err)
+	client := avian.NewRunnerService(avian.NewWithKey(srv.URL+"/oto/", key))
+	ctx := context.Background()
+
+	// the runner-key can modify its own runner and stage
+	_, err = client.Heartbeat(ctx, avian.RunnerStartRequest{Runner: runners[0].Name, ID: runners[0].ID})
+	is.NoErr(err)
+	_, err = client.FinishStage(ctx, avian.StageRequest{Runner: runners[0].Name, StageID: runners[0].Stages[0].ID})
+	is.NoErr(err)
+	_, err = client.LogInfo(ctx, avian.LogRequest{Runner: runners[0].Name, StageID: int(runners[0].Stages[0].ID), Message: "test"})
+	is.NoErr(err)
+
+	// the stage keeps the highest count of the items, the first count
+	// is written and then the count is throttled until the checkpoint
+	items := func() int64 {
+		var stage api.Stage
+		is.NoErr(db.First(&stage, runners[0].Stages[0].ID).Error)
+		return stage.Items
+	}
+	for _, count := range []int{30, 12, 45} {
+		_, err = client.LogItem(ctx, avian.LogItemRequest{Runner: runners[0].Name, StageID: int(runners[0].Stages[0].ID), Count: count})
+		is.NoErr(err)
+	}
+	is.Equal(items(), int64(30))
+	_, err = client.Checkpoint(ctx, avian.CheckpointRequest{Runner: runners[0].Name, StageID: runners[0].Stages[0].ID, Checkpoint: `{"item":45}`})
+	is.NoErr(err)
+	is.Equal(items(), int64(45))
+
+	// but not another runner or its stages
+	forbidden := func(err error) {
+		t.Helper()
+		is.True(err != nil)
+		is.True(strings.Contains(err.Error(), "forbidden"))
+	}
+	_, err = client.Finish(ctx, avian.RunnerFinishRequest{Runner: runners[1].Name, ID: runners[1].ID})
+	forbidden(err)
+	_, err = client.FinishStage(ctx, avian.StageRequest{Runner: runners[0].Name, StageID: runners[1].Stages[0].ID})
+	forbidden(err)
+	_, err = client.LogError(ctx, avian.LogRequest{Runner: runners[1].Name, Message: "test"})
+	forbidden(err)
+
+	var stage api.Stage
+	is.NoErr(db.Preload("Ocr").First(&stage, runners[1].Stages[0].ID).Error)
+	is.Equal(stage.Ocr.Status, int64(avian.StatusWaiting)) // the other runner's stage is untouched
+}
+
+func TestOwner(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	runner := api.Runner{Name: "runner", Owner: "alice"}
+	is.NoErr(db.Create(&runner).Error)
+
+	client := func(name, role string) *avian.RunnerService {
+		key, err := auth.Create(db, name, role)
+		is.NoErr(err)
+		return avian.NewRunnerService(avian.NewWithKey(srv.URL+"/oto/", key))
+	}
+
+	// investigators can only delete their own runners
+	_, err := client("bob", auth.RoleInvestigator).Delete(ctx, avian.RunnerDeleteRequest{Name: runner.Name})
+	is.True(err != nil)
+	is.Equal(err.Error(), "forbidden: bob (investigator) can only modify their own runners - owned by: alice")
+
+	// viewers cannot delete runners
+	_, err = client("carol", auth.RoleViewer).Delete(ctx, avian.RunnerDeleteRequest{Name: runner.Name})
+	is.True(err != nil)
+	is.Equal(err.Error(), "forbidden: carol (viewer) cannot call: RunnerService.Delete - requires the role: investigator")
+
+	_, err = client("alice", auth.RoleInvestigator).Delete(ctx, avian.RunnerDeleteRequest{Name: runner.Name})
+	is.NoErr(err)
+}
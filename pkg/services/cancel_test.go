@@ -0,0 +1,59 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/matryer/is"
+)
+
+func TestCancel(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	client := func(name, role string) *avian.RunnerService {
+		key, err := auth.Create(db, name, role)
+		is.NoErr(err)
+		return avian.NewRunnerService(avian.NewWithKey(srv.URL+"/oto/", key))
+	}
+	alice := client("alice", auth.RoleInvestigator)
+
+	// a waiting runner and a running runner with a running stage
+	waiting := api.Runner{Name: "waiting", Owner: "alice"}
+	is.NoErr(db.Create(&waiting).Error)
+	running := api.Runner{Name: "running", Owner: "alice", Status: avian.StatusRunning, Stages: []*api.Stage{
+		{Ocr: &api.Ocr{Status: avian.StatusFinished}},
+		{Ocr: &api.Ocr{Status: avian.StatusRunning}},
+	}}
+	is.NoErr(db.Create(&running).Error)
+
+	// investigators can only cancel their own runners
+	_, err := client("bob", auth.RoleInvestigator).Cancel(ctx, avian.RunnerCancelRequest{Name: waiting.Name})
+	is.True(err != nil)
+	is.Equal(err.Error(), "forbidden: bob (investigator) can only modify their own runners - owned by: alice")
+
+	resp, err := alice.Cancel(ctx, avian.RunnerCancelRequest{Name: waiting.Name, Reason: "wrong evidence"})
+	is.NoErr(err)
+	is.Equal(resp.Runner.Exception, "cancelled by: alice - wrong evidence")
+	var cancelled api.Runner
+	is.NoErr(db.First(&cancelled, waiting.ID).Error)
+	is.Equal(cancelled.Status, avian.StatusFailed)
+	is.Equal(cancelled.Exception, "cancelled by: alice - wrong evidence")
+
+	// the running stage is failed, the finished stage is kept
+	_, err = alice.Cancel(ctx, avian.RunnerCancelRequest{Name: running.Name})
+	is.NoErr(err)
+	var stages []api.Stage
+	is.NoErr(db.Preload("Ocr").Order("id").Find(&stages, "runner_id = ?", running.ID).Error)
+	is.Equal(stages[0].Ocr.Status, avian.StatusFinished)
+	is.Equal(stages[1].Ocr.Status, avian.StatusFailed)
+
+	// a runner that has ended cannot be cancelled
+	_, err = alice.Cancel(ctx, avian.RunnerCancelRequest{Name: waiting.Name})
+	is.True(err != nil)
+	is.Equal(err.Error(), "cannot cancel runner: waiting - it has already ended with the status: Failed")
+}
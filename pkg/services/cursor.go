@@ -0,0 +1,120 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// sortField is a field the lists can be sorted by
+type sortField struct {
+	column string
+
+	// numeric is true if the column is compared as a number
+	numeric bool
+}
+
+// cursor is the position after the last row in a page,
+// the value for the sorted column and the id for the row
+// (the id breaks the ties) - encoded as url-safe base64
+type cursor struct {
+	Sort   string `json:"sort"`
+	String string `json:"s,omitempty"`
+	Number int64  `json:"n,omitempty"`
+	ID     uint   `json:"id"`
+}
+
+func (c cursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor decodes the cursor, it must be
+// created for the same sort as the request
+func decodeCursor(value, sortBy string) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", value)
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", value)
+	}
+	if c.Sort != sortBy {
+		return nil, fmt.Errorf("the cursor is for the sort: %s - not: %s", c.Sort, sortBy)
+	}
+	return &c, nil
+}
+
+// parseSort returns the field and the direction for the sort
+// (prefixed with - for descending), sorted by id if empty
+func parseSort(sortBy string, fields map[string]sortField) (sortField, bool, error) {
+	if sortBy == "" {
+		sortBy = "id"
+	}
+	desc := strings.HasPrefix(sortBy, "-")
+	field, ok := fields[strings.TrimPrefix(sortBy, "-")]
+	if !ok {
+		var names []string
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return sortField{}, false, fmt.Errorf("cannot sort by: %s - use one of: %s (prefix with - for descending)", sortBy, strings.Join(names, ", "))
+	}
+	return field, desc, nil
+}
+
+// listPage returns the field and the direction for the sort
+// and the cursor (nil for the first page) for a list-request
+func listPage(sortBy, after string, fields map[string]sortField) (sortField, bool, *cursor, error) {
+	field, desc, err := parseSort(sortBy, fields)
+	if err != nil {
+		return sortField{}, false, nil, err
+	}
+	if after == "" {
+		return field, desc, nil, nil
+	}
+	c, err := decodeCursor(after, sortBy)
+	return field, desc, c, err
+}
+
+// paginate sorts the query by the field (and the id) and
+// continues after the cursor, limit+1 rows are selected
+// to know if there is a next page (every row if limit is 0)
+func paginate(query *gorm.DB, field sortField, desc bool, after *cursor, limit int64) *gorm.DB {
+	op, dir := ">", "asc"
+	if desc {
+		op, dir = "<", "desc"
+	}
+	if after != nil {
+		var value interface{} = after.String
+		if field.numeric {
+			value = after.Number
+		}
+		if field.column == "id" {
+			query = query.Where("id "+op+" ?", after.ID)
+		} else {
+			query = query.Where(fmt.Sprintf("(%[1]s %[2]s ?) OR (%[1]s = ? AND id %[2]s ?)", field.column, op), value, value, after.ID)
+		}
+	}
+	if field.column != "id" {
+		query = query.Order(field.column + " " + dir)
+	}
+	query = query.Order("id " + dir)
+	if limit > 0 {
+		query = query.Limit(limit + 1)
+	}
+	return query
+}
+
+// likePattern converts a pattern with * for any
+// characters to a pattern for LIKE (with \ as escape)
+func likePattern(pattern string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `*`, `%`)
+	return r.Replace(pattern)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+)
+
+// custodyKeyFile is the name of the file in the data-path
+// that holds the key to sign chain of custody documents with
+const custodyKeyFile = "custody.key"
+
+// custodyEvidence is an evidence with its
+// hashed files in the chain of custody document
+type custodyEvidence struct {
+	Name      string          `json:"name"`
+	Directory string          `json:"directory"`
+	Custodian string          `json:"custodian"`
+	Files     []custodyRecord `json:"files"`
+}
+
+// custodyRecord is a hashed evidence-file
+// in the chain of custody document
+type custodyRecord struct {
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	Algorithm  string `json:"algorithm"`
+	Hash       string `json:"hash"`
+	ModifiedAt string `json:"modifiedAt"`
+	HashedAt   string `json:"hashedAt"`
+}
+
+// custodyDocument is the chain of custody for a runner
+type custodyDocument struct {
+	Runner      string            `json:"runner"`
+	Case        string            `json:"case"`
+	Server      string            `json:"server"`
+	GeneratedAt string            `json:"generatedAt"`
+	Evidence    []custodyEvidence `json:"evidence"`
+}
+
+// newCustodyDocument creates a chain of custody document for the runner
+// from the evidence of its process-stages and the stored records
+func newCustodyDocument(runner api.Runner, records map[uint][]api.CustodyRecord) custodyDocument {
+	doc := custodyDocument{
+		Runner:      runner.Name,
+		Server:      runner.Hostname,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if runner.CaseSettings != nil && runner.CaseSettings.Case != nil {
+		doc.Case = runner.CaseSettings.Case.Name
+	}
+
+	for _, stage := range runner.Stages {
+		if stage.Process == nil {
+			continue
+		}
+		for _, evidence := range stage.Process.EvidenceStore {
+			e := custodyEvidence{
+				Name:      evidence.Name,
+				Directory: evidence.Directory,
+				Custodian: evidence.Custodian,
+				Files:     []custodyRecord{},
+			}
+			for _, record := range records[evidence.ID] {
+				e.Files = append(e.Files, custodyRecord{
+					Path:       record.Path,
+					Size:       record.Size,
+					Algorithm:  record.Algorithm,
+					Hash:       record.Hash,
+					ModifiedAt: time.Unix(record.ModifiedAt, 0).UTC().Format(time.RFC3339),
+					HashedAt:   time.Unix(record.HashedAt, 0).UTC().Format(time.RFC3339),
+				})
+			}
+			doc.Evidence = append(doc.Evidence, e)
+		}
+	}
+	return doc
+}
+
+// encode encodes the document to the specified format (json or csv)
+func (doc custodyDocument) encode(format string) ([]byte, error) {
+	switch format {
+	case "", "json":
+		return json.MarshalIndent(doc, "", "  ")
+	case "csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write([]string{"runner", "case", "evidence", "custodian", "path", "size", "algorithm", "hash", "modified_at", "hashed_at"})
+		for _, e := range doc.Evidence {
+			for _, f := range e.Files {
+				w.Write([]string{
+					doc.Runner,
+					doc.Case,
+					e.Name,
+					e.Custodian,
+					f.Path,
+					strconv.FormatInt(f.Size, 10),
+					f.Algorithm,
+					f.Hash,
+					f.ModifiedAt,
+					f.HashedAt,
+				})
+			}
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	}
+	return nil, fmt.Errorf("unknown format for chain of custody: %s - use 'json' or 'csv'", format)
+}
+
+// custodyKey returns the key to sign the chain of custody with,
+// the key is created in the data-path if it doesn't exist
+func custodyKey(dataPath string) (ed25519.PrivateKey, error) {
+	path := filepath.Join(dataPath, custodyKeyFile)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate custody-key: %v", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal custody-key: %v", err)
+		}
+		data = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write custody-key: %v", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read custody-key: %v", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode custody-key: %s", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse custody-key: %v", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("custody-key is not an ed25519-key: %s", path)
+	}
+	return key, nil
+}
+
+// signCustody signs the document with the key,
+// returns the base64-encoded signature and public-key
+func signCustody(key ed25519.PrivateKey, document []byte) (string, string) {
+	signature := ed25519.Sign(key, document)
+	public := key.Public().(ed25519.PublicKey)
+	return base64.StdEncoding.EncodeToString(signature), base64.StdEncoding.EncodeToString(public)
+}
+
+// custodyFingerprint returns the hex-encoded sha256 for the public-key,
+// the signatures are only trusted for the fingerprint that is pinned
+func custodyFingerprint(key ed25519.PrivateKey) string {
+	sum := sha256.Sum256(key.Public().(ed25519.PublicKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// CustodyFingerprint returns the fingerprint for the key that signs the
+// chain of custody (the key is created if it doesn't exist), the service
+// logs it at start so the verifiers can pin it
+func CustodyFingerprint(dataPath string) (string, error) {
+	key, err := custodyKey(dataPath)
+	if err != nil {
+		return "", err
+	}
+	return custodyFingerprint(key), nil
+}
@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/logging"
+	"github.com/avian-digital-forensics/auto-processing/pkg/services"
+	"github.com/jinzhu/gorm"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestCustody(t *testing.T) {
+	is := is.New(t)
+	db, err := gorm.Open("sqlite3", ":memory:")
+	is.NoErr(err)
+	defer db.Close()
+	is.NoErr(tables.Migrate(db))
+
+	dataPath, err := ioutil.TempDir("", "avian-data")
+	is.NoErr(err)
+	defer os.RemoveAll(dataPath)
+
+	evidence := &api.Evidence{Name: "laptop", Directory: `\\fs\evidence\laptop`, Custodian: "simon"}
+	runner := api.Runner{
+		Name:         "runner",
+		Hostname:     "dev01",
+		CaseSettings: &api.CaseSettings{Case: &api.Case{Name: "case-1"}},
+		Stages:       []*api.Stage{{Process: &api.Process{HashEvidence: true, EvidenceStore: []*api.Evidence{evidence}}}},
+	}
+	is.NoErr(db.Create(&runner).Error)
+	for _, path := range []string{`\\fs\evidence\laptop\b.pst`, `\\fs\evidence\laptop\a.pst`} {
+		is.NoErr(db.Create(&api.CustodyRecord{EvidenceID: evidence.ID, Path: path, Size: 1024, Algorithm: "sha256", Hash: "e3b0c442", ModifiedAt: 1600000000, HashedAt: 1600000100}).Error)
+	}
+
+	// a new service is a restart - it reuses the key in the data-path
+	custody := func(format string) *api.CustodyResponse {
+		service := services.NewRunnerService(db, nil, zap.NewNop(), logging.New(dataPath), "", dataPath, ruby.DefaultTemplates(), events.NewBroker(10))
+		resp, err := service.Custody(context.Background(), api.CustodyRequest{Name: "runner", Format: format})
+		is.NoErr(err)
+		return resp
+	}
+	verify := func(resp *api.CustodyResponse) {
+		public, err := base64.StdEncoding.DecodeString(resp.PublicKey)
+		is.NoErr(err)
+		signature, err := base64.StdEncoding.DecodeString(resp.Signature)
+		is.NoErr(err)
+		is.True(ed25519.Verify(public, []byte(resp.Document), signature))
+		is.True(!ed25519.Verify(public, []byte(resp.Document+"\n"), signature)) // exactly the signed bytes
+	}
+
+	doc := custody("json")
+	verify(doc)
+	var document struct {
+		Runner   string `json:"runner"`
+		Case     string `json:"case"`
+		Evidence []struct {
+			Custodian string `json:"custodian"`
+			Files     []struct {
+				Path       string `json:"path"`
+				ModifiedAt string `json:"modifiedAt"`
+			} `json:"files"`
+		} `json:"evidence"`
+	}
+	is.NoErr(json.Unmarshal([]byte(doc.Document), &document))
+	is.Equal(document.Case, "case-1")
+	is.Equal(document.Evidence[0].Custodian, "simon")
+	is.Equal(document.Evidence[0].Files[0].Path, `\\fs\evidence\laptop\a.pst`) // sorted by path
+	is.Equal(document.Evidence[0].Files[0].ModifiedAt, "2020-09-13T12:26:40Z")
+
+	list := custody("csv")
+	verify(list)
+	rows, err := csv.NewReader(strings.NewReader(list.Document)).ReadAll()
+	is.NoErr(err)
+	is.Equal(len(rows), 3)
+	is.Equal(rows[1][:5], []string{"runner", "case-1", "laptop", "simon", `\\fs\evidence\laptop\a.pst`})
+
+	is.Equal(doc.PublicKey, list.PublicKey)
+	_, err = os.Stat(filepath.Join(dataPath, "custody.key"))
+	is.NoErr(err)
+
+	// the key and the fingerprint to pin are the same as
+	// for the signatures and the fingerprint that is logged
+	service := services.NewRunnerService(db, nil, zap.NewNop(), logging.New(dataPath), "", dataPath, ruby.DefaultTemplates(), events.NewBroker(10))
+	key, err := service.CustodyKey(context.Background(), api.CustodyKeyRequest{})
+	is.NoErr(err)
+	block, _ := pem.Decode([]byte(key.PublicKey))
+	is.True(block != nil) // the public-key is PEM-encoded
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	is.NoErr(err)
+	public, err := base64.StdEncoding.DecodeString(doc.PublicKey)
+	is.NoErr(err)
+	is.Equal(parsed.(ed25519.PublicKey), ed25519.PublicKey(public))
+	sum := sha256.Sum256(public)
+	is.Equal(key.Fingerprint, hex.EncodeToString(sum[:]))
+	is.Equal(doc.Fingerprint, key.Fingerprint)
+	logged, err := services.CustodyFingerprint(dataPath)
+	is.NoErr(err)
+	is.Equal(logged, key.Fingerprint)
+}
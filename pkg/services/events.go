@@ -0,0 +1,53 @@
+package services
+
+import (
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/metrics"
+)
+
+// statusDeleted is the status in the event for a deleted runner
+const statusDeleted = "Deleted"
+
+// The state-changes for the runners and the stages and the log-lines
+// from the runner-scripts are published to the event-stream
+// (RunnerService.Events) as they are handled.
+
+// PublishRunner publishes the status for the runner
+func (s RunnerService) PublishRunner(runner api.Runner) {
+	s.events.Publish(events.Event{
+		Type:      events.TypeRunner,
+		Runner:    runner.Name,
+		Status:    avian.Status(runner.Status),
+		Exception: runner.Exception,
+	})
+}
+
+// PublishStage publishes the status for the stage
+// (and observes its duration when it has ended)
+func (s RunnerService) PublishStage(runner string, stage *api.Stage) {
+	metrics.ObserveStage(stage, time.Now())
+	s.events.Publish(events.Event{
+		Type:    events.TypeStage,
+		Runner:  runner,
+		StageID: stage.ID,
+		Stage:   avian.Name(stage),
+		Status:  avian.Status(avian.StageState(stage)),
+	})
+}
+
+// publishLog publishes the log-line from the runner-script
+func (s RunnerService) publishLog(level string, r api.LogRequest) {
+	s.events.Publish(events.Event{
+		Type:      events.TypeLog,
+		Runner:    r.Runner,
+		StageID:   uint(r.StageID),
+		Stage:     r.Stage,
+		Level:     level,
+		Message:   r.Message,
+		Exception: r.Exception,
+	})
+}
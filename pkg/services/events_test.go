@@ -0,0 +1,82 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/matryer/is"
+)
+
+func TestEvents(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	runner := api.Runner{Name: "runner", Stages: []*api.Stage{{Ocr: &api.Ocr{}}}}
+	is.NoErr(db.Create(&runner).Error)
+
+	runnerKey, err := auth.CreateRunnerKey(db, runner.ID, runner.Name)
+	is.NoErr(err)
+	script := avian.NewRunnerService(avian.NewWithKey(srv.URL+"/oto/", runnerKey))
+
+	viewerKey, err := auth.Create(db, "viewer", auth.RoleViewer)
+	is.NoErr(err)
+	cli := avian.NewRunnerService(avian.NewWithKey(srv.URL+"/oto/", viewerKey))
+
+	// follow the stream for the runner
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	followed := make(chan events.Event, 10)
+	done := make(chan error)
+	go func() {
+		done <- cli.Events(ctx, avian.EventsRequest{Runner: runner.Name, Follow: true}, func(e events.Event) error {
+			followed <- e
+			return nil
+		})
+	}()
+	time.Sleep(100 * time.Millisecond) // let the stream subscribe
+
+	_, err = script.Start(ctx, avian.RunnerStartRequest{Runner: runner.Name, ID: runner.ID})
+	is.NoErr(err)
+	_, err = script.StartStage(ctx, avian.StageRequest{Runner: runner.Name, StageID: runner.Stages[0].ID})
+	is.NoErr(err)
+	_, err = script.LogError(ctx, avian.LogRequest{Runner: runner.Name, StageID: int(runner.Stages[0].ID), Stage: "ocr", Message: "failed to ocr", Exception: "boom"})
+	is.NoErr(err)
+
+	for _, expected := range []events.Event{
+		{Type: events.TypeRunner, Runner: runner.Name, Status: "Running"},
+		{Type: events.TypeStage, Runner: runner.Name, StageID: runner.Stages[0].ID, Stage: "OCR", Status: "Running"},
+		{Type: events.TypeLog, Runner: runner.Name, StageID: runner.Stages[0].ID, Stage: "ocr", Level: "error", Message: "failed to ocr", Exception: "boom"},
+	} {
+		select {
+		case e := <-followed:
+			is.True(e.ID != 0)
+			e.ID, e.Time = 0, time.Time{}
+			is.Equal(e, expected)
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for event")
+		}
+	}
+	cancel()
+	is.NoErr(<-done)
+
+	// the history for the logs (without following)
+	var logs []events.Event
+	err = cli.Events(context.Background(), avian.EventsRequest{Runner: runner.Name, Types: []string{events.TypeLog}, History: true}, func(e events.Event) error {
+		logs = append(logs, e)
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(len(logs), 1)
+	is.Equal(logs[0].Message, "failed to ocr")
+
+	// the runner-key cannot read the stream
+	err = script.Events(context.Background(), avian.EventsRequest{History: true}, func(events.Event) error { return nil })
+	is.True(err != nil)
+	is.Equal(err.Error(), "forbidden: the runner-key for: runner cannot call: RunnerService.Events")
+}
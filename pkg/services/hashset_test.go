@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/matryer/is"
+)
+
+func TestHashSetMatches(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	// a runner that tags the items matching two lists and excludes
+	// the items matching a third, and another runner with a list
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01", AvianScripts: `C:\avian-scripts`}).Error)
+	runner := api.Runner{Name: "runner-1", Hostname: "dev01", Stages: []*api.Stage{
+		{HashSet: &api.HashSet{Action: "tag", Tag: "Known|NSRL", Lists: []*api.HashList{
+			{Path: `\\fs\hashes\nsrl.txt`, Algorithm: "md5"},
+			{Path: `\\fs\hashes\vendor.txt`, Algorithm: "sha1"},
+		}}},
+		{HashSet: &api.HashSet{Action: "exclude", Reason: "Known system-file", Lists: []*api.HashList{
+			{Path: `\\fs\hashes\system.txt`, Algorithm: "md5"},
+		}}},
+	}}
+	is.NoErr(db.Create(&runner).Error)
+	other := api.Runner{Name: "runner-2", Stages: []*api.Stage{
+		{HashSet: &api.HashSet{Action: "tag", Tag: "Known", Lists: []*api.HashList{{Path: `\\fs\hashes\other.txt`, Algorithm: "md5"}}}},
+	}}
+	is.NoErr(db.Create(&other).Error)
+
+	key, err := auth.CreateRunnerKey(db, runner.ID, runner.Name)
+	is.NoErr(err)
+	client := avian.NewRunnerService(avian.NewWithKey(srv.URL+"/oto/", key))
+
+	// the plan has the action for each stage
+	plan, err := client.Plan(ctx, avian.RunnerPlanRequest{Runner: runner.Name})
+	is.NoErr(err)
+	is.Equal(len(plan.Stages), 2)
+	is.Equal(plan.Stages[0].Kind, "hashSet")
+	is.Equal(plan.Stages[0].Stage.HashSet.Action, "tag")
+	is.Equal(plan.Stages[0].Stage.HashSet.Tag, "Known|NSRL")
+	is.Equal(plan.Stages[1].Stage.HashSet.Action, "exclude")
+	is.Equal(plan.Stages[1].Stage.HashSet.Reason, "Known system-file")
+
+	// the matches are recorded for each list
+	tag, exclude := runner.Stages[0], runner.Stages[1]
+	report := func(stage *api.Stage, list *api.HashList, matches int64) error {
+		_, err := client.HashSetMatches(ctx, avian.HashSetMatchesRequest{Runner: runner.Name, StageID: stage.ID, ListID: list.ID, Matches: matches})
+		return err
+	}
+	is.NoErr(report(tag, tag.HashSet.Lists[0], 120))
+	is.NoErr(report(tag, tag.HashSet.Lists[1], 7))
+	is.NoErr(report(exclude, exclude.HashSet.Lists[0], 3400))
+
+	var lists []api.HashList
+	is.NoErr(db.Order("id").Find(&lists, "hash_set_id IN (?)", []uint{tag.HashSet.ID, exclude.HashSet.ID}).Error)
+	is.Equal(lists[0].Matches, int64(120))
+	is.Equal(lists[1].Matches, int64(7))
+	is.Equal(lists[2].Matches, int64(3400))
+
+	// but not for a list in another stage or for another runner
+	err = report(tag, exclude.HashSet.Lists[0], 1)
+	is.True(err != nil && strings.Contains(err.Error(), "forbidden"))
+	err = report(other.Stages[0], other.Stages[0].HashSet.Lists[0], 1)
+	is.True(err != nil && strings.Contains(err.Error(), "forbidden"))
+	var untouched api.HashList
+	is.NoErr(db.First(&untouched, other.Stages[0].HashSet.Lists[0].ID).Error)
+	is.Equal(untouched.Matches, int64(0))
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"go.uber.org/zap"
+)
+
+// itemsInterval is how often the amount of items is written for a stage
+// while the runner-script logs items, it is also written when the stage
+// reports a checkpoint and when it finishes or fails
+const itemsInterval = 30 * time.Second
+
+// itemCounts keeps the highest count of the logged items for the running
+// stages, so a stage is not updated in the db for every logged item
+type itemCounts struct {
+	mu     sync.Mutex
+	stages map[uint]*itemCount
+}
+
+type itemCount struct {
+	count     int64
+	written   int64
+	writtenAt time.Time
+}
+
+func newItemCounts() *itemCounts {
+	return &itemCounts{stages: make(map[uint]*itemCount)}
+}
+
+// log keeps the count for the stage if it is the highest, ok is true
+// if the count should be written - the first count for the stage
+// and then at most once for every itemsInterval
+func (c *itemCounts) log(stageID uint, count int64, now time.Time) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stage, found := c.stages[stageID]
+	if !found {
+		stage = &itemCount{}
+		c.stages[stageID] = stage
+	}
+	if count > stage.count {
+		stage.count = count
+	}
+	if stage.count <= stage.written || now.Sub(stage.writtenAt) < itemsInterval {
+		return 0, false
+	}
+	stage.written = stage.count
+	stage.writtenAt = now
+	return stage.count, true
+}
+
+// flush returns the count for the stage that has not been written,
+// the stage is forgotten if it has ended
+func (c *itemCounts) flush(stageID uint, ended bool, now time.Time) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stage, found := c.stages[stageID]
+	if !found {
+		return 0, false
+	}
+	if ended {
+		delete(c.stages, stageID)
+	}
+	if stage.count <= stage.written {
+		return 0, false
+	}
+	stage.written = stage.count
+	stage.writtenAt = now
+	return stage.count, true
+}
+
+// writeItems keeps the highest count as the amount of items for the stage
+func (s RunnerService) writeItems(logger *zap.Logger, stageID uint, count int64) {
+	if err := s.DB.Model(&api.Stage{}).
+		Where("id = ? AND items < ?", stageID, count).
+		UpdateColumn("items", count).Error; err != nil {
+		logger.Error("Cannot update the items for the stage", zap.String("exception", err.Error()))
+	}
+}
+
+// flushItems writes the logged items for the stage that has not been written
+func (s RunnerService) flushItems(logger *zap.Logger, stageID uint, ended bool) {
+	if count, ok := s.items.flush(stageID, ended, time.Now()); ok {
+		s.writeItems(logger, stageID, count)
+	}
+}
@@ -0,0 +1,170 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/matryer/is"
+)
+
+func TestListRunners(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	// 25 runners - every fifth is running on dev02 for simon
+	for i := 1; i <= 25; i++ {
+		runner := api.Runner{Name: fmt.Sprintf("case-%02d", i), Hostname: "dev01", Nms: "nms01", Owner: "lisa", Status: avian.StatusFinished}
+		if i%5 == 0 {
+			runner.Hostname, runner.Owner, runner.Status = "dev02", "simon", avian.StatusRunning
+		}
+		is.NoErr(db.Create(&runner).Error)
+	}
+	is.NoErr(db.Create(&api.Runner{Name: "other_1", Hostname: "dev01", Nms: "nms02", Status: avian.StatusFailed}).Error)
+	// the investigator for the case is not the owner of the runner
+	is.NoErr(db.Create(&api.Runner{Name: "other_2", Hostname: "dev01", Nms: "nms02", Owner: "simon", Status: avian.StatusFailed,
+		CaseSettings: &api.CaseSettings{Case: &api.Case{Name: "other", Investigator: "Lisa Larsson"}}}).Error)
+
+	key, err := auth.Create(db, "viewer", auth.RoleViewer)
+	is.NoErr(err)
+	runners := avian.NewRunnerService(avian.NewWithKey(srv.URL+"/oto/", key))
+	names := func(r avian.RunnerListRequest) ([]string, string) {
+		resp, err := runners.List(ctx, r)
+		is.NoErr(err)
+		var list []string
+		for _, runner := range resp.Runners {
+			list = append(list, runner.Name)
+		}
+		return list, resp.NextCursor
+	}
+
+	// the filters
+	list, next := names(avian.RunnerListRequest{Status: []string{"running"}, Hostname: "dev02", Owner: "simon"})
+	is.Equal(list, []string{"case-05", "case-10", "case-15", "case-20", "case-25"})
+	is.Equal(next, "")
+	list, _ = names(avian.RunnerListRequest{Status: []string{"Failed", "running"}, Nms: "nms02"})
+	is.Equal(list, []string{"other_1", "other_2"})
+	list, _ = names(avian.RunnerListRequest{Name: "case-2*"})
+	is.Equal(len(list), 6)
+	list, _ = names(avian.RunnerListRequest{Name: "other_*"})
+	is.Equal(list, []string{"other_1", "other_2"})
+	list, _ = names(avian.RunnerListRequest{Investigator: "Lisa Larsson"})
+	is.Equal(list, []string{"other_2"})
+	list, _ = names(avian.RunnerListRequest{Investigator: "simon"})
+	is.Equal(len(list), 0)
+	list, _ = names(avian.RunnerListRequest{Name: "othe%"}) // not a wildcard
+	is.Equal(len(list), 0)
+	future := time.Now().Add(time.Hour)
+	list, _ = names(avian.RunnerListRequest{CreatedAfter: &future})
+	is.Equal(len(list), 0)
+
+	// every page is listed by following the cursors
+	var all []string
+	request := avian.RunnerListRequest{Sort: "-status", Limit: 4}
+	for {
+		list, next := names(request)
+		is.True(len(list) <= 4)
+		all = append(all, list...)
+		if next == "" {
+			break
+		}
+		request.Cursor = next
+	}
+	is.Equal(len(all), 27)
+	is.Equal(all[:6], []string{"case-24", "case-23", "case-22", "case-21", "case-19", "case-18"}) // finished first (by id desc)
+	is.Equal(all[20:], []string{"other_2", "other_1", "case-25", "case-20", "case-15", "case-10", "case-05"})
+
+	list, next = names(avian.RunnerListRequest{Sort: "name", Limit: 25})
+	is.Equal(list[0], "case-01")
+	is.True(next != "")
+	list, next = names(avian.RunnerListRequest{Sort: "name", Limit: 25, Cursor: next})
+	is.Equal(list, []string{"other_1", "other_2"})
+	is.Equal(next, "")
+
+	// invalid requests
+	_, err = runners.List(ctx, avian.RunnerListRequest{Sort: "owner"})
+	is.Equal(err.Error(), "cannot sort by: owner - use one of: created, id, name, status (prefix with - for descending)")
+	_, err = runners.List(ctx, avian.RunnerListRequest{Status: []string{"done"}})
+	is.Equal(err.Error(), "unknown status: done - use waiting, running, failed, finished or timeout")
+	_, err = runners.List(ctx, avian.RunnerListRequest{Sort: "id", Cursor: request.Cursor})
+	is.Equal(err.Error(), "the cursor is for the sort: -status - not: id")
+}
+
+func TestListServers(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	for i, hostname := range []string{"dev03", "dev01", "prod01", "dev02"} {
+		is.NoErr(db.Create(&api.Server{Hostname: hostname, Port: 5985, Active: i%2 == 0}).Error)
+	}
+
+	key, err := auth.Create(db, "viewer", auth.RoleViewer)
+	is.NoErr(err)
+	servers := avian.NewServerService(avian.NewWithKey(srv.URL+"/oto/", key))
+	hostnames := func(r avian.ServerListRequest) ([]string, string) {
+		resp, err := servers.List(ctx, r)
+		is.NoErr(err)
+		var list []string
+		for _, server := range resp.Servers {
+			list = append(list, server.Hostname)
+		}
+		return list, resp.NextCursor
+	}
+
+	list, next := hostnames(avian.ServerListRequest{})
+	is.Equal(list, []string{"dev03", "dev01", "prod01", "dev02"}) // every server without a limit
+	is.Equal(next, "")
+	active := true
+	list, _ = hostnames(avian.ServerListRequest{Hostname: "dev*", Active: &active})
+	is.Equal(list, []string{"dev03"})
+
+	list, next = hostnames(avian.ServerListRequest{Sort: "-hostname", Limit: 3})
+	is.Equal(list, []string{"prod01", "dev03", "dev02"})
+	list, next = hostnames(avian.ServerListRequest{Sort: "-hostname", Limit: 3, Cursor: next})
+	is.Equal(list, []string{"dev01"})
+	is.Equal(next, "")
+
+	_, err = servers.List(ctx, avian.ServerListRequest{Sort: "port"})
+	is.Equal(err.Error(), "cannot sort by: port - use one of: created, hostname, id (prefix with - for descending)")
+}
+
+func TestListNms(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	is.NoErr(db.Create(&api.Nms{Address: "10.0.0.2", Licences: []api.Licence{{Type: "enterprise-workstation", Amount: 2}}}).Error)
+	is.NoErr(db.Create(&api.Nms{Address: "10.0.0.1", Licences: []api.Licence{{Type: "enterprise-reviewer", Amount: 1}}}).Error)
+	is.NoErr(db.Create(&api.Nms{Address: "10.1.0.1", Licences: []api.Licence{{Type: "enterprise-workstation", Amount: 4}}}).Error)
+
+	key, err := auth.Create(db, "viewer", auth.RoleViewer)
+	is.NoErr(err)
+	nmsService := avian.NewNmsService(avian.NewWithKey(srv.URL+"/oto/", key))
+	addresses := func(r avian.NmsListRequest) ([]string, string) {
+		resp, err := nmsService.List(ctx, r)
+		is.NoErr(err)
+		var list []string
+		for _, nms := range resp.Nms {
+			is.Equal(len(nms.Licences), 1) // the licences are listed with the nms
+			list = append(list, nms.Address)
+		}
+		return list, resp.NextCursor
+	}
+
+	list, _ := addresses(avian.NmsListRequest{Licence: "enterprise-workstation"})
+	is.Equal(list, []string{"10.0.0.2", "10.1.0.1"})
+	list, _ = addresses(avian.NmsListRequest{Address: "10.0.*", Sort: "address"})
+	is.Equal(list, []string{"10.0.0.1", "10.0.0.2"})
+
+	list, next := addresses(avian.NmsListRequest{Sort: "address", Limit: 2})
+	is.Equal(list, []string{"10.0.0.1", "10.0.0.2"})
+	list, next = addresses(avian.NmsListRequest{Sort: "address", Limit: 2, Cursor: next})
+	is.Equal(list, []string{"10.1.0.1"})
+	is.Equal(next, "")
+}
@@ -2,13 +2,13 @@ package services
 
 import (
 	"context"
-	"crypto/tls"
-	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/nms"
 	"go.uber.org/zap"
 
 	"github.com/jinzhu/gorm"
@@ -28,17 +28,28 @@ func NewNmsService(db *gorm.DB, logger *zap.Logger) NmsService {
 
 // Apply applies the nms-servers to the database
 func (s NmsService) Apply(ctx context.Context, r api.NmsApplyRequests) (*api.NmsApplyResponse, error) {
+	for _, n := range r.Nms {
+		if err := nms.ValidAuth(n.Auth); err != nil {
+			return nil, fmt.Errorf("nms: %s - %v", n.Address, err)
+		}
+		if err := nms.ValidFingerprint(n.Fingerprint); err != nil {
+			return nil, fmt.Errorf("nms: %s - %v", n.Address, err)
+		}
+	}
+
 	// Start transaction to fall back
 	// if we get an error
 	s.logger.Debug("Starting db-transaction for NMS-apply")
 	tx := s.db.BeginTx(ctx, nil)
 
-	// Create http-client for testing the nms with insecure tls
-	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-		Timeout: 10 * time.Second,
+	// Create http-client for testing the nms with the tls for the nms
+	newClient := func(n api.NmsApplyRequest) *http.Client {
+		return &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: nms.TLSConfig(n.Fingerprint, n.Insecure),
+			},
+			Timeout: 10 * time.Second,
+		}
 	}
 
 	// Iterate over the requested nm-servers
@@ -46,7 +57,7 @@ func (s NmsService) Apply(ctx context.Context, r api.NmsApplyRequests) (*api.Nms
 	var resp api.NmsApplyResponse
 	for _, nms := range r.Nms {
 		// Test the http-connection to the NMS
-		if _, err := client.Get(fmt.Sprintf("https://%s:%d", nms.Address, nms.Port)); err != nil {
+		if _, err := newClient(nms).Get(fmt.Sprintf("https://%s:%d", nms.Address, nms.Port)); err != nil {
 			return nil, fmt.Errorf("Cannot establish connection to NMS with address: %s:%d - %v", nms.Address, nms.Port, err)
 		}
 
@@ -69,6 +80,11 @@ func (s NmsService) Apply(ctx context.Context, r api.NmsApplyRequests) (*api.Nms
 		newNms.Password = nms.Password
 		newNms.Workers = nms.Workers
 		newNms.IsRelay = nms.IsRelay
+		newNms.PoolsPath = nms.PoolsPath
+		newNms.LeasesPath = nms.LeasesPath
+		newNms.Auth = nms.Auth
+		newNms.Fingerprint = nms.Fingerprint
+		newNms.Insecure = nms.Insecure
 
 		// Create hash-map for the existing licences
 		s.logger.Debug("Creating hash-map for nms-licences", zap.String("nms", nms.Address))
@@ -120,16 +136,180 @@ func (s NmsService) Apply(ctx context.Context, r api.NmsApplyRequests) (*api.Nms
 // List lists the NMS-servers from the database
 func (s NmsService) List(ctx context.Context, r api.NmsListRequest) (*api.NmsListResponse, error) {
 	s.logger.Debug("Getting NMS-list")
+	query := s.db.Model(&api.Nms{})
+	if r.Address != "" {
+		query = query.Where(`address LIKE ? ESCAPE '\'`, likePattern(r.Address))
+	}
+	if r.Licence != "" {
+		licensed := s.db.Model(&api.Licence{}).Select("nms_id").Where("type = ?", r.Licence)
+		query = query.Where("id IN ?", licensed.SubQuery())
+	}
+
+	field, desc, after, err := listPage(r.Sort, r.Cursor, nmsSortFields)
+	if err != nil {
+		return nil, err
+	}
+
 	var nms []api.Nms
-	if err := s.db.Preload("Licences").Find(&nms).Error; err != nil {
+	if err := paginate(query, field, desc, after, r.Limit).Preload("Licences").Find(&nms).Error; err != nil {
 		s.logger.Error("Cannot get NMS-list", zap.String("exception", err.Error()))
 		return nil, err
 	}
+
+	var resp api.NmsListResponse
+	if r.Limit > 0 && int64(len(nms)) > r.Limit {
+		nms = nms[:r.Limit]
+		last := nms[len(nms)-1]
+		next := cursor{Sort: r.Sort, ID: last.ID}
+		switch field.column {
+		case "address":
+			next.String = last.Address
+		case "c_time":
+			next.Number = last.CTime
+		}
+		resp.NextCursor = next.encode()
+	}
+	resp.Nms = nms
 	s.logger.Debug("Got NMS-list", zap.Int("amount", len(nms)))
-	return &api.NmsListResponse{Nms: nms}, nil
+	return &resp, nil
 }
 
-// ListLicences lists licences for the specified NMS-server
+// nmsSortFields are the fields the nms-servers can be sorted by
+var nmsSortFields = map[string]sortField{
+	"id":      {column: "id", numeric: true},
+	"address": {column: "address"},
+	"created": {column: "c_time", numeric: true},
+}
+
+// ListLicences lists the licences for the specified NMS-server, with
+// the licence-pools and the leases from the NMS compared with the
+// licences reserved by the runners - the licences from the database
+// are listed (with the exception) if the NMS cannot be queried
 func (s NmsService) ListLicences(ctx context.Context, r api.NmsListLicencesRequest) (*api.NmsListLicencesResponse, error) {
-	return nil, errors.New("not implemented")
+	query := s.db.Preload("Licences")
+	switch {
+	case r.NmsID != 0:
+		query = query.Where("id = ?", r.NmsID)
+	case r.Address != "":
+		query = query.Where("address = ?", r.Address)
+	default:
+		return nil, fmt.Errorf("specify the id or the address for the nms")
+	}
+
+	var server api.Nms
+	if err := query.First(&server).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			if r.NmsID != 0 {
+				return nil, fmt.Errorf("nms with id: %d does not exist", r.NmsID)
+			}
+			return nil, fmt.Errorf("nms: %s does not exist", r.Address)
+		}
+		s.logger.Error("Cannot get nms-server", zap.String("nms", r.Address), zap.String("exception", err.Error()))
+		return nil, err
+	}
+	resp := api.NmsListLicencesResponse{Nms: server, Licences: server.Licences}
+
+	s.logger.Debug("Getting licences from NMS", zap.String("nms", server.Address))
+	client := nms.New(server)
+	pools, err := client.Pools(ctx)
+	if err != nil {
+		s.logger.Error("Cannot get licence-pools from NMS", zap.String("nms", server.Address), zap.String("exception", err.Error()))
+		resp.Exception = err.Error()
+		return &resp, nil
+	}
+	leases, err := client.Leases(ctx)
+	if err != nil {
+		s.logger.Error("Cannot get licence-leases from NMS", zap.String("nms", server.Address), zap.String("exception", err.Error()))
+		resp.Exception = err.Error()
+		return &resp, nil
+	}
+
+	resp.Usage = nms.Compare(server.Licences, pools, leases)
+	for _, lease := range leases {
+		resp.Leases = append(resp.Leases, api.LicenceLease{
+			Type:     lease.Type,
+			Workers:  lease.Workers,
+			User:     lease.User,
+			Host:     lease.Host,
+			LeasedAt: lease.LeasedAt,
+		})
+	}
+	for _, u := range resp.Usage {
+		if u.Mismatch != "" {
+			s.logger.Warn("Licences in the NMS does not match the service",
+				zap.String("nms", server.Address),
+				zap.String("licence", u.Type),
+				zap.String("mismatch", u.Mismatch),
+			)
+		}
+	}
+	return &resp, nil
+}
+
+// Get returns the NMS with its licences and the runners that uses it
+func (s NmsService) Get(ctx context.Context, r api.NmsGetRequest) (*api.NmsGetResponse, error) {
+	logger := s.logger.With(zap.String("nms", r.Address))
+
+	var server api.Nms
+	if err := s.db.Preload("Licences").First(&server, "address = ?", r.Address).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("nms: %s does not exist", r.Address)
+		}
+		logger.Error("Cannot get nms-server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	runners, total, err := linkedRunners(s.db, r.Limit, "nms = ?", r.Address)
+	if err != nil {
+		logger.Error("Cannot get the runners for the nms-server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	return &api.NmsGetResponse{Nms: server, Runners: runners, TotalRunners: total}, nil
+}
+
+// Delete deletes the NMS and its licences from the db, refused
+// while unfinished runners uses the NMS (unless forced)
+func (s NmsService) Delete(ctx context.Context, r api.NmsDeleteRequest) (*api.NmsDeleteResponse, error) {
+	logger := s.logger.With(zap.String("nms", r.Address))
+
+	var server api.Nms
+	if err := s.db.First(&server, "address = ?", r.Address).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("nms: %s does not exist", r.Address)
+		}
+		logger.Error("Cannot get nms-server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	runners, err := unfinishedRunners(s.db, "nms = ?", r.Address)
+	if err != nil {
+		logger.Error("Cannot get the runners for the nms-server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if len(runners) > 0 {
+		if !r.Force {
+			logger.Error("Cannot delete nms-server used by runners", zap.Strings("runners", runners))
+			return nil, fmt.Errorf("nms: %s is used by the unfinished runners: %s - use force to delete it", r.Address, strings.Join(runners, ", "))
+		}
+		logger.Warn("Forcing delete of nms-server used by runners", zap.Strings("runners", runners))
+	}
+
+	tx := s.db.Begin()
+	if err := tx.Where("nms_id = ?", server.ID).Delete(&api.Licence{}).Error; err != nil {
+		tx.Rollback()
+		logger.Error("Cannot delete the licences for the nms-server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if err := tx.Delete(&server).Error; err != nil {
+		tx.Rollback()
+		logger.Error("Cannot delete nms-server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		logger.Error("Cannot delete nms-server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	logger.Info("NMS has been deleted")
+	return &api.NmsDeleteResponse{}, nil
 }
@@ -0,0 +1,137 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/nms"
+	"github.com/matryer/is"
+)
+
+// newFakeNms returns a fake NMS with the licence-endpoints on the paths
+// for the config (or the defaults), it requires the username and password
+// avian/secret with basic-auth - or the token secret with bearer-auth
+// (and the fingerprint for its self-signed certificate)
+func newFakeNms(t *testing.T, config api.Nms, pools []nms.Pool, leases []nms.Lease) (string, int64, string) {
+	is := is.New(t)
+	mux := http.NewServeMux()
+	respond := func(v interface{}) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			authorized := r.Header.Get("Authorization") == "Bearer secret"
+			if config.Auth != nms.AuthBearer {
+				username, password, ok := r.BasicAuth()
+				authorized = ok && username == "avian" && password == "secret"
+			}
+			if !authorized {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(v)
+		}
+	}
+	poolsPath, leasesPath := nms.DefaultPoolsPath, nms.DefaultLeasesPath
+	if config.PoolsPath != "" {
+		poolsPath = config.PoolsPath
+	}
+	if config.LeasesPath != "" {
+		leasesPath = config.LeasesPath
+	}
+	mux.Handle(poolsPath, respond(pools))
+	mux.Handle(leasesPath, respond(leases))
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	is.NoErr(err)
+	p, err := strconv.ParseInt(port, 10, 64)
+	is.NoErr(err)
+	sum := sha256.Sum256(srv.Certificate().Raw)
+	return host, p, hex.EncodeToString(sum[:])
+}
+
+func TestListLicences(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	address, port, fingerprint := newFakeNms(t, api.Nms{},
+		[]nms.Pool{{Type: "enterprise-workstation", Total: 4, Available: 2}, {Type: "eDiscovery", Total: 2, Available: 2}},
+		[]nms.Lease{{Type: "enterprise-workstation", Workers: 4, User: "avian", Host: "dev01"}, {Type: "enterprise-workstation", Workers: 2, User: "someone", Host: "dev02"}},
+	)
+	is.NoErr(db.Create(&api.Nms{
+		Address:     address,
+		Port:        port,
+		Username:    "avian",
+		Password:    "secret",
+		Fingerprint: fingerprint,
+		Licences:    []api.Licence{{Type: "enterprise-workstation", Amount: 4, InUse: 1}, {Type: "enterprise-reviewer", Amount: 1}},
+	}).Error)
+
+	key, err := auth.Create(db, "viewer", auth.RoleViewer)
+	is.NoErr(err)
+	client := avian.NewNmsService(avian.NewWithKey(srv.URL+"/oto/", key))
+
+	resp, err := client.ListLicences(ctx, avian.NmsListLicencesRequest{Address: address})
+	is.NoErr(err)
+	is.Equal(resp.Exception, "")
+	is.Equal(len(resp.Licences), 2)
+	is.Equal(len(resp.Leases), 2)
+	is.Equal(resp.Usage, []avian.LicenceUsage{
+		{Type: "eDiscovery", Pool: 2, Mismatch: "not configured in the service"},
+		{Type: "enterprise-reviewer", Amount: 1, Mismatch: "not in the NMS"},
+		{Type: "enterprise-workstation", Amount: 4, InUse: 1, Pool: 4, Leased: 2, Mismatch: "2 leased in the NMS, 1 reserved by the runners"},
+	})
+
+	// the licences in the service are listed if the NMS cannot be queried
+	is.NoErr(db.Model(&api.Nms{}).Where("address = ?", address).Update("password", "wrong").Error)
+	resp, err = client.ListLicences(ctx, avian.NmsListLicencesRequest{NmsID: resp.Nms.ID})
+	is.NoErr(err)
+	is.Equal(resp.Exception, "NMS responded with status: 401 for "+nms.DefaultPoolsPath+" - unauthorized")
+	is.Equal(len(resp.Licences), 2)
+	is.Equal(len(resp.Usage), 0)
+
+	// the certificate for the NMS is verified unless it is pinned (or insecure)
+	is.NoErr(db.Model(&api.Nms{}).Where("address = ?", address).Update("fingerprint", "").Error)
+	resp, err = client.ListLicences(ctx, avian.NmsListLicencesRequest{Address: address})
+	is.NoErr(err)
+	is.True(strings.Contains(resp.Exception, "certificate signed by unknown authority"))
+
+	_, err = client.ListLicences(ctx, avian.NmsListLicencesRequest{Address: "unknown"})
+	is.Equal(err.Error(), "nms: unknown does not exist")
+
+	// the paths and the auth are configured per NMS
+	config := api.Nms{PoolsPath: "/nms/licences/pools", LeasesPath: "/nms/licences/leases", Auth: nms.AuthBearer}
+	_, port, _ = newFakeNms(t, config, []nms.Pool{{Type: "enterprise-workstation", Total: 1, Available: 1}}, nil)
+	config.Address, config.Port, config.Password, config.Insecure = "localhost", port, "secret", true
+	is.NoErr(db.Create(&config).Error)
+	resp, err = client.ListLicences(ctx, avian.NmsListLicencesRequest{Address: "localhost"})
+	is.NoErr(err)
+	is.Equal(resp.Exception, "")
+	is.Equal(resp.Usage, []avian.LicenceUsage{{Type: "enterprise-workstation", Pool: 1, Mismatch: "not configured in the service"}})
+}
+
+func TestApplyNmsAuth(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+
+	key, err := auth.Create(db, "admin", auth.RoleAdmin)
+	is.NoErr(err)
+	client := avian.NewNmsService(avian.NewWithKey(srv.URL+"/oto/", key))
+	_, err = client.Apply(context.Background(), avian.NmsApplyRequests{Nms: []avian.NmsApplyRequest{{Address: "license.avian.dk", Auth: "token"}}})
+	is.True(err != nil)
+	is.Equal(err.Error(), "nms: license.avian.dk - unknown auth for the NMS: token - use basic or bearer")
+	_, err = client.Apply(context.Background(), avian.NmsApplyRequests{Nms: []avian.NmsApplyRequest{{Address: "license.avian.dk", Fingerprint: "ab:cd"}}})
+	is.True(err != nil)
+	is.Equal(err.Error(), "nms: license.avian.dk - invalid fingerprint for the NMS: ab:cd - use the sha256 for the certificate in hex")
+}
@@ -2,16 +2,27 @@ package services
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
 	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/inapp"
 	"github.com/avian-digital-forensics/auto-processing/pkg/logging"
+	"github.com/avian-digital-forensics/auto-processing/pkg/metrics"
 	"github.com/avian-digital-forensics/auto-processing/pkg/pwsh"
+	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
 
 	"github.com/jinzhu/gorm"
 	"go.uber.org/zap"
@@ -26,6 +37,9 @@ type RunnerService struct {
 	logHandler logging.Service
 	dataPath   string
 	serviceURL string
+	templates  *ruby.Templates
+	events     *events.Broker
+	items      *itemCounts
 }
 
 // NewRunnerService creates a new RunnerService
@@ -34,7 +48,9 @@ func NewRunnerService(
 	shell pwsh.Powershell,
 	logger *zap.Logger,
 	logHandler logging.Service,
-	serviceURL, dataPath string) RunnerService {
+	serviceURL, dataPath string,
+	templates *ruby.Templates,
+	broker *events.Broker) RunnerService {
 	return RunnerService{
 		DB:         db,
 		shell:      shell,
@@ -42,6 +58,9 @@ func NewRunnerService(
 		logHandler: logHandler,
 		dataPath:   dataPath,
 		serviceURL: serviceURL,
+		templates:  templates,
+		events:     broker,
+		items:      newItemCounts(),
 	}
 }
 
@@ -74,9 +93,11 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 		Licence:      r.Licence,
 		Xmx:          r.Xmx,
 		Workers:      r.Workers,
+		MaxRuntime:   r.MaxRuntime,
 		CaseSettings: r.CaseSettings,
 		Stages:       r.Stages,
 		Switches:     switches,
+		Notify:       strings.Join(r.Notify, ","),
 	}
 
 	// Validate the runner
@@ -106,6 +127,12 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 			return nil, fmt.Errorf("runner: %s already exist, create a new runner by a unique name", runner.Name)
 		}
 
+		if err := auth.AuthorizeOwner(ctx, fromDB.Owner); err != nil {
+			logger.Error("Cannot update runner", zap.String("exception", err.Error()))
+			tx.Rollback()
+			return nil, err
+		}
+
 		if fromDB.Active {
 			logger.Error("Runner is active, cannot update an active runner")
 			tx.Rollback()
@@ -113,6 +140,8 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 		}
 
 		runner.ID = fromDB.ID
+		runner.CTime = fromDB.CTime
+		runner.Owner = fromDB.Owner
 		runner.CaseSettings.ID = fromDB.CaseSettings.ID
 		runner.CaseSettings.Case.ID = fromDB.CaseSettings.ID
 		runner.CaseSettings.Case.ElasticSearch.ID = fromDB.CaseSettings.Case.ElasticSearch.ID
@@ -141,6 +170,16 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 				}
 			}
 
+			if stage.HashSet != nil {
+				for _, list := range stage.HashSet.Lists {
+					if err := tx.Delete(&list).Error; err != nil {
+						tx.Rollback()
+						logger.Error("Failed to delete hash-list", zap.String("exception", err.Error()))
+						return nil, fmt.Errorf("failed to delete hash-list: %v", err)
+					}
+				}
+			}
+
 			if err := tx.Delete(&stage).Error; err != nil {
 				tx.Rollback()
 				logger.Error("Failed to delete stage", zap.String("stage", avian.Name(stage)), zap.String("exception", err.Error()))
@@ -194,6 +233,9 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 	// Add the runner to the db
 	logger.Info("Saving runner to DB")
 	runner.Status = avian.StatusWaiting
+	if runner.Owner == "" {
+		runner.Owner = auth.Identity(ctx)
+	}
 	if err := tx.Save(&runner).Error; err != nil {
 		tx.Rollback()
 		logger.Error("Cannot to save runner to DB", zap.String("exception", err.Error()))
@@ -207,29 +249,92 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 	}
 
 	logger.Info("Runner has been created")
+	s.PublishRunner(runner)
 	return &api.RunnerApplyResponse{Runner: runner}, nil
 }
 
 // List all runners from the database
 func (s RunnerService) List(ctx context.Context, r api.RunnerListRequest) (*api.RunnerListResponse, error) {
 	s.logger.Debug("Getting runners-list")
+	query := s.DB.Model(&api.Runner{})
+	if len(r.Status) > 0 {
+		var statuses []int64
+		for _, name := range r.Status {
+			status, err := avian.ParseStatus(name)
+			if err != nil {
+				return nil, err
+			}
+			statuses = append(statuses, status)
+		}
+		query = query.Where("status IN (?)", statuses)
+	}
+	if r.Hostname != "" {
+		query = query.Where("hostname = ?", r.Hostname)
+	}
+	if r.Nms != "" {
+		query = query.Where("nms = ?", r.Nms)
+	}
+	if r.Owner != "" {
+		query = query.Where("owner = ?", r.Owner)
+	}
+	if r.Investigator != "" {
+		// the investigator for the single-case in the case-settings
+		investigated := s.DB.Table("case_settings").
+			Select("case_settings.id").
+			Joins("JOIN cases ON cases.id = case_settings.case_id").
+			Where("cases.investigator = ?", r.Investigator)
+		query = query.Where("case_settings_id IN ?", investigated.SubQuery())
+	}
+	if r.CreatedAfter != nil {
+		query = query.Where("c_time > ?", r.CreatedAfter.Unix())
+	}
+	if r.Name != "" {
+		query = query.Where(`name LIKE ? ESCAPE '\'`, likePattern(r.Name))
+	}
+
+	field, desc, after, err := listPage(r.Sort, r.Cursor, runnerSortFields)
+	if err != nil {
+		return nil, err
+	}
+	limit := r.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
 	var runners []api.Runner
-	err := s.DB.Preload("Stages.Process").
-		Preload("Stages.SearchAndTag").
-		Preload("Stages.Exclude").
-		Preload("Stages.Ocr").
-		Preload("Stages.Reload").
-		Preload("Stages.Populate").
-		Preload("Stages.InApp").
-		Preload("Stages.SyncDescendants").
-		Preload("Stages.ScanNewChildItems").
+	err = tables.PreloadStages(paginate(query, field, desc, after, limit), "Stages.").
 		Find(&runners).Error
 	if err != nil {
 		s.logger.Error("Cannot get runners-list", zap.String("exception", err.Error()))
 		return nil, err
 	}
+
+	var resp api.RunnerListResponse
+	if int64(len(runners)) > limit {
+		runners = runners[:limit]
+		last := runners[len(runners)-1]
+		next := cursor{Sort: r.Sort, ID: last.ID}
+		switch field.column {
+		case "name":
+			next.String = last.Name
+		case "c_time":
+			next.Number = last.CTime
+		case "status":
+			next.Number = last.Status
+		}
+		resp.NextCursor = next.encode()
+	}
+	resp.Runners = runners
 	s.logger.Debug("Got Runners-list", zap.Int("amount", len(runners)))
-	return &api.RunnerListResponse{Runners: runners}, nil
+	return &resp, nil
+}
+
+// runnerSortFields are the fields the runners can be sorted by
+var runnerSortFields = map[string]sortField{
+	"id":      {column: "id", numeric: true},
+	"name":    {column: "name"},
+	"created": {column: "c_time", numeric: true},
+	"status":  {column: "status", numeric: true},
 }
 
 // Get the specified runner from the db
@@ -261,16 +366,8 @@ func (s RunnerService) Delete(ctx context.Context, r api.RunnerDeleteRequest) (*
 	tx := s.DB.Begin()
 
 	var runner api.Runner
-	err := tx.Preload("Switches").
-		Preload("Stages.Process").
-		Preload("Stages.SearchAndTag").
-		Preload("Stages.Exclude").
-		Preload("Stages.Ocr").
-		Preload("Stages.Reload").
-		Preload("Stages.Populate").
-		Preload("Stages.InApp").
-		Preload("Stages.SyncDescendants").
-		Preload("Stages.ScanNewChildItems").
+	err := tables.PreloadStages(tx, "Stages.").
+		Preload("Switches").
 		Preload("CaseSettings.Case.ElasticSearch").
 		Preload("CaseSettings.CompoundCase").
 		Preload("CaseSettings.ReviewCompound").
@@ -281,6 +378,12 @@ func (s RunnerService) Delete(ctx context.Context, r api.RunnerDeleteRequest) (*
 		return nil, err
 	}
 
+	if err := auth.AuthorizeOwner(ctx, runner.Owner); err != nil {
+		tx.Rollback()
+		s.logger.Error("Cannot delete runner", zap.String("runner", r.Name), zap.String("exception", err.Error()))
+		return nil, err
+	}
+
 	// check if the runner is active
 	// unless the delete is forced
 	if runner.Active {
@@ -333,13 +436,102 @@ func (s RunnerService) Delete(ctx context.Context, r api.RunnerDeleteRequest) (*
 		return nil, err
 	}
 
+	s.events.Publish(events.Event{Type: events.TypeRunner, Runner: runner.Name, Status: statusDeleted})
+
 	return &api.RunnerDeleteResponse{}, nil
 }
 
+// Cancel cancels the specified runner, a waiting runner is not started
+// by the queue and the nuix-process for an active runner is stopped -
+// the runner is set to failed with the cancel as its exception
+func (s RunnerService) Cancel(ctx context.Context, r api.RunnerCancelRequest) (*api.RunnerCancelResponse, error) {
+	logger := s.logger.With(zap.String("runner", r.Name))
+	var runner = api.Runner{Name: r.Name}
+	if err := getPreloadedRunner(s.DB, &runner); err != nil {
+		logger.Error("Cannot get runner", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot get runner: %s - %v", r.Name, err)
+	}
+
+	if err := auth.AuthorizeOwner(ctx, runner.Owner); err != nil {
+		logger.Error("Cannot cancel runner", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	if runner.Status != avian.StatusWaiting && runner.Status != avian.StatusRunning {
+		return nil, fmt.Errorf("cannot cancel runner: %s - it has already ended with the status: %s", runner.Name, avian.Status(runner.Status))
+	}
+
+	// stop the nuix-process on the remote machine
+	// if the runner has been started by the queue
+	if runner.Active {
+		logger.Info("Cancelling active runner", zap.String("server", runner.Hostname))
+		if err := s.StopRunner(runner); err != nil {
+			logger.Error("Cannot stop runner", zap.String("exception", err.Error()))
+		}
+	}
+
+	// set the running stages to failed
+	for _, stage := range runner.Stages {
+		if avian.StageState(stage) != avian.StatusRunning {
+			continue
+		}
+		if count, ok := s.items.flush(stage.ID, true, time.Now()); ok && count > stage.Items {
+			stage.Items = count
+		}
+		avian.SetStatusFailed(stage)
+		if err := s.DB.Save(stage).Error; err != nil {
+			logger.Error("Cannot save the cancelled stage", zap.Int("stage_id", int(stage.ID)), zap.String("exception", err.Error()))
+			return nil, fmt.Errorf("cannot save stage: %d - %v", stage.ID, err)
+		}
+		s.PublishStage(runner.Name, stage)
+	}
+
+	exception := "cancelled"
+	if identity := auth.Identity(ctx); identity != "" {
+		exception += " by: " + identity
+	}
+	if r.Reason != "" {
+		exception += " - " + r.Reason
+	}
+
+	active := runner.Active
+	runner.Status = avian.StatusFailed
+	runner.Active = false
+	runner.Exception = exception
+	if err := s.DB.Model(&api.Runner{}).Where("id = ?", runner.ID).Updates(map[string]interface{}{
+		"status":    runner.Status,
+		"active":    runner.Active,
+		"exception": runner.Exception,
+	}).Error; err != nil {
+		logger.Error("Cannot save the cancelled runner", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot save runner: %v", err)
+	}
+	logger.Info("CANCELLED RUNNER", zap.String("reason", exception))
+	s.PublishRunner(runner)
+
+	// release the server, nms and script for the active runner
+	if active {
+		if err := s.SetServerActivity(runner, false); err != nil {
+			logger.Error("Cannot set servers activity", zap.String("exception", err.Error()))
+		}
+		if err := s.ResetNms(runner); err != nil {
+			logger.Error("Cannot reset nms for runner", zap.String("exception", err.Error()))
+		}
+		if err := s.RemoveScript(runner); err != nil {
+			logger.Error("Cannot remove script for runner", zap.String("exception", err.Error()))
+		}
+	}
+
+	return &api.RunnerCancelResponse{Runner: runner}, nil
+}
+
 // Start the specified runner (used by ruby script)
 func (s RunnerService) Start(ctx context.Context, r api.RunnerStartRequest) (*api.RunnerStartResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("runner_id", int(r.ID)))
 	logger.Info("STARTING RUNNER")
+	if err := s.authorizeRunner(ctx, r.ID); err != nil {
+		return nil, err
+	}
 	var runner api.Runner
 	if err := s.DB.First(&runner, r.ID).Error; err != nil {
 		logger.Error("Cannot get runner", zap.String("exception", err.Error()))
@@ -348,11 +540,14 @@ func (s RunnerService) Start(ctx context.Context, r api.RunnerStartRequest) (*ap
 	now := time.Now()
 	runner.Status = avian.StatusRunning
 	runner.HealthyAt = &now
+	runner.StartedAt = &now
 	runner.CaseID = r.CaseID
+	runner.Exception = ""
 	if err := s.DB.Save(&runner).Error; err != nil {
 		logger.Error("Cannot save the started runner", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("cannot save runner: %v", err)
 	}
+	s.PublishRunner(runner)
 
 	return &api.RunnerStartResponse{}, nil
 }
@@ -361,6 +556,9 @@ func (s RunnerService) Start(ctx context.Context, r api.RunnerStartRequest) (*ap
 func (s RunnerService) Failed(ctx context.Context, r api.RunnerFailedRequest) (*api.RunnerFailedResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("runner_id", int(r.ID)))
 	logger.Info("FAILED RUNNER")
+	if err := s.authorizeRunner(ctx, r.ID); err != nil {
+		return nil, err
+	}
 	var runner api.Runner
 	if err := s.DB.First(&runner, r.ID).Error; err != nil {
 		logger.Error("Cannot get runner", zap.String("exception", err.Error()))
@@ -368,10 +566,12 @@ func (s RunnerService) Failed(ctx context.Context, r api.RunnerFailedRequest) (*
 	}
 	runner.Status = avian.StatusFailed
 	runner.Active = false
+	runner.Exception = r.Exception
 	if err := s.DB.Save(&runner).Error; err != nil {
 		logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("cannot save runner: %v", err)
 	}
+	s.PublishRunner(runner)
 
 	// Set servers activity
 	if err := s.SetServerActivity(runner, false); err != nil {
@@ -394,6 +594,9 @@ func (s RunnerService) Failed(ctx context.Context, r api.RunnerFailedRequest) (*
 func (s RunnerService) Finish(ctx context.Context, r api.RunnerFinishRequest) (*api.RunnerFinishResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("runner_id", int(r.ID)))
 	logger.Info("FINISHED RUNNER")
+	if err := s.authorizeRunner(ctx, r.ID); err != nil {
+		return nil, err
+	}
 
 	var runner api.Runner
 	if err := s.DB.First(&runner, r.ID).Error; err != nil {
@@ -406,6 +609,7 @@ func (s RunnerService) Finish(ctx context.Context, r api.RunnerFinishRequest) (*
 		logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("cannot save runner: %v", err)
 	}
+	s.PublishRunner(runner)
 
 	// Set servers activity
 	if err := s.SetServerActivity(runner, false); err != nil {
@@ -428,6 +632,9 @@ func (s RunnerService) Finish(ctx context.Context, r api.RunnerFinishRequest) (*
 func (s RunnerService) Heartbeat(ctx context.Context, r api.RunnerStartRequest) (*api.RunnerStartResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("runner_id", int(r.ID)))
 	logger.Debug("Retrieved heartbeat from runner")
+	if err := s.authorizeRunner(ctx, r.ID); err != nil {
+		return nil, err
+	}
 	if err := s.DB.Model(&api.Runner{}).Where("id = ?", r.ID).Update("healthy_at", time.Now()).Error; err != nil {
 		logger.Error("Failed to update healthy_at", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("Failed to update healthy_at: %v", err)
@@ -439,21 +646,19 @@ func (s RunnerService) Heartbeat(ctx context.Context, r api.RunnerStartRequest)
 func (s RunnerService) StartStage(ctx context.Context, r api.StageRequest) (*api.StageResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("stage_id", int(r.StageID)))
 	logger.Debug("StartStage request")
+	if err := s.authorizeStage(ctx, r.StageID); err != nil {
+		return nil, err
+	}
 	var stage api.Stage
-	if err := s.DB.Preload("Process").
-		Preload("SearchAndTag").
-		Preload("Exclude").
-		Preload("Reload").
-		Preload("Populate").
-		Preload("Ocr").
-		Preload("InApp").
-		Preload("SyncDescendants").
+	if err := tables.PreloadStages(s.DB, "").
 		First(&stage, r.StageID).Error; err != nil {
 		logger.Error("Cannot get the requested stage", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("did not get requested stage : %v", err)
 	}
 
 	logger.Debug("Set stage-status to running", zap.Int("stage_id", int(r.StageID)))
+	now := time.Now()
+	stage.StartedAt = &now
 	avian.SetStatusRunning(&stage)
 	if err := s.DB.Save(&stage).Error; err != nil {
 		logger.Error("Cannot set stage-status to running", zap.String("exception", err.Error()))
@@ -461,6 +666,7 @@ func (s RunnerService) StartStage(ctx context.Context, r api.StageRequest) (*api
 	}
 
 	logger.Info("STARTING STAGE", zap.String("stage", avian.Name(&stage)))
+	s.PublishStage(r.Runner, &stage)
 	return &api.StageResponse{Stage: stage}, nil
 }
 
@@ -468,15 +674,12 @@ func (s RunnerService) StartStage(ctx context.Context, r api.StageRequest) (*api
 func (s RunnerService) FailedStage(ctx context.Context, r api.StageRequest) (*api.StageResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("stage_id", int(r.StageID)))
 	logger.Debug("FailedStage request")
+	if err := s.authorizeStage(ctx, r.StageID); err != nil {
+		return nil, err
+	}
+	s.flushItems(logger, r.StageID, true)
 	var stage api.Stage
-	if err := s.DB.Preload("Process").
-		Preload("SearchAndTag").
-		Preload("Exclude").
-		Preload("Reload").
-		Preload("Populate").
-		Preload("Ocr").
-		Preload("InApp").
-		Preload("SyncDescendants").
+	if err := tables.PreloadStages(s.DB, "").
 		First(&stage, r.StageID).Error; err != nil {
 		logger.Error("Cannot get the requested stage", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("did not get requested stage : %v", err)
@@ -490,6 +693,7 @@ func (s RunnerService) FailedStage(ctx context.Context, r api.StageRequest) (*ap
 	}
 
 	logger.Info("FAILED STAGE", zap.String("stage", avian.Name(&stage)))
+	s.PublishStage(r.Runner, &stage)
 	return &api.StageResponse{Stage: stage}, nil
 }
 
@@ -497,15 +701,12 @@ func (s RunnerService) FailedStage(ctx context.Context, r api.StageRequest) (*ap
 func (s RunnerService) FinishStage(ctx context.Context, r api.StageRequest) (*api.StageResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("stage_id", int(r.StageID)))
 	logger.Debug("FinishStage request")
+	if err := s.authorizeStage(ctx, r.StageID); err != nil {
+		return nil, err
+	}
+	s.flushItems(logger, r.StageID, true)
 	var stage api.Stage
-	if err := s.DB.Preload("Process").
-		Preload("SearchAndTag").
-		Preload("Exclude").
-		Preload("Reload").
-		Preload("Populate").
-		Preload("Ocr").
-		Preload("InApp").
-		Preload("SyncDescendants").
+	if err := tables.PreloadStages(s.DB, "").
 		First(&stage, r.StageID).Error; err != nil {
 		logger.Error("Cannot get the requested stage", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("did not get requested stage : %v", err)
@@ -513,17 +714,231 @@ func (s RunnerService) FinishStage(ctx context.Context, r api.StageRequest) (*ap
 
 	logger.Debug("Set stage-status to finished", zap.Int("stage_id", int(r.StageID)))
 	avian.SetStatusFinished(&stage)
+	// the checkpoint is only used to resume an unfinished stage
+	stage.Checkpoint = ""
 	if err := s.DB.Save(&stage).Error; err != nil {
 		logger.Error("Cannot set stage-status to finished", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("failed to update stage to running: %v", err)
 	}
 
 	logger.Info("FINISHED STAGE", zap.String("stage", avian.Name(&stage)))
+	s.PublishStage(r.Runner, &stage)
 	return &api.StageResponse{Stage: stage}, nil
 }
 
+// Checkpoint stores the checkpoint for a stage (used by ruby script)
+func (s RunnerService) Checkpoint(ctx context.Context, r api.CheckpointRequest) (*api.CheckpointResponse, error) {
+	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("stage_id", int(r.StageID)))
+	logger.Debug("Checkpoint request", zap.String("checkpoint", r.Checkpoint))
+	if err := s.authorizeStage(ctx, r.StageID); err != nil {
+		return nil, err
+	}
+
+	if !json.Valid([]byte(r.Checkpoint)) {
+		logger.Error("Invalid checkpoint for stage", zap.String("checkpoint", r.Checkpoint))
+		return nil, fmt.Errorf("invalid checkpoint for stage: %d - must be JSON", r.StageID)
+	}
+
+	s.flushItems(logger, r.StageID, false)
+	if err := s.DB.Model(&api.Stage{}).Where("id = ?", r.StageID).Update("checkpoint", r.Checkpoint).Error; err != nil {
+		logger.Error("Cannot store checkpoint for stage", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("failed to store checkpoint for stage: %d - %v", r.StageID, err)
+	}
+	return &api.CheckpointResponse{}, nil
+}
+
+// HashSetMatches sets the matches for a hash-list (used by ruby script)
+func (s RunnerService) HashSetMatches(ctx context.Context, r api.HashSetMatchesRequest) (*api.HashSetMatchesResponse, error) {
+	logger := s.logger.With(
+		zap.String("runner", r.Runner),
+		zap.Int("stage_id", int(r.StageID)),
+		zap.Int("list_id", int(r.ListID)),
+	)
+	logger.Debug("HashSetMatches request")
+	if err := s.authorizeStage(ctx, r.StageID); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeHashList(ctx, r.StageID, r.ListID); err != nil {
+		return nil, err
+	}
+
+	var list api.HashList
+	if err := s.DB.First(&list, r.ListID).Error; err != nil {
+		logger.Error("Cannot get the requested hash-list", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("did not get requested hash-list : %v", err)
+	}
+
+	if err := s.DB.Model(&list).Update("matches", r.Matches).Error; err != nil {
+		logger.Error("Cannot update matches for hash-list", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("failed to update matches for hash-list: %v", err)
+	}
+
+	logger.Info("Matched items from hash-list", zap.String("path", list.Path), zap.Int64("matches", r.Matches))
+	return &api.HashSetMatchesResponse{}, nil
+}
+
+// ArchiveResult sets the result for an archived case (used by ruby script)
+func (s RunnerService) ArchiveResult(ctx context.Context, r api.ArchiveResultRequest) (*api.ArchiveResultResponse, error) {
+	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("stage_id", int(r.StageID)))
+	logger.Debug("ArchiveResult request")
+	if err := s.authorizeStage(ctx, r.StageID); err != nil {
+		return nil, err
+	}
+
+	var archive api.Archive
+	if err := s.DB.First(&archive, "stage_id = ?", r.StageID).Error; err != nil {
+		logger.Error("Cannot get the requested archive-stage", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("did not get requested archive-stage : %v", err)
+	}
+
+	archive.Location = r.Location
+	archive.Files = r.Files
+	archive.Mismatches = r.Mismatches
+	archive.Verified = r.Mismatches == 0
+	if err := s.DB.Save(&archive).Error; err != nil {
+		logger.Error("Cannot save the result for archive-stage", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("failed to save result for archive-stage: %v", err)
+	}
+
+	logger.Info("Archived case",
+		zap.String("location", r.Location),
+		zap.Int64("files", r.Files),
+		zap.Int64("mismatches", r.Mismatches),
+		zap.Bool("verified", archive.Verified),
+	)
+	return &api.ArchiveResultResponse{}, nil
+}
+
+// CustodyRecords stores the hashes for evidence-files (used by ruby script)
+func (s RunnerService) CustodyRecords(ctx context.Context, r api.CustodyRecordsRequest) (*api.CustodyRecordsResponse, error) {
+	logger := s.logger.With(
+		zap.String("runner", r.Runner),
+		zap.Int("stage_id", int(r.StageID)),
+		zap.Int("evidence_id", int(r.EvidenceID)),
+	)
+	logger.Debug("CustodyRecords request", zap.Int("records", len(r.Records)))
+	if err := s.authorizeStage(ctx, r.StageID); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeEvidence(ctx, r.StageID, r.EvidenceID); err != nil {
+		return nil, err
+	}
+
+	if s.DB.First(&api.Evidence{}, r.EvidenceID).RecordNotFound() {
+		logger.Error("Cannot find the evidence for the custody-records")
+		return nil, fmt.Errorf("evidence: %d does not exist", r.EvidenceID)
+	}
+
+	tx := s.DB.Begin()
+	for _, record := range r.Records {
+		// Replace the record if the evidence-file
+		// has been hashed before (processing resumed)
+		if err := tx.Where("evidence_id = ? AND path = ?", r.EvidenceID, record.Path).Delete(&api.CustodyRecord{}).Error; err != nil {
+			tx.Rollback()
+			logger.Error("Cannot delete previous custody-record", zap.String("path", record.Path), zap.String("exception", err.Error()))
+			return nil, fmt.Errorf("failed to delete previous custody-record: %v", err)
+		}
+
+		record.ID = 0
+		record.EvidenceID = r.EvidenceID
+		if err := tx.Create(&record).Error; err != nil {
+			tx.Rollback()
+			logger.Error("Cannot create custody-record", zap.String("path", record.Path), zap.String("exception", err.Error()))
+			return nil, fmt.Errorf("failed to create custody-record: %v", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Error("Cannot commit custody-records", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("failed to store custody-records: %v", err)
+	}
+
+	logger.Info("Stored custody-records", zap.Int("records", len(r.Records)))
+	return &api.CustodyRecordsResponse{}, nil
+}
+
+// Custody returns the signed chain of custody for the specified runner
+func (s RunnerService) Custody(ctx context.Context, r api.CustodyRequest) (*api.CustodyResponse, error) {
+	logger := s.logger.With(zap.String("runner", r.Name), zap.String("format", r.Format))
+	logger.Debug("Getting chain of custody")
+
+	var runner = api.Runner{Name: r.Name}
+	if err := getPreloadedRunner(s.DB, &runner); err != nil {
+		logger.Error("Cannot get runner", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	var evidenceIDs []uint
+	for _, stage := range runner.Stages {
+		if stage.Process == nil {
+			continue
+		}
+		for _, evidence := range stage.Process.EvidenceStore {
+			evidenceIDs = append(evidenceIDs, evidence.ID)
+		}
+	}
+
+	var records []api.CustodyRecord
+	if err := s.DB.Where("evidence_id IN (?)", evidenceIDs).Order("path").Find(&records).Error; err != nil {
+		logger.Error("Cannot get custody-records", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("failed to get custody-records: %v", err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no evidence has been hashed for runner: %s - set hashEvidence for the process-stage", r.Name)
+	}
+
+	recordsByEvidence := make(map[uint][]api.CustodyRecord)
+	for _, record := range records {
+		recordsByEvidence[record.EvidenceID] = append(recordsByEvidence[record.EvidenceID], record)
+	}
+
+	document, err := newCustodyDocument(runner, recordsByEvidence).encode(r.Format)
+	if err != nil {
+		logger.Error("Cannot encode chain of custody", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	key, err := custodyKey(s.dataPath)
+	if err != nil {
+		logger.Error("Cannot get custody-key", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	signature, publicKey := signCustody(key, document)
+
+	logger.Info("Exported chain of custody", zap.Int("records", len(records)))
+	return &api.CustodyResponse{
+		Document:    string(document),
+		Signature:   signature,
+		PublicKey:   publicKey,
+		Fingerprint: custodyFingerprint(key),
+	}, nil
+}
+
+// CustodyKey returns the public-key that signs the chain of custody
+func (s RunnerService) CustodyKey(ctx context.Context, r api.CustodyKeyRequest) (*api.CustodyKeyResponse, error) {
+	key, err := custodyKey(s.dataPath)
+	if err != nil {
+		s.logger.Error("Cannot get custody-key", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public custody-key: %v", err)
+	}
+	return &api.CustodyKeyResponse{
+		PublicKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		Fingerprint: custodyFingerprint(key),
+	}, nil
+}
+
 // LogItem logs an item that has been processed
 func (s RunnerService) LogItem(ctx context.Context, r api.LogItemRequest) (*api.LogResponse, error) {
+	if err := s.authorizeLog(ctx, r.Runner, r.StageID); err != nil {
+		return nil, err
+	}
+
 	logger, err := s.logHandler.Get(r.Runner + "-item.log")
 	if err != nil {
 		return nil, err
@@ -560,12 +975,26 @@ func (s RunnerService) LogItem(ctx context.Context, r api.LogItemRequest) (*api.
 		logger = logger.With(zap.Strings("flags", flags))
 	}
 
+	metrics.ItemsLogged.Inc()
+
+	// keep the highest count as the amount of items for the stage,
+	// it is written to the stage at most once for every itemsInterval
+	if r.StageID != 0 {
+		if count, ok := s.items.log(uint(r.StageID), int64(r.Count), time.Now()); ok {
+			s.writeItems(s.logger.With(zap.String("runner", r.Runner), zap.Int("stage_id", r.StageID)), uint(r.StageID), count)
+		}
+	}
+
 	logger.Debug(r.Message)
 	return &api.LogResponse{}, nil
 }
 
 // LogDebug logs a debug-message (used by ruby script)
 func (s RunnerService) LogDebug(ctx context.Context, r api.LogRequest) (*api.LogResponse, error) {
+	if err := s.authorizeLog(ctx, r.Runner, r.StageID); err != nil {
+		return nil, err
+	}
+
 	logger, err := s.logHandler.Get(r.Runner + "-runner.log")
 	if err != nil {
 		return nil, err
@@ -581,11 +1010,16 @@ func (s RunnerService) LogDebug(ctx context.Context, r api.LogRequest) (*api.Log
 	}
 
 	logger.Debug(r.Message)
+	s.publishLog("debug", r)
 	return &api.LogResponse{}, nil
 }
 
 // LogInfo logs an info-message (used by ruby script)
 func (s RunnerService) LogInfo(ctx context.Context, r api.LogRequest) (*api.LogResponse, error) {
+	if err := s.authorizeLog(ctx, r.Runner, r.StageID); err != nil {
+		return nil, err
+	}
+
 	logger, err := s.logHandler.Get(r.Runner + "-runner.log")
 	if err != nil {
 		return nil, err
@@ -597,11 +1031,16 @@ func (s RunnerService) LogInfo(ctx context.Context, r api.LogRequest) (*api.LogR
 	}
 
 	logger.Info(r.Message)
+	s.publishLog("info", r)
 	return &api.LogResponse{}, nil
 }
 
 // LogError logs a error-message (used by ruby script)
 func (s RunnerService) LogError(ctx context.Context, r api.LogRequest) (*api.LogResponse, error) {
+	if err := s.authorizeLog(ctx, r.Runner, r.StageID); err != nil {
+		return nil, err
+	}
+
 	logger, err := s.logHandler.Get(r.Runner + "-runner.log")
 	if err != nil {
 		return nil, err
@@ -616,6 +1055,7 @@ func (s RunnerService) LogError(ctx context.Context, r api.LogRequest) (*api.Log
 	}
 
 	logger.Error(r.Message)
+	s.publishLog("error", r)
 	return &api.LogResponse{}, nil
 }
 
@@ -645,12 +1085,14 @@ func (s RunnerService) ResetNms(runner api.Runner) error {
 		return err
 	}
 
-	// Reset the licences for the nms
+	// Reset the licences for the nms - the licence in the nms is
+	// updated, since the licences are saved again with the nms
 	nms.InUse = nms.InUse - runner.Workers
-	for _, lic := range nms.Licences {
+	for i := range nms.Licences {
+		lic := &nms.Licences[i]
 		if lic.Type == runner.Licence {
 			lic.InUse = lic.InUse - 1
-			if err := s.DB.Save(&lic).Error; err != nil {
+			if err := s.DB.Save(lic).Error; err != nil {
 				s.logger.Error("Cannot update licence-information to DB",
 					zap.String("runner", runner.Name),
 					zap.String("nms", runner.Nms),
@@ -677,15 +1119,7 @@ func (s RunnerService) ResetNms(runner api.Runner) error {
 
 // getPreloadedRunner gets the rnner with its stages
 func getPreloadedRunner(db *gorm.DB, runner *api.Runner) error {
-	return db.Preload("Stages.Process.EvidenceStore").
-		Preload("Stages.SearchAndTag.Files").
-		Preload("Stages.Exclude").
-		Preload("Stages.Ocr").
-		Preload("Stages.Reload").
-		Preload("Stages.Populate.Types").
-		Preload("Stages.InApp").
-		Preload("Stages.SyncDescendants").
-		Preload("Stages.ScanNewChildItems").
+	return tables.PreloadStages(db, "Stages.").
 		Preload("CaseSettings.Case.ElasticSearch").
 		Preload("CaseSettings.CompoundCase").
 		Preload("CaseSettings.ReviewCompound").
@@ -693,6 +1127,40 @@ func getPreloadedRunner(db *gorm.DB, runner *api.Runner) error {
 		First(&runner, "name = ?", runner.Name).Error
 }
 
+// StopRunner stops the runners nuix-process on the remote server
+func (s RunnerService) StopRunner(runner api.Runner) error {
+	logger := s.logger.With(zap.String("runner", runner.Name))
+	var server api.Server
+	if err := s.DB.First(&server, "hostname = ?", runner.Hostname).Error; err != nil {
+		logger.Error("Failed to retrive server from db", zap.String("server", runner.Hostname), zap.String("exception", err.Error()))
+		return fmt.Errorf("Failed to retrive server from db: %s - %v", runner.Hostname, err.Error())
+	}
+
+	logger.Info("Creating powershell-session for runner")
+	session, err := s.shell.NewSessionCredSSP(server.Hostname, server.Username, server.Password)
+	if err != nil {
+		logger.Error("Failed to create remote-client for powershell", zap.String("exception", err.Error()))
+		return fmt.Errorf("failed to create remote-client for powershell: %v", err)
+	}
+
+	// close the client on exit
+	defer session.Close()
+
+	// the runners nuix-process is found by the
+	// name of the script in its command-line
+	var scriptName = runner.Name + ".gen.rb"
+	logger.Info("Stopping nuix-process for runner", zap.String("server", runner.Hostname), zap.String("script", scriptName))
+	if err := session.StopProcess("nuix_console.exe", scriptName); err != nil {
+		logger.Error("Failed to stop nuix-process in ps-session",
+			zap.String("server", runner.Hostname),
+			zap.String("script", scriptName),
+			zap.String("exception", err.Error()),
+		)
+		return fmt.Errorf("Failed to stop nuix-process in ps-session: %s - %v", runner.Hostname, err.Error())
+	}
+	return nil
+}
+
 // RemoveScript removes the runner script from the server
 func (s RunnerService) RemoveScript(runner api.Runner) error {
 	logger := s.logger.With(zap.String("runner", runner.Name))
@@ -754,22 +1222,154 @@ func (s RunnerService) RemoveScript(runner api.Runner) error {
 	return nil
 }
 
-// Script generates the script for the runner
-func (s RunnerService) Script(ctx context.Context, r api.RunnerGetRequest) (*api.RunnerScriptResponse, error) {
+// Script generates the script for the runner, or returns
+// the archived script for an earlier start of the runner
+func (s RunnerService) Script(ctx context.Context, r api.RunnerScriptRequest) (*api.RunnerScriptResponse, error) {
 	var runner = api.Runner{Name: r.Name}
 	if err := getPreloadedRunner(s.DB, &runner); err != nil {
 		return nil, err
 	}
-	script, err := ruby.Generate(s.serviceURL, "", runner)
-	if err != nil {
+
+	var attempts int64
+	if err := s.DB.Model(&api.ScriptArchive{}).Where("runner_id = ?", runner.ID).Count(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count archived scripts: %v", err)
+	}
+
+	if r.Attempt == 0 {
+		script, err := s.templates.Generate(s.serviceURL, runner.Name)
+		if err != nil {
+			return nil, err
+		}
+		library := s.templates.Library()
+		return &api.RunnerScriptResponse{
+			Script:          script,
+			Library:         library,
+			Attempts:        attempts,
+			Hash:            ruby.Hash(script),
+			LibraryHash:     ruby.Hash(library),
+			TemplateVersion: s.templates.Version,
+		}, nil
+	}
+
+	var archive api.ScriptArchive
+	query := s.DB.Preload("Arguments", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	if err := query.Where("runner_id = ? AND attempt = ?", runner.ID, r.Attempt).First(&archive).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("no archived script for attempt: %d - runner %s has been started %d times", r.Attempt, runner.Name, attempts)
+		}
+		return nil, fmt.Errorf("failed to get archived script: %v", err)
+	}
+
+	var args []string
+	for _, arg := range archive.Arguments {
+		args = append(args, arg.Value)
+	}
+	return &api.RunnerScriptResponse{
+		Script:          archive.Script,
+		Library:         archive.Library,
+		Attempt:         archive.Attempt,
+		Attempts:        attempts,
+		Hash:            archive.Hash,
+		LibraryHash:     archive.LibraryHash,
+		Plan:            archive.Plan,
+		PlanHash:        archive.PlanHash,
+		TemplateVersion: archive.TemplateVersion,
+		Arguments:       args,
+		ArchivedAt:      archive.CTime,
+	}, nil
+}
+
+// Plan returns the stages for the runner-script to execute (used by ruby script)
+func (s RunnerService) Plan(ctx context.Context, r api.RunnerPlanRequest) (*api.RunnerPlanResponse, error) {
+	logger := s.logger.With(zap.String("runner", r.Runner))
+	logger.Debug("Plan request")
+	if err := s.authorizeRunnerName(ctx, r.Runner); err != nil {
+		return nil, err
+	}
+
+	var runner = api.Runner{Name: r.Runner}
+	if err := getPreloadedRunner(s.DB, &runner); err != nil {
+		logger.Error("Cannot get runner", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("did not get requested runner: %v", err)
+	}
+
+	var server api.Server
+	if err := s.DB.First(&server, "hostname = ?", runner.Hostname).Error; err != nil {
+		logger.Error("Cannot get server for runner", zap.String("server", runner.Hostname), zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("did not get server for runner: %s - %v", runner.Hostname, err)
+	}
+
+	// Decode the settings for the inApp-stages
+	for _, stage := range runner.Stages {
+		if stage.InApp != nil {
+			if err := inapp.Config(stage.InApp.Config, &stage.InApp.Settings); err != nil {
+				logger.Error("Failed to decode inapp config", zap.String("config", stage.InApp.Config), zap.String("exception", err.Error()))
+				return nil, fmt.Errorf("failed to decode config for in-app script: %s - %v", stage.InApp.Name, err)
+			}
+		}
+	}
+
+	plan := ruby.Plan(runner, utils.RemoteScriptDir(server.NuixPath, server.AvianScripts))
+	if err := s.archivePlan(runner, plan); err != nil {
+		logger.Error("Failed to archive plan", zap.String("exception", err.Error()))
 		return nil, err
 	}
-	return &api.RunnerScriptResponse{Script: script}, nil
+	logger.Debug("Returning plan", zap.Int("stages", len(plan.Stages)))
+	return plan, nil
 }
 
-// UploadFile uploads a file to the dataPath
+// archivePlan stores the plan with its hash in the archived
+// script for the latest start of the runner, the plan is only
+// stored for the first request - the one the script executes
+func (s RunnerService) archivePlan(runner api.Runner, plan *api.RunnerPlanResponse) error {
+	var archive api.ScriptArchive
+	if err := s.DB.Where("runner_id = ?", runner.ID).Order("attempt desc").First(&archive).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			// the runner has not been started by the queue
+			return nil
+		}
+		return fmt.Errorf("failed to get archived script for runner: %s - %v", runner.Name, err)
+	}
+	if archive.Plan != "" {
+		return nil
+	}
+
+	b, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("failed to encode plan for runner: %s - %v", runner.Name, err)
+	}
+	if err := s.DB.Model(&archive).Updates(map[string]interface{}{
+		"plan":      string(b),
+		"plan_hash": ruby.Hash(string(b)),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to archive plan for runner: %s - %v", runner.Name, err)
+	}
+	return nil
+}
+
+// uploadsDir is the folder in the data-path for the uploaded
+// files, the files for the service (like the custody-key and
+// the certificates) are kept outside it
+const uploadsDir = "uploads"
+
+// UploadFile uploads a file to the uploads-folder in the dataPath
 func (s RunnerService) UploadFile(ctx context.Context, r api.UploadFileRequest) (*api.UploadFileResponse, error) {
-	path := s.dataPath + r.Name
+	// the name must be a file-name without a path, so the
+	// file cannot be written outside the uploads-folder
+	if r.Name == "" || r.Name == "." || r.Name == ".." || strings.ContainsAny(r.Name, `/\:`) {
+		return nil, fmt.Errorf("invalid name for file: %s - must be a file-name without a path", r.Name)
+	}
+	// the names for the files of the service are refused,
+	// so an upload cannot be mistaken for them
+	if r.Name == custodyKeyFile || r.Name == "tls" {
+		return nil, fmt.Errorf("invalid name for file: %s - the name is reserved for the service", r.Name)
+	}
+
+	dir := filepath.Join(s.dataPath, uploadsDir)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create the folder for uploads: %v", err)
+	}
+	path := filepath.Join(dir, r.Name)
 
 	file, err := os.Create(path)
 	if err != nil {
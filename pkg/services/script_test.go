@@ -0,0 +1,63 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/matryer/is"
+)
+
+func TestScriptPlan(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	// a runner that has been started twice by the queue
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01"}).Error)
+	runner := api.Runner{Name: "runner", Hostname: "dev01", Stages: []*api.Stage{{Ocr: &api.Ocr{}}}}
+	is.NoErr(db.Create(&runner).Error)
+	for _, attempt := range []int64{1, 2} {
+		is.NoErr(db.Create(&api.ScriptArchive{RunnerID: runner.ID, Attempt: attempt, Script: "script"}).Error)
+	}
+
+	runnerKey, err := auth.CreateRunnerKey(db, runner.ID, runner.Name)
+	is.NoErr(err)
+	callbacks := avian.NewRunnerService(avian.NewWithKey(srv.URL+"/oto/", runnerKey))
+	adminKey, err := auth.Create(db, "admin", auth.RoleAdmin)
+	is.NoErr(err)
+	client := avian.NewRunnerService(avian.NewWithKey(srv.URL+"/oto/", adminKey))
+
+	// the plan is archived for the latest start
+	plan, err := callbacks.Plan(ctx, avian.RunnerPlanRequest{Runner: runner.Name})
+	is.NoErr(err)
+	b, err := json.Marshal(plan)
+	is.NoErr(err)
+
+	resp, err := client.Script(ctx, avian.RunnerScriptRequest{Name: runner.Name, Attempt: 2})
+	is.NoErr(err)
+	var archived avian.RunnerPlanResponse
+	is.NoErr(json.Unmarshal([]byte(resp.Plan), &archived))
+	is.Equal(archived.ID, plan.ID)
+	is.Equal(len(archived.Stages), 1)
+	is.Equal(resp.PlanHash, ruby.Hash(resp.Plan))
+	is.Equal(resp.PlanHash, ruby.Hash(string(b)))
+
+	// the first start never got the plan
+	resp, err = client.Script(ctx, avian.RunnerScriptRequest{Name: runner.Name, Attempt: 1})
+	is.NoErr(err)
+	is.Equal(resp.Plan, "")
+	is.Equal(resp.PlanHash, "")
+
+	// the plan the script executes is kept for the start
+	is.NoErr(db.Model(&runner.Stages[0]).Update("name", "changed").Error)
+	_, err = callbacks.Plan(ctx, avian.RunnerPlanRequest{Runner: runner.Name})
+	is.NoErr(err)
+	again, err := client.Script(ctx, avian.RunnerScriptRequest{Name: runner.Name, Attempt: 2})
+	is.NoErr(err)
+	is.Equal(again.PlanHash, ruby.Hash(string(b)))
+}
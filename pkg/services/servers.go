@@ -3,8 +3,10 @@ package services
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
 	"github.com/avian-digital-forensics/auto-processing/pkg/pwsh"
 	"go.uber.org/zap"
 
@@ -119,11 +121,133 @@ func (s ServerService) Apply(ctx context.Context, r api.ServerApplyRequest) (*ap
 // List the servers from the db
 func (s ServerService) List(ctx context.Context, r api.ServerListRequest) (*api.ServerListResponse, error) {
 	s.logger.Debug("Getting Servers-list")
+	query := s.db.Model(&api.Server{})
+	if r.Hostname != "" {
+		query = query.Where(`hostname LIKE ? ESCAPE '\'`, likePattern(r.Hostname))
+	}
+	if r.Active != nil {
+		query = query.Where("active = ?", *r.Active)
+	}
+
+	field, desc, after, err := listPage(r.Sort, r.Cursor, serverSortFields)
+	if err != nil {
+		return nil, err
+	}
+
 	var servers []api.Server
-	if err := s.db.Find(&servers).Error; err != nil {
+	if err := paginate(query, field, desc, after, r.Limit).Find(&servers).Error; err != nil {
 		s.logger.Error("Cannot get Servers-list", zap.String("exception", err.Error()))
 		return nil, err
 	}
+
+	var resp api.ServerListResponse
+	if r.Limit > 0 && int64(len(servers)) > r.Limit {
+		servers = servers[:r.Limit]
+		last := servers[len(servers)-1]
+		next := cursor{Sort: r.Sort, ID: last.ID}
+		switch field.column {
+		case "hostname":
+			next.String = last.Hostname
+		case "c_time":
+			next.Number = last.CTime
+		}
+		resp.NextCursor = next.encode()
+	}
+	resp.Servers = servers
 	s.logger.Debug("Got Servers-list", zap.Int("amount", len(servers)))
-	return &api.ServerListResponse{Servers: servers}, nil
+	return &resp, nil
+}
+
+// serverSortFields are the fields the servers can be sorted by
+var serverSortFields = map[string]sortField{
+	"id":       {column: "id", numeric: true},
+	"hostname": {column: "hostname"},
+	"created":  {column: "c_time", numeric: true},
+}
+
+// Get returns the server with the runners that uses it
+func (s ServerService) Get(ctx context.Context, r api.ServerGetRequest) (*api.ServerGetResponse, error) {
+	logger := s.logger.With(zap.String("server", r.Hostname))
+
+	var server api.Server
+	if err := s.db.First(&server, "hostname = ?", r.Hostname).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("server: %s does not exist", r.Hostname)
+		}
+		logger.Error("Cannot get the server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	runners, total, err := linkedRunners(s.db, r.Limit, "hostname = ?", r.Hostname)
+	if err != nil {
+		logger.Error("Cannot get the runners for the server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	return &api.ServerGetResponse{Server: server, Runners: runners, TotalRunners: total}, nil
+}
+
+// Delete deletes the server from the db, refused while
+// unfinished runners uses the server (unless forced)
+func (s ServerService) Delete(ctx context.Context, r api.ServerDeleteRequest) (*api.ServerDeleteResponse, error) {
+	logger := s.logger.With(zap.String("server", r.Hostname))
+
+	var server api.Server
+	if err := s.db.First(&server, "hostname = ?", r.Hostname).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("server: %s does not exist", r.Hostname)
+		}
+		logger.Error("Cannot get the server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	runners, err := unfinishedRunners(s.db, "hostname = ?", r.Hostname)
+	if err != nil {
+		logger.Error("Cannot get the runners for the server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if len(runners) > 0 {
+		if !r.Force {
+			logger.Error("Cannot delete server used by runners", zap.Strings("runners", runners))
+			return nil, fmt.Errorf("server: %s is used by the unfinished runners: %s - use force to delete it", r.Hostname, strings.Join(runners, ", "))
+		}
+		logger.Warn("Forcing delete of server used by runners", zap.Strings("runners", runners))
+	}
+
+	if err := s.db.Delete(&server).Error; err != nil {
+		logger.Error("Cannot delete server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	logger.Info("Server has been deleted")
+	return &api.ServerDeleteResponse{}, nil
+}
+
+// linkedRunners returns the latest runners for the query
+// (limited to 20 if no limit is specified) and the total
+func linkedRunners(db *gorm.DB, limit int64, query string, args ...interface{}) ([]api.Runner, int64, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	var total int64
+	if err := db.Model(&api.Runner{}).Where(query, args...).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var runners []api.Runner
+	err := db.Where(query, args...).
+		Order("id desc").
+		Limit(limit).
+		Find(&runners).Error
+	return runners, total, err
+}
+
+// unfinishedRunners returns the names of the runners
+// for the query that are waiting, running or active
+func unfinishedRunners(db *gorm.DB, query string, args ...interface{}) ([]string, error) {
+	var names []string
+	err := db.Model(&api.Runner{}).
+		Where(query, args...).
+		Where("status IN (?) OR active = ?", []int64{avian.StatusWaiting, avian.StatusRunning}, true).
+		Order("id").
+		Pluck("name", &names).Error
+	return names, err
 }
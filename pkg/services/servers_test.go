@@ -0,0 +1,73 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/matryer/is"
+)
+
+func TestDeleteServerAndNms(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01", Port: 5985}).Error)
+	is.NoErr(db.Create(&api.Nms{Address: "nms01", Port: 27443, Workers: 8, InUse: 2, Licences: []api.Licence{{Type: "enterprise-workstation", Amount: 2, InUse: 1}}}).Error)
+	is.NoErr(db.Create(&api.Runner{Name: "finished", Hostname: "dev01", Nms: "nms01", Status: avian.StatusFinished}).Error)
+	is.NoErr(db.Create(&api.Runner{Name: "running", Hostname: "dev01", Nms: "nms01", Status: avian.StatusRunning}).Error)
+	is.NoErr(db.Create(&api.Runner{Name: "failed", Hostname: "dev01", Nms: "nms01", Status: avian.StatusFailed}).Error)
+	is.NoErr(db.Create(&api.Runner{Name: "timeout", Hostname: "dev01", Nms: "nms01", Status: avian.StatusTimeout}).Error)
+
+	client := func(name, role string) *avian.Client {
+		key, err := auth.Create(db, name, role)
+		is.NoErr(err)
+		return avian.NewWithKey(srv.URL+"/oto/", key)
+	}
+	admin, viewer := client("admin", auth.RoleAdmin), client("viewer", auth.RoleViewer)
+	servers, nmsService := avian.NewServerService(admin), avian.NewNmsService(admin)
+
+	// describe lists the runners that uses the resource
+	server, err := avian.NewServerService(viewer).Get(ctx, avian.ServerGetRequest{Hostname: "dev01"})
+	is.NoErr(err)
+	is.Equal(server.Server.Port, int64(5985))
+	is.Equal(len(server.Runners), 4)
+	is.Equal(server.Runners[0].Name, "timeout") // the latest first
+	is.Equal(server.TotalRunners, int64(4))
+	nms, err := avian.NewNmsService(viewer).Get(ctx, avian.NmsGetRequest{Address: "nms01", Limit: 1})
+	is.NoErr(err)
+	is.Equal(nms.Nms.InUse, int64(2))
+	is.Equal(len(nms.Nms.Licences), 1)
+	is.Equal(len(nms.Runners), 1)
+	is.Equal(nms.Runners[0].Name, "timeout")
+	is.Equal(nms.TotalRunners, int64(4))
+
+	// viewers cannot delete
+	_, err = avian.NewServerService(viewer).Delete(ctx, avian.ServerDeleteRequest{Hostname: "dev01"})
+	is.Equal(err.Error(), "forbidden: viewer (viewer) cannot call: ServerService.Delete - requires the role: admin")
+
+	// the delete is refused while unfinished runners uses the
+	// resource (the failed and timed out runners are done)
+	_, err = servers.Delete(ctx, avian.ServerDeleteRequest{Hostname: "dev01"})
+	is.Equal(err.Error(), "server: dev01 is used by the unfinished runners: running - use force to delete it")
+	_, err = nmsService.Delete(ctx, avian.NmsDeleteRequest{Address: "nms01"})
+	is.Equal(err.Error(), "nms: nms01 is used by the unfinished runners: running - use force to delete it")
+
+	_, err = servers.Delete(ctx, avian.ServerDeleteRequest{Hostname: "dev01", Force: true})
+	is.NoErr(err)
+	_, err = servers.Get(ctx, avian.ServerGetRequest{Hostname: "dev01"})
+	is.Equal(err.Error(), "server: dev01 does not exist")
+
+	// the licences are deleted with the nms
+	is.NoErr(db.Model(&api.Runner{}).Where("name = ?", "running").Update("status", avian.StatusFinished).Error)
+	_, err = nmsService.Delete(ctx, avian.NmsDeleteRequest{Address: "nms01"})
+	is.NoErr(err)
+	var licences int
+	is.NoErr(db.Model(&api.Licence{}).Count(&licences).Error)
+	is.Equal(licences, 0)
+	_, err = nmsService.Delete(ctx, avian.NmsDeleteRequest{Address: "nms01"})
+	is.Equal(err.Error(), "nms: nms01 does not exist")
+}
@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/logging"
+	"github.com/avian-digital-forensics/auto-processing/pkg/services"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestUploadFile(t *testing.T) {
+	is := is.New(t)
+	dataPath, err := ioutil.TempDir("", "avian-data")
+	is.NoErr(err)
+	defer os.RemoveAll(dataPath)
+	is.NoErr(ioutil.WriteFile(filepath.Join(dataPath, "custody.key"), []byte("key"), 0600))
+
+	service := services.NewRunnerService(nil, nil, zap.NewNop(), logging.New(dataPath), "", dataPath, ruby.DefaultTemplates(), events.NewBroker(10))
+	upload := func(name string) (*api.UploadFileResponse, error) {
+		return service.UploadFile(context.Background(), api.UploadFileRequest{Name: name, Content: []byte("upload")})
+	}
+
+	// the files are written to the uploads-folder
+	resp, err := upload("profile.xml")
+	is.NoErr(err)
+	is.Equal(resp.Path, filepath.Join(dataPath, "uploads", "profile.xml"))
+	content, err := ioutil.ReadFile(resp.Path)
+	is.NoErr(err)
+	is.Equal(string(content), "upload")
+
+	// names with a path and the names for the files of the service are refused
+	for _, name := range []string{"", "..", "../x", `..\x`, "tls/cert.pem", `C:\x`, "custody.key", "tls"} {
+		_, err := upload(name)
+		is.True(err != nil) // the name is refused
+	}
+	content, err = ioutil.ReadFile(filepath.Join(dataPath, "custody.key"))
+	is.NoErr(err)
+	is.Equal(string(content), "key")
+	_, err = os.Stat(filepath.Join(dataPath, "x"))
+	is.True(os.IsNotExist(err))
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/webhooks"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+)
+
+// WebhookService holds the dependencies
+// for the WebhookService
+type WebhookService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewWebhookService creates a new webhook-service
+func NewWebhookService(db *gorm.DB, logger *zap.Logger) WebhookService {
+	return WebhookService{db: db, logger: logger}
+}
+
+// Add adds a webhook to the db
+func (s WebhookService) Add(ctx context.Context, r api.WebhookAddRequest) (*api.WebhookAddResponse, error) {
+	logger := s.logger.With(
+		zap.String("webhook", r.Name),
+		zap.String("endpoint", r.Endpoint),
+		zap.Strings("events", r.Events),
+		zap.String("runner", r.Runner),
+	)
+
+	if r.Name == "" {
+		return nil, fmt.Errorf("specify a name for the webhook")
+	}
+
+	endpoint, err := url.Parse(r.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		logger.Error("Invalid endpoint for webhook", zap.String("exception", "invalid endpoint"))
+		return nil, fmt.Errorf("invalid endpoint for webhook %s: %s - use an http or https url", r.Name, r.Endpoint)
+	}
+
+	if err := webhooks.ValidEvents(r.Events); err != nil {
+		logger.Error("Invalid events for webhook", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.Model(&api.Webhook{}).Where("name = ?", r.Name).Count(&count).Error; err != nil {
+		logger.Error("Cannot check if the webhook exists", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("webhook: %s already exists", r.Name)
+	}
+
+	secret := r.Secret
+	if secret == "" {
+		if secret, err = webhooks.NewSecret(); err != nil {
+			logger.Error("Cannot create secret for webhook", zap.String("exception", err.Error()))
+			return nil, err
+		}
+	}
+
+	webhook := api.Webhook{
+		Name:     r.Name,
+		Endpoint: r.Endpoint,
+		Events:   strings.Join(r.Events, ","),
+		Runner:   r.Runner,
+		Secret:   secret,
+	}
+	if err := s.db.Create(&webhook).Error; err != nil {
+		logger.Error("Cannot save webhook to DB", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("failed to add webhook %s : %v", r.Name, err)
+	}
+
+	logger.Info("Webhook has been added")
+	return &api.WebhookAddResponse{Webhook: webhook}, nil
+}
+
+// List the webhooks from the db (without their secrets)
+func (s WebhookService) List(ctx context.Context, r api.WebhookListRequest) (*api.WebhookListResponse, error) {
+	s.logger.Debug("Getting Webhooks-list")
+	var list []api.Webhook
+	if err := s.db.Order("name").Find(&list).Error; err != nil {
+		s.logger.Error("Cannot get Webhooks-list", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	for i := range list {
+		list[i].Secret = ""
+	}
+	s.logger.Debug("Got Webhooks-list", zap.Int("amount", len(list)))
+	return &api.WebhookListResponse{Webhooks: list}, nil
+}
+
+// Delete deletes the webhook and its deliveries from the db
+func (s WebhookService) Delete(ctx context.Context, r api.WebhookDeleteRequest) (*api.WebhookDeleteResponse, error) {
+	logger := s.logger.With(zap.String("webhook", r.Name))
+
+	webhook, err := s.get(r.Name)
+	if err != nil {
+		logger.Error("Cannot get webhook", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	tx := s.db.Begin()
+	if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&api.WebhookDelivery{}).Error; err != nil {
+		tx.Rollback()
+		logger.Error("Cannot delete the deliveries for the webhook", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if err := tx.Delete(&webhook).Error; err != nil {
+		tx.Rollback()
+		logger.Error("Cannot delete webhook", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		logger.Error("Cannot delete webhook", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	logger.Info("Webhook has been deleted")
+	return &api.WebhookDeleteResponse{}, nil
+}
+
+// Deliveries returns the latest deliveries for the webhook
+func (s WebhookService) Deliveries(ctx context.Context, r api.WebhookDeliveriesRequest) (*api.WebhookDeliveriesResponse, error) {
+	webhook, err := s.get(r.Name)
+	if err != nil {
+		s.logger.Error("Cannot get webhook", zap.String("webhook", r.Name), zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	limit := r.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var deliveries []api.WebhookDelivery
+	if err := s.db.Where("webhook_id = ?", webhook.ID).Order("id desc").Limit(limit).Find(&deliveries).Error; err != nil {
+		s.logger.Error("Cannot get the deliveries for the webhook", zap.String("webhook", r.Name), zap.String("exception", err.Error()))
+		return nil, err
+	}
+	return &api.WebhookDeliveriesResponse{Deliveries: deliveries}, nil
+}
+
+// get returns the webhook with the name
+func (s WebhookService) get(name string) (api.Webhook, error) {
+	var webhook api.Webhook
+	if err := s.db.Where("name = ?", name).First(&webhook).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return webhook, fmt.Errorf("webhook: %s does not exist", name)
+		}
+		return webhook, err
+	}
+	return webhook, nil
+}
@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/matryer/is"
+)
+
+func TestWebhooks(t *testing.T) {
+	is := is.New(t)
+	db, srv := newTestService(t)
+	ctx := context.Background()
+
+	client := func(name, role string) *avian.WebhookService {
+		key, err := auth.Create(db, name, role)
+		is.NoErr(err)
+		return avian.NewWebhookService(avian.NewWithKey(srv.URL+"/oto/", key))
+	}
+	admin := client("admin", auth.RoleAdmin)
+	viewer := client("viewer", auth.RoleViewer)
+
+	// the secret is generated and only returned when the webhook is added
+	resp, err := admin.Add(ctx, avian.WebhookAddRequest{Name: "chat", Endpoint: "https://chat.example.com/hooks", Events: []string{"runner.failed", "stage.failed"}})
+	is.NoErr(err)
+	is.True(strings.HasPrefix(resp.Webhook.Secret, "whsec_"))
+	is.Equal(resp.Webhook.Events, "runner.failed,stage.failed")
+
+	list, err := viewer.List(ctx, avian.WebhookListRequest{})
+	is.NoErr(err)
+	is.Equal(len(list.Webhooks), 1)
+	is.Equal(list.Webhooks[0].Name, "chat")
+	is.Equal(list.Webhooks[0].Secret, "")
+
+	// invalid webhooks
+	_, err = admin.Add(ctx, avian.WebhookAddRequest{Name: "chat", Endpoint: "https://chat.example.com/hooks"})
+	is.Equal(err.Error(), "webhook: chat already exists")
+	_, err = admin.Add(ctx, avian.WebhookAddRequest{Name: "ftp", Endpoint: "ftp://example.com"})
+	is.Equal(err.Error(), "invalid endpoint for webhook ftp: ftp://example.com - use an http or https url")
+	_, err = admin.Add(ctx, avian.WebhookAddRequest{Name: "unknown", Endpoint: "http://example.com", Events: []string{"runner.deleted"}})
+	is.True(err != nil)
+	is.True(strings.HasPrefix(err.Error(), "unknown event: runner.deleted"))
+
+	// viewers cannot add or delete webhooks
+	_, err = viewer.Add(ctx, avian.WebhookAddRequest{Name: "viewer", Endpoint: "http://example.com"})
+	is.Equal(err.Error(), "forbidden: viewer (viewer) cannot call: WebhookService.Add - requires the role: admin")
+	_, err = viewer.Delete(ctx, avian.WebhookDeleteRequest{Name: "chat"})
+	is.True(err != nil)
+
+	// the deliveries are deleted with the webhook
+	is.NoErr(db.Create(&api.WebhookDelivery{WebhookID: resp.Webhook.ID, Event: "runner.failed"}).Error)
+	deliveries, err := viewer.Deliveries(ctx, avian.WebhookDeliveriesRequest{Name: "chat"})
+	is.NoErr(err)
+	is.Equal(len(deliveries.Deliveries), 1)
+
+	_, err = admin.Delete(ctx, avian.WebhookDeleteRequest{Name: "chat"})
+	is.NoErr(err)
+	var count int64
+	is.NoErr(db.Model(&api.WebhookDelivery{}).Count(&count).Error)
+	is.Equal(count, int64(0))
+	_, err = viewer.Deliveries(ctx, avian.WebhookDeliveriesRequest{Name: "chat"})
+	is.Equal(err.Error(), "webhook: chat does not exist")
+}
@@ -0,0 +1,306 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Config for the OTLP-exporter
+type Config struct {
+	// Endpoint for the OTLP/HTTP-collector, the spans
+	// are posted to <endpoint>/v1/traces - disabled if empty
+	Endpoint string
+
+	// Headers to send with the spans (like an api-key for the collector)
+	Headers map[string]string
+
+	// ServiceName for the resource of the spans
+	ServiceName string
+}
+
+// Enabled returns true if there is an endpoint to export to
+func (c Config) Enabled() bool { return c.Endpoint != "" }
+
+// ParseHeaders parses the headers in the format
+// for OTEL_EXPORTER_OTLP_HEADERS (key1=value1,key2=value2)
+func ParseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			continue
+		}
+		headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return headers
+}
+
+// Tracer starts the spans and exports them in
+// batches with OTLP/HTTP (JSON) to the collector
+type Tracer struct {
+	config Config
+	client *http.Client
+	logger *zap.Logger
+	wake   chan struct{}
+
+	mu    sync.Mutex
+	spans []*Span
+
+	// BatchSize is the amount of spans that
+	// are exported before the interval
+	BatchSize int
+
+	// MaxQueue is the amount of spans to keep if the
+	// collector is down, the new spans are dropped
+	MaxQueue int
+
+	// Interval to export the spans
+	Interval time.Duration
+}
+
+// New returns a Tracer that exports the spans every
+// 5 seconds (or when there is 512 spans to export)
+func New(config Config, logger *zap.Logger) *Tracer {
+	if config.ServiceName == "" {
+		config.ServiceName = "avian"
+	}
+	return &Tracer{
+		config:    config,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		logger:    logger,
+		wake:      make(chan struct{}, 1),
+		BatchSize: 512,
+		MaxQueue:  8192,
+		Interval:  5 * time.Second,
+	}
+}
+
+// Start starts a span of the kind as a child of the span (or the
+// remote parent) in the context, it is a no-op for a nil-tracer
+func (t *Tracer) Start(ctx context.Context, name string, kind int, attrs ...Attribute) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	s := &Span{tracer: t, name: name, kind: kind, start: time.Now(), attrs: attrs}
+	if parent := SpanFromContext(ctx); parent != nil {
+		s.sc.traceID = parent.sc.traceID
+		s.parent = parent.sc.spanID
+	} else if remote, ok := ctx.Value(remoteKey).(spanContext); ok {
+		s.sc.traceID = remote.traceID
+		s.parent = remote.spanID
+	} else {
+		newID(s.sc.traceID[:])
+	}
+	newID(s.sc.spanID[:])
+	return context.WithValue(ctx, spanKey, s), s
+}
+
+// queue queues the ended span for the export
+func (t *Tracer) queue(s *Span) {
+	t.mu.Lock()
+	if len(t.spans) >= t.MaxQueue {
+		t.mu.Unlock()
+		return
+	}
+	t.spans = append(t.spans, s)
+	full := len(t.spans) >= t.BatchSize
+	t.mu.Unlock()
+
+	if full {
+		select {
+		case t.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run exports the spans until the context
+// is done, the last spans are exported on exit
+func (t *Tracer) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			t.Flush()
+			return
+		case <-ticker.C:
+		case <-t.wake:
+		}
+		t.Flush()
+	}
+}
+
+// Flush exports the queued spans to the collector,
+// the spans are kept for the next export if it fails
+func (t *Tracer) Flush() {
+	for {
+		t.mu.Lock()
+		n := len(t.spans)
+		if n > t.BatchSize {
+			n = t.BatchSize
+		}
+		batch := t.spans[:n:n]
+		t.mu.Unlock()
+		if n == 0 {
+			return
+		}
+
+		if err := t.export(batch); err != nil {
+			t.logger.Warn("Cannot export spans to the collector",
+				zap.String("endpoint", t.config.Endpoint),
+				zap.Int("spans", len(batch)),
+				zap.String("exception", err.Error()),
+			)
+			return
+		}
+
+		t.mu.Lock()
+		t.spans = t.spans[n:]
+		t.mu.Unlock()
+	}
+}
+
+// export posts the spans to the collector
+func (t *Tracer) export(spans []*Span) error {
+	body, err := json.Marshal(t.request(spans))
+	if err != nil {
+		return fmt.Errorf("cannot marshal spans: %v", err)
+	}
+	url := strings.TrimSuffix(t.config.Endpoint, "/") + "/v1/traces"
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector responded with status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// The OTLP/HTTP JSON-encoding for the spans
+// (ids in hex and the timestamps as strings)
+type (
+	exportRequest struct {
+		ResourceSpans []resourceSpans `json:"resourceSpans"`
+	}
+
+	resourceSpans struct {
+		Resource   resource     `json:"resource"`
+		ScopeSpans []scopeSpans `json:"scopeSpans"`
+	}
+
+	resource struct {
+		Attributes []keyValue `json:"attributes"`
+	}
+
+	scopeSpans struct {
+		Scope scope      `json:"scope"`
+		Spans []spanJSON `json:"spans"`
+	}
+
+	scope struct {
+		Name string `json:"name"`
+	}
+
+	spanJSON struct {
+		TraceID           string     `json:"traceId"`
+		SpanID            string     `json:"spanId"`
+		ParentSpanID      string     `json:"parentSpanId,omitempty"`
+		Name              string     `json:"name"`
+		Kind              int        `json:"kind"`
+		StartTimeUnixNano string     `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+		Attributes        []keyValue `json:"attributes,omitempty"`
+		Status            status     `json:"status"`
+	}
+
+	status struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+
+	keyValue struct {
+		Key   string   `json:"key"`
+		Value anyValue `json:"value"`
+	}
+
+	anyValue struct {
+		StringValue *string `json:"stringValue,omitempty"`
+		IntValue    *string `json:"intValue,omitempty"`
+		BoolValue   *bool   `json:"boolValue,omitempty"`
+	}
+)
+
+// request returns the export-request for the spans
+func (t *Tracer) request(spans []*Span) exportRequest {
+	list := make([]spanJSON, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := spanJSON{
+			TraceID:           hex.EncodeToString(s.sc.traceID[:]),
+			SpanID:            hex.EncodeToString(s.sc.spanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        attributes(s.attrs),
+			Status:            status{Code: s.status, Message: s.message},
+		}
+		s.mu.Unlock()
+		if s.parent != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parent[:])
+		}
+		list = append(list, span)
+	}
+
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: attributes([]Attribute{String("service.name", t.config.ServiceName)})},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: "github.com/avian-digital-forensics/auto-processing"}, Spans: list}},
+	}}}
+}
+
+// attributes returns the attributes in the OTLP-encoding
+func attributes(attrs []Attribute) []keyValue {
+	var list []keyValue
+	for _, attr := range attrs {
+		var v anyValue
+		switch value := attr.Value.(type) {
+		case string:
+			v.StringValue = &value
+		case int64:
+			s := strconv.FormatInt(value, 10)
+			v.IntValue = &s
+		case bool:
+			v.BoolValue = &value
+		default:
+			s := fmt.Sprint(value)
+			v.StringValue = &s
+		}
+		list = append(list, keyValue{Key: attr.Key, Value: v})
+	}
+	return list
+}
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Middleware starts a server-span for each request to the oto-methods
+// (named by the method, like RunnerService.LogItem), the span is a child
+// of the traceparent-header if it is set - so the callbacks from the
+// runner-scripts joins the trace for the runner
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if header := r.Header.Get(TraceParentHeader); header != "" {
+			ctx = ContextWithTraceParent(ctx, header)
+		}
+		ctx, span := StartKind(ctx, strings.TrimPrefix(r.URL.Path, "/oto/"), KindServer,
+			String("http.method", r.Method),
+			String("http.target", r.URL.Path),
+		)
+		if span == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		span.SetAttributes(Int("http.status_code", int64(rec.status)))
+		if rec.status >= http.StatusBadRequest {
+			span.RecordError(fmt.Errorf("%d %s", rec.status, http.StatusText(rec.status)))
+		}
+	})
+}
+
+// statusRecorder records the status for the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
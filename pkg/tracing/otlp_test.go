@@ -0,0 +1,129 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/tracing"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// TestOTLP decodes the payload with the upstream OTLP-types, so the
+// exporter is checked against the protocol (not only against itself)
+func TestOTLP(t *testing.T) {
+	is := is.New(t)
+	payloads := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		payloads <- b
+	}))
+	defer srv.Close()
+
+	tracer := tracing.New(tracing.Config{Endpoint: srv.URL, ServiceName: "avian-test"}, zap.NewNop())
+	ctx, run := tracer.Start(context.Background(), "run.start", tracing.KindInternal)
+	_, step := tracer.Start(ctx, "pwsh.CreateFile", tracing.KindInternal)
+	step.End()
+	run.End()
+	tracer.Flush()
+
+	var req coltracepb.ExportTraceServiceRequest
+	is.NoErr(protojson.Unmarshal(protoJSON(t, <-payloads), &req)) // unknown fields are errors
+	is.Equal(len(req.ResourceSpans), 1)
+	rs := req.ResourceSpans[0]
+	is.Equal(rs.Resource.Attributes[0].Key, "service.name")
+	is.Equal(rs.Resource.Attributes[0].Value.GetStringValue(), "avian-test")
+	is.Equal(len(rs.ScopeSpans), 1)
+	is.Equal(rs.ScopeSpans[0].Scope.Name, "github.com/avian-digital-forensics/auto-processing")
+
+	spans := rs.ScopeSpans[0].Spans
+	is.Equal(len(spans), 2)
+	child, root := spans[0], spans[1] // in the order they ended
+	is.Equal(root.Name, "run.start")
+	is.Equal(len(root.TraceId), 16)
+	is.Equal(len(root.SpanId), 8)
+	is.Equal(len(root.ParentSpanId), 0)
+	is.Equal(root.Kind, tracepb.Span_SPAN_KIND_INTERNAL)
+	is.True(root.StartTimeUnixNano > 0 && root.EndTimeUnixNano >= root.StartTimeUnixNano)
+	is.Equal(child.Name, "pwsh.CreateFile")
+	is.Equal(child.TraceId, root.TraceId)
+	is.Equal(child.ParentSpanId, root.SpanId)
+}
+
+// TestOTLPAttributes checks the attributes and the
+// status with the upstream OTLP-types
+func TestOTLPAttributes(t *testing.T) {
+	is := is.New(t)
+	payloads := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		payloads <- b
+	}))
+	defer srv.Close()
+
+	tracer := tracing.New(tracing.Config{Endpoint: srv.URL}, zap.NewNop())
+	_, span := tracer.Start(context.Background(), "RunnerService.LogItem", tracing.KindServer,
+		tracing.String("runner", "runner-1"), tracing.Int("workers", 4), tracing.Bool("relay", true))
+	span.RecordError(errors.New("access denied"))
+	span.End()
+	tracer.Flush()
+
+	var req coltracepb.ExportTraceServiceRequest
+	is.NoErr(protojson.Unmarshal(protoJSON(t, <-payloads), &req))
+	s := req.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	is.Equal(s.Kind, tracepb.Span_SPAN_KIND_SERVER)
+	is.Equal(s.Status.Code, tracepb.Status_STATUS_CODE_ERROR)
+	is.Equal(s.Status.Message, "access denied")
+	is.Equal(len(s.Attributes), 3)
+	is.Equal(s.Attributes[0].Value.GetStringValue(), "runner-1")
+	is.Equal(s.Attributes[1].Value.GetIntValue(), int64(4))
+	is.Equal(s.Attributes[2].Value.GetBoolValue(), true)
+	is.Equal(req.ResourceSpans[0].Resource.Attributes[0].Value.GetStringValue(), "avian")
+}
+
+// protoJSON converts the OTLP/JSON to the protobuf JSON-mapping, they
+// only differ in the ids (hex in OTLP/JSON and base64 in protobuf)
+func protoJSON(t *testing.T, payload []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(payload, &v); err != nil {
+		t.Fatalf("cannot decode the payload: %v", err)
+	}
+	var convert func(v interface{})
+	convert = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, value := range v {
+				id, ok := value.(string)
+				if key != "traceId" && key != "spanId" && key != "parentSpanId" || !ok {
+					convert(value)
+					continue
+				}
+				b, err := hex.DecodeString(id)
+				if err != nil {
+					t.Fatalf("%s is not in hex: %s", key, id)
+				}
+				v[key] = base64.StdEncoding.EncodeToString(b)
+			}
+		case []interface{}:
+			for _, value := range v {
+				convert(value)
+			}
+		}
+	}
+	convert(v)
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("cannot encode the payload: %v", err)
+	}
+	return b
+}
@@ -0,0 +1,213 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceParentHeader is the W3C-header for the trace-context
+const TraceParentHeader = "traceparent"
+
+// TraceParentEnv is the env-variable for the trace-context that
+// the runner-scripts sends in the callbacks to the service
+const TraceParentEnv = "AVIAN_TRACEPARENT"
+
+// The kinds for the spans (as in OTLP)
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// statusError is the OTLP-code for the status of a failed span
+const statusError = 2
+
+// Attribute is a key-value for a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string-attribute
+func String(key, value string) Attribute { return Attribute{key, value} }
+
+// Int returns an int-attribute
+func Int(key string, value int64) Attribute { return Attribute{key, value} }
+
+// Bool returns a bool-attribute
+func Bool(key string, value bool) Attribute { return Attribute{key, value} }
+
+// spanContext identifies a span in a trace
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+}
+
+func (sc spanContext) valid() bool {
+	return sc.traceID != [16]byte{} && sc.spanID != [8]byte{}
+}
+
+// traceParent returns the W3C-header for the span-context
+func (sc spanContext) traceParent() string {
+	return "00-" + hex.EncodeToString(sc.traceID[:]) + "-" + hex.EncodeToString(sc.spanID[:]) + "-01"
+}
+
+// parseTraceParent parses the W3C-header (version 00)
+func parseTraceParent(header string) (spanContext, error) {
+	var sc spanContext
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return sc, fmt.Errorf("invalid traceparent: %s", header)
+	}
+	if _, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil {
+		return sc, fmt.Errorf("invalid trace-id in traceparent: %v", err)
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil {
+		return sc, fmt.Errorf("invalid span-id in traceparent: %v", err)
+	}
+	if !sc.valid() {
+		return sc, fmt.Errorf("invalid traceparent: %s", header)
+	}
+	return sc, nil
+}
+
+// Span is an operation in a trace, a nil-span (when
+// tracing is disabled) can be used like a span
+type Span struct {
+	tracer *Tracer
+	name   string
+	kind   int
+	sc     spanContext
+	parent [8]byte
+	start  time.Time
+
+	mu      sync.Mutex
+	end     time.Time
+	attrs   []Attribute
+	status  int
+	message string
+	ended   bool
+}
+
+// SetAttributes adds the attributes to the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// RecordError sets the status for the span to error
+// with the message for the error (if it is not nil)
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = statusError
+	s.message = err.Error()
+}
+
+// End ends the span and queues it for the export
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+	s.tracer.queue(s)
+}
+
+// TraceID returns the id for the trace (hex), used
+// to correlate the logs with the trace
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.sc.traceID[:])
+}
+
+// TraceParent returns the W3C-header for the span,
+// it is empty if tracing is disabled
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	return s.sc.traceParent()
+}
+
+type contextKey int
+
+const (
+	spanKey contextKey = iota
+	remoteKey
+)
+
+// ContextWithTraceParent returns a context with the remote
+// parent from the W3C-header, the next span that is started
+// with the context is a child of it (in the same trace)
+func ContextWithTraceParent(ctx context.Context, header string) context.Context {
+	sc, err := parseTraceParent(header)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey, sc)
+}
+
+// SpanFromContext returns the span in the context (or nil)
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey).(*Span)
+	return s
+}
+
+// Start starts a span with the default tracer as a child of the span
+// (or the remote parent) in the context, the span is nil if there is no
+// default tracer - see SetDefault
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return defaultTracer().Start(ctx, name, KindInternal, attrs...)
+}
+
+// StartKind starts a span of the kind with the default tracer
+func StartKind(ctx context.Context, name string, kind int, attrs ...Attribute) (context.Context, *Span) {
+	return defaultTracer().Start(ctx, name, kind, attrs...)
+}
+
+var (
+	mu       sync.RWMutex
+	fallback *Tracer
+)
+
+// SetDefault sets the default tracer for Start
+// (nil disables the tracing)
+func SetDefault(t *Tracer) {
+	mu.Lock()
+	defer mu.Unlock()
+	fallback = t
+}
+
+func defaultTracer() *Tracer {
+	mu.RLock()
+	defer mu.RUnlock()
+	return fallback
+}
+
+// newID fills the id with random bytes
+func newID(id []byte) {
+	if _, err := rand.Read(id); err != nil {
+		panic(fmt.Sprintf("tracing: cannot create id: %v", err))
+	}
+}
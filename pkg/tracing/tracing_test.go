@@ -0,0 +1,146 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/tracing"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+// span is a span as it is received by the collector
+type span struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Kind         int    `json:"kind"`
+	Attributes   []struct {
+		Key   string `json:"key"`
+		Value struct {
+			StringValue string `json:"stringValue"`
+			IntValue    string `json:"intValue"`
+		} `json:"value"`
+	} `json:"attributes"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+// collector is a fake OTLP/HTTP-collector
+type collector struct {
+	mu      sync.Mutex
+	spans   map[string]span
+	headers http.Header
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []span `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.headers = r.Header
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, s := range ss.Spans {
+				c.spans[s.Name] = s
+			}
+		}
+	}
+}
+
+func TestTracer(t *testing.T) {
+	is := is.New(t)
+	c := &collector{spans: make(map[string]span)}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	tracer := tracing.New(tracing.Config{
+		Endpoint: srv.URL,
+		Headers:  tracing.ParseHeaders("api-key=secret, other=value"),
+	}, zap.NewNop())
+	tracing.SetDefault(tracer)
+	defer tracing.SetDefault(nil)
+
+	// a run with a step, the traceparent is passed to the script
+	ctx, run := tracing.Start(context.Background(), "run.start", tracing.String("runner", "runner-1"))
+	_, step := tracing.Start(ctx, "pwsh.CreateFile")
+	step.RecordError(errors.New("access denied"))
+	step.End()
+	traceParent := run.TraceParent()
+	run.End()
+
+	// the callback from the script joins the trace for the run
+	h := tracing.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.True(tracing.SpanFromContext(r.Context()) != nil)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/oto/RunnerService.LogItem", nil)
+	req.Header.Set(tracing.TraceParentHeader, traceParent)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	tracer.Flush()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	is.Equal(c.headers.Get("api-key"), "secret")
+	is.Equal(len(c.spans), 3)
+
+	root := c.spans["run.start"]
+	is.Equal(len(root.TraceID), 32)
+	is.Equal(root.ParentSpanID, "")
+	is.Equal(root.Kind, tracing.KindInternal)
+	is.Equal(root.Attributes[0].Key, "runner")
+	is.Equal(root.Attributes[0].Value.StringValue, "runner-1")
+	is.Equal(root.Status.Code, 0)
+	is.Equal(traceParent, "00-"+root.TraceID+"-"+root.SpanID+"-01")
+
+	child := c.spans["pwsh.CreateFile"]
+	is.Equal(child.TraceID, root.TraceID)
+	is.Equal(child.ParentSpanID, root.SpanID)
+	is.Equal(child.Status.Code, 2)
+	is.Equal(child.Status.Message, "access denied")
+
+	callback := c.spans["RunnerService.LogItem"]
+	is.Equal(callback.TraceID, root.TraceID)
+	is.Equal(callback.ParentSpanID, root.SpanID)
+	is.Equal(callback.Kind, tracing.KindServer)
+	is.Equal(callback.Status.Code, 2)
+}
+
+func TestDisabled(t *testing.T) {
+	is := is.New(t)
+	tracing.SetDefault(nil)
+
+	// the spans are nil without a tracer, and can be used as spans
+	ctx, span := tracing.Start(context.Background(), "Queue.loop")
+	is.True(span == nil)
+	is.True(tracing.SpanFromContext(ctx) == nil)
+	span.SetAttributes(tracing.Int("runners", 1))
+	span.RecordError(errors.New("failed"))
+	span.End()
+	is.Equal(span.TraceParent(), "")
+
+	// invalid traceparents are ignored
+	ctx = tracing.ContextWithTraceParent(context.Background(), "00-invalid-01")
+	is.Equal(ctx, context.Background())
+}
@@ -0,0 +1,225 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+)
+
+// Dispatcher delivers the lifecycle-events to the webhooks. The
+// deliveries are stored in the db before they are sent, so the
+// retries continues after a restart of the service.
+type Dispatcher struct {
+	db     *gorm.DB
+	client *http.Client
+	logger *zap.Logger
+	wake   chan struct{}
+
+	// MaxAttempts to deliver an event
+	MaxAttempts int64
+
+	// Backoff before the first retry,
+	// it is doubled for every retry
+	Backoff time.Duration
+
+	// Interval to check for deliveries to retry
+	Interval time.Duration
+}
+
+// New returns a Dispatcher that retries a delivery 5 times
+// (after 30 seconds, 1, 2 and 4 minutes) before it gives up
+func New(db *gorm.DB, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		db:          db,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		logger:      logger,
+		wake:        make(chan struct{}, 1),
+		MaxAttempts: 5,
+		Backoff:     30 * time.Second,
+		Interval:    10 * time.Second,
+	}
+}
+
+// Run delivers the events from the broker
+// to the webhooks until the context is done
+func (d *Dispatcher) Run(ctx context.Context, b *events.Broker) {
+	go d.retry(ctx)
+
+	filter := events.Filter{Types: []string{events.TypeRunner, events.TypeStage}}
+	events.Follow(ctx, b, filter, d.logger.With(zap.String("subscriber", "webhooks")), d.enqueue)
+}
+
+// enqueue stores a delivery for every webhook that matches the event
+func (d *Dispatcher) enqueue(e events.Event) {
+	name := Name(e)
+	if name == "" {
+		return
+	}
+
+	var webhooks []api.Webhook
+	if err := d.db.Find(&webhooks).Error; err != nil {
+		d.logger.Error("Cannot get the webhooks", zap.String("event", name), zap.String("exception", err.Error()))
+		return
+	}
+
+	payload, err := json.Marshal(NewPayload(e))
+	if err != nil {
+		d.logger.Error("Cannot marshal the payload for the webhooks", zap.String("event", name), zap.String("exception", err.Error()))
+		return
+	}
+
+	now := time.Now()
+	queued := false
+	for _, webhook := range webhooks {
+		if !Match(webhook, name, e.Runner) {
+			continue
+		}
+		delivery := api.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			Event:         name,
+			Runner:        e.Runner,
+			Payload:       string(payload),
+			NextAttemptAt: &now,
+		}
+		if err := d.db.Create(&delivery).Error; err != nil {
+			d.logger.Error("Cannot save the delivery for the webhook",
+				zap.String("webhook", webhook.Name),
+				zap.String("event", name),
+				zap.String("exception", err.Error()),
+			)
+			continue
+		}
+		queued = true
+	}
+
+	if queued {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// retry sends the deliveries that are due
+// when they are queued and at every interval
+func (d *Dispatcher) retry(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		d.deliver(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// deliver sends the deliveries that are due
+func (d *Dispatcher) deliver(ctx context.Context) {
+	var deliveries []api.WebhookDelivery
+	err := d.db.Where("delivered = ? AND next_attempt_at <= ?", false, time.Now()).
+		Order("id").
+		Find(&deliveries).Error
+	if err != nil {
+		d.logger.Error("Cannot get the deliveries for the webhooks", zap.String("exception", err.Error()))
+		return
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		d.attempt(ctx, delivery)
+	}
+}
+
+// attempt sends the delivery to its webhook and
+// schedules the next attempt if it was not delivered
+func (d *Dispatcher) attempt(ctx context.Context, delivery api.WebhookDelivery) {
+	var webhook api.Webhook
+	if err := d.db.First(&webhook, delivery.WebhookID).Error; err != nil {
+		d.logger.Error("Cannot get the webhook for the delivery",
+			zap.Uint("delivery_id", delivery.ID),
+			zap.String("exception", err.Error()),
+		)
+		return
+	}
+
+	logger := d.logger.With(
+		zap.String("webhook", webhook.Name),
+		zap.String("event", delivery.Event),
+		zap.String("runner", delivery.Runner),
+		zap.Uint("delivery_id", delivery.ID),
+	)
+
+	statusCode, err := d.send(ctx, webhook, delivery)
+	delivery.Attempts++
+	delivery.StatusCode = statusCode
+	delivery.Exception = ""
+	delivery.NextAttemptAt = nil
+	switch {
+	case err == nil:
+		delivery.Delivered = true
+		logger.Debug("Delivered event to webhook", zap.Int64("attempts", delivery.Attempts))
+	case delivery.Attempts >= d.MaxAttempts:
+		delivery.Exception = err.Error()
+		logger.Error("Failed to deliver event to webhook - no attempts left",
+			zap.Int64("attempts", delivery.Attempts),
+			zap.String("exception", err.Error()),
+		)
+	default:
+		delivery.Exception = err.Error()
+		next := time.Now().Add(d.Backoff << uint(delivery.Attempts-1))
+		delivery.NextAttemptAt = &next
+		logger.Warn("Failed to deliver event to webhook - will retry",
+			zap.Int64("attempts", delivery.Attempts),
+			zap.Time("next_attempt_at", next),
+			zap.String("exception", err.Error()),
+		)
+	}
+
+	if err := d.db.Save(&delivery).Error; err != nil {
+		logger.Error("Cannot save the delivery for the webhook", zap.String("exception", err.Error()))
+	}
+}
+
+// send posts the payload to the webhook and returns
+// the status-code, a non-2xx status is an error
+func (d *Dispatcher) send(ctx context.Context, webhook api.Webhook, delivery api.WebhookDelivery) (int64, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "avian-webhooks")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return int64(resp.StatusCode), fmt.Errorf("webhook responded with: %s", resp.Status)
+	}
+	return int64(resp.StatusCode), nil
+}
@@ -0,0 +1,160 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+)
+
+// The lifecycle-events that are sent to the webhooks
+const (
+	RunnerStarted  = "runner.started"
+	RunnerFinished = "runner.finished"
+	RunnerFailed   = "runner.failed"
+	RunnerTimeout  = "runner.timeout"
+	StageStarted   = "stage.started"
+	StageFinished  = "stage.finished"
+	StageFailed    = "stage.failed"
+	StageTimeout   = "stage.timeout"
+)
+
+// Events are the lifecycle-events a webhook can filter on
+var Events = []string{
+	RunnerStarted,
+	RunnerFinished,
+	RunnerFailed,
+	RunnerTimeout,
+	StageStarted,
+	StageFinished,
+	StageFailed,
+	StageTimeout,
+}
+
+// Headers for the requests to the webhooks
+const (
+	// SignatureHeader holds the HMAC-SHA256 for the body
+	// with the secret for the webhook (sha256=<hex>)
+	SignatureHeader = "X-Avian-Signature"
+
+	// EventHeader holds the name of the event
+	EventHeader = "X-Avian-Event"
+
+	// DeliveryHeader holds the id for the delivery,
+	// it is the same for the retries of a delivery
+	DeliveryHeader = "X-Avian-Delivery"
+)
+
+// actions maps the statuses for the runners
+// and the stages to the names of the events
+var actions = map[string]string{
+	"Running":  "started",
+	"Finished": "finished",
+	"Failed":   "failed",
+	"Timeout":  "timeout",
+}
+
+// Payload is the JSON-body for an event
+type Payload struct {
+	// Event is the name of the event (e.g runner.started)
+	Event string `json:"event"`
+
+	// Time for the event
+	Time time.Time `json:"time"`
+
+	// Runner the event belongs to
+	Runner string `json:"runner"`
+
+	// Status for the runner or the stage
+	Status string `json:"status"`
+
+	// StageID for the stage (for stage-events)
+	StageID uint `json:"stageID,omitempty"`
+
+	// Stage is the name of the stage (for stage-events)
+	Stage string `json:"stage,omitempty"`
+}
+
+// Name returns the name of the lifecycle-event for the
+// event, empty if the event is not sent to the webhooks
+func Name(e events.Event) string {
+	if e.Type != events.TypeRunner && e.Type != events.TypeStage {
+		return ""
+	}
+	action, ok := actions[e.Status]
+	if !ok {
+		return ""
+	}
+	return e.Type + "." + action
+}
+
+// NewPayload returns the payload for the event
+func NewPayload(e events.Event) Payload {
+	return Payload{
+		Event:   Name(e),
+		Time:    e.Time,
+		Runner:  e.Runner,
+		Status:  e.Status,
+		StageID: e.StageID,
+		Stage:   e.Stage,
+	}
+}
+
+// ValidEvents returns an error if an event is unknown
+func ValidEvents(names []string) error {
+	for _, name := range names {
+		if !contains(Events, name) {
+			return fmt.Errorf("unknown event: %s - use: %s", name, strings.Join(Events, ", "))
+		}
+	}
+	return nil
+}
+
+// Match returns true if the webhook
+// should get the event for the runner
+func Match(webhook api.Webhook, event, runner string) bool {
+	if webhook.Runner != "" && webhook.Runner != runner {
+		return false
+	}
+	if webhook.Events == "" {
+		return true
+	}
+	return contains(strings.Split(webhook.Events, ","), event)
+}
+
+// NewSecret returns a random secret for a webhook
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature for the body (sha256=<hex>)
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if the signature is valid for the body,
+// it is used by the receivers of the events to verify them
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/webhooks"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+// receiver is a webhook-endpoint that verifies the signatures
+// and fails the first requests
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	failures int
+	payloads []webhooks.Payload
+	invalid  int
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	body, _ := ioutil.ReadAll(r.Body)
+	if !webhooks.Verify(rc.secret, body, r.Header.Get(webhooks.SignatureHeader)) {
+		rc.invalid++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if rc.failures > 0 {
+		rc.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var payload webhooks.Payload
+	json.Unmarshal(body, &payload)
+	if payload.Event != r.Header.Get(webhooks.EventHeader) {
+		rc.invalid++
+	}
+	rc.payloads = append(rc.payloads, payload)
+}
+
+func (rc *receiver) events() []string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	var list []string
+	for _, p := range rc.payloads {
+		list = append(list, p.Event+" "+p.Runner)
+	}
+	sort.Strings(list) // a retried event is received after the later events
+	return list
+}
+
+func TestDispatcher(t *testing.T) {
+	is := is.New(t)
+	db, err := gorm.Open("sqlite3", ":memory:")
+	is.NoErr(err)
+	defer db.Close()
+	db.DB().SetMaxOpenConns(1) // every connection gets its own in-memory db
+	is.NoErr(tables.Migrate(db))
+
+	all := &receiver{secret: "secret-all", failures: 1}
+	failed := &receiver{secret: "secret-failed"}
+	down := &receiver{secret: "secret-down", failures: 100}
+	for name, rc := range map[string]*receiver{"all": all, "failed": failed, "down": down} {
+		srv := httptest.NewServer(rc)
+		defer srv.Close()
+		webhook := api.Webhook{Name: name, Endpoint: srv.URL, Secret: rc.secret}
+		if name == "failed" {
+			webhook.Events = webhooks.StageFailed + "," + webhooks.RunnerFailed
+			webhook.Runner = "runner-1"
+		}
+		is.NoErr(db.Create(&webhook).Error)
+	}
+
+	dispatcher := webhooks.New(db, zap.NewNop())
+	dispatcher.Backoff = 10 * time.Millisecond
+	dispatcher.Interval = 10 * time.Millisecond
+	dispatcher.MaxAttempts = 3
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := events.NewBroker(100)
+	go dispatcher.Run(ctx, broker)
+	time.Sleep(50 * time.Millisecond) // let the dispatcher subscribe
+
+	broker.Publish(events.Event{Type: events.TypeRunner, Runner: "runner-1", Status: "Waiting"}) // not a lifecycle-event
+	broker.Publish(events.Event{Type: events.TypeRunner, Runner: "runner-1", Status: "Running"})
+	broker.Publish(events.Event{Type: events.TypeLog, Runner: "runner-1", Message: "log"})
+	broker.Publish(events.Event{Type: events.TypeStage, Runner: "runner-1", StageID: 1, Stage: "OCR", Status: "Failed"})
+	broker.Publish(events.Event{Type: events.TypeStage, Runner: "runner-2", StageID: 2, Stage: "Process", Status: "Failed"})
+	broker.Publish(events.Event{Type: events.TypeRunner, Runner: "runner-2", Status: "Timeout"})
+
+	// wait until every delivery is done
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		var queued, pending int64
+		is.NoErr(db.Model(&api.WebhookDelivery{}).Count(&queued).Error)
+		is.NoErr(db.Model(&api.WebhookDelivery{}).Where("next_attempt_at IS NOT NULL").Count(&pending).Error)
+		if queued == 9 && pending == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of %d deliveries are still pending", pending, queued)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the first delivery to all is retried after the failure
+	is.Equal(all.events(), []string{"runner.started runner-1", "runner.timeout runner-2", "stage.failed runner-1", "stage.failed runner-2"})
+	is.Equal(failed.events(), []string{"stage.failed runner-1"})
+	is.Equal(down.events(), nil)
+	is.Equal(all.invalid+failed.invalid+down.invalid, 0)
+	is.Equal(failed.payloads[0].Stage, "OCR")
+	is.Equal(failed.payloads[0].StageID, uint(1))
+	is.Equal(failed.payloads[0].Status, "Failed")
+
+	// the delivery-log
+	var deliveries []api.WebhookDelivery
+	is.NoErr(db.Order("id").Find(&deliveries).Error)
+	is.Equal(len(deliveries), 9)
+	attempts := map[string][]int64{}
+	for _, d := range deliveries {
+		var webhook api.Webhook
+		is.NoErr(db.First(&webhook, d.WebhookID).Error)
+		attempts[webhook.Name] = append(attempts[webhook.Name], d.Attempts)
+		if webhook.Name == "down" {
+			is.True(!d.Delivered)
+			is.Equal(d.StatusCode, int64(http.StatusInternalServerError))
+			is.True(strings.Contains(d.Exception, "500"))
+			continue
+		}
+		is.True(d.Delivered)
+		is.Equal(d.StatusCode, int64(http.StatusOK))
+		is.Equal(d.Exception, "")
+	}
+	is.Equal(attempts["all"], []int64{2, 1, 1, 1})
+	is.Equal(attempts["failed"], []int64{1})
+	is.Equal(attempts["down"], []int64{3, 3, 3, 3})
+}
+
+func TestMatch(t *testing.T) {
+	is := is.New(t)
+	is.Equal(webhooks.Name(events.Event{Type: events.TypeStage, Status: "Finished"}), webhooks.StageFinished)
+	is.Equal(webhooks.Name(events.Event{Type: events.TypeRunner, Status: "Deleted"}), "")
+	is.Equal(webhooks.Name(events.Event{Type: events.TypeLog, Status: "Failed"}), "")
+
+	webhook := api.Webhook{Events: "runner.failed,stage.failed", Runner: "runner"}
+	is.True(webhooks.Match(webhook, webhooks.RunnerFailed, "runner"))
+	is.True(!webhooks.Match(webhook, webhooks.RunnerStarted, "runner"))
+	is.True(!webhooks.Match(webhook, webhooks.RunnerFailed, "other"))
+	is.True(webhooks.Match(api.Webhook{}, webhooks.StageStarted, "other"))
+
+	is.NoErr(webhooks.ValidEvents([]string{webhooks.RunnerStarted, webhooks.StageTimeout}))
+	is.True(webhooks.ValidEvents([]string{"runner.deleted"}) != nil)
+
+	is.True(webhooks.Verify("secret", []byte("body"), webhooks.Sign("secret", []byte("body"))))
+	is.True(!webhooks.Verify("other", []byte("body"), webhooks.Sign("secret", []byte("body"))))
+}